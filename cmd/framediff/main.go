@@ -0,0 +1,161 @@
+// Command framediff compares two directories of frames captured with the -capture flag, usually from
+// two builds of the engine playing the same replay, and writes a heatmap of the differences per frame.
+//
+//	go run raycast.go -replay demo.json -capture before
+//	(switch to the other build)
+//	go run raycast.go -replay demo.json -capture after
+//	go run ./cmd/framediff -out diff before after
+package main
+
+import (
+	"flag"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// frameDiff is the result of comparing one pair of frames
+type frameDiff struct {
+	name    string
+	changed int
+	total   int
+	maxDiff int
+}
+
+func main() {
+	outDir := flag.String("out", "", "directory to write heatmaps of frames that differ into")
+	tolerance := flag.Int("tolerance", 0, "largest per channel difference that still counts as unchanged")
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: framediff [-out dir] [-tolerance n] <before dir> <after dir>\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() != 2 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	before, after := flag.Arg(0), flag.Arg(1)
+
+	names, err := filepath.Glob(filepath.Join(before, "frame-*.png"))
+	if err != nil {
+		log.Fatal(err)
+	}
+	sort.Strings(names)
+	if len(names) == 0 {
+		log.Fatalf("no captured frames in %s", before)
+	}
+
+	if *outDir != "" {
+		if err := os.MkdirAll(*outDir, 0755); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	differ := 0
+	for _, path := range names {
+		name := filepath.Base(path)
+		a, err := loadPNG(path)
+		if err != nil {
+			log.Fatal(err)
+		}
+		b, err := loadPNG(filepath.Join(after, name))
+		if err != nil {
+			fmt.Printf("%s: missing from %s\n", name, after)
+			differ++
+			continue
+		}
+
+		heat, d := diff(a, b, *tolerance)
+		d.name = name
+		if d.changed == 0 {
+			continue
+		}
+
+		differ++
+		fmt.Printf("%s: %d of %d pixels changed (%.2f%%), max difference %d\n",
+			d.name, d.changed, d.total, 100*float64(d.changed)/float64(d.total), d.maxDiff)
+		if *outDir != "" {
+			if err := savePNG(filepath.Join(*outDir, name), heat); err != nil {
+				log.Fatal(err)
+			}
+		}
+	}
+
+	fmt.Printf("%d of %d frames differ\n", differ, len(names))
+	if differ > 0 {
+		os.Exit(1)
+	}
+}
+
+// diff compares two frames, returning a heatmap over a dimmed copy of the first frame where
+// changed pixels run from blue for small differences to red for large ones
+func diff(a, b image.Image, tolerance int) (*image.RGBA, frameDiff) {
+	r := a.Bounds()
+	heat := image.NewRGBA(r)
+	d := frameDiff{total: r.Dx() * r.Dy()}
+
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		for x := r.Min.X; x < r.Max.X; x++ {
+			ca := color.RGBAModel.Convert(a.At(x, y)).(color.RGBA)
+			var cb color.RGBA
+			if (image.Point{X: x, Y: y}).In(b.Bounds()) {
+				cb = color.RGBAModel.Convert(b.At(x, y)).(color.RGBA)
+			}
+
+			delta := maxInt(absInt(int(ca.R)-int(cb.R)), absInt(int(ca.G)-int(cb.G)), absInt(int(ca.B)-int(cb.B)))
+			if delta <= tolerance {
+				gray := uint8((int(ca.R) + int(ca.G) + int(ca.B)) / 12)
+				heat.SetRGBA(x, y, color.RGBA{gray, gray, gray, 255})
+				continue
+			}
+
+			d.changed++
+			if delta > d.maxDiff {
+				d.maxDiff = delta
+			}
+			heat.SetRGBA(x, y, color.RGBA{uint8(delta), 0, uint8(255 - delta), 255})
+		}
+	}
+
+	return heat, d
+}
+
+func loadPNG(path string) (image.Image, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return png.Decode(f)
+}
+
+func savePNG(path string, img image.Image) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return png.Encode(f, img)
+}
+
+func absInt(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
+
+func maxInt(v ...int) int {
+	m := v[0]
+	for _, n := range v[1:] {
+		if n > m {
+			m = n
+		}
+	}
+	return m
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"go/build"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"raycaster-go/engine/sim"
+)
+
+// runMainEnv makes the test binary run main instead of the tests, so TestHeadlessRun can start it as a
+// separate process the way the command is used.
+const runMainEnv = "HEADLESS_TEST_RUN_MAIN"
+
+// repoPath is the import path the packages of this repository are found under
+const repoPath = "raycaster-go"
+
+func TestMain(m *testing.M) {
+	if os.Getenv(runMainEnv) != "" {
+		os.Args = append([]string{os.Args[0]}, strings.Fields(os.Getenv(runMainEnv))...)
+		main()
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+func TestHeadlessRun(t *testing.T) {
+	if runtime.GOOS == "js" {
+		t.Skip("processes cannot be started on js")
+	}
+	mapFile := filepath.Join(t.TempDir(), "level.json")
+	f, err := os.Create(mapFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := sim.NewEmptyMap(nil, 8, 8).ExportJSON(f); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	cmd := exec.Command(os.Args[0])
+	// no display or graphics driver to open, as on a dedicated server
+	for _, kv := range os.Environ() {
+		if !strings.HasPrefix(kv, "DISPLAY=") && !strings.HasPrefix(kv, "WAYLAND_DISPLAY=") {
+			cmd.Env = append(cmd.Env, kv)
+		}
+	}
+	cmd.Env = append(cmd.Env, runMainEnv+"=-map "+mapFile+" -tps 30 -seconds 1")
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("headless run failed: %v\n%s", err, out)
+	}
+	if !strings.HasPrefix(string(out), "30 ticks in ") {
+		t.Errorf("unexpected output:\n%s", out)
+	}
+}
+
+func TestNoEbitenDependency(t *testing.T) {
+	// walk the imports of the command through the packages of this repository, which go list cannot do
+	// without a module file
+	root := filepath.Join("..", "..")
+	seen := map[string]bool{}
+	var walk func(dir, path string)
+	walk = func(dir, path string) {
+		if seen[dir] {
+			return
+		}
+		seen[dir] = true
+
+		pkg, err := build.ImportDir(dir, 0)
+		if err != nil {
+			t.Fatalf("unable to read package %s: %v", path, err)
+		}
+		for _, imp := range pkg.Imports {
+			switch {
+			case strings.HasPrefix(imp, "github.com/hajimehoshi/ebiten"):
+				t.Errorf("headless command depends on %s through %s", imp, path)
+			case strings.HasPrefix(imp, repoPath+"/"):
+				walk(filepath.Join(root, filepath.FromSlash(strings.TrimPrefix(imp, repoPath+"/"))), imp)
+			}
+		}
+	}
+	walk(".", repoPath+"/cmd/headless")
+
+	if !seen[filepath.Join(root, "engine", "sim")] {
+		t.Error("headless command does not import the sim package")
+	}
+}
@@ -0,0 +1,175 @@
+package engine
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"raycaster-go/engine/raycaster"
+	"raycaster-go/engine/sim"
+	"sort"
+	"time"
+
+	"github.com/hajimehoshi/ebiten"
+)
+
+const (
+	// map name the benchmark scene is played under
+	benchmarkMapName = "benchmark"
+
+	// camera speed along the flythrough in grid cells per tick, and how fast it turns towards the path
+	benchmarkSpeed = 0.08
+	benchmarkTurn  = 0.1
+)
+
+// errBenchmarkDone is returned from Update to stop the game once the benchmark has reported
+var errBenchmarkDone = errors.New("benchmark finished")
+
+// BenchmarkOptions configures the generated stress scene and the flythrough timed in it
+type BenchmarkOptions struct {
+	// MapSize --width and height of the map in grid cells--//
+	MapSize int
+
+	// Sprites --number of sprites scattered over the map--//
+	Sprites int
+
+	// Lights --number of lit floor tiles--//
+	Lights int
+
+	// Width, Height --render resolution, 0 keeps the window size--//
+	Width, Height int
+
+	// Seconds --length of the flythrough--//
+	Seconds float64
+
+	// Seed --random seed for the scene, the same seed always builds the same scene--//
+	Seed int64
+}
+
+// DefaultBenchmarkOptions returns the options of the standard benchmark, results are only comparable
+// between runs with the same options
+func DefaultBenchmarkOptions() BenchmarkOptions {
+	return BenchmarkOptions{MapSize: 24, Sprites: 200, Lights: 24, Seconds: 30, Seed: 1}
+}
+
+// benchmark is a running flythrough and the time taken by each tick of it
+type benchmark struct {
+	opts     BenchmarkOptions
+	follower *sim.PathFollower
+	pos      sim.Vector2
+	dir      sim.Vector2
+	ticks    int
+	times    []time.Duration
+}
+
+// StartBenchmark replaces the map with a generated stress scene and flies the camera around it for the
+// configured time, then prints the average and 99th percentile tick times and stops the game.
+// Tick time covers the update, raycast and draw calls of each tick, rendering is never skipped.
+func (g *Game) StartBenchmark(opts BenchmarkOptions) {
+	if opts.Width > 0 && opts.Height > 0 {
+		g.setResolution(opts.Width, opts.Height)
+	}
+
+	mapObj := sim.NewStressMap(g.tex, sim.StressMapOptions{
+		Size:           opts.MapSize,
+		Sprites:        opts.Sprites,
+		Lights:         opts.Lights,
+		Seed:           opts.Seed,
+		WallTextures:   []int{0, 1, 2, 3, 4},
+		SpriteTextures: []int{9, 10, 14},
+	})
+	g.setMap(benchmarkMapName, mapObj)
+
+	path := mapObj.GetPath(sim.StressPathName)
+	b := &benchmark{opts: opts, follower: sim.NewPathFollower(path, benchmarkSpeed)}
+	b.pos = path.Points[len(path.Points)-1]
+	b.dir = sim.Vector2{X: 1}
+	g.bench = b
+	b.fly(g.camera)
+
+	fmt.Printf("Benchmark: %dx%d map, %d sprites, %d lights, %dx%d for %.0fs\n",
+		opts.MapSize, opts.MapSize, opts.Sprites, opts.Lights, g.width, g.height, opts.Seconds)
+}
+
+// IsBenchmarking returns true while the benchmark flythrough is running
+func (g *Game) IsBenchmarking() bool {
+	return g.bench != nil
+}
+
+// updateBenchmark records the time the tick took and moves the camera along the flythrough,
+// returning errBenchmarkDone after printing the results once the flythrough is over
+func (g *Game) updateBenchmark(start time.Time) error {
+	b := g.bench
+	b.times = append(b.times, time.Since(start))
+	b.ticks++
+	if float64(b.ticks)/float64(ebiten.MaxTPS()) >= b.opts.Seconds {
+		fmt.Println(b.report())
+		return errBenchmarkDone
+	}
+
+	b.fly(g.camera)
+	return nil
+}
+
+// fly advances the flythrough one tick and poses the camera on it, turning smoothly towards the path
+func (b *benchmark) fly(camera *raycaster.Camera) {
+	b.follower.Advance(&b.pos, benchmarkSpeed)
+	target := b.follower.Target()
+	dx, dy := target.X-b.pos.X, target.Y-b.pos.Y
+	if l := math.Hypot(dx, dy); l > 0 {
+		b.dir.X += (dx/l - b.dir.X) * benchmarkTurn
+		b.dir.Y += (dy/l - b.dir.Y) * benchmarkTurn
+	}
+	l := math.Hypot(b.dir.X, b.dir.Y)
+	b.dir.X, b.dir.Y = b.dir.X/l, b.dir.Y/l
+
+	state := camera.Snapshot().Camera
+	planeLen := math.Hypot(state.Plane.X, state.Plane.Y)
+	state.Pos, state.Dir = b.pos, b.dir
+	state.Plane = sim.Vector2{X: b.dir.Y * planeLen, Y: -b.dir.X * planeLen}
+	state.PosZ, state.VelZ, state.Air = 0, 0, 1
+	camera.Restore(&sim.Snapshot{Version: sim.SnapshotVersion, Camera: state}, sim.RestoreCamera)
+}
+
+// report returns the tick count with the average and 99th percentile tick times
+func (b *benchmark) report() string {
+	if len(b.times) == 0 {
+		return "Benchmark: no ticks recorded"
+	}
+
+	sorted := append([]time.Duration(nil), b.times...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	var total time.Duration
+	for _, t := range sorted {
+		total += t
+	}
+	avg := total / time.Duration(len(sorted))
+	p99 := sorted[int(0.99*float64(len(sorted)-1))]
+
+	ms := func(d time.Duration) float64 { return float64(d) / float64(time.Millisecond) }
+	return fmt.Sprintf("Benchmark: %d ticks, average %.2fms (%.0f fps), 99th percentile %.2fms",
+		len(sorted), ms(avg), 1000/math.Max(ms(avg), 0.001), ms(p99))
+}
+
+// setResolution changes the render size, recreating the buffers that depend on it.
+// The world view is made again with the next setMap.
+func (g *Game) setResolution(width, height int) {
+	g.width, g.height = width, height
+	g.frame, _ = ebiten.NewImage(width, height, ebiten.FilterNearest)
+	g.worldFrame, _ = ebiten.NewImage(width, height, ebiten.FilterNearest)
+	g.newBloomBuffers()
+
+	// size dependent images that are made again when next needed
+	g.vignetteImg, g.zoomImg, g.themeFade = nil, nil, nil
+	if g.prevFrame != nil {
+		g.prevFrame = nil
+		g.SetMotionBlur(g.motionBlur)
+	}
+	if pm := g.palette; pm != nil {
+		pm.pixels = make([]byte, 4*width*height)
+		pm.img, _ = ebiten.NewImage(width, height, ebiten.FilterNearest)
+	}
+	if g.layout != nil {
+		g.layout.Resize(width, height)
+	}
+	g.securityViews = nil
+}
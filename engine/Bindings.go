@@ -0,0 +1,137 @@
+package engine
+
+import "github.com/hajimehoshi/ebiten"
+
+// actions the player can bind keys to
+const (
+	ActionForward   = "forward"
+	ActionBackward  = "backward"
+	ActionTurnLeft  = "turnLeft"
+	ActionTurnRight = "turnRight"
+	ActionStrafe    = "strafe"
+	ActionSprint    = "sprint"
+	ActionUp        = "up"
+	ActionDown      = "down"
+	ActionLookUp    = "lookUp"
+	ActionLookDown  = "lookDown"
+	ActionUse       = "use"
+	ActionRespawn   = "respawn"
+	ActionZoom      = "zoom"
+	ActionPause     = "pause"
+	ActionChat      = "chat"
+)
+
+// actionOrder lists the actions in the order the controls menu shows them
+var actionOrder = []string{
+	ActionForward, ActionBackward, ActionTurnLeft, ActionTurnRight, ActionStrafe, ActionSprint,
+	ActionUp, ActionDown, ActionLookUp, ActionLookDown, ActionUse, ActionRespawn, ActionZoom, ActionPause,
+	ActionChat,
+}
+
+// keyNames names the keys that can be bound, all of them recorded in replays
+var keyNames = map[ebiten.Key]string{
+	ebiten.KeyA: "A", ebiten.KeyB: "B", ebiten.KeyC: "C", ebiten.KeyD: "D", ebiten.KeyE: "E", ebiten.KeyF: "F",
+	ebiten.KeyG: "G", ebiten.KeyH: "H", ebiten.KeyI: "I", ebiten.KeyJ: "J", ebiten.KeyK: "K", ebiten.KeyL: "L",
+	ebiten.KeyM: "M", ebiten.KeyN: "N", ebiten.KeyO: "O", ebiten.KeyP: "P", ebiten.KeyQ: "Q", ebiten.KeyR: "R",
+	ebiten.KeyS: "S", ebiten.KeyT: "T", ebiten.KeyU: "U", ebiten.KeyV: "V", ebiten.KeyW: "W", ebiten.KeyX: "X",
+	ebiten.KeyY: "Y", ebiten.KeyZ: "Z",
+	ebiten.Key0: "0", ebiten.Key1: "1", ebiten.Key2: "2", ebiten.Key3: "3", ebiten.Key4: "4",
+	ebiten.Key5: "5", ebiten.Key6: "6", ebiten.Key7: "7", ebiten.Key8: "8", ebiten.Key9: "9",
+	ebiten.KeyUp: "Up", ebiten.KeyDown: "Down", ebiten.KeyLeft: "Left", ebiten.KeyRight: "Right",
+	ebiten.KeyShift: "Shift", ebiten.KeyAlt: "Alt", ebiten.KeyControl: "Ctrl", ebiten.KeySpace: "Space",
+	ebiten.KeyTab: "Tab", ebiten.KeyEnter: "Enter", ebiten.KeyHome: "Home", ebiten.KeyEnd: "End",
+	ebiten.KeyPageUp: "PageUp", ebiten.KeyPageDown: "PageDown",
+}
+
+// Bindings maps each action to the keys that trigger it
+type Bindings map[string][]ebiten.Key
+
+// DefaultBindings returns the keys of the demo: WASD or arrows to move, Alt to strafe, Shift to sprint
+func DefaultBindings() Bindings {
+	return Bindings{
+		ActionForward:   {ebiten.KeyW, ebiten.KeyUp},
+		ActionBackward:  {ebiten.KeyS, ebiten.KeyDown},
+		ActionTurnLeft:  {ebiten.KeyA, ebiten.KeyLeft},
+		ActionTurnRight: {ebiten.KeyD, ebiten.KeyRight},
+		ActionStrafe:    {ebiten.KeyAlt},
+		ActionSprint:    {ebiten.KeyShift},
+		ActionUp:        {ebiten.KeySpace},
+		ActionDown:      {ebiten.KeyC},
+		ActionLookUp:    {ebiten.KeyPageUp},
+		ActionLookDown:  {ebiten.KeyPageDown},
+		ActionUse:       {ebiten.KeyE},
+		ActionRespawn:   {ebiten.KeyR},
+		ActionZoom:      {ebiten.KeyZ},
+		ActionPause:     {ebiten.KeyP},
+		ActionChat:      {ebiten.KeyT},
+	}
+}
+
+// KeyName returns the name of a key shown in menus and stored in settings, empty if it cannot be bound
+func KeyName(k ebiten.Key) string {
+	return keyNames[k]
+}
+
+// keyByName returns the bindable key with the given name
+func keyByName(name string) (ebiten.Key, bool) {
+	for k, n := range keyNames {
+		if n == name {
+			return k, true
+		}
+	}
+	return 0, false
+}
+
+// SetBinding binds an action to a single key, returning false if the key cannot be bound
+func (g *Game) SetBinding(action string, k ebiten.Key) bool {
+	if KeyName(k) == "" {
+		return false
+	}
+	g.bindings[action] = []ebiten.Key{k}
+	return true
+}
+
+// GetBindings returns the keys bound to each action
+func (g *Game) GetBindings() Bindings {
+	return g.bindings
+}
+
+// actionPressed returns true if a key bound to the action is held down this tick
+func (g *Game) actionPressed(action string) bool {
+	return g.actionPressedIn(g.input, action)
+}
+
+// actionJustPressed returns true if a key bound to the action was pressed this tick
+func (g *Game) actionJustPressed(action string) bool {
+	return g.actionJustPressedIn(g.input, action)
+}
+
+// actionPressedIn returns true if a key bound to the action is held down in an input frame other than
+// the one the game is playing, such as the keyboard while a replay plays
+func (g *Game) actionPressedIn(f inputFrame, action string) bool {
+	for _, k := range g.bindings[action] {
+		if f.pressed(k) {
+			return true
+		}
+	}
+	return false
+}
+
+// withoutAction returns an input frame with the keys bound to the action released
+func (g *Game) withoutAction(f inputFrame, action string) inputFrame {
+	for _, k := range g.bindings[action] {
+		f.Pressed &^= keyBit(k)
+		f.Just &^= keyBit(k)
+	}
+	return f
+}
+
+// actionJustPressedIn returns true if a key bound to the action was pressed in an input frame
+func (g *Game) actionJustPressedIn(f inputFrame, action string) bool {
+	for _, k := range g.bindings[action] {
+		if f.justPressed(k) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,104 @@
+package engine
+
+import (
+	"image/color"
+	"raycaster-go/engine/raycaster"
+
+	"github.com/hajimehoshi/ebiten"
+)
+
+const (
+	// bloom is blurred at a fraction of the view size, which also widens the glow
+	bloomDownscale = 4
+
+	// blur radius in downscaled pixels
+	bloomRadius = 2
+
+	// brightness of the glow added back over the view
+	bloomStrength = 0.9
+)
+
+// blacked out slices hide emissive surfaces behind non-emissive ones
+var bloomOccluder = &color.RGBA{0, 0, 0, 255}
+
+// newBloomBuffers creates the offscreen images used by the bloom pass
+func (g *Game) newBloomBuffers() {
+	g.emissiveFrame, _ = ebiten.NewImage(g.width, g.height, ebiten.FilterLinear)
+	g.bloomSmall, _ = ebiten.NewImage(g.width/bloomDownscale, g.height/bloomDownscale, ebiten.FilterLinear)
+	g.bloomBlur, _ = ebiten.NewImage(g.width/bloomDownscale, g.height/bloomDownscale, ebiten.FilterLinear)
+}
+
+// drawEmissive renders only the emissive channel of the view, with everything else drawn black
+func (g *Game) drawEmissive(target *ebiten.Image, v *worldView) {
+	g.spriteBatch.target = target
+
+	//--walls--//
+	for x := 0; x < v.width; x++ {
+		for i := cap(v.levels) - 1; i >= 0; i-- {
+			g.drawEmissiveSlice(v.levels[i], x)
+		}
+	}
+
+	//--sprites--//
+	spriteLvls := v.camera.GetSpriteLevels()
+	for x := 0; x < v.width; x++ {
+		for _, spriteLvl := range spriteLvls {
+			if spriteLvl != nil {
+				g.drawEmissiveSlice(spriteLvl, x)
+			}
+		}
+	}
+}
+
+// drawEmissiveSlice draws the emissive channel of a level slice, or a black slice if it does not glow
+func (g *Game) drawEmissiveSlice(lvl *raycaster.Level, x int) {
+	texture := lvl.CurrTex[x]
+	if texture == nil {
+		return
+	}
+
+	if emissive := g.tex.GetEmissive(texture); emissive != nil {
+		g.spriteBatch.draw(emissive, lvl.Sv[x], lvl.Cts[x], nil)
+	} else {
+		g.spriteBatch.draw(texture, lvl.Sv[x], lvl.Cts[x], bloomOccluder)
+	}
+}
+
+// drawBloom blurs the emissive parts of the world view and adds them over the rendered world
+func (g *Game) drawBloom() {
+	if !g.bloomEnabled || !g.tex.HasEmissive() {
+		return
+	}
+
+	g.emissiveFrame.Clear()
+	g.drawEmissive(g.emissiveFrame, g.shownWorld())
+
+	//--downscale--//
+	g.bloomSmall.Clear()
+	op := &ebiten.DrawImageOptions{}
+	op.Filter = ebiten.FilterLinear
+	op.GeoM.Scale(1.0/bloomDownscale, 1.0/bloomDownscale)
+	g.bloomSmall.DrawImage(g.emissiveFrame, op)
+
+	//--box blur by adding up shifted copies--//
+	g.bloomBlur.Clear()
+	taps := float64((2*bloomRadius + 1) * (2*bloomRadius + 1))
+	for dx := -bloomRadius; dx <= bloomRadius; dx++ {
+		for dy := -bloomRadius; dy <= bloomRadius; dy++ {
+			op := &ebiten.DrawImageOptions{}
+			op.Filter = ebiten.FilterLinear
+			op.GeoM.Translate(float64(dx), float64(dy))
+			op.ColorM.Scale(1/taps, 1/taps, 1/taps, 1)
+			op.CompositeMode = ebiten.CompositeModeLighter
+			g.bloomBlur.DrawImage(g.bloomSmall, op)
+		}
+	}
+
+	//--upscale and add over the world--//
+	op = &ebiten.DrawImageOptions{}
+	op.Filter = ebiten.FilterLinear
+	op.GeoM.Scale(bloomDownscale, bloomDownscale)
+	op.ColorM.Scale(bloomStrength, bloomStrength, bloomStrength, 1)
+	op.CompositeMode = ebiten.CompositeModeLighter
+	g.worldFrame.DrawImage(g.bloomBlur, op)
+}
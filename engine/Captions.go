@@ -0,0 +1,139 @@
+package engine
+
+import (
+	"image/color"
+	"math"
+	"raycaster-go/engine/sim"
+
+	"github.com/hajimehoshi/ebiten"
+	"github.com/hajimehoshi/ebiten/ebitenutil"
+)
+
+const (
+	// seconds a caption stays up before it has faded out
+	captionSeconds = 3.0
+
+	// most captions shown at once, the oldest is dropped first
+	maxCaptions = 3
+
+	// quietest noise that gets a caption
+	captionThreshold = 1.0
+)
+
+var captionBack = color.RGBA{0, 0, 0, 160}
+
+// caption is the text of a heard noise with the direction it came from
+type caption struct {
+	text  string
+	from  sim.Vector2
+	alpha float64
+	fade  *sim.Tween
+}
+
+// captionListener hears noises at the camera position and turns those with a caption into on-screen text
+type captionListener struct {
+	g *Game
+}
+
+// ListenPosition returns the camera position
+func (l *captionListener) ListenPosition() sim.Vector2 {
+	return l.g.camera.GetPosition()
+}
+
+// HearingThreshold returns the quietest noise that is captioned
+func (l *captionListener) HearingThreshold() float64 {
+	return captionThreshold
+}
+
+// OnNoise shows the caption of the noise, if it has one and captions are on
+func (l *captionListener) OnNoise(n sim.Noise, loudness float64) {
+	if l.g.captionsEnabled && n.Caption != "" {
+		l.g.addCaption(n.Caption, n.Pos)
+	}
+}
+
+// SetCaptions turns captions for sounds on or off
+func (g *Game) SetCaptions(enabled bool) {
+	g.captionsEnabled = enabled
+	if !enabled {
+		g.captions = nil
+	}
+}
+
+// IsCaptions returns true if captions for sounds are shown
+func (g *Game) IsCaptions() bool {
+	return g.captionsEnabled
+}
+
+// addCaption shows a caption from the world position, refreshing it if the same text is already up
+func (g *Game) addCaption(text string, from sim.Vector2) {
+	var c *caption
+	for i, existing := range g.captions {
+		if existing.text == text {
+			c = existing
+			g.captions = append(g.captions[:i], g.captions[i+1:]...)
+			break
+		}
+	}
+	if c == nil {
+		c = &caption{text: text}
+		if len(g.captions) >= maxCaptions {
+			g.captions = g.captions[1:]
+		}
+	}
+
+	c.from, c.alpha = from, 1
+	c.fade = sim.TweenFloat(&c.alpha, 0, captionSeconds, sim.EaseInCubic)
+	g.captions = append(g.captions, c)
+}
+
+// captionArrow returns an arrow pointing from the view direction towards the caption source
+func (g *Game) captionArrow(c *caption) string {
+	angle := g.camera.AngleTo(c.from.X, c.from.Y)
+	switch {
+	case math.Abs(angle) < math.Pi/8:
+		return "^"
+	case math.Abs(angle) > math.Pi*7/8:
+		return "v"
+	case angle > 0:
+		return ">"
+	default:
+		return "<"
+	}
+}
+
+// drawCaptions draws the captions stacked above the bottom of the view, newest at the bottom, and advances their fade
+func (g *Game) drawCaptions() {
+	bottom := g.height - dialogueMargin
+	if g.dialogue != nil {
+		bottom -= dialogueHeight + dialogueMargin
+	}
+
+	active := g.captions[:0]
+	y := bottom - lineHeight*len(g.captions)
+	for _, c := range g.captions {
+		text := g.tr(c.text)
+		if arrow := g.captionArrow(c); arrow == ">" {
+			text = text + " " + arrow
+		} else {
+			text = arrow + " " + text
+		}
+
+		w := g.textWidth(text) + 2*dialoguePadding
+		x := (g.width - w) / 2
+		back := captionBack
+		back.A = uint8(float64(back.A) * c.alpha)
+		ebitenutil.DrawRect(g.view, float64(x), float64(y), float64(w), lineHeight, back)
+		if c.alpha > 0.3 {
+			// the debug font can't be faded, so drop the text once the box is mostly gone
+			g.drawText(text, x+dialoguePadding, y)
+		}
+		y += lineHeight
+
+		c.fade = c.fade.Update(1.0 / float64(ebiten.MaxTPS()))
+		if c.fade != nil {
+			active = append(active, c)
+		}
+	}
+	g.captions = active
+}
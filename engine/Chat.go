@@ -0,0 +1,213 @@
+package engine
+
+import (
+	"fmt"
+	"image/color"
+	"strings"
+	"sync"
+
+	"github.com/hajimehoshi/ebiten"
+	"github.com/hajimehoshi/ebiten/ebitenutil"
+	"github.com/hajimehoshi/ebiten/inpututil"
+)
+
+const (
+	// messages kept in the chat log
+	chatHistory = 32
+
+	// messages shown while the chat prompt is closed, and while it is open
+	chatLines     = 4
+	chatOpenLines = 8
+
+	// ticks a message stays up while the prompt is closed, the last of them fading out
+	chatShowTicks = 8 * targetTPS
+	chatFadeTicks = targetTPS
+
+	// longest message that can be typed, in runes
+	chatMaxLength = 120
+
+	// name messages typed here are sent under until SetChatName is called
+	defaultChatName = "Player"
+)
+
+var chatBack = color.RGBA{0, 0, 0, 140}
+
+// ChatMessage is one line of chat
+type ChatMessage struct {
+	From string
+	Text string
+}
+
+// ChatTransport carries chat messages typed in this game to the other players. Messages from them are
+// passed to Game.ReceiveChat. Send is called from the game loop and should not block.
+type ChatTransport interface {
+	Send(msg ChatMessage) error
+}
+
+// chatLine is a message in the chat log and how long it has been up
+type chatLine struct {
+	msg ChatMessage
+	age int
+}
+
+// chat is the multiplayer chat log and prompt, opened with the chat action (T)
+type chat struct {
+	open bool
+	text string
+	name string
+	log  []chatLine
+
+	transport ChatTransport
+
+	//--messages received off the game loop, added to the log on the next update--//
+	mu    sync.Mutex
+	inbox []ChatMessage
+}
+
+// SetChatTransport sets where typed chat messages are sent, nil to only show them in the local log
+func (g *Game) SetChatTransport(t ChatTransport) {
+	g.chat.transport = t
+}
+
+// SetChatName sets the name typed chat messages are sent under
+func (g *Game) SetChatName(name string) {
+	g.chat.name = name
+}
+
+// ReceiveChat adds a message from another player to the chat log. It is safe to call from the goroutine
+// reading the network, the message shows on the next update.
+func (g *Game) ReceiveChat(msg ChatMessage) {
+	c := g.chat
+	c.mu.Lock()
+	c.inbox = append(c.inbox, msg)
+	c.mu.Unlock()
+}
+
+// SendChat adds a message to the chat log and sends it to the other players through the transport
+func (g *Game) SendChat(text string) {
+	c := g.chat
+	msg := ChatMessage{From: c.name, Text: text}
+	c.add(msg)
+	if c.transport == nil {
+		return
+	}
+	if err := c.transport.Send(msg); err != nil {
+		fmt.Printf("Unable to send chat message: %v\n", err)
+	}
+}
+
+// IsChatOpen returns true while a chat message is being typed
+func (g *Game) IsChatOpen() bool {
+	return g.chat.open
+}
+
+// GetChatLog returns the messages in the chat log, oldest first
+func (g *Game) GetChatLog() []ChatMessage {
+	msgs := make([]ChatMessage, len(g.chat.log))
+	for i, l := range g.chat.log {
+		msgs[i] = l.msg
+	}
+	return msgs
+}
+
+// add puts a message at the end of the log, dropping the oldest past the history length
+func (c *chat) add(msg ChatMessage) {
+	c.log = append(c.log, chatLine{msg: msg})
+	if len(c.log) > chatHistory {
+		c.log = c.log[len(c.log)-chatHistory:]
+	}
+}
+
+// updateChat reads the chat prompt from the keyboard. While it is open it takes the keyboard and the
+// game sees no keys pressed, like the console. Typed messages are not part of the input frame, so replays
+// neither record nor send them.
+func (g *Game) updateChat(f *inputFrame) {
+	c := g.chat
+	if g.console.open {
+		return
+	}
+	if !c.open {
+		if g.actionJustPressedIn(*f, ActionChat) && g.menu == nil {
+			c.open = true
+			c.text = ""
+			f.Pressed, f.Just = 0, 0
+		}
+		return
+	}
+	f.Pressed, f.Just = 0, 0
+
+	for _, r := range ebiten.InputChars() {
+		if len([]rune(c.text)) < chatMaxLength {
+			c.text += string(r)
+		}
+	}
+	switch {
+	case inpututil.IsKeyJustPressed(ebiten.KeyBackspace) && len(c.text) > 0:
+		runes := []rune(c.text)
+		c.text = string(runes[:len(runes)-1])
+	case inpututil.IsKeyJustPressed(ebiten.KeyEnter):
+		if text := strings.TrimSpace(c.text); text != "" {
+			g.SendChat(text)
+		}
+		c.open = false
+		c.text = ""
+	case inpututil.IsKeyJustPressed(ebiten.KeyEscape):
+		c.open = false
+		c.text = ""
+	}
+}
+
+// updateChatLog adds the received messages to the log and ages the messages shown
+func (g *Game) updateChatLog() {
+	c := g.chat
+	c.mu.Lock()
+	inbox := c.inbox
+	c.inbox = nil
+	c.mu.Unlock()
+	for _, msg := range inbox {
+		c.add(msg)
+	}
+
+	for i := range c.log {
+		if c.log[i].age < chatShowTicks {
+			c.log[i].age++
+		}
+	}
+}
+
+// drawChat draws the latest chat messages in the bottom left corner, fading them out after a while, and
+// the prompt with more of the log behind it while a message is being typed
+func (g *Game) drawChat() {
+	c := g.chat
+	lines := chatLines
+	if c.open {
+		lines = chatOpenLines
+	}
+	log := c.log
+	if len(log) > lines {
+		log = log[len(log)-lines:]
+	}
+
+	x, y := dialogueMargin, g.height-dialogueMargin-(len(log)+1)*lineHeight
+	for _, l := range log {
+		alpha := 1.0
+		if left := chatShowTicks - l.age; !c.open && left < chatFadeTicks {
+			alpha = float64(left) / chatFadeTicks
+		}
+
+		// faded like captions, the text goes before its backing has faded out
+		text := l.msg.From + ": " + l.msg.Text
+		back := chatBack
+		back.A = uint8(float64(back.A) * alpha)
+		ebitenutil.DrawRect(g.view, float64(x-2), float64(y), float64(g.textWidth(text)+4), lineHeight, back)
+		if alpha > 0.3 {
+			g.drawText(text, x, y)
+		}
+		y += lineHeight
+	}
+	if c.open {
+		prompt := g.tr("chat.prompt") + c.text + "_"
+		ebitenutil.DrawRect(g.view, float64(x-2), float64(y), float64(g.textWidth(prompt)+4), lineHeight, chatBack)
+		g.drawText(prompt, x, y)
+	}
+}
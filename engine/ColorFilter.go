@@ -0,0 +1,139 @@
+package engine
+
+import (
+	"fmt"
+
+	"github.com/hajimehoshi/ebiten"
+)
+
+// ColorFilter is a color blindness filter applied to the final frame
+type ColorFilter int
+
+const (
+	// ColorFilterNone --frame is drawn as is--//
+	ColorFilterNone ColorFilter = iota
+
+	// ColorFilterProtanopia --corrects colors for red blindness--//
+	ColorFilterProtanopia
+
+	// ColorFilterDeuteranopia --corrects colors for green blindness--//
+	ColorFilterDeuteranopia
+
+	// ColorFilterTritanopia --corrects colors for blue blindness--//
+	ColorFilterTritanopia
+
+	// ColorFilterSimulateProtanopia --shows the frame as seen with red blindness, for checking content--//
+	ColorFilterSimulateProtanopia
+
+	// ColorFilterSimulateDeuteranopia --shows the frame as seen with green blindness, for checking content--//
+	ColorFilterSimulateDeuteranopia
+
+	// ColorFilterSimulateTritanopia --shows the frame as seen with blue blindness, for checking content--//
+	ColorFilterSimulateTritanopia
+
+	numColorFilters
+)
+
+type colorMatrix [3][3]float64
+
+var (
+	identityMatrix = colorMatrix{{1, 0, 0}, {0, 1, 0}, {0, 0, 1}}
+
+	//--color blindness simulation matrices--//
+	protanopiaMatrix   = colorMatrix{{0.567, 0.433, 0}, {0.558, 0.442, 0}, {0, 0.242, 0.758}}
+	deuteranopiaMatrix = colorMatrix{{0.625, 0.375, 0}, {0.7, 0.3, 0}, {0, 0.3, 0.7}}
+	tritanopiaMatrix   = colorMatrix{{0.95, 0.05, 0}, {0, 0.433, 0.567}, {0, 0.475, 0.525}}
+
+	// shifts the colors lost by a simulation into channels that are still seen (daltonization)
+	errorShiftMatrix = colorMatrix{{0, 0, 0}, {0.7, 1, 0}, {0.7, 0, 1}}
+)
+
+// String returns the name of the filter for settings menus
+func (f ColorFilter) String() string {
+	switch f {
+	case ColorFilterProtanopia:
+		return "Protanopia"
+	case ColorFilterDeuteranopia:
+		return "Deuteranopia"
+	case ColorFilterTritanopia:
+		return "Tritanopia"
+	case ColorFilterSimulateProtanopia:
+		return "Protanopia (simulated)"
+	case ColorFilterSimulateDeuteranopia:
+		return "Deuteranopia (simulated)"
+	case ColorFilterSimulateTritanopia:
+		return "Tritanopia (simulated)"
+	}
+	return "None"
+}
+
+// matrix returns the color matrix of the filter
+func (f ColorFilter) matrix() colorMatrix {
+	switch f {
+	case ColorFilterProtanopia:
+		return correctionMatrix(protanopiaMatrix)
+	case ColorFilterDeuteranopia:
+		return correctionMatrix(deuteranopiaMatrix)
+	case ColorFilterTritanopia:
+		return correctionMatrix(tritanopiaMatrix)
+	case ColorFilterSimulateProtanopia:
+		return protanopiaMatrix
+	case ColorFilterSimulateDeuteranopia:
+		return deuteranopiaMatrix
+	case ColorFilterSimulateTritanopia:
+		return tritanopiaMatrix
+	}
+	return identityMatrix
+}
+
+// correctionMatrix returns I + E*(I - S), moving the colors the simulation S loses to where they can be seen
+func correctionMatrix(sim colorMatrix) colorMatrix {
+	var lost, result colorMatrix
+	for i := 0; i < 3; i++ {
+		for j := 0; j < 3; j++ {
+			lost[i][j] = identityMatrix[i][j] - sim[i][j]
+		}
+	}
+	for i := 0; i < 3; i++ {
+		for j := 0; j < 3; j++ {
+			result[i][j] = identityMatrix[i][j]
+			for k := 0; k < 3; k++ {
+				result[i][j] += errorShiftMatrix[i][k] * lost[k][j]
+			}
+		}
+	}
+	return result
+}
+
+// SetColorFilter sets the color blindness filter applied to the final frame
+func (g *Game) SetColorFilter(f ColorFilter) {
+	if f < ColorFilterNone || f >= numColorFilters {
+		f = ColorFilterNone
+	}
+	g.colorFilter = f
+}
+
+// GetColorFilter returns the color blindness filter applied to the final frame
+func (g *Game) GetColorFilter() ColorFilter {
+	return g.colorFilter
+}
+
+// cycleColorFilter switches to the next color filter
+func (g *Game) cycleColorFilter() {
+	g.SetColorFilter((g.colorFilter + 1) % numColorFilters)
+	fmt.Printf("Color filter: %v\n", g.colorFilter)
+}
+
+// presentFrame draws the rendered frame to the screen, quantized to the palette if set, through the color filter
+func (g *Game) presentFrame(screen *ebiten.Image) {
+	op := &ebiten.DrawImageOptions{}
+	if g.colorFilter != ColorFilterNone {
+		m := g.colorFilter.matrix()
+		for i := 0; i < 3; i++ {
+			for j := 0; j < 3; j++ {
+				op.ColorM.SetElement(i, j, m[i][j])
+			}
+		}
+	}
+	screen.DrawImage(g.quantizeFrame(), op)
+}
@@ -0,0 +1,134 @@
+package engine
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hajimehoshi/ebiten"
+	"github.com/hajimehoshi/ebiten/inpututil"
+)
+
+// DebugCommand is a named command run from the console or a key chord (e.g. "warp 12 4")
+type DebugCommand struct {
+	// Name --first word of the command line--//
+	Name string
+
+	// Usage --arguments shown in help (e.g. "<x> <y>")--//
+	Usage string
+
+	// Help --one line description--//
+	Help string
+
+	// Enabled --the command can be run, disabled commands are still listed--//
+	Enabled bool
+
+	// Run --runs the command with the words after its name, returning a message for the console--//
+	Run func(g *Game, args []string) (string, error)
+}
+
+// commandChord runs a command line when a combination of keys is pressed
+type commandChord struct {
+	keys []ebiten.Key
+	line string
+}
+
+// CommandRegistry holds the debug commands and the key chords bound to them
+type CommandRegistry struct {
+	commands map[string]*DebugCommand
+	chords   []commandChord
+}
+
+// NewCommandRegistry creates an empty command registry
+func NewCommandRegistry() *CommandRegistry {
+	return &CommandRegistry{commands: make(map[string]*DebugCommand)}
+}
+
+// Register adds a command, replacing any command with the same name
+func (r *CommandRegistry) Register(cmd *DebugCommand) {
+	r.commands[strings.ToLower(cmd.Name)] = cmd
+}
+
+// Get returns the named command, nil if there is none
+func (r *CommandRegistry) Get(name string) *DebugCommand {
+	return r.commands[strings.ToLower(name)]
+}
+
+// SetEnabled enables or disables the named command
+func (r *CommandRegistry) SetEnabled(name string, enabled bool) {
+	if cmd := r.Get(name); cmd != nil {
+		cmd.Enabled = enabled
+	}
+}
+
+// Names returns the names of every command in alphabetical order
+func (r *CommandRegistry) Names() []string {
+	names := make([]string, 0, len(r.commands))
+	for name := range r.commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// BindChord runs the command line when the keys are held together, triggered as the last one is pressed
+func (r *CommandRegistry) BindChord(line string, keys ...ebiten.Key) {
+	if len(keys) == 0 {
+		return
+	}
+	r.chords = append(r.chords, commandChord{keys: keys, line: line})
+}
+
+// chordLines returns the command lines of the chords completed this tick, read straight from the keyboard
+func (r *CommandRegistry) chordLines() []string {
+	var lines []string
+	for _, c := range r.chords {
+		last := c.keys[len(c.keys)-1]
+		if !inpututil.IsKeyJustPressed(last) {
+			continue
+		}
+		held := true
+		for _, k := range c.keys[:len(c.keys)-1] {
+			held = held && ebiten.IsKeyPressed(k)
+		}
+		if held {
+			lines = append(lines, c.line)
+		}
+	}
+	return lines
+}
+
+// GetCommands returns the debug command registry
+func (g *Game) GetCommands() *CommandRegistry {
+	return g.commands
+}
+
+// RunCommand parses and runs a command line, returning the message of the command
+func (g *Game) RunCommand(line string) (string, error) {
+	words := strings.Fields(line)
+	if len(words) == 0 {
+		return "", nil
+	}
+
+	cmd := g.commands.Get(words[0])
+	if cmd == nil {
+		return "", fmt.Errorf("unknown command %q", words[0])
+	}
+	if !cmd.Enabled {
+		return "", fmt.Errorf("command %q is disabled", cmd.Name)
+	}
+	return cmd.Run(g, words[1:])
+}
+
+// runInputCommands runs the command lines entered this tick, printing their results to the console
+func (g *Game) runInputCommands() {
+	for _, line := range g.input.Commands {
+		msg, err := g.RunCommand(line)
+		if err != nil {
+			msg = err.Error()
+		}
+		if msg != "" {
+			g.console.print(msg)
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package engine
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+	"raycaster-go/engine/sim"
+
+	"github.com/hajimehoshi/ebiten/ebitenutil"
+)
+
+const (
+	// compass strip layout
+	compassWidth  = 240
+	compassHeight = 18
+	compassTop    = 4
+
+	// angle covered by the compass strip, in radians
+	compassSpan = math.Pi
+
+	// size of a waypoint marker and how far it is kept from the screen edge
+	waypointSize   = 8
+	waypointMargin = 12
+)
+
+var (
+	compassBack = color.RGBA{0, 0, 0, 140}
+	compassTick = color.RGBA{200, 200, 200, 200}
+)
+
+// compassMarks are the directions labelled on the compass strip, north being towards the top of the map grid
+var compassMarks = []struct {
+	label  string
+	dx, dy float64
+}{
+	{"N", 0, -1}, {"NE", 1, -1}, {"E", 1, 0}, {"SE", 1, 1},
+	{"S", 0, 1}, {"SW", -1, 1}, {"W", -1, 0}, {"NW", -1, -1},
+}
+
+// compassX returns the x position on the compass strip of an angle relative to the view, false if it is off the strip
+func (g *Game) compassX(angle float64) (float64, bool) {
+	if math.Abs(angle) > compassSpan/2 {
+		return 0, false
+	}
+	return float64(g.width)/2 + angle/(compassSpan/2)*compassWidth/2, true
+}
+
+// drawCompass draws the heading strip along the top of the screen with the active waypoints on it
+func (g *Game) drawCompass() {
+	left := float64(g.width-compassWidth) / 2
+	ebitenutil.DrawRect(g.view, left, compassTop, compassWidth, compassHeight, compassBack)
+
+	for _, m := range compassMarks {
+		x, ok := g.compassX(g.camera.RelativeAngle(m.dx, m.dy))
+		if !ok {
+			continue
+		}
+		if len(m.label) == 1 {
+			ebitenutil.DebugPrintAt(g.view, m.label, int(x)-3, compassTop+1)
+		} else {
+			ebitenutil.DrawRect(g.view, x, compassTop+compassHeight-6, 1, 6, compassTick)
+		}
+	}
+
+	for _, w := range g.mapObj.GetActiveWaypoints() {
+		x, ok := g.compassX(g.camera.AngleTo(w.X, w.Y))
+		if !ok {
+			// pin to the end of the strip on the side it is on
+			x = left
+			if g.camera.AngleTo(w.X, w.Y) > 0 {
+				x = left + compassWidth - 4
+			}
+		}
+		ebitenutil.DrawRect(g.view, x-2, compassTop+compassHeight-4, 4, 4, w.Color)
+	}
+
+	// center notch
+	ebitenutil.DrawRect(g.view, float64(g.width)/2, compassTop+compassHeight, 1, 4, compassTick)
+}
+
+// drawWaypoints draws the active waypoint markers where they are in the world,
+// clamped to the screen edges when they are out of view
+func (g *Game) drawWaypoints() {
+	for _, w := range g.mapObj.GetActiveWaypoints() {
+		x, y := g.waypointScreenPos(w)
+
+		ebitenutil.DrawRect(g.view, x-waypointSize/2-1, y-waypointSize/2-1, waypointSize+2, waypointSize+2, compassBack)
+		ebitenutil.DrawRect(g.view, x-waypointSize/2, y-waypointSize/2, waypointSize, waypointSize, w.Color)
+
+		text := fmt.Sprintf("%s %.0fm", g.tr(w.Label), g.camera.DistanceTo(w.X, w.Y))
+		tx := int(x) - g.textWidth(text)/2
+		if tx < 0 {
+			tx = 0
+		} else if tx > g.width-g.textWidth(text) {
+			tx = g.width - g.textWidth(text)
+		}
+		g.drawText(text, tx, int(y)+waypointSize)
+	}
+}
+
+// waypointScreenPos returns where the waypoint marker is drawn on screen
+func (g *Game) waypointScreenPos(w *sim.Waypoint) (float64, float64) {
+	minX, maxX := float64(waypointMargin), float64(g.width-waypointMargin)
+	minY, maxY := float64(compassTop+compassHeight+waypointMargin), float64(g.height-waypointMargin-lineHeight)
+
+	x, y, depth := g.camera.ProjectPoint(w.X, w.Y, w.Z)
+	if depth <= 0 {
+		// behind the camera, hold it at the side it is closest to turn towards
+		x, y = minX, float64(g.height)/2
+		if g.camera.AngleTo(w.X, w.Y) > 0 {
+			x = maxX
+		}
+	}
+
+	return math.Max(minX, math.Min(maxX, x)), math.Max(minY, math.Min(maxY, y))
+}
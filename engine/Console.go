@@ -0,0 +1,99 @@
+package engine
+
+import (
+	"fmt"
+	"image/color"
+	"strings"
+
+	"github.com/hajimehoshi/ebiten"
+	"github.com/hajimehoshi/ebiten/ebitenutil"
+	"github.com/hajimehoshi/ebiten/inpututil"
+)
+
+const (
+	// lines of output shown above the console prompt
+	consoleLines = 8
+
+	// lines of output kept
+	consoleHistory = 64
+)
+
+var consoleBack = color.RGBA{0, 0, 0, 190}
+
+// console is the debug command prompt, toggled with the grave accent key
+type console struct {
+	open   bool
+	text   string
+	output []string
+}
+
+// print adds a message to the console output and standard output
+func (c *console) print(msg string) {
+	fmt.Println(msg)
+	c.output = append(c.output, strings.Split(msg, "\n")...)
+	if len(c.output) > consoleHistory {
+		c.output = c.output[len(c.output)-consoleHistory:]
+	}
+}
+
+// updateConsole reads the console prompt and command key chords from the keyboard, adding entered
+// command lines to the input frame so replays run them too. While the console is open it takes
+// the keyboard and the game sees no keys pressed.
+func (g *Game) updateConsole(f *inputFrame) {
+	if len(g.commands.commands) == 0 {
+		return
+	}
+	c := g.console
+
+	if inpututil.IsKeyJustPressed(ebiten.KeyGraveAccent) {
+		c.open = !c.open
+		c.text = ""
+		f.Pressed, f.Just = 0, 0
+		return
+	}
+	if !c.open {
+		f.Commands = append(f.Commands, g.commands.chordLines()...)
+		return
+	}
+	f.Pressed, f.Just = 0, 0
+
+	for _, r := range ebiten.InputChars() {
+		if r != '`' && r != '~' {
+			c.text += string(r)
+		}
+	}
+	switch {
+	case inpututil.IsKeyJustPressed(ebiten.KeyBackspace) && len(c.text) > 0:
+		runes := []rune(c.text)
+		c.text = string(runes[:len(runes)-1])
+	case inpututil.IsKeyJustPressed(ebiten.KeyEnter):
+		if strings.TrimSpace(c.text) != "" {
+			c.output = append(c.output, "> "+c.text)
+			f.Commands = append(f.Commands, c.text)
+		}
+		c.text = ""
+	case inpututil.IsKeyJustPressed(ebiten.KeyEscape):
+		c.open = false
+		c.text = ""
+	}
+}
+
+// drawConsole draws the console output and prompt across the top of the view while it is open
+func (g *Game) drawConsole() {
+	c := g.console
+	if !c.open {
+		return
+	}
+
+	const lineHeight = 16
+	ebitenutil.DrawRect(g.view, 0, 0, float64(g.width), float64((consoleLines+1)*lineHeight+8), consoleBack)
+
+	lines := c.output
+	if len(lines) > consoleLines {
+		lines = lines[len(lines)-consoleLines:]
+	}
+	for i, line := range lines {
+		g.drawText(line, 4, 4+i*lineHeight)
+	}
+	g.drawText("] "+c.text+"_", 4, 4+consoleLines*lineHeight)
+}
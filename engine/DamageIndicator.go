@@ -0,0 +1,81 @@
+package engine
+
+import (
+	"image/color"
+	"math"
+	"raycaster-go/engine/sim"
+
+	"github.com/hajimehoshi/ebiten"
+	"github.com/hajimehoshi/ebiten/ebitenutil"
+)
+
+const (
+	// seconds a damage indicator takes to fade out
+	damageIndicatorSeconds = 1.5
+
+	// distance of the indicator arc from the screen center, as a fraction of the view height
+	damageIndicatorRadius = 0.3
+
+	// angle covered by the indicator arc in radians, and the number of blocks it is drawn with
+	damageIndicatorSpan     = 0.5
+	damageIndicatorSegments = 9
+	damageIndicatorSize     = 5
+
+	// hits from sources closer together than this refresh the same indicator
+	damageIndicatorMerge = 0.5
+)
+
+var damageIndicatorColor = color.RGBA{220, 20, 20, 200}
+
+// damageIndicator points from the screen center towards where damage came from
+type damageIndicator struct {
+	from  sim.Vector2
+	alpha float64
+	fade  *sim.Tween
+}
+
+// addDamageIndicator shows a fading indicator towards the world position damage came from
+func (g *Game) addDamageIndicator(from sim.Vector2) {
+	var d *damageIndicator
+	for _, existing := range g.damageIndicators {
+		if math.Hypot(existing.from.X-from.X, existing.from.Y-from.Y) < damageIndicatorMerge {
+			d = existing
+			break
+		}
+	}
+	if d == nil {
+		d = &damageIndicator{}
+		g.damageIndicators = append(g.damageIndicators, d)
+	}
+
+	d.from, d.alpha = from, 1
+	d.fade = sim.TweenFloat(&d.alpha, 0, damageIndicatorSeconds, sim.EaseInQuad)
+}
+
+// drawDamageIndicators draws an arc around the screen center towards each damage source and advances their fade.
+// The angle is worked out every frame so the indicators follow as the camera turns.
+func (g *Game) drawDamageIndicators() {
+	cx, cy := float64(g.width)/2, float64(g.height)/2
+	radius := float64(g.height) * damageIndicatorRadius
+
+	active := g.damageIndicators[:0]
+	for _, d := range g.damageIndicators {
+		// straight ahead is up on the screen
+		angle := g.camera.AngleTo(d.from.X, d.from.Y)
+		clr := damageIndicatorColor
+		clr.A = uint8(float64(clr.A) * d.alpha)
+
+		for i := 0; i < damageIndicatorSegments; i++ {
+			a := angle + damageIndicatorSpan*(float64(i)/(damageIndicatorSegments-1)-0.5)
+			x := cx + radius*math.Sin(a)
+			y := cy - radius*math.Cos(a)
+			ebitenutil.DrawRect(g.view, x-damageIndicatorSize/2, y-damageIndicatorSize/2, damageIndicatorSize, damageIndicatorSize, clr)
+		}
+
+		d.fade = d.fade.Update(1.0 / float64(ebiten.MaxTPS()))
+		if d.fade != nil {
+			active = append(active, d)
+		}
+	}
+	g.damageIndicators = active
+}
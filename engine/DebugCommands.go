@@ -0,0 +1,228 @@
+//go:build !release
+// +build !release
+
+package engine
+
+import (
+	"fmt"
+	"raycaster-go/engine/sim"
+	"strconv"
+	"strings"
+
+	"github.com/hajimehoshi/ebiten"
+)
+
+// seconds a given status effect lasts when no duration is passed
+const giveEffectSeconds = 10.0
+
+// registerDebugCommands adds the built in debug commands, left out of builds with the release tag
+func registerDebugCommands(r *CommandRegistry) {
+	r.Register(&DebugCommand{
+		Name: "help", Help: "list the commands", Enabled: true,
+		Run: func(g *Game, args []string) (string, error) {
+			var lines []string
+			for _, name := range g.commands.Names() {
+				cmd := g.commands.Get(name)
+				line := strings.TrimSpace(cmd.Name + " " + cmd.Usage)
+				if !cmd.Enabled {
+					line += " (disabled)"
+				}
+				lines = append(lines, fmt.Sprintf("%-16s %s", line, cmd.Help))
+			}
+			return strings.Join(lines, "\n"), nil
+		},
+	})
+
+	r.Register(&DebugCommand{
+		Name: "god", Help: "toggle taking damage", Enabled: true,
+		Run: func(g *Game, args []string) (string, error) {
+			g.camera.SetInvulnerable(!g.camera.IsInvulnerable())
+			return onOff("god mode", g.camera.IsInvulnerable()), nil
+		},
+	})
+
+	r.Register(&DebugCommand{
+		Name: "noclip", Help: "toggle moving through walls", Enabled: true,
+		Run: func(g *Game, args []string) (string, error) {
+			g.camera.SetNoClip(!g.camera.IsNoClip())
+			return onOff("noclip", g.camera.IsNoClip()), nil
+		},
+	})
+
+	r.Register(&DebugCommand{
+		Name: "give", Usage: "<air|haste|slow|blind|poison> [seconds]", Help: "refill air or add a status effect", Enabled: true,
+		Run: func(g *Game, args []string) (string, error) {
+			if len(args) == 0 {
+				return "", fmt.Errorf("give what?")
+			}
+			seconds := giveEffectSeconds
+			if len(args) > 1 {
+				s, err := strconv.ParseFloat(args[1], 64)
+				if err != nil {
+					return "", fmt.Errorf("invalid seconds %q", args[1])
+				}
+				seconds = s
+			}
+
+			var effect *sim.StatusEffect
+			switch strings.ToLower(args[0]) {
+			case "air":
+				g.camera.RefillAir()
+				return "air refilled", nil
+			case "haste":
+				effect = sim.NewHasteEffect(seconds)
+			case "slow":
+				effect = sim.NewSlowEffect(seconds)
+			case "blind":
+				effect = sim.NewBlindEffect(seconds)
+			case "poison":
+				effect = sim.NewPoisonEffect(seconds, 1)
+			default:
+				return "", fmt.Errorf("nothing called %q to give", args[0])
+			}
+			g.camera.AddEffect(effect)
+			return fmt.Sprintf("%s for %.0fs", effect.Name, seconds), nil
+		},
+	})
+
+	r.Register(&DebugCommand{
+		Name: "warp", Usage: "<x> <y> [heading]", Help: "move to a grid position, facing a heading in degrees", Enabled: true,
+		Run: func(g *Game, args []string) (string, error) {
+			if len(args) != 2 && len(args) != 3 {
+				return "", fmt.Errorf("usage: warp <x> <y> [heading]")
+			}
+			x, errX := strconv.ParseFloat(args[0], 64)
+			y, errY := strconv.ParseFloat(args[1], 64)
+			if errX != nil || errY != nil {
+				return "", fmt.Errorf("invalid position %s %s", args[0], args[1])
+			}
+			w, h := g.mapObj.GetSize()
+			if x < 0 || y < 0 || x >= float64(w) || y >= float64(h) {
+				return "", fmt.Errorf("position %.1f %.1f is outside the %dx%d map", x, y, w, h)
+			}
+			g.camera.Warp(sim.Vector2{X: x, Y: y})
+			if len(args) == 3 {
+				heading, err := strconv.ParseFloat(args[2], 64)
+				if err != nil {
+					return "", fmt.Errorf("invalid heading %s", args[2])
+				}
+				g.camera.SetHeading(heading)
+			}
+			return fmt.Sprintf("warped to %.1f %.1f facing %.0f", x, y, g.camera.GetHeading()), nil
+		},
+	})
+
+	r.Register(&DebugCommand{
+		Name: "warpshot", Usage: "<file>", Help: "move to where a screenshot was taken", Enabled: true,
+		Run: func(g *Game, args []string) (string, error) {
+			if len(args) != 1 {
+				return "", fmt.Errorf("usage: warpshot <file>")
+			}
+			if err := g.WarpToScreenshot(args[0]); err != nil {
+				return "", err
+			}
+			return "warped to " + args[0], nil
+		},
+	})
+
+	r.Register(&DebugCommand{
+		Name: "fov", Usage: "[degrees]", Help: "show or set the field of view", Enabled: true,
+		Run: func(g *Game, args []string) (string, error) {
+			if len(args) > 0 {
+				degrees, err := strconv.ParseFloat(args[0], 64)
+				if err != nil {
+					return "", fmt.Errorf("invalid field of view %q", args[0])
+				}
+				g.camera.SetFOV(degrees)
+			}
+			return fmt.Sprintf("field of view %.1f degrees", g.camera.GetFOV()), nil
+		},
+	})
+
+	r.Register(&DebugCommand{
+		Name: "settile", Usage: "<x> <y> <level> <value>", Help: "change a wall cell of the map", Enabled: true,
+		Run: func(g *Game, args []string) (string, error) {
+			if len(args) < 4 {
+				return "", fmt.Errorf("settile needs x, y, level and value")
+			}
+			var values [4]int
+			for i := range values {
+				v, err := strconv.Atoi(args[i])
+				if err != nil {
+					return "", fmt.Errorf("invalid number %q", args[i])
+				}
+				values[i] = v
+			}
+			x, y, level, value := values[0], values[1], values[2], values[3]
+			g.mapObj.SetTile(x, y, level, value)
+			return fmt.Sprintf("tile %d,%d level %d is %d", x, y, level, g.mapObj.GetTile(x, y, level)), nil
+		},
+	})
+
+	r.Register(&DebugCommand{
+		Name: "observe", Usage: "[camera]", Help: "watch a security camera full screen, or stop watching", Enabled: true,
+		Run: func(g *Game, args []string) (string, error) {
+			g.layout.Remove(observerName)
+			if len(args) == 0 {
+				return "observer off", nil
+			}
+			if g.mapObj.GetSecurityCamera(args[0]) == nil {
+				return "", fmt.Errorf("no security camera %q", args[0])
+			}
+			v := g.NewSecurityViewport(args[0])
+			v.Name, v.Anchor, v.Width, v.Height, v.Margin = observerName, AnchorTopLeft, 1, 1, 0
+			g.layout.Add(v)
+			return "observing " + args[0], nil
+		},
+	})
+
+	r.Register(&DebugCommand{
+		Name: "viewport", Usage: "[name]", Help: "list the viewports or show and hide one", Enabled: true,
+		Run: func(g *Game, args []string) (string, error) {
+			if len(args) == 0 {
+				var names []string
+				for _, v := range g.layout.GetViewports() {
+					names = append(names, v.Name)
+				}
+				return "viewports " + strings.Join(names, ", "), nil
+			}
+			v := g.layout.Get(args[0])
+			if v == nil {
+				return "", fmt.Errorf("no viewport %q", args[0])
+			}
+			v.Hidden = !v.Hidden
+			return onOff(v.Name, !v.Hidden), nil
+		},
+	})
+
+	r.Register(&DebugCommand{
+		Name: "lang", Usage: "[language]", Help: "show or select the language of engine text", Enabled: true,
+		Run: func(g *Game, args []string) (string, error) {
+			if len(args) > 0 {
+				if err := g.SetLanguage(args[0]); err != nil {
+					return "", err
+				}
+			}
+			return fmt.Sprintf("language %s of %s", g.locale.GetLanguage(), strings.Join(g.locale.GetLanguages(), ", ")), nil
+		},
+	})
+
+	r.Register(&DebugCommand{
+		Name: "showfps", Help: "toggle the TPS counter", Enabled: true,
+		Run: func(g *Game, args []string) (string, error) {
+			g.showFPS = !g.showFPS
+			return onOff("TPS counter", g.showFPS), nil
+		},
+	})
+
+	r.BindChord("god", ebiten.KeyControl, ebiten.KeyShift, ebiten.KeyG)
+	r.BindChord("noclip", ebiten.KeyControl, ebiten.KeyShift, ebiten.KeyN)
+}
+
+// onOff describes a toggled setting
+func onOff(name string, on bool) string {
+	if on {
+		return name + " on"
+	}
+	return name + " off"
+}
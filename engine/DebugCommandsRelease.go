@@ -0,0 +1,7 @@
+//go:build release
+// +build release
+
+package engine
+
+// registerDebugCommands leaves out the debug commands in builds with the release tag
+func registerDebugCommands(r *CommandRegistry) {}
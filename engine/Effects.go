@@ -0,0 +1,125 @@
+package engine
+
+import (
+	"image/color"
+	"math"
+	"raycaster-go/engine/sim"
+
+	"github.com/hajimehoshi/ebiten"
+	"github.com/hajimehoshi/ebiten/ebitenutil"
+)
+
+var (
+	// tint used while the camera eye is below water
+	underwaterTint = color.RGBA{0, 40, 120, 110}
+
+	// flash used when taking damage
+	damageTint = color.RGBA{200, 0, 0, 90}
+
+	// air meter colors
+	airBarBack = color.RGBA{0, 0, 0, 150}
+	airBarFill = color.RGBA{120, 200, 255, 220}
+
+	// turn vignette color at full strength
+	vignetteColor = color.RGBA{0, 0, 0, 200}
+)
+
+const (
+	// scale of the world frame while it is moved, to hide the edges
+	viewOverscan = 1.08
+
+	// turn speed in radians per tick at which the vignette is fully dark
+	vignetteTurnSpeed = 0.04
+
+	// width of the vignette on each side as a fraction of the view width, and the number of bands it fades over
+	vignetteWidth = 0.2
+	vignetteBands = 6
+)
+
+// drawScreenTint blends a translucent color over the whole view
+func (g *Game) drawScreenTint(clr color.RGBA) {
+	ebitenutil.DrawRect(g.view, 0, 0, float64(g.width), float64(g.height), clr)
+}
+
+// screenFlash is a full-screen tint that fades out
+type screenFlash struct {
+	clr   color.RGBA
+	alpha float64
+	fade  *sim.Tween
+}
+
+// flashScreen starts a full-screen tint that fades out over the given number of ticks
+func (g *Game) flashScreen(clr color.RGBA, ticks int) {
+	f := &screenFlash{clr: clr, alpha: 1}
+	f.fade = sim.TweenFloat(&f.alpha, 0, float64(ticks)/float64(ebiten.MaxTPS()), sim.EaseOutQuad)
+	g.flash = f
+}
+
+// drawScreenFlash draws the current screen flash, if any, and advances its fade
+func (g *Game) drawScreenFlash() {
+	f := g.flash
+	if f == nil {
+		return
+	}
+
+	clr := f.clr
+	clr.A = uint8(float64(clr.A) * f.alpha)
+	g.drawScreenTint(clr)
+
+	f.fade = f.fade.Update(1.0 / float64(ebiten.MaxTPS()))
+	if f.fade == nil {
+		g.flash = nil
+	}
+}
+
+// drawAirMeter draws the air meter at the bottom center of the view while it is not full
+func (g *Game) drawAirMeter() {
+	air := g.camera.GetAir()
+	if air >= 1 {
+		return
+	}
+
+	barW, barH := 200.0, 8.0
+	barX := (float64(g.width) - barW) / 2
+	barY := float64(g.height) - 3*barH
+
+	ebitenutil.DrawRect(g.view, barX-1, barY-1, barW+2, barH+2, airBarBack)
+	ebitenutil.DrawRect(g.view, barX, barY, barW*air, barH, airBarFill)
+}
+
+// drawWorldFrame draws the rendered world into the view, moved by the camera head bob and shake.
+// The world is scaled up slightly around the center so the moved edges stay covered.
+func (g *Game) drawWorldFrame() {
+	offsetX, offsetY := g.camera.GetViewOffset()
+
+	op := &ebiten.DrawImageOptions{}
+	if offsetX != 0 || offsetY != 0 {
+		w, h := float64(g.width), float64(g.height)
+		op.GeoM.Translate(-w/2, -h/2)
+		op.GeoM.Scale(viewOverscan, viewOverscan)
+		op.GeoM.Translate(w/2+offsetX*h, h/2+offsetY*h)
+	}
+	g.view.DrawImage(g.worldFrame, op)
+}
+
+// drawTurnVignette darkens the left and right edges of the view while turning, if enabled for comfort
+func (g *Game) drawTurnVignette() {
+	if !g.camera.GetComfort().TurnVignette {
+		return
+	}
+
+	strength := math.Min(1, math.Abs(g.camera.GetTurnSpeed())/vignetteTurnSpeed)
+	if strength <= 0 {
+		return
+	}
+
+	// bands get lighter towards the center
+	bandW := float64(g.width) * vignetteWidth / vignetteBands
+	for i := 0; i < vignetteBands; i++ {
+		clr := vignetteColor
+		clr.A = uint8(float64(clr.A) * strength * float64(vignetteBands-i) / vignetteBands)
+
+		ebitenutil.DrawRect(g.view, float64(i)*bandW, 0, bandW, float64(g.height), clr)
+		ebitenutil.DrawRect(g.view, float64(g.width)-float64(i+1)*bandW, 0, bandW, float64(g.height), clr)
+	}
+}
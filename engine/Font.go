@@ -0,0 +1,104 @@
+package engine
+
+import (
+	"fmt"
+	"image"
+	"unicode/utf8"
+
+	"github.com/hajimehoshi/ebiten"
+	"github.com/hajimehoshi/ebiten/ebitenutil"
+)
+
+const (
+	// width of a glyph of the debug font
+	debugGlyphWidth = 6
+)
+
+// BitmapFont draws text from a sheet of fixed size glyphs laid out in rows, for text the debug font
+// cannot show since it only has ASCII. Glyphs should be at most a line (16 pixels) tall.
+type BitmapFont struct {
+	sheet   *ebiten.Image
+	glyphW  int
+	glyphH  int
+	columns int
+	glyphs  map[rune]int
+}
+
+// NewBitmapFont creates a font from a glyph sheet, runes lists the characters of the sheet in order
+// from the top left
+func NewBitmapFont(sheet *ebiten.Image, glyphW, glyphH int, runes string) (*BitmapFont, error) {
+	w, h := sheet.Size()
+	if glyphW <= 0 || glyphH <= 0 || w < glyphW {
+		return nil, fmt.Errorf("invalid glyph size %dx%d", glyphW, glyphH)
+	}
+	f := &BitmapFont{sheet: sheet, glyphW: glyphW, glyphH: glyphH, columns: w / glyphW, glyphs: make(map[rune]int)}
+	i := 0
+	for _, r := range runes {
+		f.glyphs[r] = i
+		i++
+	}
+	if rows := (i + f.columns - 1) / f.columns; rows*glyphH > h {
+		return nil, fmt.Errorf("glyph sheet has room for %d glyphs, %d listed", (h/glyphH)*f.columns, i)
+	}
+	return f, nil
+}
+
+// LoadBitmapFont loads a font from a glyph sheet image file
+func LoadBitmapFont(path string, glyphW, glyphH int, runes string) (*BitmapFont, error) {
+	sheet, _, err := ebitenutil.NewImageFromFile(path, ebiten.FilterNearest)
+	if err != nil {
+		return nil, err
+	}
+	return NewBitmapFont(sheet, glyphW, glyphH, runes)
+}
+
+// Draw draws text with its top left at x, y. Characters missing from the font are drawn as '?' if it has
+// one and left blank otherwise, and newlines start a new line.
+func (f *BitmapFont) Draw(dst *ebiten.Image, text string, x, y int) {
+	tx := x
+	for _, r := range text {
+		if r == '\n' {
+			tx, y = x, y+lineHeight
+			continue
+		}
+		i, ok := f.glyphs[r]
+		if !ok {
+			i, ok = f.glyphs['?']
+		}
+		if ok {
+			gx, gy := (i%f.columns)*f.glyphW, (i/f.columns)*f.glyphH
+			glyph := f.sheet.SubImage(image.Rect(gx, gy, gx+f.glyphW, gy+f.glyphH)).(*ebiten.Image)
+			op := &ebiten.DrawImageOptions{}
+			op.GeoM.Translate(float64(tx), float64(y))
+			dst.DrawImage(glyph, op)
+		}
+		tx += f.glyphW
+	}
+}
+
+// Width returns the width of a line of text
+func (f *BitmapFont) Width(text string) int {
+	return utf8.RuneCountInString(text) * f.glyphW
+}
+
+// SetFont sets the font the HUD, console, dialogue and menus draw text with, nil for the debug font
+func (g *Game) SetFont(f *BitmapFont) {
+	g.font = f
+}
+
+// drawText draws text with the font of the game, or the debug font if none is set
+func (g *Game) drawText(text string, x, y int) {
+	if g.font != nil {
+		g.font.Draw(g.view, text, x, y)
+		return
+	}
+	ebitenutil.DebugPrintAt(g.view, text, x, y)
+}
+
+// textWidth returns the width of a line of text in the font of the game
+func (g *Game) textWidth(text string) int {
+	if g.font != nil {
+		return g.font.Width(text)
+	}
+	return utf8.RuneCountInString(text) * debugGlyphWidth
+}
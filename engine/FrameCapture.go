@@ -0,0 +1,60 @@
+package engine
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+
+	"github.com/hajimehoshi/ebiten"
+)
+
+// SetFrameCapture turns frame capture on, writing every rendered frame as a numbered png into dir.
+// Frames are never skipped while capturing and the TPS counter is hidden, so two builds played on the
+// same replay produce frames that can be compared one to one with the framediff tool.
+// An empty dir turns it off.
+func (g *Game) SetFrameCapture(dir string) error {
+	g.captureDir = dir
+	g.captureFrame = 0
+	if dir == "" {
+		return nil
+	}
+	return os.MkdirAll(dir, 0755)
+}
+
+// IsCapturingFrames returns true while rendered frames are written to disk
+func (g *Game) IsCapturingFrames() bool {
+	return g.captureDir != ""
+}
+
+// writeCapturedFrame writes the final screen image to the capture directory
+func (g *Game) writeCapturedFrame(screen *ebiten.Image) {
+	g.captureFrame++
+	img := screenRGBA(screen)
+
+	path := filepath.Join(g.captureDir, fmt.Sprintf("frame-%05d.png", g.captureFrame))
+	f, err := os.Create(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Unable to capture frame: %v\n", err)
+		return
+	}
+	defer f.Close()
+
+	if err := png.Encode(f, img); err != nil {
+		fmt.Fprintf(os.Stderr, "Unable to capture frame: %v\n", err)
+	}
+}
+
+// screenRGBA reads the screen image back into memory
+func screenRGBA(screen *ebiten.Image) *image.RGBA {
+	b := screen.Bounds()
+	img := image.NewRGBA(b)
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			img.Set(x, y, color.RGBAModel.Convert(screen.At(x, y)))
+		}
+	}
+	return img
+}
@@ -0,0 +1,185 @@
+package engine
+
+import (
+	"fmt"
+	"image/color"
+	"runtime/debug"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/ebitenutil"
+)
+
+const (
+	// number of recent frames kept for the histogram
+	frameStatsHistory = 240
+
+	// histogram bins and the frame time each bin covers in milliseconds, the last bin holds everything slower
+	frameStatsBins  = 20
+	frameStatsBinMs = 2.0
+
+	// a frame taking longer than the budget by this factor is logged as a spike
+	spikeFactor = 1.5
+
+	// number of spikes kept in the log
+	spikeLogSize = 6
+
+	// histogram panel layout
+	histogramWidth  = 160
+	histogramHeight = 48
+)
+
+var (
+	histogramBack   = color.RGBA{0, 0, 0, 160}
+	histogramBar    = color.RGBA{80, 200, 120, 220}
+	histogramOver   = color.RGBA{230, 80, 60, 220}
+	histogramBudget = color.RGBA{255, 255, 255, 180}
+)
+
+// framePass is the time one named pass of a frame took
+type framePass struct {
+	name     string
+	duration time.Duration
+}
+
+// frameSpike records a frame that went over budget and the pass that took the longest in it
+type frameSpike struct {
+	frame   int
+	total   time.Duration
+	slowest framePass
+	gc      bool
+}
+
+// frameStats keeps a rolling history of frame times and a log of spikes with the pass that caused them
+type frameStats struct {
+	times []time.Duration
+	next  int
+	frame int
+
+	// frames counted per histogram bin, kept up to date as times are replaced
+	bins [frameStatsBins]int
+
+	start  time.Time
+	passes []framePass
+
+	spikes []frameSpike
+
+	gcStats debug.GCStats
+	numGC   int64
+}
+
+// newFrameStats creates empty frame statistics
+func newFrameStats() *frameStats {
+	return &frameStats{times: make([]time.Duration, 0, frameStatsHistory)}
+}
+
+// frameBin returns the histogram bin of a frame time
+func frameBin(d time.Duration) int {
+	bin := int(float64(d) / float64(time.Millisecond) / frameStatsBinMs)
+	if bin >= frameStatsBins {
+		bin = frameStatsBins - 1
+	}
+	return bin
+}
+
+// beginFrame ends the frame in progress, if any, logging it as a spike if it went over budget, and starts the next
+func (s *frameStats) beginFrame(budget time.Duration) {
+	now := time.Now()
+	if !s.start.IsZero() {
+		s.endFrame(now.Sub(s.start), budget)
+	}
+	s.start = now
+	s.passes = s.passes[:0]
+}
+
+// endFrame adds the frame time to the history and logs a spike
+func (s *frameStats) endFrame(total, budget time.Duration) {
+	if len(s.times) < frameStatsHistory {
+		s.times = append(s.times, total)
+	} else {
+		s.bins[frameBin(s.times[s.next])]--
+		s.times[s.next] = total
+		s.next = (s.next + 1) % frameStatsHistory
+	}
+	s.bins[frameBin(total)]++
+	s.frame++
+
+	// garbage collections since the last frame, the pause list is reused between reads
+	debug.ReadGCStats(&s.gcStats)
+	gc := s.gcStats.NumGC != s.numGC
+	s.numGC = s.gcStats.NumGC
+
+	if float64(total) <= float64(budget)*spikeFactor {
+		return
+	}
+
+	spike := frameSpike{frame: s.frame, total: total, gc: gc}
+	for _, p := range s.passes {
+		if p.duration > spike.slowest.duration {
+			spike.slowest = p
+		}
+	}
+	if len(s.spikes) >= spikeLogSize {
+		s.spikes = s.spikes[1:]
+	}
+	s.spikes = append(s.spikes, spike)
+}
+
+// pass records the time since start as a named pass of the current frame
+func (s *frameStats) pass(name string, start time.Time) {
+	s.passes = append(s.passes, framePass{name: name, duration: time.Since(start)})
+}
+
+// drawFrameStats draws the frame time histogram and the spike log under the TPS counter
+func (g *Game) drawFrameStats() {
+	s := g.frameStats
+	x, y := 4.0, float64(lineHeight+4)
+	ebitenutil.DrawRect(g.view, x, y, histogramWidth, histogramHeight, histogramBack)
+
+	max := 1
+	for _, n := range s.bins {
+		if n > max {
+			max = n
+		}
+	}
+
+	budgetMs := 1000 / float64(targetTPS)
+	binW := float64(histogramWidth) / frameStatsBins
+	for i, n := range s.bins {
+		if n == 0 {
+			continue
+		}
+		clr := histogramBar
+		if float64(i)*frameStatsBinMs >= budgetMs*spikeFactor {
+			clr = histogramOver
+		}
+		h := float64(n) / float64(max) * (histogramHeight - 2)
+		ebitenutil.DrawRect(g.view, x+float64(i)*binW, y+histogramHeight-h, binW-1, h, clr)
+	}
+
+	// frame budget marker
+	bx := x + budgetMs/frameStatsBinMs*binW
+	ebitenutil.DrawRect(g.view, bx, y, 1, histogramHeight, histogramBudget)
+
+	ty := int(y) + histogramHeight + 2
+	ebitenutil.DebugPrintAt(g.view, fmt.Sprintf("0-%.0fms, budget %.1fms", frameStatsBins*frameStatsBinMs, budgetMs), int(x), ty)
+	ty += lineHeight
+	texLine := fmt.Sprintf("textures %.1fMB", float64(g.tex.MemoryUsed())/(1<<20))
+	if budget := g.tex.GetMemoryBudget(); budget > 0 {
+		texLine += fmt.Sprintf(" of %.0fMB", float64(budget)/(1<<20))
+	}
+	ebitenutil.DebugPrintAt(g.view, texLine, int(x), ty)
+	for i := len(s.spikes) - 1; i >= 0; i-- {
+		sp := s.spikes[i]
+		ty += lineHeight
+		line := fmt.Sprintf("#%d %.1fms %s %.1fms", sp.frame, ms(sp.total), sp.slowest.name, ms(sp.slowest.duration))
+		if sp.gc {
+			line += " gc"
+		}
+		ebitenutil.DebugPrintAt(g.view, line, int(x), ty)
+	}
+}
+
+// ms returns a duration in milliseconds
+func ms(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
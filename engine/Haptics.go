@@ -0,0 +1,110 @@
+package engine
+
+import (
+	"math"
+	"raycaster-go/engine/sim"
+	"time"
+)
+
+const (
+	// how often the combined rumble strength is sent to the device
+	rumbleInterval = 100 * time.Millisecond
+)
+
+// RumbleDevice vibrates a controller or device at a strength from 0 to 1 for a duration
+type RumbleDevice func(strength float64, duration time.Duration)
+
+// noRumble is the default device, ebiten has no vibration support so nothing is felt until a game sets one
+func noRumble(strength float64, duration time.Duration) {}
+
+// rumble is a playing rumble envelope
+type rumble struct {
+	env  sim.RumbleEnvelope
+	time float64
+}
+
+// Haptics plays rumble envelopes for engine events, sending the strongest of the playing rumbles
+// to the device at a fixed interval
+type Haptics struct {
+	// Enabled --rumble is sent to the device--//
+	Enabled bool
+
+	// Scale --strength multiplier for every rumble, from 0 to 1--//
+	Scale float64
+
+	// Device --receives the combined rumble, does nothing by default--//
+	Device RumbleDevice
+
+	active []*rumble
+	peak   float64
+	sent   float64
+}
+
+// NewHaptics creates haptics sending to a device that does nothing, set Device to feel them
+func NewHaptics() *Haptics {
+	return &Haptics{Enabled: true, Scale: 1, Device: noRumble}
+}
+
+// Rumble starts playing a rumble envelope, on top of any already playing
+func (h *Haptics) Rumble(env sim.RumbleEnvelope) {
+	if !h.Enabled || env.Strength <= 0 {
+		return
+	}
+	h.active = append(h.active, &rumble{env: env.Scaled(h.Scale)})
+}
+
+// Strength returns the combined strength of the playing rumbles
+func (h *Haptics) Strength() float64 {
+	strength := 0.0
+	for _, r := range h.active {
+		strength = math.Max(strength, r.env.At(r.time))
+	}
+	return math.Min(1, strength)
+}
+
+// Update advances the playing rumbles and sends the strongest since the last interval to the device
+func (h *Haptics) Update(dt float64) {
+	h.peak = math.Max(h.peak, h.Strength())
+
+	active := h.active[:0]
+	for _, r := range h.active {
+		r.time += dt
+		if r.time < r.env.Duration() {
+			active = append(active, r)
+		}
+	}
+	h.active = active
+
+	h.sent += dt
+	if h.sent < rumbleInterval.Seconds() {
+		return
+	}
+	h.sent = 0
+	if h.Enabled && h.peak > 0 && h.Device != nil {
+		h.Device(h.peak, rumbleInterval)
+	}
+	h.peak = 0
+}
+
+// hapticsListener hears noises at the camera position and rumbles for those that carry a rumble,
+// weaker the further they travelled
+type hapticsListener struct {
+	g *Game
+}
+
+// ListenPosition returns the camera position
+func (l *hapticsListener) ListenPosition() sim.Vector2 {
+	return l.g.camera.GetPosition()
+}
+
+// HearingThreshold returns the quietest noise that can be felt
+func (l *hapticsListener) HearingThreshold() float64 {
+	return 0
+}
+
+// OnNoise rumbles for the noise, scaled by how loud it is where the player is
+func (l *hapticsListener) OnNoise(n sim.Noise, loudness float64) {
+	if n.Rumble != nil && n.Loudness > 0 {
+		l.g.haptics.Rumble(n.Rumble.Scaled(loudness / n.Loudness))
+	}
+}
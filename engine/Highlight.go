@@ -0,0 +1,52 @@
+package engine
+
+import (
+	"image"
+	"image/color"
+	"raycaster-go/engine/raycaster"
+
+	"github.com/hajimehoshi/ebiten"
+)
+
+const (
+	// outline thickness in pixels around usable sprites
+	highlightWidth = 2
+)
+
+var (
+	// outline color around usable sprites
+	highlightColor = color.RGBA{255, 220, 120, 200}
+)
+
+// drawOutline draws a silhouette of the texture slice in a solid color offset around the destination,
+// so the slice drawn over it afterwards ends up outlined
+func (s *SpriteBatch) drawOutline(texture *ebiten.Image, destinationRectangle *image.Rectangle, sourceRectangle *image.Rectangle, clr color.RGBA, width int) {
+	if texture == nil || destinationRectangle == nil || sourceRectangle == nil {
+		return
+	}
+
+	offsets := [][2]int{{-width, 0}, {width, 0}, {0, -width}, {0, width}}
+	for _, offset := range offsets {
+		op, _, _ := sliceOptions(destinationRectangle, sourceRectangle)
+		op.GeoM.Translate(float64(offset[0]), float64(offset[1]))
+
+		// keep only the alpha of the texture, filled with the outline color
+		op.ColorM.Scale(0, 0, 0, float64(clr.A)/255)
+		op.ColorM.Translate(float64(clr.R)/255, float64(clr.G)/255, float64(clr.B)/255, 0)
+
+		s.target.DrawImage(texture.SubImage(*sourceRectangle).(*ebiten.Image), op)
+	}
+}
+
+// highlightLevel returns the sprite level of the sprite the view camera can use, if it is to be outlined
+func (g *Game) highlightLevel(v *worldView) *raycaster.Level {
+	if !g.highlightUsable {
+		return nil
+	}
+
+	usable := v.camera.GetUsable()
+	if usable == nil {
+		return nil
+	}
+	return v.camera.GetSpriteLevel(usable)
+}
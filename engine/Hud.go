@@ -0,0 +1,143 @@
+package engine
+
+import (
+	"image/color"
+	"raycaster-go/engine/sim"
+
+	"github.com/hajimehoshi/ebiten/ebitenutil"
+)
+
+const (
+	// dialogue box layout
+	dialogueHeight  = 60
+	dialogueMargin  = 20
+	dialoguePadding = 10
+
+	// height of a line of debug font text
+	lineHeight = 16
+
+	// boss health bar layout, below the compass
+	bossBarWidth  = 400
+	bossBarHeight = 8
+	bossBarTop    = 44
+)
+
+var (
+	dialogueBack   = color.RGBA{0, 0, 0, 180}
+	dialogueBorder = color.RGBA{200, 200, 200, 220}
+	bossBarBack    = color.RGBA{40, 0, 0, 200}
+	bossBarFill    = color.RGBA{200, 30, 30, 230}
+)
+
+// dialogueBox shows the lines of an interaction one at a time
+type dialogueBox struct {
+	lines []string
+	line  int
+}
+
+// showDialogue opens the dialogue box with the interaction lines, if there are any
+func (g *Game) showDialogue(interaction *sim.Interaction) {
+	if interaction == nil || len(interaction.Dialogue) == 0 {
+		return
+	}
+	g.dialogue = &dialogueBox{lines: interaction.Dialogue}
+}
+
+// advanceDialogue moves to the next dialogue line, closing the box after the last one
+func (g *Game) advanceDialogue() {
+	g.dialogue.line++
+	if g.dialogue.line >= len(g.dialogue.lines) {
+		g.dialogue = nil
+	}
+}
+
+// drawHud draws the prompts and boxes layered over the view
+func (g *Game) drawHud() {
+	g.drawWaypoints()
+	g.drawCompass()
+	g.drawObjectives()
+	g.drawBossBar()
+
+	if g.menu != nil {
+		g.drawMenu()
+		return
+	}
+	if g.mapObj.GetClock().IsPaused() {
+		paused := g.tr("hud.paused")
+		g.drawText(paused, g.width/2-g.textWidth(paused)/2, g.height/2-lineHeight)
+		return
+	}
+
+	g.drawCaptions()
+	g.drawChat()
+	if g.spectator != nil {
+		g.drawSpectator()
+	} else if g.IsReplaying() {
+		g.drawReplayCamera()
+	}
+
+	if g.intermission != nil {
+		g.drawIntermission()
+	} else if g.dialogue != nil {
+		g.drawDialogue()
+	} else if g.camera.GetUsable() != nil {
+		prompt := g.tr("hud.use")
+		g.drawText(prompt, g.width/2-g.textWidth(prompt)/2, g.height/2+lineHeight)
+	}
+}
+
+// drawObjectives lists the map objectives in the top right corner
+func (g *Game) drawObjectives() {
+	objectives := g.mapObj.GetObjectives()
+	if objectives == nil {
+		return
+	}
+
+	x, y := g.width-260, dialogueMargin
+	for _, o := range objectives.List() {
+		mark := "[ ]"
+		if o.Complete {
+			mark = "[x]"
+		}
+		g.drawText(mark+" "+g.tr(o.Description), x, y)
+		y += lineHeight
+	}
+}
+
+// drawBossBar shows the name and health of the boss while an arena fight is on
+func (g *Game) drawBossBar() {
+	arena := g.mapObj.GetActiveArena()
+	if arena == nil {
+		return
+	}
+	health, maxHealth := arena.GetHealth()
+	if maxHealth <= 0 {
+		return
+	}
+
+	x := float64(g.width-bossBarWidth) / 2
+	y := float64(bossBarTop)
+	name := g.tr(arena.BossName)
+	g.drawText(name, g.width/2-g.textWidth(name)/2, bossBarTop-lineHeight-2)
+	ebitenutil.DrawRect(g.view, x-1, y-1, bossBarWidth+2, bossBarHeight+2, dialogueBorder)
+	ebitenutil.DrawRect(g.view, x, y, bossBarWidth, bossBarHeight, bossBarBack)
+	ebitenutil.DrawRect(g.view, x, y, bossBarWidth*health/maxHealth, bossBarHeight, bossBarFill)
+}
+
+func (g *Game) drawDialogue() {
+	x := float64(dialogueMargin)
+	y := float64(g.height - dialogueHeight - dialogueMargin)
+	w := float64(g.width - 2*dialogueMargin)
+	h := float64(dialogueHeight)
+
+	ebitenutil.DrawRect(g.view, x-1, y-1, w+2, h+2, dialogueBorder)
+	ebitenutil.DrawRect(g.view, x, y, w, h, dialogueBack)
+
+	tx, ty := int(x)+dialoguePadding, int(y)+dialoguePadding
+	g.drawText(g.tr(g.dialogue.lines[g.dialogue.line]), tx, ty)
+	if g.dialogue.line < len(g.dialogue.lines)-1 {
+		g.drawText(g.tr("dialogue.more"), tx, ty+2*lineHeight)
+	} else {
+		g.drawText(g.tr("dialogue.close"), tx, ty+2*lineHeight)
+	}
+}
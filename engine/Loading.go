@@ -0,0 +1,158 @@
+package engine
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+	"raycaster-go/engine/sim"
+	"sync/atomic"
+	"time"
+
+	"github.com/hajimehoshi/ebiten"
+	"github.com/hajimehoshi/ebiten/ebitenutil"
+)
+
+const (
+	// loading screen layout
+	loadingBarWidth  = 400
+	loadingBarHeight = 12
+	spinnerRadius    = 12
+	spinnerDots      = 8
+)
+
+var (
+	loadingBack = color.RGBA{10, 10, 14, 255}
+	loadingFill = color.RGBA{200, 200, 200, 255}
+)
+
+// loadingScreen tracks loaders running in the background, each reporting its progress atomically
+type loadingScreen struct {
+	done  []int64
+	total []int64
+
+	finished  chan error
+	remaining int
+	err       error
+	onDone    func(err error)
+	start     time.Time
+}
+
+// Load shows the loading screen while the loaders run in the background, then calls onDone on the game loop
+// with the first error any of them returned. The map is not updated until loading is done.
+func (g *Game) Load(onDone func(err error), loaders ...sim.Loader) {
+	l := &loadingScreen{
+		done:      make([]int64, len(loaders)),
+		total:     make([]int64, len(loaders)),
+		finished:  make(chan error, len(loaders)),
+		remaining: len(loaders),
+		onDone:    onDone,
+		start:     time.Now(),
+	}
+	for i, loader := range loaders {
+		i, loader := i, loader
+		go func() {
+			l.finished <- loader.Load(func(done, total int) {
+				atomic.StoreInt64(&l.done[i], int64(done))
+				atomic.StoreInt64(&l.total[i], int64(total))
+			})
+		}()
+	}
+	g.loading = l
+}
+
+// LoadMap loads a map JSON file behind the loading screen along with the lazy textures and the sound
+// effects given, then makes it the map the player is in
+func (g *Game) LoadMap(name, path string, sounds ...string) {
+	mapLoader := &sim.MapLoader{Path: path, Tex: g.tex}
+	g.Load(func(err error) {
+		if err != nil {
+			fmt.Printf("Unable to load map %s: %v\n", path, err)
+			return
+		}
+		g.setMap(name, mapLoader.Map)
+	}, mapLoader, g.tex, g.sounds.Preload(sounds...))
+}
+
+// IsLoading returns true while the loading screen is up
+func (g *Game) IsLoading() bool {
+	return g.loading != nil
+}
+
+// GetLoadProgress returns how much of the loading is done from 0 to 1, each loader counting the same
+func (g *Game) GetLoadProgress() float64 {
+	l := g.loading
+	if l == nil || len(l.done) == 0 {
+		return 1
+	}
+	progress := 0.0
+	for i := range l.done {
+		done, total := atomic.LoadInt64(&l.done[i]), atomic.LoadInt64(&l.total[i])
+		if total > 0 {
+			progress += math.Min(1, float64(done)/float64(total))
+		}
+	}
+	return progress / float64(len(l.done))
+}
+
+// updateLoading collects the loaders that have finished, closing the loading screen once all have
+func (g *Game) updateLoading() {
+	l := g.loading
+	for l.remaining > 0 {
+		select {
+		case err := <-l.finished:
+			l.remaining--
+			if err != nil && l.err == nil {
+				l.err = err
+			}
+			continue
+		default:
+		}
+		return
+	}
+
+	g.loading = nil
+	if l.onDone != nil {
+		l.onDone(l.err)
+	}
+}
+
+// drawLoading draws the progress bar and a spinner in place of the view
+func (g *Game) drawLoading() {
+	ebitenutil.DrawRect(g.view, 0, 0, float64(g.width), float64(g.height), loadingBack)
+
+	x := float64(g.width-loadingBarWidth) / 2
+	y := float64(g.height)/2 + 2*spinnerRadius
+	ebitenutil.DrawRect(g.view, x-1, y-1, loadingBarWidth+2, loadingBarHeight+2, dialogueBorder)
+	ebitenutil.DrawRect(g.view, x, y, loadingBarWidth, loadingBarHeight, loadingBack)
+	ebitenutil.DrawRect(g.view, x, y, loadingBarWidth*g.GetLoadProgress(), loadingBarHeight, loadingFill)
+
+	text := g.tr("loading.title")
+	g.drawText(text, g.width/2-g.textWidth(text)/2, int(y)+loadingBarHeight+lineHeight/2)
+
+	// dots around a circle fading behind the one in front, a full turn a second
+	turn := time.Since(g.loading.start).Seconds()
+	cx, cy := float64(g.width)/2, float64(g.height)/2-spinnerRadius
+	for i := 0; i < spinnerDots; i++ {
+		angle := 2 * math.Pi * float64(i) / spinnerDots
+		behind := math.Mod(turn*spinnerDots-float64(i)+spinnerDots, spinnerDots)
+		dot := loadingFill
+		dot.A = uint8(255 * (1 - behind/spinnerDots))
+		ebitenutil.DrawRect(g.view, cx+math.Cos(angle)*spinnerRadius-2, cy+math.Sin(angle)*spinnerRadius-2, 4, 4, dot)
+	}
+}
+
+// updateLoadingScreen runs a tick of the loading screen in place of the game, returning false once loading
+// is done and the game can carry on with the tick
+func (g *Game) updateLoadingScreen(screen *ebiten.Image, tickStart time.Time) bool {
+	g.updateLoading()
+	if g.loading == nil {
+		return false
+	}
+	if !ebiten.IsDrawingSkipped() {
+		g.view.Clear()
+		g.drawLoading()
+		g.presentFrame(screen)
+	}
+	g.runBackgroundTasks(tickStart)
+	return true
+}
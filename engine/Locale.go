@@ -0,0 +1,184 @@
+package engine
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// DefaultLanguage is the language of the built-in engine strings, used for keys missing from the selected one
+const DefaultLanguage = "en"
+
+// engineStrings is the English text of the built-in UI pieces, by key
+var engineStrings = map[string]string{
+	"hud.paused":     "PAUSED",
+	"hud.use":        "[E] Use",
+	"hud.spectating": "SPECTATING - [E] Free camera",
+	"hud.freecam":    "FREE CAMERA - [E] Follow player",
+	"hud.feedlost":   "Spectator feed lost",
+	"hud.replay":     "REPLAY - [E] Free camera",
+	"chat.prompt":    "Say: ",
+	"dialogue.more":  "[E] ...",
+	"dialogue.close": "[E] Close",
+	"stats.title":    "LEVEL COMPLETE",
+	"stats.kills":    "Kills",
+	"stats.items":    "Items",
+	"stats.secrets":  "Secrets",
+	"stats.damage":   "Damage",
+	"stats.time":     "Time",
+	"stats.continue": "[E] Continue",
+
+	"loading.title": "Loading...",
+
+	"menu.title":      "RAYCASTER-GO",
+	"menu.paused":     "PAUSED",
+	"menu.start":      "Start",
+	"menu.resume":     "Resume",
+	"menu.options":    "Options",
+	"menu.quit":       "Quit",
+	"menu.back":       "Back",
+	"menu.fov":        "Field of view",
+	"menu.captions":   "Captions",
+	"menu.motionblur": "Motion blur",
+	"menu.mirror":     "Rear-view mirror",
+	"menu.language":   "Language",
+	"menu.controls":   "Controls",
+	"menu.defaults":   "Reset to defaults",
+	"menu.on":         "On",
+	"menu.off":        "Off",
+	"menu.presskey":   "press a key...",
+
+	"action.forward":   "Forward",
+	"action.backward":  "Backward",
+	"action.turnLeft":  "Turn left",
+	"action.turnRight": "Turn right",
+	"action.strafe":    "Strafe",
+	"action.sprint":    "Sprint",
+	"action.up":        "Swim up",
+	"action.down":      "Swim down",
+	"action.lookUp":    "Look up",
+	"action.lookDown":  "Look down",
+	"action.use":       "Use",
+	"action.respawn":   "Respawn",
+	"action.zoom":      "Zoom",
+	"action.pause":     "Pause",
+	"action.chat":      "Chat",
+}
+
+// Localizer holds a string table for each language and looks text up in the selected one, falling back
+// to English and then to the key itself, so map text that is not a key shows as written
+type Localizer struct {
+	tables   map[string]map[string]string
+	language string
+}
+
+// NewLocalizer creates a localizer with the English engine strings selected
+func NewLocalizer() *Localizer {
+	l := &Localizer{tables: make(map[string]map[string]string), language: DefaultLanguage}
+	l.AddStrings(DefaultLanguage, engineStrings)
+	return l
+}
+
+// AddStrings adds text to the string table of a language, replacing keys it already has
+func (l *Localizer) AddStrings(language string, table map[string]string) {
+	t := l.tables[language]
+	if t == nil {
+		t = make(map[string]string)
+		l.tables[language] = t
+	}
+	for k, v := range table {
+		t[k] = v
+	}
+}
+
+// LoadStrings adds the string table of a language from a json file of keys to text
+func (l *Localizer) LoadStrings(language, path string) error {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	var table map[string]string
+	if err := json.Unmarshal(data, &table); err != nil {
+		return fmt.Errorf("invalid string table %s: %v", path, err)
+	}
+	l.AddStrings(language, table)
+	return nil
+}
+
+// LoadDir adds the string tables of every <language>.json file in a directory, doing nothing if the
+// directory does not exist
+func (l *Localizer) LoadDir(dir string) error {
+	files, err := ioutil.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	for _, f := range files {
+		if f.IsDir() || filepath.Ext(f.Name()) != ".json" {
+			continue
+		}
+		if err := l.LoadStrings(strings.TrimSuffix(f.Name(), ".json"), filepath.Join(dir, f.Name())); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SetLanguage selects the language text is looked up in
+func (l *Localizer) SetLanguage(language string) error {
+	if _, ok := l.tables[language]; !ok {
+		return fmt.Errorf("no strings for language %q", language)
+	}
+	l.language = language
+	return nil
+}
+
+// GetLanguage returns the selected language
+func (l *Localizer) GetLanguage() string {
+	return l.language
+}
+
+// GetLanguages returns the languages with a string table, sorted
+func (l *Localizer) GetLanguages() []string {
+	languages := make([]string, 0, len(l.tables))
+	for language := range l.tables {
+		languages = append(languages, language)
+	}
+	sort.Strings(languages)
+	return languages
+}
+
+// Translate returns the text of a key in the selected language, formatted with args if there are any
+func (l *Localizer) Translate(key string, args ...interface{}) string {
+	text, ok := l.tables[l.language][key]
+	if !ok {
+		if text, ok = l.tables[DefaultLanguage][key]; !ok {
+			text = key
+		}
+	}
+	if len(args) > 0 {
+		return fmt.Sprintf(text, args...)
+	}
+	return text
+}
+
+// GetLocalizer returns the string tables used for the text the engine draws
+func (g *Game) GetLocalizer() *Localizer {
+	return g.locale
+}
+
+// SetLanguage selects the language of the text the engine draws
+func (g *Game) SetLanguage(language string) error {
+	return g.locale.SetLanguage(language)
+}
+
+// tr returns the text of a key in the selected language
+func (g *Game) tr(key string, args ...interface{}) string {
+	return g.locale.Translate(key, args...)
+}
@@ -0,0 +1,400 @@
+package engine
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"net"
+	"raycaster-go/engine/raycaster"
+	"raycaster-go/engine/sim"
+	"sync"
+	"time"
+)
+
+const (
+	// ticks the input of a tick is sent ahead of being played, hiding that much round trip from the players
+	lockstepDelay = 3
+
+	// how long a tick waits for the partner's input before the session is dropped
+	lockstepTimeout = 5 * time.Second
+
+	// partner input frames read ahead of the game loop
+	lockstepQueue = 64
+)
+
+// lockstepHello is sent by the host to the joining partner: the map, the simulation seed, the state both
+// start from and the checksum of the host's world once seeded, which the partner's must match
+type lockstepHello struct {
+	Map   string          `json:"map"`
+	Seed  int64           `json:"seed"`
+	Start json.RawMessage `json:"start"`
+	Sum   uint64          `json:"sum"`
+}
+
+// lockstepFrame is the input of one player for one tick, with the checksum of the world the sender was in
+// lockstepDelay ticks before it
+type lockstepFrame struct {
+	Tick  uint64     `json:"t"`
+	Input inputFrame `json:"i"`
+	Sum   uint64     `json:"s"`
+}
+
+// DesyncError is why a co-op session ends when the partner's game no longer simulates the same world
+type DesyncError struct {
+	// Tick --first tick the checksums of the two games differed after--//
+	Tick uint64
+}
+
+func (e *DesyncError) Error() string {
+	return fmt.Sprintf("co-op games out of sync at tick %d", e.Tick)
+}
+
+// Lockstep plays a small co-op game by exchanging only input with the partner over TCP. Both games advance
+// the same simulation one tick at a time from the same state and seed, the host's player first, and a tick
+// waits until the partner's input for it has arrived. The partner is a headless camera in the local map.
+type Lockstep struct {
+	conn net.Conn
+	host bool
+	tick uint64
+
+	//--own input sent but not played yet, the partner's input as it arrives--//
+	local  []inputFrame
+	remote chan lockstepFrame
+
+	//--input of both players for this tick, the host's first--//
+	play [2]inputFrame
+
+	//--own checksums by tick, checked against those the partner sends--//
+	sums map[uint64]uint64
+
+	partner    *sim.Camera
+	partnerMap *sim.Map
+	ghost      *sim.Sprite
+
+	mu  sync.Mutex
+	err error
+}
+
+// HostLockstep waits for a co-op partner to connect on a TCP address (e.g. ":7778") and starts a lockstep
+// game with them from the current map and state. It blocks until the partner has connected.
+func (g *Game) HostLockstep(addr string) error {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("unable to listen for a co-op partner on %s: %v", addr, err)
+	}
+	defer ln.Close()
+	fmt.Printf("Waiting for a co-op partner on %s\n", addr)
+	conn, err := ln.Accept()
+	if err != nil {
+		return fmt.Errorf("unable to accept a co-op partner: %v", err)
+	}
+
+	// both games restart the simulation from a new seed along with the starting state
+	g.seed = time.Now().UnixNano()
+	start, err := g.camera.Snapshot().Marshal()
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("unable to encode co-op starting state: %v", err)
+	}
+	g.mapObj.SetSeed(g.seed)
+	sum := g.mapObj.Checksum(g.camera.Camera)
+	hello, err := json.Marshal(lockstepHello{Map: g.mapName, Seed: g.seed, Start: start, Sum: sum})
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("unable to encode co-op hello: %v", err)
+	}
+	if _, err := conn.Write(append(hello, '\n')); err != nil {
+		conn.Close()
+		return fmt.Errorf("unable to send co-op hello: %v", err)
+	}
+
+	g.startLockstep(conn, newLockstepScanner(conn), true)
+	return nil
+}
+
+// JoinLockstep connects to a co-op game hosted on a TCP address, which must be on the same map, and starts
+// from the host's state and seed. It refuses to start if the state could not be brought in line with the
+// host's, e.g. when the host has sprites the game added itself.
+func (g *Game) JoinLockstep(addr string) error {
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("unable to connect to co-op host %s: %v", addr, err)
+	}
+	scanner := newLockstepScanner(conn)
+	if !scanner.Scan() {
+		conn.Close()
+		err := scanner.Err()
+		if err == nil {
+			err = fmt.Errorf("connection closed")
+		}
+		return fmt.Errorf("unable to read co-op hello: %v", err)
+	}
+	var hello lockstepHello
+	if err := json.Unmarshal(scanner.Bytes(), &hello); err != nil {
+		conn.Close()
+		return fmt.Errorf("unable to decode co-op hello: %v", err)
+	}
+	if hello.Map != g.mapName {
+		conn.Close()
+		return fmt.Errorf("co-op host is on map %q, not %q", hello.Map, g.mapName)
+	}
+	start, err := sim.UnmarshalSnapshot(hello.Start)
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("unable to decode co-op starting state: %v", err)
+	}
+
+	// seeded after the restore, which brings the host's random state from before it seeded too
+	g.camera.Restore(start, sim.RestoreAll)
+	g.seed = hello.Seed
+	g.mapObj.SetSeed(hello.Seed)
+	if sum := g.mapObj.Checksum(g.camera.Camera); sum != hello.Sum {
+		conn.Close()
+		return fmt.Errorf("co-op starting state differs from the host's: checksum %x, host %x", sum, hello.Sum)
+	}
+	g.startLockstep(conn, scanner, false)
+	return nil
+}
+
+// GetLockstep returns the co-op session, nil if not playing co-op
+func (g *Game) GetLockstep() *Lockstep {
+	return g.lockstep
+}
+
+// IsHost returns true for the game the partner connected to, whose player moves first each tick
+func (l *Lockstep) IsHost() bool {
+	return l.host
+}
+
+// GetTick returns the number of ticks played together so far
+func (l *Lockstep) GetTick() uint64 {
+	return l.tick
+}
+
+// GetPartner returns the camera of the partner's player in the local map
+func (l *Lockstep) GetPartner() *sim.Camera {
+	return l.partner
+}
+
+// Err returns why the partner's input stopped arriving, nil while connected
+func (l *Lockstep) Err() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.err
+}
+
+// Close disconnects from the partner
+func (l *Lockstep) Close() {
+	l.conn.Close()
+}
+
+// newLockstepScanner reads lines from the partner, long enough for the starting state
+func newLockstepScanner(conn net.Conn) *bufio.Scanner {
+	scanner := bufio.NewScanner(conn)
+	scanner.Buffer(make([]byte, 64*1024), spectatorMaxLine)
+	return scanner
+}
+
+// startLockstep sets up the session over a connected partner, whose input is read from the scanner
+func (g *Game) startLockstep(conn net.Conn, scanner *bufio.Scanner, host bool) {
+	l := &Lockstep{
+		conn:   conn,
+		host:   host,
+		local:  make([]inputFrame, lockstepDelay),
+		remote: make(chan lockstepFrame, lockstepQueue),
+		sums:   make(map[uint64]uint64),
+		ghost:  sim.NewSprite(0, 0, g.playerSpriteImage()),
+	}
+	g.spawnPartner(l)
+	go l.receive(scanner)
+
+	if g.lockstep != nil {
+		g.lockstep.Close()
+	}
+	g.lockstep = l
+}
+
+// spawnPartner puts the partner's player in the current map where the local player is, which is where
+// the partner game puts this one
+func (g *Game) spawnPartner(l *Lockstep) {
+	l.partner = sim.NewCamera(g.mapObj, sim.WithTargetTPS(targetTPS))
+	l.partner.Follow(g.camera.Camera)
+	l.partnerMap = g.mapObj
+	l.partner.Events.OnEnterTile = func(x, y int, tile *sim.TileType) {
+		if tile.Trigger != "" {
+			g.mapObj.FireTrigger(tile.Trigger)
+			g.mapObj.GetObjectives().Complete(tile.Trigger)
+		}
+	}
+}
+
+// receive reads the partner's input frames until the connection closes
+func (l *Lockstep) receive(scanner *bufio.Scanner) {
+	defer close(l.remote)
+	for scanner.Scan() {
+		var fr lockstepFrame
+		if err := json.Unmarshal(scanner.Bytes(), &fr); err != nil {
+			l.setErr(fmt.Errorf("unable to decode co-op input: %v", err))
+			return
+		}
+		l.remote <- fr
+	}
+
+	err := scanner.Err()
+	if err == nil {
+		err = fmt.Errorf("co-op partner disconnected")
+	}
+	l.setErr(err)
+}
+
+func (l *Lockstep) setErr(err error) {
+	l.mu.Lock()
+	if l.err == nil {
+		l.err = err
+	}
+	l.mu.Unlock()
+}
+
+// players returns the cameras of both players in the order they move each tick, the host's first
+func (l *Lockstep) players(local *raycaster.Camera) [2]*sim.Camera {
+	if l.host {
+		return [2]*sim.Camera{local.Camera, l.partner}
+	}
+	return [2]*sim.Camera{l.partner, local.Camera}
+}
+
+// exchangeLockstep sends the input of this tick to be played lockstepDelay ticks from now and waits for the
+// partner's input for this tick. Nothing the local player does in a menu, dialogue or the stats screen is
+// sent, the partner would otherwise see it move. The session is dropped if the partner is lost or the two games
+// fall out of sync.
+func (g *Game) exchangeLockstep(f inputFrame) {
+	l := g.lockstep
+	if err := l.exchange(g, f); err != nil {
+		fmt.Printf("Co-op session ended: %v\n", err)
+		l.Close()
+		g.camera.SetViewSprites(nil)
+		g.lockstep = nil
+	}
+}
+
+func (l *Lockstep) exchange(g *Game, f inputFrame) error {
+	sum := g.lockstepChecksum()
+	l.sums[l.tick] = sum
+
+	// console and chat commands stay in this game
+	send := inputFrame{Pressed: f.Pressed, Just: f.Just}
+	if g.menu != nil || g.dialogue != nil || g.intermission != nil {
+		send = inputFrame{}
+	}
+	data, err := json.Marshal(lockstepFrame{Tick: l.tick + lockstepDelay, Input: send, Sum: sum})
+	if err != nil {
+		return fmt.Errorf("unable to encode co-op input: %v", err)
+	}
+	if _, err := l.conn.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("unable to send co-op input: %v", err)
+	}
+	l.local = append(l.local, send)
+	own := l.local[0]
+	l.local = l.local[1:]
+
+	// nobody has input for the first ticks, both games play them standing still
+	var partner inputFrame
+	if l.tick >= lockstepDelay {
+		select {
+		case fr, ok := <-l.remote:
+			if !ok {
+				return l.Err()
+			}
+			if fr.Tick != l.tick {
+				return fmt.Errorf("co-op input for tick %d arrived at tick %d", fr.Tick, l.tick)
+			}
+			sent := l.tick - lockstepDelay
+			want, ok := l.sums[sent]
+			delete(l.sums, sent)
+			if ok && fr.Sum != want {
+				return &DesyncError{Tick: sent}
+			}
+			partner = fr.Input
+		case <-time.After(lockstepTimeout):
+			return fmt.Errorf("no co-op input for tick %d after %v", l.tick, lockstepTimeout)
+		}
+	}
+
+	if l.host {
+		l.play = [2]inputFrame{own, partner}
+	} else {
+		l.play = [2]inputFrame{partner, own}
+	}
+	l.tick++
+	return nil
+}
+
+// lockstepChecksum hashes the state of both players and the map, which drifts apart first when the two games
+// stop simulating the same world
+func (g *Game) lockstepChecksum() uint64 {
+	players := g.lockstep.players(g.camera)
+	return g.mapObj.Checksum(players[0], players[1])
+}
+
+// updateLockstep steps both players through a tick, the host's first, rendering only the local one. The
+// partner is drawn where it stood at the start of the tick.
+func (g *Game) updateLockstep(dt float64) {
+	l := g.lockstep
+	if l.partnerMap != g.mapObj {
+		g.spawnPartner(l)
+	}
+
+	pos := l.partner.GetPosition()
+	l.ghost.X, l.ghost.Y, l.ghost.Dir = pos.X, pos.Y, l.partner.GetDirection()
+	g.camera.SetViewSprites([]*sim.Sprite{l.ghost})
+
+	for _, cam := range l.players(g.camera) {
+		if cam == g.camera.Camera {
+			g.camera.UpdateWithDelta(dt)
+		} else {
+			cam.SimulateWithDelta(dt)
+		}
+	}
+}
+
+// applyLockstepInput plays the input of both players for this tick, the host's first. Either player can
+// pause the game for both. It runs whatever the local menus are doing so both games play the same input.
+func (g *Game) applyLockstepInput() {
+	l := g.lockstep
+	clock := g.mapObj.GetClock()
+	for _, f := range l.play {
+		if g.actionJustPressedIn(f, ActionPause) {
+			clock.SetPaused(!clock.IsPaused())
+		}
+	}
+	if clock.IsPaused() {
+		return
+	}
+
+	players := l.players(g.camera)
+	for i, cam := range players {
+		g.handlePlayerInput(cam, l.play[i])
+	}
+}
+
+// handleLockstepUI lets the use key close the stats screen and move the dialogue on, the keyboard does
+// nothing else in the world while playing co-op
+func (g *Game) handleLockstepUI() {
+	if !g.actionJustPressed(ActionUse) {
+		return
+	}
+	if g.intermission != nil {
+		g.intermission = nil
+	} else if g.dialogue != nil {
+		g.advanceDialogue()
+	}
+}
+
+// perceptionTarget returns where sprites look out for the player, the host's player while playing co-op so
+// both games see the same thing
+func (g *Game) perceptionTarget() sim.Vector2 {
+	if l := g.lockstep; l != nil {
+		return l.players(g.camera)[0].GetPosition()
+	}
+	return g.camera.GetPosition()
+}
@@ -5,12 +5,14 @@ import (
 	"image"
 	"image/color"
 	"log"
-	"math"
+	"math/rand"
 	"path/filepath"
 	"raycaster-go/engine/raycaster"
-	"runtime"
+	"raycaster-go/engine/sim"
+	"time"
 
 	"github.com/hajimehoshi/ebiten"
+	"github.com/hajimehoshi/ebiten/audio"
 	"github.com/hajimehoshi/ebiten/ebitenutil"
 )
 
@@ -23,6 +25,21 @@ const (
 	//--RaycastEngine constants
 	//--set constant, texture size to be the wall (and sprite) texture size--//
 	texSize = 256
+
+	// target framerate (TPS)
+	targetTPS = 60
+
+	// halved texture copies used for distant walls and floors
+	mipLevels = 3
+
+	// texture memory budget in browsers, where memory is tight
+	browserTextureBudget = 64 << 20
+
+	// floor rows further than this are sampled bilinearly
+	floorFilterDistance = 4.0
+
+	// share of the tick time background tasks may use up to, leaving headroom for presenting the frame
+	backgroundShare = 0.8
 )
 
 // Game - This is the main type for your game.
@@ -46,11 +63,157 @@ type Game struct {
 	floor *ebiten.Image
 	sky   *ebiten.Image
 
-	//--array of levels, levels reffer to "floors" of the world--//
-	mapObj     *raycaster.Map
-	levels     []*raycaster.Level
-	spriteLvls []*raycaster.Level
-	floorLvl   *raycaster.HorLevel
+	//--textures for the floor caster--//
+	floorTex []*image.RGBA
+
+	//--the world the player is in and its map--//
+	world  *worldView
+	mapObj *sim.Map
+
+	//--a second world rendered into a wall texture--//
+	portal *worldView
+
+	//--full-screen tint that fades out (e.g. damage)--//
+	flash *screenFlash
+
+	//--world frame from before a theme change, and the textures to swap back to--//
+	themeFade    *themeFade
+	themeRestore *raycaster.TextureTheme
+
+	//--map music and seconds left of the combat music--//
+	music      *MusicManager
+	combatLeft float64
+
+	//--sound effects with the reverb of where the player is--//
+	sounds *SoundPlayer
+
+	//--controller rumble for damage and nearby noises--//
+	haptics *Haptics
+
+	//--text for heard sounds with the direction they came from--//
+	captionsEnabled bool
+	captions        []*caption
+
+	//--arcs pointing towards where recent damage came from--//
+	damageIndicators []*damageIndicator
+
+	//--open dialogue from interacting with a sprite--//
+	dialogue *dialogueBox
+
+	//--outline sprites that can be used--//
+	highlightUsable bool
+
+	//--frame rendered offscreen so it can be filtered on the way to the screen--//
+	frame       *ebiten.Image
+	colorFilter ColorFilter
+
+	//--world rendered offscreen so it can be moved by head bob and shake--//
+	worldFrame *ebiten.Image
+
+	//--glow around emissive textures--//
+	bloomEnabled  bool
+	emissiveFrame *ebiten.Image
+	bloomSmall    *ebiten.Image
+	bloomBlur     *ebiten.Image
+
+	//--blur of fast turns from the previous world frame--//
+	motionBlur bool
+	prevFrame  *ebiten.Image
+
+	//--vignette and film grain--//
+	postEffects PostEffects
+	vignetteImg *ebiten.Image
+	grainImg    *ebiten.Image
+	grainRand   *rand.Rand
+
+	//--binocular mask shown while zoomed--//
+	zoomImg *ebiten.Image
+
+	//--settings trading looks for speed--//
+	quality QualityPreset
+
+	//--paletted output, nil for true color--//
+	palette *paletteMode
+
+	//--low priority work run in slices with the time left in each tick, and the path requests it answers--//
+	scheduler *sim.Scheduler
+	paths     *sim.PathQueue
+
+	//--frame time histogram and spike log, toggled with F3--//
+	frameStats     *frameStats
+	showFrameStats bool
+
+	//--viewports drawn over the main view (minimap, second cameras)--//
+	layout *Layout
+
+	//--render buffers of security cameras and the wall textures their views are shown on--//
+	securityViews   map[securityViewKey]*worldView
+	securityScreens map[int]*ebiten.Image
+	securityTicks   int
+
+	//--top-down navigation debug view, toggled with F4--//
+	showNavDebug bool
+
+	//--screenshot requested for the next rendered frame--//
+	screenshotPending bool
+
+	//--tally of the level being played and the intermission screen showing it once complete--//
+	stats        *LevelStats
+	intermission *LevelStats
+
+	// OnLevelComplete --called with the level stats when all objectives of the map are complete--//
+	OnLevelComplete func(stats *LevelStats)
+
+	//--progress, settings and unlocks of the player, saved as levels are completed--//
+	profile *Profile
+
+	//--world state streamed to spectators, or received from a feed while spectating--//
+	feed      *SpectatorFeed
+	spectator *Spectator
+	lockstep  *Lockstep
+
+	//--benchmark flythrough, nil unless running--//
+	bench *benchmark
+
+	//--loaders running behind the loading screen, nil once loading is done--//
+	loading *loadingScreen
+
+	//--open menu, keys bound to each action and whether Quit was chosen--//
+	menu     *Menu
+	bindings Bindings
+	quit     bool
+
+	//--string tables and font for the text of the HUD, console, dialogue and menus--//
+	locale *Localizer
+	font   *BitmapFont
+
+	//--debug commands, the console they are typed into and the TPS counter they can hide--//
+	commands *CommandRegistry
+	console  *console
+	showFPS  bool
+
+	//--multiplayer chat log and prompt--//
+	chat *chat
+
+	//--map name and simulation seed, stored in crash replays--//
+	mapName string
+	seed    int64
+
+	//--input for the current tick, recorded for crash replays or played back from one--//
+	input    inputFrame
+	crashDir string
+	recorder *replayRecorder
+	player   *replayPlayer
+
+	//--free camera a replay is watched through, nil while following the recorded player--//
+	replayCam *replayCamera
+
+	//--image other players are drawn with, loaded when first needed--//
+	playerSprite *ebiten.Image
+
+	//--rendered frames written to disk for visual regression diffs--//
+	captureDir   string
+	captureFrame int
 
 	// for debugging
 	DebugX    int
@@ -62,6 +225,9 @@ type Game struct {
 // Enables a group of sprites to be drawn using the same settings.
 type SpriteBatch struct {
 	g *Game
+
+	// image to draw into
+	target *ebiten.Image
 }
 
 // NewGame - Allows the game to perform any initialization it needs to before starting to run.
@@ -74,32 +240,156 @@ func NewGame() *Game {
 	// initialize Game object
 	g := new(Game)
 
-	// use scale to keep the desired window width and height
-	g.width = int(math.Floor(float64(screenWidth) / screenScale))
-	g.height = int(math.Floor(float64(screenHeight) / screenScale))
+	// render at a fraction of the window size on slow devices, scaled up to keep the desired window width and height
+	g.quality = DefaultQuality()
+	g.width, g.height = renderSize(g.quality.RenderScale)
 
 	g.tex = raycaster.NewTextureHandler(texSize)
 
+	// keys of the player actions, rebound in the controls menu
+	g.bindings = DefaultBindings()
+
+	// english engine text, with the languages found in the content folder
+	g.locale = NewLocalizer()
+	if err := g.locale.LoadDir(filepath.Join("engine", "content", "lang")); err != nil {
+		fmt.Printf("Unable to load languages: %v\n", err)
+	}
+
+	// background work runs in the tick time left over after drawing
+	g.scheduler = sim.NewScheduler()
+	g.tex.SetScheduler(g.scheduler)
+
 	//--init texture slices--//
 	g.slices = g.tex.GetSlices()
 
+	// seed the simulation so crash replays can start from the same seed
+	g.seed = time.Now().UnixNano()
+
 	// load map
-	g.mapObj = raycaster.NewMap(g.tex)
+	g.mapObj = sim.NewMap(g.tex)
+	g.mapObj.SetSeed(g.seed)
+	g.mapName = sampleMapName
 
-	//--inits the levels--//
-	g.levels, g.floorLvl = g.createLevels(4)
+	g.paths = sim.NewPathQueue(g.mapObj, g.scheduler)
 
 	// load content once when first run
 	g.loadContent()
 
 	// init the sprites
 	g.mapObj.LoadSprites()
-	g.spriteLvls = g.createSpriteLevels()
 
-	//--init camera--//
-	g.camera = raycaster.NewCamera(g.width, g.height, texSize, g.mapObj, g.slices, g.levels, g.floorLvl, g.spriteLvls, g.tex)
+	//--init the world view and camera--//
+	g.world = g.newWorldView(g.mapObj, g.width, g.height)
+	g.camera = g.world.camera
+
+	// outline sprites in reach that can be used
+	g.highlightUsable = true
+
+	// offscreen frames for post processing
+	g.frame, _ = ebiten.NewImage(g.width, g.height, ebiten.FilterNearest)
+	g.worldFrame, _ = ebiten.NewImage(g.width, g.height, ebiten.FilterNearest)
+
+	// glow around emissive textures
+	g.bloomEnabled = g.quality.Bloom
+	g.newBloomBuffers()
+
+	// atmosphere effects, off until a game turns them on
+	g.SetPostEffects(PostEffects{AnimateGrain: g.quality.AnimateGrain})
+
+	//--init the dream world seen through the portal--//
+	g.portal = g.newPortal()
+
+	// viewports over the main view, the minimap and rear-view mirror start hidden
+	g.layout = NewLayout(g.width, g.height)
+	minimap, mirror := g.NewMinimap(), g.NewRearViewMirror()
+	minimap.Hidden, mirror.Hidden = true, true
+	g.layout.Add(minimap)
+	g.layout.Add(mirror)
+
+	// music of the map, silent if the audio device or the tracks are missing
+	audioCtx, err := audio.NewContext(audioSampleRate)
+	if err != nil {
+		fmt.Printf("Unable to open audio: %v\n", err)
+	}
+	g.music = NewMusicManager(audioCtx, filepath.Join("engine", "content", "music"))
+	g.music.PlayMap(g.mapObj.GetMusic())
+	g.sounds = NewSoundPlayer(audioCtx, filepath.Join("engine", "content", "sounds"))
+
+	// rumble for damage and noises that shake the ground
+	g.haptics = NewHaptics()
+
+	// captions, sound effects and rumble for noises in the map
+	g.addMapListeners()
+
+	// kills, items, secrets, damage and time for the end of level stats
+	g.startLevelStats()
+
+	g.camera.Events.OnTileDamage = func(damage float64, tile *sim.TileType) {
+		g.stats.addDamage(damage)
+		if g.flash == nil {
+			g.camera.PunchFOV(0.95, 0.3)
+			g.haptics.Rumble(sim.RumbleDamage.Scaled(0.5))
+		}
+		g.flashScreen(damageTint, 15)
+		g.camera.Shake(0.3, 0.25)
+		g.enterCombat()
+	}
+	g.camera.Events.OnCrush = func(solid *sim.Solid) {
+		if g.flash == nil {
+			g.camera.PunchFOV(0.9, 0.4)
+			g.haptics.Rumble(sim.RumbleDamage)
+		}
+		g.flashScreen(damageTint, 15)
+		g.camera.Shake(1, 0.5)
+		g.addDamageIndicator(solid.Center())
+		g.enterCombat()
+	}
+	g.camera.Events.OnDamageFrom = func(damage float64, from sim.Vector2) {
+		g.stats.addDamage(damage)
+		if g.flash == nil {
+			g.camera.PunchFOV(0.95, 0.3)
+		}
+		g.flashScreen(damageTint, 15)
+		g.camera.Shake(0.5, 0.3)
+		g.addDamageIndicator(from)
+		g.enterCombat()
+		g.haptics.Rumble(sim.RumbleDamage)
+	}
+	g.camera.Events.OnEffectDamage = func(damage float64, effect *sim.StatusEffect) {
+		g.stats.addDamage(damage)
+		if g.flash == nil {
+			g.flashScreen(damageTint, 15)
+		}
+	}
+	g.camera.Events.OnAirDepleted = func() {
+		g.camera.Respawn()
+	}
+	g.camera.Events.OnCheckpoint = func() {
+		fmt.Printf("Checkpoint reached\n")
+	}
+	g.camera.Events.OnFootstep = func(step sim.Footstep) {
+		g.mapObj.EmitNoise(sim.Noise{
+			Pos:      step.Pos,
+			Loudness: footstepLoudness,
+			Source:   step,
+			Sound:    "footstep_" + string(step.Material) + ".wav",
+		})
+	}
+	g.camera.Events.OnEnterTile = func(x, y int, tile *sim.TileType) {
+		g.stats.enterTile(x, y, tile)
+		if tile.Trigger != "" {
+			g.mapObj.FireTrigger(tile.Trigger)
+			g.mapObj.GetObjectives().Complete(tile.Trigger)
+		}
+	}
 
 	// for debugging
+	g.commands = NewCommandRegistry()
+	registerDebugCommands(g.commands)
+	g.console = &console{}
+	g.chat = &chat{name: defaultChatName}
+	g.showFPS = true
+	g.frameStats = newFrameStats()
 	g.DebugX = -1
 	g.DebugY = -1
 
@@ -115,31 +405,49 @@ func (g *Game) loadContent() {
 	// TODO: use loadContent to load your game content here
 	g.tex.Textures = make([]*ebiten.Image, 16)
 
-	g.tex.Textures[0] = getTextureFromFile("stone.png")
-	g.tex.Textures[1] = getTextureFromFile("left_bot_house.png")
-	g.tex.Textures[2] = getTextureFromFile("right_bot_house.png")
-	g.tex.Textures[3] = getTextureFromFile("left_top_house.png")
-	g.tex.Textures[4] = getTextureFromFile("right_top_house.png")
+	g.tex.Textures[0] = g.getTextureFromFile("stone.png")
+	g.tex.Textures[1] = g.getTextureFromFile("left_bot_house.png")
+	g.tex.Textures[2] = g.getTextureFromFile("right_bot_house.png")
+
+	// upper house walls are only seen from a distance, decoded the first time they come into view
+	g.loadLazyTexture(3, "left_top_house.png")
+	g.loadLazyTexture(4, "right_top_house.png")
 
 	// separating sprites out a bit from wall textures
-	g.tex.Textures[9] = getSpriteFromFile("tree_09.png")
-	g.tex.Textures[10] = getSpriteFromFile("tree_10.png")
-	g.tex.Textures[14] = getSpriteFromFile("tree_14.png")
+	g.tex.Textures[9] = g.getSpriteFromFile("tree_09.png")
+	g.tex.Textures[10] = g.getSpriteFromFile("tree_10.png")
+	g.tex.Textures[14] = g.getSpriteFromFile("tree_14.png")
+
+	g.tex.Textures[15] = g.getSpriteFromFile("sorcerer_sheet.png")
 
-	g.tex.Textures[15] = getSpriteFromFile("sorcerer_sheet.png")
+	// names for the wall textures, with generated variants of the stone for visual variety
+	g.tex.NameTexture("stone", 0)
+	g.tex.NameTexture("house", 1)
+	g.tex.GenerateVariants("stone",
+		raycaster.VariantDarkened(0.4),
+		raycaster.VariantDamaged(1, 0.6),
+		raycaster.VariantMossy(2, 0.5),
+		raycaster.VariantDesaturated(0.7),
+	)
 
-	g.floor = getTextureFromFile("floor.png")
-	g.sky = getTextureFromFile("sky.png")
+	g.floor = g.getTextureFromFile("floor.png")
+	g.sky = g.getTextureFromFile("sky.png")
 
 	// just setting the grass texture apart from the rest since it gets special handling
-	g.floorLvl.TexRGBA = make([]*image.RGBA, 1)
-	g.floorLvl.TexRGBA[0] = getRGBAFromFile("grass.png")
+	g.floorTex = make([]*image.RGBA, 2)
+	g.floorTex[0] = g.getRGBAFromFile("grass.png")
+
+	// roof tops of buildings
+	g.floorTex[1] = g.getRGBAFromFile("wood.png")
+
+	// smaller copies of the wall textures so distant walls do not shimmer
+	g.tex.GenerateMipmaps(mipLevels)
 }
 
-func getRGBAFromFile(texFile string) *image.RGBA {
+func (g *Game) getRGBAFromFile(texFile string) *image.RGBA {
 	var rgba *image.RGBA
 	resourcePath := filepath.Join("engine", "content", "textures")
-	_, tex, err := ebitenutil.NewImageFromFile(filepath.Join(resourcePath, texFile), ebiten.FilterNearest)
+	_, tex, err := g.tex.LoadImage(filepath.Join(resourcePath, texFile))
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -148,7 +456,7 @@ func getRGBAFromFile(texFile string) *image.RGBA {
 		// convert into RGBA format
 		for x := 0; x < texSize; x++ {
 			for y := 0; y < texSize; y++ {
-				clr := tex.At(x, y).(color.RGBA)
+				clr := color.RGBAModel.Convert(tex.At(x, y)).(color.RGBA)
 				rgba.SetRGBA(x, y, clr)
 			}
 		}
@@ -157,18 +465,23 @@ func getRGBAFromFile(texFile string) *image.RGBA {
 	return rgba
 }
 
-func getTextureFromFile(texFile string) *ebiten.Image {
+func (g *Game) getTextureFromFile(texFile string) *ebiten.Image {
 	resourcePath := filepath.Join("engine", "content", "textures")
-	eImg, _, err := ebitenutil.NewImageFromFile(filepath.Join(resourcePath, texFile), ebiten.FilterNearest)
+	eImg, _, err := g.tex.LoadImage(filepath.Join(resourcePath, texFile))
 	if err != nil {
 		log.Fatal(err)
 	}
 	return eImg
 }
 
-func getSpriteFromFile(sFile string) *ebiten.Image {
+func (g *Game) loadLazyTexture(texNum int, texFile string) {
+	resourcePath := filepath.Join("engine", "content", "textures")
+	g.tex.LoadLazy(texNum, filepath.Join(resourcePath, texFile))
+}
+
+func (g *Game) getSpriteFromFile(sFile string) *ebiten.Image {
 	resourcePath := filepath.Join("engine", "content", "sprites")
-	eImg, _, err := ebitenutil.NewImageFromFile(filepath.Join(resourcePath, sFile), ebiten.FilterNearest)
+	eImg, _, err := g.tex.LoadImage(filepath.Join(resourcePath, sFile))
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -179,11 +492,17 @@ func getSpriteFromFile(sFile string) *ebiten.Image {
 func (g *Game) Run() {
 	// On browsers, let's use fullscreen so that this is playable on any browsers.
 	// It is planned to ignore the given 'scale' apply fullscreen automatically on browsers (#571).
-	if runtime.GOARCH == "js" || runtime.GOOS == "js" {
+	if isBrowser() {
 		ebiten.SetFullscreen(true)
+		g.tex.SetMemoryBudget(browserTextureBudget)
 	}
 
-	if err := ebiten.Run(g.Update, g.width, g.height, screenScale, "Raycaster-Go"); err != nil {
+	// set target FPS (TPS) only once the game loop is ours to run
+	ebiten.SetMaxTPS(targetTPS)
+
+	scale := float64(screenWidth) / float64(g.width)
+	err := ebiten.Run(g.Update, g.width, g.height, scale, "Raycaster-Go")
+	if err != nil && err != errBenchmarkDone && err != errQuit {
 		log.Fatal(err)
 	}
 }
@@ -191,37 +510,139 @@ func (g *Game) Run() {
 // Update - Allows the game to run logic such as updating the world,
 // checking for collisions, gathering input, and playing audio.
 func (g *Game) Update(screen *ebiten.Image) error {
-	g.view = screen
+	defer g.captureCrash()
+	tickStart := time.Now()
+
+	g.view = g.frame
+	g.input = g.nextInput()
+	g.updateChatLog()
+
+	// swap in textures decoded in the background
+	g.tex.Update()
+	g.frameStats.beginFrame(time.Second / targetTPS)
+
+	// nothing else runs until loading is done
+	if g.loading != nil && g.updateLoadingScreen(screen, tickStart) {
+		return nil
+	}
+
+	// Perform logical updates, the camera keeps its last view while paused
+	// spectators take the map state from their feed instead of simulating it
+	// every step of this tick covers the same real time, whatever the TPS has been set to since
+	dt := 1.0 / float64(ebiten.MaxTPS())
+	start := time.Now()
+	if g.spectator == nil {
+		g.mapObj.Update(dt)
+	}
+	g.frameStats.pass("map", start)
+	if !g.mapObj.GetClock().IsPaused() {
+		start = time.Now()
+		if g.spectator != nil {
+			g.updateSpectator()
+		} else if g.lockstep != nil {
+			g.updateLockstep(dt)
+		} else {
+			g.camera.UpdateWithDelta(dt)
+		}
+		g.frameStats.pass("cast", start)
+		g.updateReplayCamera()
+		if g.feed != nil {
+			g.updateSpectatorFeed()
+		}
 
-	// Perform logical updates
-	g.camera.Update()
+		// sprites looking out for the player
+		g.mapObj.UpdatePerception(g.perceptionTarget(), sim.PlayerFaction)
+
+		start = time.Now()
+		g.updatePortal()
+		g.updateSecurityScreens()
+		g.frameStats.pass("portal", start)
+
+		start = time.Now()
+		g.layout.update()
+		g.frameStats.pass("viewports", start)
+	}
 
 	// TODO: Add your update logic here
 	g.handleInput()
+	if g.lockstep != nil {
+		g.applyLockstepInput()
+	}
+	g.updateAudio()
+	g.haptics.Update(1.0 / float64(ebiten.MaxTPS()))
+	if g.quit {
+		return errQuit
+	}
 
-	if ebiten.IsDrawingSkipped() {
+	if ebiten.IsDrawingSkipped() && !g.IsCapturingFrames() && !g.IsBenchmarking() {
 		// When the game is running slowly, the rendering result
 		// will not be adopted.
+		g.runBackgroundTasks(tickStart)
 		return nil
 	}
 
 	// Render game to screen
+	start = time.Now()
 	g.draw()
+	g.frameStats.pass("draw", start)
 
-	// TPS counter
-	fps := fmt.Sprintf("TPS: %f/%v", ebiten.CurrentTPS(), ebiten.MaxTPS())
-	ebitenutil.DebugPrint(g.view, fps)
+	// TPS counter, hidden while capturing since it differs between runs
+	if g.showFPS && !g.IsCapturingFrames() {
+		fps := fmt.Sprintf("TPS: %f/%v", ebiten.CurrentTPS(), ebiten.MaxTPS())
+		ebitenutil.DebugPrint(g.view, fps)
+	}
+	if g.showFrameStats {
+		g.drawFrameStats()
+	}
+	if g.showNavDebug {
+		g.drawNavDebug()
+	}
+	g.drawConsole()
+
+	// apply post processing to the final frame
+	start = time.Now()
+	g.presentFrame(screen)
+	g.frameStats.pass("present", start)
+
+	if g.IsCapturingFrames() {
+		g.writeCapturedFrame(screen)
+	}
+	if g.screenshotPending {
+		g.writeScreenshot(screenRGBA(screen))
+	}
+	if g.IsBenchmarking() {
+		if err := g.updateBenchmark(tickStart); err != nil {
+			return err
+		}
+	}
+	g.runBackgroundTasks(tickStart)
 
 	return nil
 }
 
+// GetScheduler returns the scheduler running background work with the time left in each tick
+func (g *Game) GetScheduler() *sim.Scheduler {
+	return g.scheduler
+}
+
+// GetPathQueue returns the queue answering path requests for the current map in the background
+func (g *Game) GetPathQueue() *sim.PathQueue {
+	return g.paths
+}
+
+// runBackgroundTasks gives the scheduler whatever is left of the tick budget
+func (g *Game) runBackgroundTasks(tickStart time.Time) {
+	tick := time.Second / targetTPS
+	budget := time.Duration(float64(tick)*backgroundShare) - time.Since(tickStart)
+	start := time.Now()
+	g.scheduler.Run(budget)
+	g.frameStats.pass("background", start)
+}
+
 func (g *Game) handleInput() {
-	mx, my := ebiten.CursorPosition()
+	g.runInputCommands()
 
-	forward := false
-	backward := false
-	rotLeft := false
-	rotRight := false
+	mx, my := ebiten.CursorPosition()
 
 	if ebiten.IsMouseButtonPressed(ebiten.MouseButtonLeft) {
 		fmt.Printf("mouse left clicked: (%v, %v)\n", mx, my)
@@ -244,38 +665,158 @@ func (g *Game) handleInput() {
 		g.DebugOnce = false
 	}
 
-	if ebiten.IsKeyPressed(ebiten.KeyA) || ebiten.IsKeyPressed(ebiten.KeyLeft) {
+	clock := g.mapObj.GetClock()
+	if g.input.justPressed(ebiten.KeyF3) {
+		g.showFrameStats = !g.showFrameStats
+	}
+
+	if g.input.justPressed(ebiten.KeyF4) {
+		g.showNavDebug = !g.showNavDebug
+	}
+
+	if g.input.justPressed(ebiten.KeyF7) {
+		g.SetCaptions(!g.IsCaptions())
+	}
+
+	if g.input.justPressed(ebiten.KeyF8) {
+		if g.camera.GetShading() == raycaster.ShadingLinear {
+			g.camera.SetShading(raycaster.ShadingClassic)
+		} else {
+			g.camera.SetShading(raycaster.ShadingLinear)
+		}
+	}
+
+	if g.input.justPressed(ebiten.KeyF9) {
+		g.cycleColorFilter()
+	}
+
+	if g.input.justPressed(ebiten.KeyF10) {
+		g.toggleWinterTheme()
+	}
+
+	if g.input.justPressed(ebiten.KeyF12) {
+		g.TakeScreenshot()
+	}
+
+	if g.menu != nil {
+		g.updateMenu()
+		return
+	}
+	if g.input.justPressed(ebiten.KeyEscape) {
+		g.OpenMenu(g.NewPauseMenu())
+		return
+	}
+	if g.spectator != nil {
+		g.handleSpectatorInput()
+		return
+	}
+
+	if g.lockstep != nil {
+		// the player moves by the input exchanged with the partner in applyLockstepInput
+		g.handleLockstepUI()
+		return
+	}
+
+	if g.actionJustPressed(ActionPause) {
+		clock.SetPaused(!clock.IsPaused())
+	}
+	if clock.IsPaused() {
+		return
+	}
+
+	// use closes the stats screen or moves the dialogue on before it uses anything in the world
+	f := g.input
+	if g.actionJustPressed(ActionUse) && (g.intermission != nil || g.dialogue != nil) {
+		if g.intermission != nil {
+			g.intermission = nil
+		} else {
+			g.advanceDialogue()
+		}
+		f = g.withoutAction(f, ActionUse)
+	}
+	g.handlePlayerInput(g.camera.Camera, f)
+}
+
+// handlePlayerInput uses, respawns and moves a player camera by the actions in an input frame, the local
+// player by the input of this tick or a lockstep partner by theirs
+func (g *Game) handlePlayerInput(cam *sim.Camera, f inputFrame) {
+	forward := false
+	backward := false
+	rotLeft := false
+	rotRight := false
+
+	if g.actionJustPressedIn(f, ActionUse) {
+		s, interaction := cam.Use()
+		if door := cam.GetUsableDoor(); s == nil && door != nil {
+			door.Toggle()
+		}
+		if cam == g.camera.Camera {
+			g.showDialogue(interaction)
+		}
+		if interaction != nil && interaction.Action != "" {
+			g.mapObj.GetObjectives().Complete(interaction.Action)
+		}
+	}
+
+	if g.actionJustPressedIn(f, ActionRespawn) {
+		cam.Respawn()
+	}
+
+	if g.actionJustPressedIn(f, ActionZoom) && cam == g.camera.Camera {
+		g.toggleZoom()
+	}
+
+	if g.actionPressedIn(f, ActionTurnLeft) {
 		rotLeft = true
 	}
-	if ebiten.IsKeyPressed(ebiten.KeyD) || ebiten.IsKeyPressed(ebiten.KeyRight) {
+	if g.actionPressedIn(f, ActionTurnRight) {
 		rotRight = true
 	}
 
-	if ebiten.IsKeyPressed(ebiten.KeyW) || ebiten.IsKeyPressed(ebiten.KeyUp) {
+	if g.actionPressedIn(f, ActionForward) {
 		forward = true
 	}
-	if ebiten.IsKeyPressed(ebiten.KeyS) || ebiten.IsKeyPressed(ebiten.KeyDown) {
+	if g.actionPressedIn(f, ActionBackward) {
 		backward = true
 	}
 
-	if forward {
-		g.camera.Move(0.06)
+	// sprint forward, widening the view
+	sprint := forward && g.actionPressedIn(f, ActionSprint)
+	cam.SprintFOV(sprint)
+
+	if sprint {
+		cam.Move(0.1)
+	} else if forward {
+		cam.Move(0.06)
 	} else if backward {
-		g.camera.Move(-0.06)
+		cam.Move(-0.06)
+	}
+
+	// look up and down
+	if g.actionPressedIn(f, ActionLookUp) {
+		cam.Pitch(1)
+	} else if g.actionPressedIn(f, ActionLookDown) {
+		cam.Pitch(-1)
+	}
+
+	if g.actionPressedIn(f, ActionUp) {
+		cam.Swim(0.004)
+	} else if g.actionPressedIn(f, ActionDown) {
+		cam.Swim(-0.004)
 	}
 
-	if ebiten.IsKeyPressed(ebiten.KeyAlt) {
+	if g.actionPressedIn(f, ActionStrafe) {
 		// strafe instead of rotate
 		if rotLeft {
-			g.camera.Strafe(-0.05)
+			cam.Strafe(-0.05)
 		} else if rotRight {
-			g.camera.Strafe(0.05)
+			cam.Strafe(0.05)
 		}
 	} else {
 		if rotLeft {
-			g.camera.Rotate(0.03)
+			cam.Rotate(0.03)
 		} else if rotRight {
-			g.camera.Rotate(-0.03)
+			cam.Rotate(-0.03)
 		}
 	}
 }
@@ -283,55 +824,29 @@ func (g *Game) handleInput() {
 func (g *Game) draw() {
 	g.view.Clear()
 
-	//--draw basic sky and floor--//
-	texRect := image.Rect(0, 0, texSize, texSize)
-	whiteRGBA := &color.RGBA{255, 255, 255, 255}
-
-	// spriteBatch.Draw(floor,
-	//    new Rectangle(0, (int)(height * 0.5f), width, (int)(height * 0.5f)),
-	//    new Rectangle(0, 0, texSize, texSize),
-	//    Color.White);
-	floorRect := image.Rect(0, int(float64(g.height)*0.5), g.width, 2*int(float64(g.height)*0.5))
-	g.spriteBatch.draw(g.floor, &floorRect, &texRect, whiteRGBA)
-
-	// spriteBatch.Draw(sky,
-	//    new Rectangle(0, 0, width, (int)(height * 0.5f)),
-	//    new Rectangle(0, 0, texSize, texSize),
-	//    Color.White);
-	skyRect := image.Rect(0, 0, g.width, int(float64(g.height)*0.5))
-	g.spriteBatch.draw(g.sky, &skyRect, &texRect, whiteRGBA)
-
-	//--draw walls--//
-	for x := 0; x < g.width; x++ {
-		for i := cap(g.levels) - 1; i >= 0; i-- {
-			g.spriteBatch.draw(g.levels[i].CurrTex[x], g.levels[i].Sv[x], g.levels[i].Cts[x], g.levels[i].St[x])
-		}
+	//--world is drawn offscreen so head bob and shake can move it without moving the HUD--//
+	g.worldFrame.Clear()
+	g.drawWorld(g.worldFrame, g.shownWorld())
+	g.drawBloom()
+	g.drawMotionBlur()
+	g.drawThemeFade()
+	g.drawWorldFrame()
+
+	//--screen effects and meters--//
+	if g.camera.IsUnderwater() {
+		g.drawScreenTint(underwaterTint)
 	}
-
-	// draw textured floor
-	floorImg, err := ebiten.NewImageFromImage(g.floorLvl.HorBuffer, ebiten.FilterLinear)
-	if err != nil || floorImg == nil {
-		log.Fatal(err)
-	} else {
-		op := &ebiten.DrawImageOptions{}
-		op.Filter = ebiten.FilterLinear
-		g.view.DrawImage(floorImg, op)
-	}
-
-	// draw sprites
-	for x := 0; x < g.width; x++ {
-		for i := 0; i < cap(g.spriteLvls); i++ {
-			spriteLvl := g.spriteLvls[i]
-			if spriteLvl == nil {
-				continue
-			}
-
-			texture := spriteLvl.CurrTex[x]
-			if texture != nil {
-				g.spriteBatch.draw(texture, spriteLvl.Sv[x], spriteLvl.Cts[x], spriteLvl.St[x])
-			}
-		}
+	for _, tint := range g.camera.GetEffectTints() {
+		g.drawScreenTint(tint)
 	}
+	g.drawScreenFlash()
+	g.drawDamageIndicators()
+	g.drawPostEffects()
+	g.drawTurnVignette()
+	g.drawZoom()
+	g.drawAirMeter()
+	g.layout.draw(g.view)
+	g.drawHud()
 
 	if g.DebugOnce {
 		// end DebugOnce after one loop
@@ -351,40 +866,8 @@ func (g *Game) draw() {
 	}
 }
 
-//returns initialised Level structs
-func (g *Game) createLevels(numLevels int) ([]*raycaster.Level, *raycaster.HorLevel) {
-	var levelArr []*raycaster.Level
-	levelArr = make([]*raycaster.Level, numLevels)
-
-	for i := 0; i < numLevels; i++ {
-		levelArr[i] = new(raycaster.Level)
-		levelArr[i].Sv = raycaster.SliceView(g.width, g.height)
-		levelArr[i].Cts = make([]*image.Rectangle, g.width)
-		levelArr[i].St = make([]*color.RGBA, g.width)
-		levelArr[i].CurrTex = make([]*ebiten.Image, g.width)
-	}
-
-	horizontalLevel := new(raycaster.HorLevel)
-	horizontalLevel.Clear(g.width, g.height)
-
-	return levelArr, horizontalLevel
-}
-
-func (g *Game) createSpriteLevels() []*raycaster.Level {
-	// create empty "level" for all sprites to render using similar slice methods as walls
-	numSprites := g.mapObj.GetNumSprites()
-
-	var spriteArr []*raycaster.Level
-	spriteArr = make([]*raycaster.Level, numSprites)
-
-	return spriteArr
-}
-
-func (s *SpriteBatch) draw(texture *ebiten.Image, destinationRectangle *image.Rectangle, sourceRectangle *image.Rectangle, color *color.RGBA) {
-	if texture == nil || destinationRectangle == nil || sourceRectangle == nil {
-		return
-	}
-
+// sliceOptions returns the draw options placing the source rectangle of a texture over the destination rectangle
+func sliceOptions(destinationRectangle *image.Rectangle, sourceRectangle *image.Rectangle) (*ebiten.DrawImageOptions, float64, float64) {
 	if sourceRectangle.Min.X == 0 {
 		// fixes subImage from clipping at edges of textures which can cause gaps
 		sourceRectangle.Min.X++
@@ -407,14 +890,41 @@ func (s *SpriteBatch) draw(texture *ebiten.Image, destinationRectangle *image.Re
 	op.GeoM.Scale(scaleX, scaleY)
 	op.GeoM.Translate(float64(destinationRectangle.Min.X), float64(destinationRectangle.Min.Y))
 
+	return op, scaleX, scaleY
+}
+
+func (s *SpriteBatch) draw(texture *ebiten.Image, destinationRectangle *image.Rectangle, sourceRectangle *image.Rectangle, color *color.RGBA) {
+	s.drawHooked(texture, destinationRectangle, sourceRectangle, color, 0, nil)
+}
+
+// drawHooked draws like draw, scaled by shade instead of the color tint when shade is above 0,
+// then lets the texture draw hook and the given hook change the draw options
+func (s *SpriteBatch) drawHooked(texture *ebiten.Image, destinationRectangle *image.Rectangle, sourceRectangle *image.Rectangle, color *color.RGBA, shade float64, hook raycaster.DrawHook) {
+	if texture == nil || destinationRectangle == nil || sourceRectangle == nil {
+		return
+	}
+
+	op, scaleX, scaleY := sliceOptions(destinationRectangle, sourceRectangle)
+
 	var destTexture *ebiten.Image
 	destTexture = texture.SubImage(*sourceRectangle).(*ebiten.Image)
 
-	if color != nil {
+	if shade > 0 {
+		// linear light shading, kept as float so distant slices don't band
+		op.ColorM.Scale(shade, shade, shade, 1)
+	} else if color != nil {
 		// color channel modulation/tinting
 		op.ColorM.Scale(float64(color.R)/255, float64(color.G)/255, float64(color.B)/255, float64(color.A)/255)
 	}
 
+	// custom draw effects
+	if texHook := s.g.tex.GetDrawHook(texture); texHook != nil {
+		texHook(op, destinationRectangle.Min.X)
+	}
+	if hook != nil {
+		hook(op, destinationRectangle.Min.X)
+	}
+
 	if s.g.DebugX > destinationRectangle.Min.X && s.g.DebugX <= destinationRectangle.Max.X &&
 		s.g.DebugY > destinationRectangle.Min.Y && s.g.DebugY <= destinationRectangle.Max.Y {
 
@@ -426,8 +936,7 @@ func (s *SpriteBatch) draw(texture *ebiten.Image, destinationRectangle *image.Re
 		}
 	}
 
-	view := s.g.view
-	view.DrawImage(destTexture, op)
+	s.target.DrawImage(destTexture, op)
 }
 
 // DebugPrintfOnce prints info to screen only one time until g.DebugFlag cleared again
@@ -0,0 +1,393 @@
+package engine
+
+import (
+	"errors"
+	"fmt"
+	"image/color"
+	"strconv"
+	"strings"
+
+	"github.com/hajimehoshi/ebiten"
+	"github.com/hajimehoshi/ebiten/ebitenutil"
+)
+
+const (
+	// menu layout
+	menuWidth       = 320
+	menuSliderWidth = 10
+
+	// field of view range of the options menu slider
+	menuMinFOV = 50.0
+	menuMaxFOV = 110.0
+)
+
+var menuHighlight = color.RGBA{255, 255, 255, 40}
+
+// errQuit is returned from Update to stop the game when Quit is chosen in a menu
+var errQuit = errors.New("quit")
+
+// MenuItemKind is what a menu item does when chosen
+type MenuItemKind int
+
+const (
+	// MenuButton calls OnSelect
+	MenuButton MenuItemKind = iota
+
+	// MenuToggle flips a setting on and off
+	MenuToggle
+
+	// MenuSlider moves a value between Min and Max in Steps with left and right
+	MenuSlider
+
+	// MenuKey waits for a key press and binds it to Action
+	MenuKey
+)
+
+// MenuItem is one line of a menu
+type MenuItem struct {
+	// Label --string table key of the text shown--//
+	Label string
+
+	Kind MenuItemKind
+
+	// OnSelect --called when a button is chosen--//
+	OnSelect func()
+
+	// Detail --text shown after the label of a button, e.g. the current choice--//
+	Detail func() string
+
+	// Checked, SetChecked --state of a toggle--//
+	Checked    func() bool
+	SetChecked func(bool)
+
+	// Value, SetValue, Min, Max, Step --value of a slider and its range--//
+	Value    func() float64
+	SetValue func(float64)
+	Min      float64
+	Max      float64
+	Step     float64
+
+	// Action --action a key item rebinds--//
+	Action string
+}
+
+// NewButton creates an item calling onSelect when chosen
+func NewButton(label string, onSelect func()) *MenuItem {
+	return &MenuItem{Label: label, Kind: MenuButton, OnSelect: onSelect}
+}
+
+// NewToggle creates an item switching a setting on and off
+func NewToggle(label string, checked func() bool, setChecked func(bool)) *MenuItem {
+	return &MenuItem{Label: label, Kind: MenuToggle, Checked: checked, SetChecked: setChecked}
+}
+
+// NewSlider creates an item moving a value between min and max in steps
+func NewSlider(label string, min, max, step float64, value func() float64, setValue func(float64)) *MenuItem {
+	return &MenuItem{Label: label, Kind: MenuSlider, Min: min, Max: max, Step: step, Value: value, SetValue: setValue}
+}
+
+// NewKeyBinding creates an item rebinding an action to the next key pressed
+func NewKeyBinding(action string) *MenuItem {
+	return &MenuItem{Label: "action." + action, Kind: MenuKey, Action: action}
+}
+
+// Menu is a vertical list of items, drawn over the view and navigated with the arrow keys, Enter and Escape.
+// The map clock is paused while a menu is open.
+type Menu struct {
+	// Title --string table key of the heading--//
+	Title string
+
+	Items []*MenuItem
+
+	// OnClose --called when the menu is closed with Escape or Back, e.g. to save settings--//
+	OnClose func()
+
+	selected  int
+	capturing bool
+	parent    *Menu
+	paused    bool
+}
+
+// OpenMenu opens a menu, over the one already open if there is one so closing it goes back
+func (g *Game) OpenMenu(m *Menu) {
+	m.parent, m.selected, m.capturing = g.menu, 0, false
+	if g.menu == nil {
+		clock := g.mapObj.GetClock()
+		m.paused = !clock.IsPaused()
+		clock.SetPaused(true)
+	} else {
+		m.paused = g.menu.paused
+	}
+	g.menu = m
+}
+
+// CloseMenu closes the open menu, going back to the one it was opened over
+func (g *Game) CloseMenu() {
+	m := g.menu
+	if m == nil {
+		return
+	}
+	if m.OnClose != nil {
+		m.OnClose()
+	}
+	g.menu = m.parent
+	if g.menu == nil && m.paused {
+		g.mapObj.GetClock().SetPaused(false)
+	}
+}
+
+// IsMenuOpen returns true while a menu is open
+func (g *Game) IsMenuOpen() bool {
+	return g.menu != nil
+}
+
+// Quit stops the game at the end of the tick
+func (g *Game) Quit() {
+	g.quit = true
+}
+
+// NewTitleMenu creates the menu shown before play starts
+func (g *Game) NewTitleMenu() *Menu {
+	return &Menu{Title: "menu.title", Items: []*MenuItem{
+		NewButton("menu.start", g.closeMenus),
+		NewButton("menu.options", func() { g.OpenMenu(g.NewOptionsMenu()) }),
+		NewButton("menu.quit", g.Quit),
+	}}
+}
+
+// NewPauseMenu creates the menu opened with Escape during play
+func (g *Game) NewPauseMenu() *Menu {
+	return &Menu{Title: "menu.paused", Items: []*MenuItem{
+		NewButton("menu.resume", g.closeMenus),
+		NewButton("menu.options", func() { g.OpenMenu(g.NewOptionsMenu()) }),
+		NewButton("menu.quit", g.Quit),
+	}}
+}
+
+// NewOptionsMenu creates the menu of engine settings, saved to the profile when it closes
+func (g *Game) NewOptionsMenu() *Menu {
+	language := NewButton("menu.language", g.cycleLanguage)
+	language.Detail = g.locale.GetLanguage
+
+	return &Menu{Title: "menu.options", OnClose: g.saveSettings, Items: []*MenuItem{
+		NewSlider("menu.fov", menuMinFOV, menuMaxFOV, 5, g.camera.GetFOV, g.camera.SetFOV),
+		NewToggle("menu.captions", g.IsCaptions, g.SetCaptions),
+		NewToggle("menu.motionblur", g.IsMotionBlur, g.SetMotionBlur),
+		NewToggle("menu.mirror", g.IsRearViewMirror, g.SetRearViewMirror),
+		language,
+		NewButton("menu.controls", func() { g.OpenMenu(g.NewControlsMenu()) }),
+		NewButton("menu.back", g.CloseMenu),
+	}}
+}
+
+// NewControlsMenu creates the menu rebinding the key of each action
+func (g *Game) NewControlsMenu() *Menu {
+	m := &Menu{Title: "menu.controls", OnClose: g.saveSettings}
+	for _, action := range actionOrder {
+		m.Items = append(m.Items, NewKeyBinding(action))
+	}
+	m.Items = append(m.Items,
+		NewButton("menu.defaults", func() { g.bindings = DefaultBindings() }),
+		NewButton("menu.back", g.CloseMenu),
+	)
+	return m
+}
+
+// closeMenus closes every open menu
+func (g *Game) closeMenus() {
+	for g.menu != nil {
+		g.CloseMenu()
+	}
+}
+
+// cycleLanguage selects the next language with a string table
+func (g *Game) cycleLanguage() {
+	languages := g.locale.GetLanguages()
+	for i, l := range languages {
+		if l == g.locale.GetLanguage() {
+			g.locale.SetLanguage(languages[(i+1)%len(languages)])
+			return
+		}
+	}
+}
+
+// updateMenu moves through the open menu and changes its items from the input of the tick
+func (g *Game) updateMenu() {
+	m := g.menu
+	if len(m.Items) == 0 {
+		if g.input.justPressed(ebiten.KeyEscape) {
+			g.CloseMenu()
+		}
+		return
+	}
+	item := m.Items[m.selected]
+
+	if m.capturing {
+		// the first bindable key pressed is bound, Escape cancels
+		if g.input.justPressed(ebiten.KeyEscape) {
+			m.capturing = false
+			return
+		}
+		for _, k := range replayKeys {
+			if g.input.justPressed(k) && g.SetBinding(item.Action, k) {
+				m.capturing = false
+				return
+			}
+		}
+		return
+	}
+
+	switch {
+	case g.input.justPressed(ebiten.KeyEscape):
+		g.CloseMenu()
+	case g.input.justPressed(ebiten.KeyUp) || g.input.justPressed(ebiten.KeyW):
+		m.selected = (m.selected + len(m.Items) - 1) % len(m.Items)
+	case g.input.justPressed(ebiten.KeyDown) || g.input.justPressed(ebiten.KeyS):
+		m.selected = (m.selected + 1) % len(m.Items)
+	case item.Kind == MenuSlider && (g.input.justPressed(ebiten.KeyLeft) || g.input.justPressed(ebiten.KeyA)):
+		item.SetValue(clampFloat(item.Value()-item.Step, item.Min, item.Max))
+	case item.Kind == MenuSlider && (g.input.justPressed(ebiten.KeyRight) || g.input.justPressed(ebiten.KeyD)):
+		item.SetValue(clampFloat(item.Value()+item.Step, item.Min, item.Max))
+	case g.input.justPressed(ebiten.KeyEnter) || g.input.justPressed(ebiten.KeySpace) || g.input.justPressed(ebiten.KeyE):
+		switch item.Kind {
+		case MenuButton:
+			if item.OnSelect != nil {
+				item.OnSelect()
+			}
+		case MenuToggle:
+			item.SetChecked(!item.Checked())
+		case MenuKey:
+			m.capturing = true
+		}
+	}
+}
+
+// clampFloat returns v limited to the range min to max
+func clampFloat(v, min, max float64) float64 {
+	if v < min {
+		return min
+	}
+	if v > max {
+		return max
+	}
+	return v
+}
+
+// itemText returns the label and current value of a menu item
+func (g *Game) itemText(m *Menu, item *MenuItem, selected bool) string {
+	label := g.tr(item.Label)
+	switch item.Kind {
+	case MenuButton:
+		if item.Detail != nil {
+			return fmt.Sprintf("%-16s%s", label, item.Detail())
+		}
+	case MenuToggle:
+		state := g.tr("menu.off")
+		if item.Checked() {
+			state = g.tr("menu.on")
+		}
+		return fmt.Sprintf("%-16s%s", label, state)
+	case MenuSlider:
+		filled := 0
+		if item.Max > item.Min {
+			filled = int((item.Value() - item.Min) / (item.Max - item.Min) * menuSliderWidth)
+		}
+		bar := strings.Repeat("#", filled) + strings.Repeat("-", menuSliderWidth-filled)
+		return fmt.Sprintf("%-16s[%s] %s", label, bar, strconv.FormatFloat(item.Value(), 'f', 0, 64))
+	case MenuKey:
+		if selected && m.capturing {
+			return fmt.Sprintf("%-16s%s", label, g.tr("menu.presskey"))
+		}
+		var names []string
+		for _, k := range g.bindings[item.Action] {
+			names = append(names, KeyName(k))
+		}
+		return fmt.Sprintf("%-16s%s", label, strings.Join(names, " / "))
+	}
+	return label
+}
+
+// drawMenu draws the open menu in a box in the middle of the view, marking the selected item
+func (g *Game) drawMenu() {
+	m := g.menu
+	w := float64(menuWidth)
+	h := float64((len(m.Items)+2)*lineHeight + 2*dialoguePadding)
+	x, y := float64(g.width)/2-w/2, float64(g.height)/2-h/2
+
+	ebitenutil.DrawRect(g.view, x-1, y-1, w+2, h+2, dialogueBorder)
+	ebitenutil.DrawRect(g.view, x, y, w, h, dialogueBack)
+
+	title := g.tr(m.Title)
+	tx, ty := int(x)+dialoguePadding, int(y)+dialoguePadding
+	g.drawText(title, g.width/2-g.textWidth(title)/2, ty)
+	for i, item := range m.Items {
+		iy := ty + (i+2)*lineHeight
+		if i == m.selected {
+			ebitenutil.DrawRect(g.view, x, float64(iy), w, lineHeight, menuHighlight)
+			g.drawText(">", tx, iy)
+		}
+		g.drawText(g.itemText(m, item, i == m.selected), tx+2*debugGlyphWidth, iy)
+	}
+}
+
+// saveSettings stores the menu settings and key bindings in the profile and saves it
+func (g *Game) saveSettings() {
+	p := g.profile
+	if p == nil {
+		return
+	}
+	p.SetSetting("fov", strconv.FormatFloat(g.camera.GetFOV(), 'f', -1, 64))
+	p.SetSetting("captions", strconv.FormatBool(g.IsCaptions()))
+	p.SetSetting("motionBlur", strconv.FormatBool(g.IsMotionBlur()))
+	p.SetSetting("mirror", strconv.FormatBool(g.IsRearViewMirror()))
+	p.SetSetting("language", g.locale.GetLanguage())
+	for action, keys := range g.bindings {
+		var names []string
+		for _, k := range keys {
+			names = append(names, KeyName(k))
+		}
+		p.SetSetting("key."+action, strings.Join(names, ","))
+	}
+	if err := p.Save(); err != nil {
+		fmt.Printf("Unable to save profile: %v\n", err)
+	}
+}
+
+// applySettings sets the menu settings and key bindings stored in the profile, keeping the current value
+// of any that are missing or invalid
+func (g *Game) applySettings() {
+	p := g.profile
+	if p == nil {
+		return
+	}
+	if fov, err := strconv.ParseFloat(p.GetSetting("fov", ""), 64); err == nil {
+		g.camera.SetFOV(fov)
+	}
+	if on, err := strconv.ParseBool(p.GetSetting("captions", "")); err == nil {
+		g.SetCaptions(on)
+	}
+	if on, err := strconv.ParseBool(p.GetSetting("motionBlur", "")); err == nil {
+		g.SetMotionBlur(on)
+	}
+	if on, err := strconv.ParseBool(p.GetSetting("mirror", "")); err == nil {
+		g.SetRearViewMirror(on)
+	}
+	if language := p.GetSetting("language", ""); language != "" {
+		g.locale.SetLanguage(language)
+	}
+	for _, action := range actionOrder {
+		names := p.GetSetting("key."+action, "")
+		if names == "" {
+			continue
+		}
+		var keys []ebiten.Key
+		for _, name := range strings.Split(names, ",") {
+			if k, ok := keyByName(name); ok {
+				keys = append(keys, k)
+			}
+		}
+		if len(keys) > 0 {
+			g.bindings[action] = keys
+		}
+	}
+}
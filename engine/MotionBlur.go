@@ -0,0 +1,55 @@
+package engine
+
+import (
+	"math"
+
+	"github.com/hajimehoshi/ebiten"
+)
+
+const (
+	// opacity of the previous frame blended over the current one at full turn speed
+	motionBlurAlpha = 0.6
+
+	// turn speed in radians per tick at which the blur is at full strength
+	motionBlurTurnSpeed = 0.05
+
+	// horizontal field of view of the camera plane, used to turn angular velocity into pixels
+	cameraFOV = 2 * 0.5829 // 2 * atan(0.66)
+)
+
+// SetMotionBlur turns the rotational motion blur on or off
+func (g *Game) SetMotionBlur(enabled bool) {
+	g.motionBlur = enabled
+	if enabled && g.prevFrame == nil {
+		g.prevFrame, _ = ebiten.NewImage(g.width, g.height, ebiten.FilterLinear)
+	}
+}
+
+// IsMotionBlur returns true if the rotational motion blur is on
+func (g *Game) IsMotionBlur() bool {
+	return g.motionBlur
+}
+
+// drawMotionBlur blends the previous world frame over the current one while turning, shifted along the
+// turn in proportion to the angular velocity, then keeps the current frame for the next tick
+func (g *Game) drawMotionBlur() {
+	if !g.motionBlur {
+		return
+	}
+
+	turn := g.camera.GetTurnSpeed()
+	strength := math.Min(1, math.Abs(turn)/motionBlurTurnSpeed)
+	if strength > 0 {
+		// turning left (positive) moves the scene right across the screen
+		shift := turn / cameraFOV * float64(g.width) / 2
+
+		op := &ebiten.DrawImageOptions{}
+		op.Filter = ebiten.FilterLinear
+		op.GeoM.Translate(shift, 0)
+		op.ColorM.Scale(1, 1, 1, motionBlurAlpha*strength)
+		g.worldFrame.DrawImage(g.prevFrame, op)
+	}
+
+	g.prevFrame.Clear()
+	g.prevFrame.DrawImage(g.worldFrame, &ebiten.DrawImageOptions{})
+}
@@ -0,0 +1,169 @@
+package engine
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"raycaster-go/engine/sim"
+
+	"github.com/hajimehoshi/ebiten"
+	"github.com/hajimehoshi/ebiten/audio"
+	"github.com/hajimehoshi/ebiten/audio/vorbis"
+)
+
+const (
+	// sample rate of the audio context, music files are resampled to it
+	audioSampleRate = 44100
+
+	// seconds tracks crossfade over when the map or the intensity changes
+	musicFadeSeconds = 2.0
+
+	// seconds the combat music keeps playing after the last damage
+	musicCombatSeconds = 8.0
+
+	// air below which the danger music plays
+	musicDangerAir = 0.3
+)
+
+// musicTrack is a looping music file with its volume, which fades in and out
+type musicTrack struct {
+	name   string
+	player *audio.Player
+	volume float64
+	fade   *sim.Tween
+}
+
+// MusicManager plays the music tracks of the current map, crossfading to a new track when the map
+// or the music intensity changes. Tracks are Ogg Vorbis files streamed from the music directory.
+type MusicManager struct {
+	ctx *audio.Context
+	dir string
+
+	// Volume --master music volume from 0 to 1--//
+	Volume float64
+
+	music     sim.MapMusic
+	intensity sim.MusicIntensity
+
+	//--the track fading in or playing, and tracks fading out--//
+	current *musicTrack
+	fading  []*musicTrack
+}
+
+// NewMusicManager creates a music manager playing tracks from the directory
+func NewMusicManager(ctx *audio.Context, dir string) *MusicManager {
+	return &MusicManager{ctx: ctx, dir: dir, Volume: 1}
+}
+
+// PlayMap crossfades to the music of a map at the current intensity
+func (m *MusicManager) PlayMap(music sim.MapMusic) {
+	m.music = music
+	m.switchTo(music.Track(m.intensity))
+}
+
+// SetIntensity crossfades to the track of the current map for the intensity, if it differs
+func (m *MusicManager) SetIntensity(intensity sim.MusicIntensity) {
+	if intensity == m.intensity {
+		return
+	}
+	m.intensity = intensity
+	m.switchTo(m.music.Track(intensity))
+}
+
+// GetIntensity returns the current music intensity
+func (m *MusicManager) GetIntensity() sim.MusicIntensity {
+	return m.intensity
+}
+
+// Update advances the crossfades and closes tracks that have faded out
+func (m *MusicManager) Update(dt float64) {
+	if m.current != nil {
+		m.current.update(dt, m.Volume)
+	}
+
+	fading := m.fading[:0]
+	for _, t := range m.fading {
+		t.update(dt, m.Volume)
+		if t.fade == nil {
+			t.player.Close()
+			continue
+		}
+		fading = append(fading, t)
+	}
+	m.fading = fading
+}
+
+// switchTo fades out the current track and fades in the named one, nothing if it is already playing
+func (m *MusicManager) switchTo(name string) {
+	if m.current != nil && m.current.name == name {
+		return
+	}
+
+	if m.current != nil {
+		m.current.fade = sim.TweenFloat(&m.current.volume, 0, musicFadeSeconds, sim.EaseInOutQuad)
+		m.fading = append(m.fading, m.current)
+		m.current = nil
+	}
+	if name == "" || m.ctx == nil {
+		return
+	}
+
+	player, err := m.open(name)
+	if err != nil {
+		fmt.Printf("Unable to play music %s: %v\n", name, err)
+		return
+	}
+	t := &musicTrack{name: name, player: player}
+	t.fade = sim.TweenFloat(&t.volume, 1, musicFadeSeconds, sim.EaseInOutQuad)
+	player.SetVolume(0)
+	player.Play()
+	m.current = t
+}
+
+// open creates a looping player for a music file
+func (m *MusicManager) open(name string) (*audio.Player, error) {
+	f, err := os.Open(filepath.Join(m.dir, name))
+	if err != nil {
+		return nil, err
+	}
+	stream, err := vorbis.Decode(m.ctx, f)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	return audio.NewPlayer(m.ctx, audio.NewInfiniteLoop(stream, stream.Length()))
+}
+
+// update advances the fade of the track and applies its volume
+func (t *musicTrack) update(dt, master float64) {
+	if t.fade != nil {
+		t.fade = t.fade.Update(dt)
+	}
+	t.player.SetVolume(t.volume * master)
+}
+
+// enterCombat switches to the combat music until musicCombatSeconds pass without damage
+func (g *Game) enterCombat() {
+	g.combatLeft = musicCombatSeconds
+}
+
+// updateAudio picks the music intensity from what the player is going through and advances the music,
+// then sets the reverb of sound effects for where the player is
+func (g *Game) updateAudio() {
+	dt := 1.0 / float64(ebiten.MaxTPS())
+	if g.combatLeft > 0 {
+		g.combatLeft -= dt
+	}
+
+	intensity := sim.MusicAmbient
+	if g.camera.GetAir() < musicDangerAir {
+		intensity = sim.MusicDanger
+	} else if g.combatLeft > 0 {
+		intensity = sim.MusicCombat
+	}
+	g.music.SetIntensity(intensity)
+	g.music.Update(dt)
+
+	// effects take on the echo of the part of the map the player is in
+	g.sounds.SetReverb(g.mapObj.GetReverb(g.camera.GetPosition()))
+}
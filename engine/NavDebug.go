@@ -0,0 +1,110 @@
+package engine
+
+import (
+	"image/color"
+	"raycaster-go/engine/sim"
+
+	"github.com/hajimehoshi/ebiten/ebitenutil"
+)
+
+const (
+	// pixels per grid cell in the navigation debug view
+	navCellSize = 8
+)
+
+var (
+	navBack      = color.RGBA{0, 0, 0, 170}
+	navWall      = color.RGBA{120, 120, 120, 220}
+	navCamera    = color.RGBA{80, 160, 255, 255}
+	navSprite    = color.RGBA{200, 200, 200, 200}
+	navRoute     = color.RGBA{255, 160, 40, 220}
+	navTarget    = color.RGBA{255, 80, 200, 255}
+	navSightLine = color.RGBA{60, 220, 60, 200}
+	navSightCut  = color.RGBA{220, 50, 50, 200}
+)
+
+// drawNavDebug draws a top-down view of the map in the bottom left corner with the camera, sprites and waypoints.
+// Sprites with NavDebug set also show their route, the point they are heading for and their line of sight to the camera.
+func (g *Game) drawNavDebug() {
+	w, h := g.mapObj.GetSize()
+	left := float64(dialogueMargin)
+	top := float64(g.height - dialogueMargin - h*navCellSize)
+
+	// grid position to screen position
+	toScreen := func(p sim.Vector2) (float64, float64) {
+		return left + p.X*navCellSize, top + p.Y*navCellSize
+	}
+
+	ebitenutil.DrawRect(g.view, left, top, float64(w*navCellSize), float64(h*navCellSize), navBack)
+	if levels := g.mapObj.GetLevels(); len(levels) > 0 {
+		for x := 0; x < w; x++ {
+			for y := 0; y < h; y++ {
+				if levels[0][x][y] > 0 {
+					ebitenutil.DrawRect(g.view, left+float64(x*navCellSize), top+float64(y*navCellSize), navCellSize, navCellSize, navWall)
+				}
+			}
+		}
+	}
+
+	for _, wp := range g.mapObj.GetActiveWaypoints() {
+		x, y := toScreen(sim.Vector2{X: wp.X, Y: wp.Y})
+		ebitenutil.DrawRect(g.view, x-2, y-2, 4, 4, wp.Color)
+	}
+
+	//--routes of the moving solids carrying debugged sprites--//
+	followers := make(map[*sim.Sprite]*sim.PathFollower)
+	for _, s := range g.mapObj.GetSolids() {
+		if s.Sprite != nil && s.GetFollower() != nil {
+			followers[s.Sprite] = s.GetFollower()
+		}
+	}
+
+	camPos := g.camera.GetPosition()
+	for _, s := range g.mapObj.GetSprites() {
+		pos := sim.Vector2{X: s.X, Y: s.Y}
+		sx, sy := toScreen(pos)
+		ebitenutil.DrawRect(g.view, sx-1, sy-1, 3, 3, navSprite)
+		if !s.NavDebug {
+			continue
+		}
+
+		follower := s.Patrol
+		if follower == nil {
+			follower = followers[s]
+		}
+		if follower != nil && follower.Path != nil && len(follower.Path.Points) > 0 {
+			g.drawNavRoute(follower, toScreen)
+			tx, ty := toScreen(follower.Target())
+			ebitenutil.DrawLine(g.view, sx, sy, tx, ty, navTarget)
+			ebitenutil.DrawRect(g.view, tx-2, ty-2, 5, 5, navTarget)
+		}
+
+		sight := navSightLine
+		if !g.mapObj.HasLineOfSight(pos, camPos) {
+			sight = navSightCut
+		}
+		cx, cy := toScreen(camPos)
+		ebitenutil.DrawLine(g.view, sx, sy, cx, cy, sight)
+	}
+
+	// camera with its facing direction
+	cx, cy := toScreen(camPos)
+	dir := g.camera.GetDirection()
+	ebitenutil.DrawRect(g.view, cx-2, cy-2, 5, 5, navCamera)
+	ebitenutil.DrawLine(g.view, cx, cy, cx+dir.X*2*navCellSize, cy+dir.Y*2*navCellSize, navCamera)
+}
+
+// drawNavRoute draws the lines between the points of the path being followed
+func (g *Game) drawNavRoute(f *sim.PathFollower, toScreen func(p sim.Vector2) (float64, float64)) {
+	points := f.Path.Points
+	for i := 1; i < len(points); i++ {
+		x1, y1 := toScreen(points[i-1])
+		x2, y2 := toScreen(points[i])
+		ebitenutil.DrawLine(g.view, x1, y1, x2, y2, navRoute)
+	}
+	if f.Path.Loop && len(points) > 2 {
+		x1, y1 := toScreen(points[len(points)-1])
+		x2, y2 := toScreen(points[0])
+		ebitenutil.DrawLine(g.view, x1, y1, x2, y2, navRoute)
+	}
+}
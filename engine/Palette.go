@@ -0,0 +1,158 @@
+package engine
+
+import (
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten"
+)
+
+const (
+	// strength of the ordered dithering, in 8-bit color steps
+	ditherSpread = 32.0
+)
+
+// 4x4 Bayer matrix for ordered dithering
+var bayer4 = [4][4]float64{
+	{0, 8, 2, 10},
+	{12, 4, 14, 6},
+	{3, 11, 1, 9},
+	{15, 7, 13, 5},
+}
+
+// paletteMode quantizes the final frame to a palette of up to 256 colors
+type paletteMode struct {
+	// colors frame pixels are matched against
+	match []color.RGBA
+
+	// colors drawn for each palette index, rotated or swapped for palette effects
+	output []color.RGBA
+
+	dither bool
+
+	// nearest palette index for each 15-bit color, -1 until looked up
+	lookup []int16
+
+	pixels []byte
+	img    *ebiten.Image
+}
+
+// SetPalette turns on paletted output using the given palette of up to 256 colors, with optional Bayer
+// dithering. A nil or empty palette turns paletted output off.
+func (g *Game) SetPalette(p color.Palette, dither bool) {
+	if len(p) == 0 {
+		g.palette = nil
+		return
+	}
+	if len(p) > 256 {
+		p = p[:256]
+	}
+
+	pm := &paletteMode{dither: dither}
+	pm.match = make([]color.RGBA, len(p))
+	for i, c := range p {
+		pm.match[i] = color.RGBAModel.Convert(c).(color.RGBA)
+	}
+	pm.output = make([]color.RGBA, len(pm.match))
+	copy(pm.output, pm.match)
+
+	pm.lookup = make([]int16, 1<<15)
+	for i := range pm.lookup {
+		pm.lookup[i] = -1
+	}
+
+	pm.pixels = make([]byte, 4*g.width*g.height)
+	pm.img, _ = ebiten.NewImage(g.width, g.height, ebiten.FilterNearest)
+	g.palette = pm
+}
+
+// RotatePalette shifts the output colors of palette entries start to end (inclusive) by n places,
+// for color cycling effects such as flowing water or lava
+func (g *Game) RotatePalette(start, end, n int) {
+	if g.palette == nil || start < 0 || end >= len(g.palette.output) || start >= end {
+		return
+	}
+
+	size := end - start + 1
+	n = ((n % size) + size) % size
+	rotated := make([]color.RGBA, size)
+	for i := 0; i < size; i++ {
+		rotated[(i+n)%size] = g.palette.output[start+i]
+	}
+	copy(g.palette.output[start:], rotated)
+}
+
+// SwapPalette draws each palette index in the color of the same index of the given palette,
+// for effects such as a red pain flash, until RestorePalette is called
+func (g *Game) SwapPalette(p color.Palette) {
+	if g.palette == nil {
+		return
+	}
+	for i := range g.palette.output {
+		if i < len(p) {
+			g.palette.output[i] = color.RGBAModel.Convert(p[i]).(color.RGBA)
+		}
+	}
+}
+
+// RestorePalette undoes any palette rotation or swap
+func (g *Game) RestorePalette() {
+	if g.palette != nil {
+		copy(g.palette.output, g.palette.match)
+	}
+}
+
+// quantizeFrame returns the frame matched to the palette, or the frame itself if paletted output is off
+func (g *Game) quantizeFrame() *ebiten.Image {
+	pm := g.palette
+	if pm == nil {
+		return g.frame
+	}
+
+	for y := 0; y < g.height; y++ {
+		for x := 0; x < g.width; x++ {
+			c := color.RGBAModel.Convert(g.frame.At(x, y)).(color.RGBA)
+
+			r, gr, b := int(c.R), int(c.G), int(c.B)
+			if pm.dither {
+				d := int((bayer4[y%4][x%4]/16 - 0.5) * ditherSpread)
+				r, gr, b = clampByte(r+d), clampByte(gr+d), clampByte(b+d)
+			}
+
+			out := pm.output[pm.nearest(r, gr, b)]
+			i := 4 * (y*g.width + x)
+			pm.pixels[i], pm.pixels[i+1], pm.pixels[i+2], pm.pixels[i+3] = out.R, out.G, out.B, 255
+		}
+	}
+
+	pm.img.ReplacePixels(pm.pixels)
+	return pm.img
+}
+
+// nearest returns the index of the palette color closest to the color, cached at 5 bits per channel
+func (pm *paletteMode) nearest(r, g, b int) int {
+	key := (r>>3)<<10 | (g>>3)<<5 | b>>3
+	if idx := pm.lookup[key]; idx >= 0 {
+		return int(idx)
+	}
+
+	best, bestDist := 0, -1
+	for i, c := range pm.match {
+		dr, dg, db := r-int(c.R), g-int(c.G), b-int(c.B)
+		dist := dr*dr + dg*dg + db*db
+		if bestDist < 0 || dist < bestDist {
+			best, bestDist = i, dist
+		}
+	}
+
+	pm.lookup[key] = int16(best)
+	return best
+}
+
+func clampByte(v int) int {
+	if v < 0 {
+		return 0
+	} else if v > 255 {
+		return 255
+	}
+	return v
+}
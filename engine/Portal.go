@@ -0,0 +1,63 @@
+package engine
+
+import (
+	"raycaster-go/engine/raycaster"
+	"raycaster-go/engine/sim"
+
+	"github.com/hajimehoshi/ebiten"
+)
+
+const (
+	// wall texture index the dream world is rendered into
+	portalTexNum = 5
+
+	// dream camera turn per tick
+	portalRotSpeed = 0.005
+)
+
+// newDreamMap creates a small walled arena with a ring of pillars, shown through the portal
+func newDreamMap(tex *raycaster.TextureHandler) *sim.Map {
+	size := 24
+	world := make([][]int, size)
+	mid := make([][]int, size)
+	up := make([][]int, size)
+	for x := 0; x < size; x++ {
+		world[x] = make([]int, size)
+		mid[x] = make([]int, size)
+		up[x] = make([]int, size)
+		for y := 0; y < size; y++ {
+			if x == 0 || y == 0 || x == size-1 || y == size-1 {
+				world[x][y], mid[x][y], up[x][y] = 1, 1, 1
+			} else if x%4 == 2 && y%4 == 2 {
+				world[x][y], mid[x][y] = 4, 5
+			}
+		}
+	}
+
+	return sim.NewMapFromGrids(tex, world, mid, up)
+}
+
+// newPortal creates the dream world view rendered into the portal wall texture
+func (g *Game) newPortal() *worldView {
+	dreamMap := newDreamMap(g.tex)
+	portal := g.newWorldView(dreamMap, texSize, texSize)
+	g.tex.Textures[portalTexNum], _ = ebiten.NewImage(texSize, texSize, ebiten.FilterNearest)
+
+	// the portal glows
+	g.tex.SetEmissive(g.tex.Textures[portalTexNum], g.tex.Textures[portalTexNum])
+
+	return portal
+}
+
+// updatePortal advances the dream world and renders it into the portal wall texture
+func (g *Game) updatePortal() {
+	dt := 1.0 / float64(ebiten.MaxTPS())
+	g.portal.mapObj.Update(dt)
+	g.portal.camera.SetDeltaTime(dt)
+	g.portal.camera.Rotate(portalRotSpeed)
+	g.portal.camera.Update()
+
+	portalImg := g.tex.Textures[portalTexNum]
+	portalImg.Clear()
+	g.drawWorld(portalImg, g.portal)
+}
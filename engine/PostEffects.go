@@ -0,0 +1,118 @@
+package engine
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"math/rand"
+
+	"github.com/hajimehoshi/ebiten"
+)
+
+const (
+	// size of the tiled film grain noise texture
+	grainSize = 128
+)
+
+// PostEffects are atmosphere effects drawn over the world view, under the HUD and before the color filter
+type PostEffects struct {
+	// Vignette --darkness of the view corners from 0 (off) to 1--//
+	Vignette float64
+
+	// Grain --opacity of the film grain from 0 (off) to 1--//
+	Grain float64
+
+	// GrainSeed --seed of the grain pattern, the same seed gives the same grain--//
+	GrainSeed int64
+
+	// AnimateGrain --move the grain every tick, otherwise it stays fixed on screen--//
+	AnimateGrain bool
+}
+
+// SetPostEffects sets the vignette and film grain drawn over the world view
+func (g *Game) SetPostEffects(p PostEffects) {
+	if p.GrainSeed != g.postEffects.GrainSeed {
+		// grain pattern is made again from the new seed
+		g.grainImg = nil
+	}
+	g.postEffects = p
+	g.grainRand = rand.New(rand.NewSource(p.GrainSeed))
+}
+
+// GetPostEffects returns the vignette and film grain drawn over the world view
+func (g *Game) GetPostEffects() PostEffects {
+	return g.postEffects
+}
+
+// drawPostEffects draws the vignette and film grain over the view
+func (g *Game) drawPostEffects() {
+	if g.postEffects.Vignette > 0 {
+		if g.vignetteImg == nil {
+			g.vignetteImg = newVignetteImage(g.width, g.height)
+		}
+
+		op := &ebiten.DrawImageOptions{}
+		op.ColorM.Scale(1, 1, 1, math.Min(1, g.postEffects.Vignette))
+		g.view.DrawImage(g.vignetteImg, op)
+	}
+
+	if g.postEffects.Grain > 0 {
+		if g.grainImg == nil {
+			g.grainImg = newGrainImage(g.postEffects.GrainSeed)
+		}
+
+		// the grain pattern is offset by a random amount each tick to animate it
+		offsetX, offsetY := 0, 0
+		if g.postEffects.AnimateGrain {
+			offsetX, offsetY = g.grainRand.Intn(grainSize), g.grainRand.Intn(grainSize)
+		}
+
+		for x := -offsetX; x < g.width; x += grainSize {
+			for y := -offsetY; y < g.height; y += grainSize {
+				op := &ebiten.DrawImageOptions{}
+				op.GeoM.Translate(float64(x), float64(y))
+				op.ColorM.Scale(1, 1, 1, math.Min(1, g.postEffects.Grain))
+				g.view.DrawImage(g.grainImg, op)
+			}
+		}
+	}
+}
+
+// newVignetteImage creates a black image that is transparent in the middle and opaque in the corners
+func newVignetteImage(width, height int) *ebiten.Image {
+	rgba := image.NewRGBA(image.Rect(0, 0, width, height))
+	cx, cy := float64(width)/2, float64(height)/2
+	maxDist := math.Hypot(cx, cy)
+
+	for x := 0; x < width; x++ {
+		for y := 0; y < height; y++ {
+			dist := math.Hypot(float64(x)-cx, float64(y)-cy) / maxDist
+			// clear up to half way out, then fading in smoothly
+			t := math.Max(0, (dist-0.5)/0.5)
+			alpha := t * t * (3 - 2*t)
+			rgba.SetRGBA(x, y, color.RGBA{0, 0, 0, uint8(255 * alpha)})
+		}
+	}
+
+	img, _ := ebiten.NewImageFromImage(rgba, ebiten.FilterLinear)
+	return img
+}
+
+// newGrainImage creates a tile of random gray noise
+func newGrainImage(seed int64) *ebiten.Image {
+	r := rand.New(rand.NewSource(seed))
+	rgba := image.NewRGBA(image.Rect(0, 0, grainSize, grainSize))
+
+	for x := 0; x < grainSize; x++ {
+		for y := 0; y < grainSize; y++ {
+			v := uint8(r.Intn(256))
+			// premultiplied, grain is drawn at half opacity before the effect intensity is applied
+			a := uint8(128)
+			v = uint8(uint16(v) * uint16(a) / 255)
+			rgba.SetRGBA(x, y, color.RGBA{v, v, v, a})
+		}
+	}
+
+	img, _ := ebiten.NewImageFromImage(rgba, ebiten.FilterNearest)
+	return img
+}
@@ -0,0 +1,151 @@
+package engine
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// ErrNoProfile is returned by a ProfileStorage when nothing has been saved under a key yet
+var ErrNoProfile = errors.New("no saved profile")
+
+// ProfileStorage is where profiles are kept, a directory of files on desktop and localStorage in browsers,
+// so games persist progress the same way on every platform
+type ProfileStorage interface {
+	// Load returns the data saved under the key, ErrNoProfile if there is none
+	Load(key string) ([]byte, error)
+
+	// Save replaces the data saved under the key
+	Save(key string, data []byte) error
+}
+
+// Profile is the persistent state of a player: level progress, settings and unlocks
+type Profile struct {
+	// Name --key the profile is saved under--//
+	Name string `json:"name"`
+
+	// Progress --stats of the best completion of each map, by map name--//
+	Progress map[string]*LevelStats `json:"progress"`
+
+	// Settings --game settings as text, by setting name--//
+	Settings map[string]string `json:"settings"`
+
+	// Unlocks --ids of what the player has unlocked--//
+	Unlocks []string `json:"unlocks"`
+
+	storage ProfileStorage
+}
+
+// NewProfile creates an empty profile saved to storage under the name
+func NewProfile(storage ProfileStorage, name string) *Profile {
+	return &Profile{
+		Name:     name,
+		Progress: make(map[string]*LevelStats),
+		Settings: make(map[string]string),
+		storage:  storage,
+	}
+}
+
+// LoadProfile loads the profile saved in storage under the name, or creates an empty one if none is saved
+func LoadProfile(storage ProfileStorage, name string) (*Profile, error) {
+	p := NewProfile(storage, name)
+	data, err := storage.Load(name)
+	if err == ErrNoProfile {
+		return p, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, p); err != nil {
+		return nil, fmt.Errorf("invalid profile %q: %v", name, err)
+	}
+	if p.Progress == nil {
+		p.Progress = make(map[string]*LevelStats)
+	}
+	if p.Settings == nil {
+		p.Settings = make(map[string]string)
+	}
+	return p, nil
+}
+
+// Save writes the profile to its storage
+func (p *Profile) Save() error {
+	data, err := json.Marshal(p)
+	if err != nil {
+		return err
+	}
+	return p.storage.Save(p.Name, data)
+}
+
+// RecordLevel keeps the stats of a completed level if it is the first or fastest completion of its map
+func (p *Profile) RecordLevel(stats *LevelStats) {
+	if !stats.Complete {
+		return
+	}
+	if best, ok := p.Progress[stats.Map]; ok && best.Time <= stats.Time {
+		return
+	}
+	record := *stats
+	p.Progress[stats.Map] = &record
+}
+
+// IsCompleted returns true if the map has been completed
+func (p *Profile) IsCompleted(mapName string) bool {
+	_, ok := p.Progress[mapName]
+	return ok
+}
+
+// GetSetting returns the value of a setting, def if it has not been set
+func (p *Profile) GetSetting(name, def string) string {
+	if v, ok := p.Settings[name]; ok {
+		return v
+	}
+	return def
+}
+
+// SetSetting sets the value of a setting
+func (p *Profile) SetSetting(name, value string) {
+	p.Settings[name] = value
+}
+
+// Unlock adds an unlock, returning false if it was already unlocked
+func (p *Profile) Unlock(id string) bool {
+	if p.IsUnlocked(id) {
+		return false
+	}
+	p.Unlocks = append(p.Unlocks, id)
+	return true
+}
+
+// IsUnlocked returns true if the id has been unlocked
+func (p *Profile) IsUnlocked(id string) bool {
+	for _, u := range p.Unlocks {
+		if u == id {
+			return true
+		}
+	}
+	return false
+}
+
+// SetProfile sets the profile completed levels are recorded and saved to, nil for none, and applies the
+// settings saved in it
+func (g *Game) SetProfile(p *Profile) {
+	g.profile = p
+	g.applySettings()
+}
+
+// GetProfile returns the profile of the player, nil if none is set
+func (g *Game) GetProfile() *Profile {
+	return g.profile
+}
+
+// saveProgress records a completed level in the profile and saves it
+func (g *Game) saveProgress(stats *LevelStats) {
+	if g.profile == nil {
+		return
+	}
+	g.profile.RecordLevel(stats)
+	if err := g.profile.Save(); err != nil {
+		fmt.Printf("Unable to save profile: %v\n", err)
+	}
+}
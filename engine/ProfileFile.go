@@ -0,0 +1,51 @@
+//go:build !js
+// +build !js
+
+package engine
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// FileStorage keeps each profile as a json file in a directory
+type FileStorage struct {
+	Dir string
+}
+
+// NewProfileStorage returns the storage for profiles of the named game, a directory in the user config
+// directory on desktop
+func NewProfileStorage(game string) (ProfileStorage, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return nil, err
+	}
+	return &FileStorage{Dir: filepath.Join(dir, game, "profiles")}, nil
+}
+
+// Load reads the file of the key
+func (f *FileStorage) Load(key string) ([]byte, error) {
+	data, err := ioutil.ReadFile(f.path(key))
+	if os.IsNotExist(err) {
+		return nil, ErrNoProfile
+	}
+	return data, err
+}
+
+// Save writes the file of the key, through a temporary file so a crash does not leave it half written
+func (f *FileStorage) Save(key string, data []byte) error {
+	if err := os.MkdirAll(f.Dir, 0755); err != nil {
+		return err
+	}
+	tmp := f.path(key) + ".tmp"
+	if err := ioutil.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, f.path(key))
+}
+
+// path returns the file of a key, keeping it inside the directory
+func (f *FileStorage) path(key string) string {
+	return filepath.Join(f.Dir, filepath.Base(key)+".json")
+}
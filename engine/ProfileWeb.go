@@ -0,0 +1,42 @@
+//go:build js && wasm
+// +build js,wasm
+
+package engine
+
+import (
+	"errors"
+	"syscall/js"
+)
+
+// LocalStorage keeps each profile as json in the browser localStorage, under a prefix for the game
+type LocalStorage struct {
+	Prefix string
+}
+
+// NewProfileStorage returns the storage for profiles of the named game, localStorage in browsers
+func NewProfileStorage(game string) (ProfileStorage, error) {
+	if js.Global().Get("localStorage").IsUndefined() {
+		return nil, errors.New("localStorage is not available")
+	}
+	return &LocalStorage{Prefix: game + "/profiles/"}, nil
+}
+
+// Load reads the item of the key
+func (l *LocalStorage) Load(key string) ([]byte, error) {
+	item := js.Global().Get("localStorage").Call("getItem", l.Prefix+key)
+	if item.IsNull() {
+		return nil, ErrNoProfile
+	}
+	return []byte(item.String()), nil
+}
+
+// Save sets the item of the key, failing if the storage quota is exceeded
+func (l *LocalStorage) Save(key string, data []byte) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = errors.New("unable to write localStorage")
+		}
+	}()
+	js.Global().Get("localStorage").Call("setItem", l.Prefix+key, string(data))
+	return nil
+}
@@ -0,0 +1,126 @@
+package engine
+
+import (
+	"fmt"
+	"math"
+	"raycaster-go/engine/raycaster"
+	"raycaster-go/engine/sim"
+	"runtime"
+	"strings"
+)
+
+// QualityPreset bundles the settings trading looks for speed, so slow devices and browsers can run smoothly
+type QualityPreset struct {
+	// Name --low, medium or high--//
+	Name string
+
+	// RenderScale --fraction of the window resolution the game renders at, scaled up to fill the window--//
+	RenderScale float64
+
+	// FloorDrawDistance --floor rows further than this show the flat floor instead of being cast, 0 for no limit--//
+	FloorDrawDistance float64
+
+	// FloorFilterDistance --floor rows further than this are sampled bilinearly, 0 to never filter--//
+	FloorFilterDistance float64
+
+	// Workers --goroutines the camera column passes are split over--//
+	Workers int
+
+	// Bloom --glow around emissive textures--//
+	Bloom bool
+
+	// AnimateGrain --move the film grain every tick--//
+	AnimateGrain bool
+}
+
+var (
+	// QualityLow suits browsers, where WASM runs on a single thread
+	QualityLow = QualityPreset{Name: "low", RenderScale: 0.5, FloorDrawDistance: 8, Workers: 1}
+
+	// QualityMedium suits machines with few cores
+	QualityMedium = QualityPreset{
+		Name: "medium", RenderScale: 0.75, FloorDrawDistance: 16, FloorFilterDistance: floorFilterDistance,
+		Workers: 2, Bloom: true,
+	}
+
+	// QualityHigh renders everything at full resolution
+	QualityHigh = QualityPreset{
+		Name: "high", RenderScale: 1, FloorFilterDistance: floorFilterDistance, Workers: 4, Bloom: true,
+		AnimateGrain: true,
+	}
+)
+
+// isBrowser returns true when running as WASM (or GopherJS) in a browser
+func isBrowser() bool {
+	return runtime.GOARCH == "js" || runtime.GOOS == "js"
+}
+
+// DefaultQuality picks a preset for where the game is running: low in browsers, medium with two cores or
+// fewer and high otherwise
+func DefaultQuality() QualityPreset {
+	switch {
+	case isBrowser():
+		return QualityLow
+	case runtime.NumCPU() <= 2:
+		return QualityMedium
+	default:
+		return QualityHigh
+	}
+}
+
+// ParseQuality returns the preset with the given name, or the default for the platform for "auto"
+func ParseQuality(name string) (QualityPreset, error) {
+	switch strings.ToLower(name) {
+	case "auto", "":
+		return DefaultQuality(), nil
+	case QualityLow.Name:
+		return QualityLow, nil
+	case QualityMedium.Name:
+		return QualityMedium, nil
+	case QualityHigh.Name:
+		return QualityHigh, nil
+	}
+	return QualityPreset{}, fmt.Errorf("unknown quality preset %q", name)
+}
+
+// SetQuality applies a quality preset. A change of render scale remakes the world view at the new size with
+// the camera where it was, and only resizes the window if called before Run.
+func (g *Game) SetQuality(p QualityPreset) {
+	if p.RenderScale <= 0 {
+		p.RenderScale = 1
+	}
+	g.quality = p
+
+	if w, h := renderSize(p.RenderScale); w != g.width || h != g.height {
+		state, events := g.camera.Snapshot(), g.camera.Events
+		g.setResolution(w, h)
+		g.world = g.newWorldView(g.mapObj, w, h)
+		g.camera = g.world.camera
+		g.camera.Events = events
+		g.camera.Restore(state, sim.RestoreAll)
+	}
+
+	g.applyQuality(g.world.camera)
+	g.bloomEnabled = p.Bloom
+	effects := g.GetPostEffects()
+	effects.AnimateGrain = p.AnimateGrain
+	g.SetPostEffects(effects)
+}
+
+// GetQuality returns the quality preset in use
+func (g *Game) GetQuality() QualityPreset {
+	return g.quality
+}
+
+// applyQuality sets the camera settings of the quality preset
+func (g *Game) applyQuality(c *raycaster.Camera) {
+	c.FloorDrawDistance = g.quality.FloorDrawDistance
+	c.FloorFilterDistance = g.quality.FloorFilterDistance
+	c.SetWorkers(g.quality.Workers)
+}
+
+// renderSize returns the resolution the game renders at for a render scale
+func renderSize(scale float64) (int, int) {
+	return int(math.Floor(float64(screenWidth) / screenScale * scale)),
+		int(math.Floor(float64(screenHeight) / screenScale * scale))
+}
@@ -0,0 +1,273 @@
+package engine
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"raycaster-go/engine/sim"
+	"time"
+
+	"github.com/hajimehoshi/ebiten"
+	"github.com/hajimehoshi/ebiten/inpututil"
+)
+
+const (
+	// ReplayVersion is the current crash replay file format version
+	ReplayVersion = 1
+
+	// how many seconds of input each recorded window covers, a crash dump holds up to two windows
+	replayWindowSeconds = 10
+
+	// name of the built-in map, stored in replays so they are only played back on the same map
+	sampleMapName = "sample"
+)
+
+// replayKeys are the keys read by handleInput, each is one bit of an inputFrame
+var replayKeys = []ebiten.Key{
+	ebiten.KeyW, ebiten.KeyA, ebiten.KeyS, ebiten.KeyD,
+	ebiten.KeyUp, ebiten.KeyDown, ebiten.KeyLeft, ebiten.KeyRight,
+	ebiten.KeyShift, ebiten.KeyAlt, ebiten.KeySpace, ebiten.KeyC,
+	ebiten.KeyE, ebiten.KeyR, ebiten.KeyP, ebiten.KeyZ,
+	ebiten.KeyF3, ebiten.KeyF4, ebiten.KeyF7, ebiten.KeyF8, ebiten.KeyF9, ebiten.KeyF10,
+	ebiten.KeyF12, ebiten.KeyPageUp, ebiten.KeyPageDown,
+	ebiten.KeyB, ebiten.KeyF, ebiten.KeyG, ebiten.KeyH, ebiten.KeyI, ebiten.KeyJ, ebiten.KeyK, ebiten.KeyL,
+	ebiten.KeyM, ebiten.KeyN, ebiten.KeyO, ebiten.KeyQ, ebiten.KeyT, ebiten.KeyU, ebiten.KeyV, ebiten.KeyX,
+	ebiten.KeyY,
+	ebiten.Key0, ebiten.Key1, ebiten.Key2, ebiten.Key3, ebiten.Key4,
+	ebiten.Key5, ebiten.Key6, ebiten.Key7, ebiten.Key8, ebiten.Key9,
+	ebiten.KeyControl, ebiten.KeyTab, ebiten.KeyEnter, ebiten.KeyEscape, ebiten.KeyHome, ebiten.KeyEnd,
+}
+
+// inputFrame is the keyboard state for one tick
+type inputFrame struct {
+	// Pressed --bits of replayKeys held down--//
+	Pressed uint64 `json:"p,omitempty"`
+
+	// Just --bits of replayKeys pressed this tick--//
+	Just uint64 `json:"j,omitempty"`
+
+	// Commands --debug command lines entered this tick--//
+	Commands []string `json:"c,omitempty"`
+}
+
+// readInput reads the current keyboard state from ebiten
+func readInput() inputFrame {
+	var f inputFrame
+	for i, k := range replayKeys {
+		if ebiten.IsKeyPressed(k) {
+			f.Pressed |= 1 << uint(i)
+		}
+		if inpututil.IsKeyJustPressed(k) {
+			f.Just |= 1 << uint(i)
+		}
+	}
+	return f
+}
+
+// keyBit returns the bit of the key in an inputFrame, 0 if the key is not recorded
+func keyBit(k ebiten.Key) uint64 {
+	for i, rk := range replayKeys {
+		if rk == k {
+			return 1 << uint(i)
+		}
+	}
+	return 0
+}
+
+// pressed returns true if the key is held down in the frame
+func (f inputFrame) pressed(k ebiten.Key) bool {
+	return f.Pressed&keyBit(k) != 0
+}
+
+// justPressed returns true if the key was pressed in the frame
+func (f inputFrame) justPressed(k ebiten.Key) bool {
+	return f.Just&keyBit(k) != 0
+}
+
+// Replay is a recording of player input from a known starting state, written when the game
+// panics so the crash can be reproduced by playing it back
+type Replay struct {
+	// Version --file format version--//
+	Version int `json:"version"`
+
+	// Map --name of the map being played--//
+	Map string `json:"map"`
+
+	// Seed --seed the map simulation was started with, Start holds its state when the recording starts--//
+	Seed int64 `json:"seed"`
+
+	// Panic --the value the game panicked with--//
+	Panic string `json:"panic,omitempty"`
+
+	// Start --camera and map state before the first recorded tick--//
+	Start *sim.Snapshot `json:"start"`
+
+	// Inputs --keyboard state for each tick after Start--//
+	Inputs []inputFrame `json:"inputs"`
+}
+
+// replayWindow is the input recorded since a snapshot
+type replayWindow struct {
+	start  *sim.Snapshot
+	inputs []inputFrame
+}
+
+// replayRecorder keeps the last two windows of input, so a dump always covers at least one full window
+type replayRecorder struct {
+	prev, curr *replayWindow
+	ticks      int
+}
+
+// record adds one tick of input, starting a new window from a fresh snapshot when the current one is full.
+// The snapshot holds the random state of the map, so random events play out the same from any window.
+func (r *replayRecorder) record(camera *sim.Camera, f inputFrame) {
+	if r.curr == nil || len(r.curr.inputs) >= r.ticks {
+		r.prev = r.curr
+		r.curr = &replayWindow{start: camera.Snapshot(), inputs: make([]inputFrame, 0, r.ticks)}
+	}
+	r.curr.inputs = append(r.curr.inputs, f)
+}
+
+// replay returns the recorded input from the start of the oldest window kept
+func (r *replayRecorder) replay() *Replay {
+	rp := &Replay{Version: ReplayVersion}
+	if r.prev != nil {
+		rp.Start = r.prev.start
+		rp.Inputs = append(rp.Inputs, r.prev.inputs...)
+	} else if r.curr != nil {
+		rp.Start = r.curr.start
+	}
+	if r.curr != nil {
+		rp.Inputs = append(rp.Inputs, r.curr.inputs...)
+	}
+	return rp
+}
+
+// replayPlayer feeds recorded input to the game in place of the keyboard
+type replayPlayer struct {
+	replay  *Replay
+	next    int
+	started bool
+}
+
+// SetCrashCapture turns crash capture on, recording input so that a panic writes a replay file into dir.
+// An empty dir turns it off.
+func (g *Game) SetCrashCapture(dir string) {
+	g.crashDir = dir
+	if dir == "" {
+		g.recorder = nil
+		return
+	}
+	g.recorder = &replayRecorder{ticks: replayWindowSeconds * targetTPS}
+}
+
+// LoadReplay reads a replay file written by crash capture
+func LoadReplay(path string) (*Replay, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	r := new(Replay)
+	if err := json.Unmarshal(data, r); err != nil {
+		return nil, err
+	}
+	if r.Version > ReplayVersion {
+		return nil, fmt.Errorf("replay version %d is newer than supported version %d", r.Version, ReplayVersion)
+	}
+	if r.Start == nil {
+		return nil, fmt.Errorf("replay %s has no starting snapshot", path)
+	}
+	return r, nil
+}
+
+// PlayReplay restores the replay's starting state and plays back its input in place of the keyboard.
+// The keyboard takes over again once the recorded input runs out.
+func (g *Game) PlayReplay(r *Replay) error {
+	if r.Map != g.mapName {
+		return fmt.Errorf("replay was recorded on map %q, not %q", r.Map, g.mapName)
+	}
+	g.seed = r.Seed
+	g.player = &replayPlayer{replay: r}
+	return nil
+}
+
+// IsReplaying returns true while recorded input is being played back
+func (g *Game) IsReplaying() bool {
+	return g.player != nil
+}
+
+// nextInput returns the input for this tick, from the replay being played or the keyboard,
+// and records it when crash capture is on
+func (g *Game) nextInput() inputFrame {
+	var f inputFrame
+	if p := g.player; p != nil {
+		if !p.started {
+			// snapshots from before the random state was saved only have the seed to go on
+			g.mapObj.SetSeed(p.replay.Seed)
+			g.camera.Restore(p.replay.Start, sim.RestoreAll)
+			p.started = true
+		}
+		if p.next < len(p.replay.Inputs) {
+			f = p.replay.Inputs[p.next]
+			p.next++
+		}
+		if p.next >= len(p.replay.Inputs) {
+			fmt.Printf("Replay finished after %d ticks\n", p.next)
+			g.player = nil
+		}
+	} else {
+		f = readInput()
+		g.updateConsole(&f)
+		g.updateChat(&f)
+		// nothing is played while a map loads, so neither game counts those ticks
+		if g.lockstep != nil && g.loading == nil {
+			g.exchangeLockstep(f)
+		}
+	}
+
+	if g.recorder != nil {
+		g.recorder.record(g.camera.Camera, f)
+	}
+	return f
+}
+
+// captureCrash is deferred by Update, on a panic it writes the recorded input to the crash directory
+// and panics again so the crash is still reported as usual
+func (g *Game) captureCrash() {
+	if g.recorder == nil {
+		return
+	}
+	r := recover()
+	if r == nil {
+		return
+	}
+
+	if path, err := g.writeCrashReplay(r); err != nil {
+		fmt.Fprintf(os.Stderr, "Unable to write crash replay: %v\n", err)
+	} else {
+		fmt.Fprintf(os.Stderr, "Crash replay written to %s\n", path)
+	}
+	panic(r)
+}
+
+// writeCrashReplay writes the recorded input to a new file in the crash directory
+func (g *Game) writeCrashReplay(reason interface{}) (string, error) {
+	rp := g.recorder.replay()
+	rp.Map = g.mapName
+	rp.Seed = g.seed
+	rp.Panic = fmt.Sprint(reason)
+
+	data, err := json.Marshal(rp)
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(g.crashDir, 0755); err != nil {
+		return "", err
+	}
+
+	path := filepath.Join(g.crashDir, fmt.Sprintf("crash-%s.json", time.Now().Format("20060102-150405")))
+	return path, ioutil.WriteFile(path, data, 0644)
+}
@@ -0,0 +1,131 @@
+package engine
+
+import (
+	"fmt"
+	"image/color"
+	"path/filepath"
+	"raycaster-go/engine/sim"
+
+	"github.com/hajimehoshi/ebiten"
+)
+
+const (
+	// sheet other players are drawn from (the recorded player of a replay, a co-op partner), and the size of
+	// its frames, the first of which is the player standing
+	playerSpriteSheet = "knight_sheet.png"
+	playerSpriteFrame = 84
+)
+
+var (
+	// drawn for other players if the sheet cannot be loaded
+	playerSpriteFallback = color.RGBA{200, 40, 40, 255}
+)
+
+// replayCamera is the free camera a replay can be watched through, with the recorded player drawn as a
+// sprite seen only by it
+type replayCamera struct {
+	view  *worldView
+	ghost *sim.Sprite
+}
+
+// SetReplayFreeCamera detaches the view from the recorded player to fly around while the replay carries
+// on, or follows the player again. Does nothing unless a replay is playing.
+func (g *Game) SetReplayFreeCamera(free bool) {
+	if !free || !g.IsReplaying() {
+		g.replayCam = nil
+		return
+	}
+	if g.replayCam == nil {
+		g.replayCam = &replayCamera{ghost: sim.NewSprite(0, 0, g.playerSpriteImage())}
+	}
+}
+
+// IsReplayFreeCamera returns true while a replay is watched through the free camera
+func (g *Game) IsReplayFreeCamera() bool {
+	return g.replayCam != nil
+}
+
+// playerSpriteImage returns the image other players are drawn with, loaded the first time
+func (g *Game) playerSpriteImage() *ebiten.Image {
+	if g.playerSprite != nil {
+		return g.playerSprite
+	}
+
+	sheet, _, err := g.tex.LoadImage(filepath.Join("engine", "content", "sprites", playerSpriteSheet))
+	if err == nil {
+		if frames := sim.SliceSheet(sheet, playerSpriteFrame, playerSpriteFrame); len(frames) > 0 && len(frames[0]) > 0 {
+			g.playerSprite = frames[0][0].(*ebiten.Image)
+			return g.playerSprite
+		}
+		err = fmt.Errorf("%s has no %dx%d frames", playerSpriteSheet, playerSpriteFrame, playerSpriteFrame)
+	}
+	fmt.Printf("Unable to load player sprite: %v\n", err)
+	g.playerSprite, _ = ebiten.NewImage(texSize, texSize, ebiten.FilterNearest)
+	g.playerSprite.Fill(playerSpriteFallback)
+	return g.playerSprite
+}
+
+// updateReplayCamera reads the keyboard while a replay plays, the recorded input driving the player: the use
+// key toggles the free camera, which flies through walls with the movement keys. The recorded player is
+// drawn where it is now. The free camera is dropped when the replay ends.
+func (g *Game) updateReplayCamera() {
+	if !g.IsReplaying() {
+		g.replayCam = nil
+		return
+	}
+
+	keys := readInput()
+	if g.actionJustPressedIn(keys, ActionUse) {
+		g.SetReplayFreeCamera(g.replayCam == nil)
+	}
+	rc := g.replayCam
+	if rc == nil {
+		return
+	}
+
+	// made again, starting from the player, for a new map or resolution
+	if rc.view == nil || rc.view.mapObj != g.mapObj || rc.view.width != g.width || rc.view.height != g.height {
+		rc.view = g.newWorldView(g.mapObj, g.width, g.height)
+		rc.view.camera.Follow(g.camera.Camera)
+		rc.view.camera.SetNoClip(true)
+		rc.view.camera.SetViewSprites([]*sim.Sprite{rc.ghost})
+	}
+
+	cam := rc.view.camera
+	if g.actionPressedIn(keys, ActionForward) {
+		cam.Move(spectatorMoveSpeed)
+	} else if g.actionPressedIn(keys, ActionBackward) {
+		cam.Move(-spectatorMoveSpeed)
+	}
+	if g.actionPressedIn(keys, ActionTurnLeft) {
+		cam.Rotate(spectatorTurnSpeed)
+	} else if g.actionPressedIn(keys, ActionTurnRight) {
+		cam.Rotate(-spectatorTurnSpeed)
+	}
+	if g.actionPressedIn(keys, ActionLookUp) {
+		cam.Pitch(1)
+	} else if g.actionPressedIn(keys, ActionLookDown) {
+		cam.Pitch(-1)
+	}
+
+	pos := g.camera.GetPosition()
+	rc.ghost.X, rc.ghost.Y, rc.ghost.Dir = pos.X, pos.Y, g.camera.GetDirection()
+	cam.Render()
+}
+
+// shownWorld returns the view drawn to the screen, the free camera while watching a replay through it
+func (g *Game) shownWorld() *worldView {
+	if g.replayCam != nil && g.replayCam.view != nil {
+		return g.replayCam.view
+	}
+	return g.world
+}
+
+// drawReplayCamera shows whether the replay view follows the recorded player or flies freely
+func (g *Game) drawReplayCamera() {
+	text := g.tr("hud.replay")
+	if g.replayCam != nil {
+		text = g.tr("hud.freecam")
+	}
+	g.drawText(text, g.width/2-g.textWidth(text)/2, dialogueMargin)
+}
@@ -0,0 +1,115 @@
+package engine
+
+import (
+	"encoding/json"
+	"testing"
+
+	"raycaster-go/engine/sim"
+
+	"github.com/hajimehoshi/ebiten"
+)
+
+// replayWorld is a walled map with sprites that spread shots knock around, so where they end up depends on
+// the random state of the map
+type replayWorld struct {
+	m      *sim.Map
+	camera *sim.Camera
+}
+
+func newReplayWorld() *replayWorld {
+	const size = 16
+	m := sim.NewEmptyMap(nil, size, size)
+	for i := 0; i < size; i++ {
+		m.SetTile(i, 0, 0, 1)
+		m.SetTile(i, size-1, 0, 1)
+		m.SetTile(0, i, 0, 1)
+		m.SetTile(size-1, i, 0, 1)
+	}
+	for i := 0; i < 6; i++ {
+		s := sim.NewSprite(6.5+float64(i), 4.5+float64(i%3)*3, nil)
+		s.BlocksRays, s.Radius = true, 0.4
+		m.AddSprite(s)
+	}
+	m.SetSeed(7)
+	m.Update(0)
+	return &replayWorld{m: m, camera: sim.NewCamera(m, sim.WithStartPosition(sim.Vector2{X: 2.5, Y: 8.5}))}
+}
+
+// step plays one tick of input: W walks, Left turns, Space fires a spread shot that pushes back what it hits
+func (w *replayWorld) step(f inputFrame) {
+	if f.pressed(ebiten.KeyW) {
+		w.camera.Move(0.02)
+	}
+	if f.pressed(ebiten.KeyLeft) {
+		w.camera.Rotate(0.03)
+	}
+	if f.justPressed(ebiten.KeySpace) {
+		pos := w.camera.GetPosition()
+		dir := sim.Vector2{X: 1, Y: 0}
+		for _, hit := range w.m.Hitscan(pos, dir, sim.HitscanOptions{Pellets: 3, Spread: 0.8}) {
+			if hit.Sprite != nil {
+				hit.Sprite.X += (hit.Point.X - pos.X) * 0.02
+				hit.Sprite.Y += (hit.Point.Y - pos.Y) * 0.02
+			}
+		}
+	}
+	w.m.Update(1.0 / 60)
+	w.camera.SimulateWithDelta(1.0 / 60)
+}
+
+// positions returns where the camera and every sprite are
+func (w *replayWorld) positions() []sim.Vector2 {
+	pos := []sim.Vector2{w.camera.GetPosition()}
+	for _, s := range w.m.GetSprites() {
+		pos = append(pos, sim.Vector2{X: s.X, Y: s.Y})
+	}
+	return pos
+}
+
+func TestReplayWindowsRestoreRandomState(t *testing.T) {
+	w := newReplayWorld()
+	rec := &replayRecorder{ticks: 40}
+
+	for tick := 0; tick < 150; tick++ {
+		var f inputFrame
+		if tick%3 != 0 {
+			f.Pressed |= keyBit(ebiten.KeyW)
+		}
+		if tick%50 < 5 {
+			f.Pressed |= keyBit(ebiten.KeyLeft)
+		}
+		if tick%7 == 0 {
+			f.Just |= keyBit(ebiten.KeySpace)
+		}
+		rec.record(w.camera, f)
+		w.step(f)
+	}
+	want := w.positions()
+
+	// through a crash file and back, the way a replay is played
+	data, err := json.Marshal(rec.replay())
+	if err != nil {
+		t.Fatal(err)
+	}
+	rp := new(Replay)
+	if err := json.Unmarshal(data, rp); err != nil {
+		t.Fatal(err)
+	}
+	if rp.Start.Rand == nil {
+		t.Fatal("replay start has no random state")
+	}
+
+	// the oldest window kept starts mid-game, well after the map was seeded
+	w.m.SetSeed(7)
+	w.camera.Restore(rp.Start, sim.RestoreAll)
+	for _, f := range rp.Inputs {
+		w.step(f)
+	}
+
+	got := w.positions()
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("position %d replayed to %v, recorded %v", i, got[i], want[i])
+		}
+	}
+}
@@ -0,0 +1,198 @@
+package engine
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"hash/crc32"
+	"image"
+	"image/png"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"raycaster-go/engine/sim"
+	"time"
+)
+
+const (
+	// directory screenshots are written to
+	screenshotDir = "screenshots"
+
+	// PNG text chunk keyword the pose is stored under
+	screenshotPoseKey = "raycaster-pose"
+
+	// ScreenshotPoseVersion is the format version of the pose stored in screenshots
+	ScreenshotPoseVersion = 1
+)
+
+// pngSignature starts every PNG file
+var pngSignature = []byte("\x89PNG\r\n\x1a\n")
+
+// ScreenshotPose is the camera pose a screenshot was taken from, stored in the PNG so the camera
+// can be warped back to the same view
+type ScreenshotPose struct {
+	// Version --pose format version--//
+	Version int `json:"version"`
+
+	// Map --name of the map the screenshot was taken on--//
+	Map string `json:"map"`
+
+	// Camera --camera position, direction, plane and height--//
+	Camera sim.CameraState `json:"camera"`
+
+	// FOV --horizontal field of view in degrees--//
+	FOV float64 `json:"fov"`
+}
+
+// TakeScreenshot writes the next rendered frame to the screenshots directory along with the camera pose
+func (g *Game) TakeScreenshot() {
+	g.screenshotPending = true
+}
+
+// WarpToScreenshot moves the camera to the pose stored in a screenshot taken on the same map
+func (g *Game) WarpToScreenshot(path string) error {
+	pose, err := LoadScreenshotPose(path)
+	if err != nil {
+		return err
+	}
+	if pose.Map != g.mapName {
+		return fmt.Errorf("screenshot was taken on map %q, not %q", pose.Map, g.mapName)
+	}
+
+	g.camera.Restore(&sim.Snapshot{Version: sim.SnapshotVersion, Camera: pose.Camera}, sim.RestoreCamera)
+	if pose.FOV > 0 {
+		g.camera.SetFOV(pose.FOV)
+	}
+	return nil
+}
+
+// LoadScreenshotPose reads the camera pose stored in a screenshot
+func LoadScreenshotPose(path string) (*ScreenshotPose, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	text, err := readPNGText(f, screenshotPoseKey)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	pose := new(ScreenshotPose)
+	if err := json.Unmarshal(text, pose); err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	if pose.Version > ScreenshotPoseVersion {
+		return nil, fmt.Errorf("screenshot pose version %d is newer than supported version %d", pose.Version, ScreenshotPoseVersion)
+	}
+	return pose, nil
+}
+
+// writeScreenshot writes the final screen image with the current camera pose to a new file
+func (g *Game) writeScreenshot(img image.Image) {
+	g.screenshotPending = false
+
+	pose := &ScreenshotPose{
+		Version: ScreenshotPoseVersion,
+		Map:     g.mapName,
+		Camera:  g.camera.Snapshot().Camera,
+		FOV:     g.camera.GetFOV(),
+	}
+	text, err := json.Marshal(pose)
+	if err == nil {
+		err = os.MkdirAll(screenshotDir, 0755)
+	}
+
+	var buf bytes.Buffer
+	if err == nil {
+		err = writePNGText(&buf, img, screenshotPoseKey, text)
+	}
+	path := filepath.Join(screenshotDir, fmt.Sprintf("screenshot-%s.png", time.Now().Format("20060102-150405.000")))
+	if err == nil {
+		err = ioutil.WriteFile(path, buf.Bytes(), 0644)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Unable to write screenshot: %v\n", err)
+		return
+	}
+	g.console.print("Screenshot written to " + path)
+}
+
+// writePNGText encodes the image as PNG with a tEXt chunk holding the text under the keyword,
+// placed right after the header so readers find it without decoding the image
+func writePNGText(w io.Writer, img image.Image, keyword string, text []byte) error {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return err
+	}
+	data := buf.Bytes()
+
+	// the IHDR chunk is always first: 4 byte length, 4 byte type, 13 bytes of data and a 4 byte crc
+	headerEnd := len(pngSignature) + 4 + 4 + 13 + 4
+	if _, err := w.Write(data[:headerEnd]); err != nil {
+		return err
+	}
+
+	chunk := append([]byte(keyword), 0)
+	chunk = append(chunk, text...)
+	if err := writePNGChunk(w, "tEXt", chunk); err != nil {
+		return err
+	}
+
+	_, err := w.Write(data[headerEnd:])
+	return err
+}
+
+// writePNGChunk writes one PNG chunk with its length and crc
+func writePNGChunk(w io.Writer, chunkType string, data []byte) error {
+	var head [8]byte
+	binary.BigEndian.PutUint32(head[:4], uint32(len(data)))
+	copy(head[4:], chunkType)
+
+	crc := crc32.NewIEEE()
+	crc.Write(head[4:])
+	crc.Write(data)
+	var tail [4]byte
+	binary.BigEndian.PutUint32(tail[:], crc.Sum32())
+
+	for _, b := range [][]byte{head[:], data, tail[:]} {
+		if _, err := w.Write(b); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// readPNGText returns the text of the tEXt chunk with the keyword, reading chunks up to the image data
+func readPNGText(r io.Reader, keyword string) ([]byte, error) {
+	sig := make([]byte, len(pngSignature))
+	if _, err := io.ReadFull(r, sig); err != nil || !bytes.Equal(sig, pngSignature) {
+		return nil, fmt.Errorf("not a PNG file")
+	}
+
+	for {
+		var head [8]byte
+		if _, err := io.ReadFull(r, head[:]); err != nil {
+			return nil, fmt.Errorf("no %s text found", keyword)
+		}
+		length := binary.BigEndian.Uint32(head[:4])
+		chunkType := string(head[4:])
+		if chunkType == "IDAT" || chunkType == "IEND" {
+			return nil, fmt.Errorf("no %s text found", keyword)
+		}
+
+		data := make([]byte, length+4)
+		if _, err := io.ReadFull(r, data); err != nil {
+			return nil, err
+		}
+		data = data[:length]
+		if chunkType != "tEXt" {
+			continue
+		}
+		if sep := bytes.IndexByte(data, 0); sep >= 0 && string(data[:sep]) == keyword {
+			return data[sep+1:], nil
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package engine
+
+import (
+	"image/color"
+	"raycaster-go/engine/sim"
+
+	"github.com/hajimehoshi/ebiten"
+)
+
+const (
+	// ticks between renders of the security camera views shown on wall screens
+	securityScreenInterval = 3
+)
+
+var (
+	// shown in place of the view of a disabled security camera
+	securityStatic = color.RGBA{40, 40, 40, 255}
+)
+
+// securityViewKey tells apart the render buffers of a security camera rendered at different sizes
+type securityViewKey struct {
+	camera        *sim.SecurityCamera
+	width, height int
+}
+
+// RenderSecurityCamera draws what a security camera of the current map sees into the target image,
+// or static if it is disabled. Render buffers are made the first time a camera is rendered at a size.
+func (g *Game) RenderSecurityCamera(sc *sim.SecurityCamera, target *ebiten.Image) {
+	if sc.Disabled {
+		target.Fill(securityStatic)
+		return
+	}
+
+	w, h := target.Size()
+	key := securityViewKey{camera: sc, width: w, height: h}
+	if g.securityViews == nil {
+		g.securityViews = make(map[securityViewKey]*worldView)
+	}
+	view := g.securityViews[key]
+	if view == nil || view.mapObj != g.mapObj {
+		view = g.newWorldView(g.mapObj, w, h)
+		g.securityViews[key] = view
+	}
+
+	view.camera.LookFrom(sc)
+	view.camera.Render()
+	g.drawWorld(target, view)
+}
+
+// updateSecurityScreens renders the views of the security cameras with a screen into the wall textures
+// they are shown on, every few ticks
+func (g *Game) updateSecurityScreens() {
+	g.securityTicks++
+	if g.securityTicks < securityScreenInterval {
+		return
+	}
+	g.securityTicks = 0
+
+	for _, sc := range g.mapObj.GetSecurityCameras() {
+		texNum := sc.Screen - 1
+		if texNum < 0 || texNum >= len(g.tex.Textures) {
+			continue
+		}
+
+		// the screen texture is swapped for an image of its own the first time, like the portal
+		screen := g.securityScreens[texNum]
+		if screen == nil {
+			screen, _ = ebiten.NewImage(texSize, texSize, ebiten.FilterNearest)
+			if g.securityScreens == nil {
+				g.securityScreens = make(map[int]*ebiten.Image)
+			}
+			g.securityScreens[texNum] = screen
+			g.tex.Textures[texNum] = screen
+		}
+
+		screen.Clear()
+		g.RenderSecurityCamera(sc, screen)
+	}
+}
+
+// NewSecurityViewport creates a viewport showing the view of the named security camera of the current map,
+// static while the camera is disabled or missing. Sized to fill the window it works as an observer view.
+func (g *Game) NewSecurityViewport(name string) *Viewport {
+	return &Viewport{
+		Name: name, Anchor: AnchorBottomRight, Width: 0.3, Height: 0.3, Margin: dialogueMargin,
+		Interval: 2, Border: minimapBorder,
+		Render: func(target *ebiten.Image) {
+			if sc := g.mapObj.GetSecurityCamera(name); sc != nil {
+				g.RenderSecurityCamera(sc, target)
+			} else {
+				target.Fill(securityStatic)
+			}
+		},
+	}
+}
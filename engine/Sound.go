@@ -0,0 +1,217 @@
+package engine
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"raycaster-go/engine/sim"
+	"strings"
+	"sync"
+
+	"github.com/hajimehoshi/ebiten/audio"
+	"github.com/hajimehoshi/ebiten/audio/vorbis"
+	"github.com/hajimehoshi/ebiten/audio/wav"
+)
+
+const (
+	// echoes of a reverb stop once they are quieter than this
+	reverbCutoff = 0.01
+
+	// loudness of the player's footsteps, heard this many cells away
+	footstepLoudness = 4.0
+)
+
+// SoundPlayer plays sound effects with the reverb of the part of the map the player is in.
+// Effects are wav or Ogg Vorbis files, decoded once and kept with each reverb applied to them.
+type SoundPlayer struct {
+	ctx *audio.Context
+	dir string
+
+	// Volume --master effects volume from 0 to 1--//
+	Volume float64
+
+	reverb sim.ReverbPreset
+
+	//--decoded 16 bit stereo samples by file name, nil for files that failed to load--//
+	mu  sync.Mutex
+	dry map[string][]byte
+	wet map[string][]byte
+}
+
+// NewSoundPlayer creates a sound player playing effects from the directory
+func NewSoundPlayer(ctx *audio.Context, dir string) *SoundPlayer {
+	return &SoundPlayer{
+		ctx:    ctx,
+		dir:    dir,
+		Volume: 1,
+		reverb: sim.ReverbDry,
+		dry:    make(map[string][]byte),
+		wet:    make(map[string][]byte),
+	}
+}
+
+// SetReverb sets the reverb applied to effects played from now on
+func (s *SoundPlayer) SetReverb(preset sim.ReverbPreset) {
+	s.reverb = preset
+}
+
+// GetReverb returns the reverb applied to effects
+func (s *SoundPlayer) GetReverb() sim.ReverbPreset {
+	return s.reverb
+}
+
+// Play plays an effect file once at a volume from 0 to 1
+func (s *SoundPlayer) Play(name string, volume float64) {
+	if s.ctx == nil || name == "" {
+		return
+	}
+
+	key := name + "|" + s.reverb.Name
+	s.mu.Lock()
+	pcm, ok := s.wet[key]
+	s.mu.Unlock()
+	if !ok {
+		dry := s.load(name)
+		if dry != nil {
+			pcm = applyReverb(dry, s.reverb, s.ctx.SampleRate())
+		}
+		s.mu.Lock()
+		s.wet[key] = pcm
+		s.mu.Unlock()
+	}
+	if pcm == nil {
+		return
+	}
+
+	p, err := audio.NewPlayerFromBytes(s.ctx, pcm)
+	if err != nil {
+		return
+	}
+	p.SetVolume(math.Min(1, volume*s.Volume))
+	p.Play()
+}
+
+// load returns the decoded samples of an effect file, reading it the first time it is asked for
+func (s *SoundPlayer) load(name string) []byte {
+	s.mu.Lock()
+	pcm, ok := s.dry[name]
+	s.mu.Unlock()
+	if ok {
+		return pcm
+	}
+
+	pcm, err := s.decode(name)
+	if err != nil {
+		fmt.Printf("Unable to load sound %s: %v\n", name, err)
+	}
+	s.mu.Lock()
+	s.dry[name] = pcm
+	s.mu.Unlock()
+	return pcm
+}
+
+// Preload returns a loader decoding effect files ahead of their first use, so a loading screen can
+// cover the time instead of the first time each is played
+func (s *SoundPlayer) Preload(names ...string) sim.Loader {
+	return sim.LoaderFunc(func(progress sim.Progress) error {
+		for i, name := range names {
+			progress(i, len(names))
+			if s.ctx != nil {
+				s.load(name)
+			}
+		}
+		progress(len(names), len(names))
+		return nil
+	})
+}
+
+// decode reads an effect file as 16 bit stereo samples at the sample rate of the audio context
+func (s *SoundPlayer) decode(name string) ([]byte, error) {
+	f, err := os.Open(filepath.Join(s.dir, name))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var stream io.Reader
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".wav":
+		stream, err = wav.Decode(s.ctx, f)
+	case ".ogg":
+		stream, err = vorbis.Decode(s.ctx, f)
+	default:
+		return nil, fmt.Errorf("unsupported sound format %q", filepath.Ext(name))
+	}
+	if err != nil {
+		return nil, err
+	}
+	return ioutil.ReadAll(stream)
+}
+
+// applyReverb returns the 16 bit stereo samples mixed with their echoes, lengthened to fit the tail
+func applyReverb(pcm []byte, preset sim.ReverbPreset, sampleRate int) []byte {
+	delay := int(preset.Delay * float64(sampleRate))
+	if delay <= 0 || preset.Mix <= 0 || preset.Feedback <= 0 || preset.Feedback >= 1 {
+		return pcm
+	}
+
+	//--two comb filters at slightly different delays keep the echoes from sounding metallic--//
+	delays := []int{delay, delay * 137 / 100}
+	echoes := int(math.Ceil(math.Log(reverbCutoff) / math.Log(preset.Feedback)))
+	frames := len(pcm)/4 + delays[1]*echoes
+
+	out := make([]byte, frames*4)
+	for ch := 0; ch < 2; ch++ {
+		dry := make([]float64, frames)
+		for i := 0; i < len(pcm)/4; i++ {
+			dry[i] = float64(int16(uint16(pcm[i*4+ch*2]) | uint16(pcm[i*4+ch*2+1])<<8))
+		}
+
+		wet := make([]float64, frames)
+		for _, d := range delays {
+			comb := make([]float64, frames)
+			for i := range comb {
+				comb[i] = dry[i]
+				if i >= d {
+					comb[i] += comb[i-d] * preset.Feedback
+				}
+				wet[i] += (comb[i] - dry[i]) / float64(len(delays))
+			}
+		}
+
+		for i := 0; i < frames; i++ {
+			v := dry[i] + wet[i]*preset.Mix
+			v = math.Max(math.MinInt16, math.Min(math.MaxInt16, v))
+			sample := uint16(int16(v))
+			out[i*4+ch*2] = byte(sample)
+			out[i*4+ch*2+1] = byte(sample >> 8)
+		}
+	}
+	return out
+}
+
+// soundListener hears noises at the camera position and plays the sound effect of those that have one,
+// quieter the further they travelled
+type soundListener struct {
+	g *Game
+}
+
+// ListenPosition returns the camera position
+func (l *soundListener) ListenPosition() sim.Vector2 {
+	return l.g.camera.GetPosition()
+}
+
+// HearingThreshold returns the quietest noise that is played
+func (l *soundListener) HearingThreshold() float64 {
+	return 0
+}
+
+// OnNoise plays the sound effect of the noise
+func (l *soundListener) OnNoise(n sim.Noise, loudness float64) {
+	if n.Sound != "" && n.Loudness > 0 {
+		l.g.sounds.Play(n.Sound, loudness/n.Loudness)
+	}
+}
@@ -0,0 +1,285 @@
+package engine
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"raycaster-go/engine/sim"
+	"sync"
+)
+
+const (
+	// ticks between snapshots sent to spectators
+	spectatorInterval = 2
+
+	// snapshots queued for a slow spectator before newer ones are dropped
+	spectatorQueue = 8
+
+	// longest snapshot line a spectator reads
+	spectatorMaxLine = 4 << 20
+
+	// free camera speeds
+	spectatorMoveSpeed = 0.1
+	spectatorTurnSpeed = 0.03
+)
+
+// SpectatorFeed streams the world state of the game to spectators connected over TCP, one snapshot JSON per
+// line. Spectators only receive, nothing they send is read, so they cannot affect the game.
+type SpectatorFeed struct {
+	listener net.Listener
+
+	mu      sync.Mutex
+	clients map[net.Conn]chan []byte
+
+	ticks int
+}
+
+// ServeSpectators starts accepting spectators on a TCP address (e.g. ":7777") and streams the world state
+// to them while the game runs
+func (g *Game) ServeSpectators(addr string) error {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("unable to listen for spectators on %s: %v", addr, err)
+	}
+	f := &SpectatorFeed{listener: ln, clients: make(map[net.Conn]chan []byte)}
+	go f.accept()
+
+	if g.feed != nil {
+		g.feed.Close()
+	}
+	g.feed = f
+	return nil
+}
+
+// GetSpectatorFeed returns the feed spectators are served from, nil if not serving
+func (g *Game) GetSpectatorFeed() *SpectatorFeed {
+	return g.feed
+}
+
+// GetSpectators returns the number of connected spectators
+func (f *SpectatorFeed) GetSpectators() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.clients)
+}
+
+// Close stops accepting spectators and disconnects the connected ones
+func (f *SpectatorFeed) Close() {
+	f.listener.Close()
+	f.mu.Lock()
+	conns := make([]net.Conn, 0, len(f.clients))
+	for conn := range f.clients {
+		conns = append(conns, conn)
+	}
+	f.mu.Unlock()
+	for _, conn := range conns {
+		f.drop(conn)
+	}
+}
+
+// accept adds spectators until the listener is closed
+func (f *SpectatorFeed) accept() {
+	for {
+		conn, err := f.listener.Accept()
+		if err != nil {
+			return
+		}
+		queue := make(chan []byte, spectatorQueue)
+		f.mu.Lock()
+		f.clients[conn] = queue
+		f.mu.Unlock()
+		go f.send(conn, queue)
+	}
+}
+
+// send writes the queued snapshots to a spectator until it disconnects
+func (f *SpectatorFeed) send(conn net.Conn, queue chan []byte) {
+	defer f.drop(conn)
+	for line := range queue {
+		if _, err := conn.Write(line); err != nil {
+			return
+		}
+	}
+}
+
+// drop disconnects a spectator
+func (f *SpectatorFeed) drop(conn net.Conn) {
+	f.mu.Lock()
+	if queue, ok := f.clients[conn]; ok {
+		delete(f.clients, conn)
+		close(queue)
+	}
+	f.mu.Unlock()
+	conn.Close()
+}
+
+// broadcast queues a snapshot line for every spectator, skipping those that have fallen behind
+func (f *SpectatorFeed) broadcast(line []byte) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, queue := range f.clients {
+		select {
+		case queue <- line:
+		default:
+		}
+	}
+}
+
+// updateSpectatorFeed sends the world state to the spectators every few ticks
+func (g *Game) updateSpectatorFeed() {
+	f := g.feed
+	f.ticks++
+	if f.ticks < spectatorInterval || f.GetSpectators() == 0 {
+		return
+	}
+	f.ticks = 0
+
+	data, err := g.camera.Snapshot().Marshal()
+	if err != nil {
+		fmt.Printf("Unable to encode spectator snapshot: %v\n", err)
+		return
+	}
+	f.broadcast(append(data, '\n'))
+}
+
+// Spectator receives the world state streamed by ServeSpectators into the current map, which should be the
+// map the player is in. It shows the viewpoint of the player or flies freely, and sends no input.
+type Spectator struct {
+	conn net.Conn
+
+	mu     sync.Mutex
+	latest *sim.Snapshot
+	err    error
+
+	free bool
+}
+
+// Spectate connects to a spectator feed and shows the game it streams instead of simulating the map
+func (g *Game) Spectate(addr string) error {
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("unable to connect to spectator feed %s: %v", addr, err)
+	}
+	s := &Spectator{conn: conn}
+	go s.receive()
+
+	if g.spectator != nil {
+		g.spectator.Close()
+	}
+	g.spectator = s
+	return nil
+}
+
+// GetSpectator returns the spectator connection, nil if not spectating
+func (g *Game) GetSpectator() *Spectator {
+	return g.spectator
+}
+
+// SetFreeCamera detaches the view from the player to fly around the map, or follows the player again
+func (s *Spectator) SetFreeCamera(free bool) {
+	s.free = free
+}
+
+// IsFreeCamera returns true if the view flies freely rather than following the player
+func (s *Spectator) IsFreeCamera() bool {
+	return s.free
+}
+
+// Err returns why the feed stopped, nil while it is connected
+func (s *Spectator) Err() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.err
+}
+
+// Close disconnects from the feed
+func (s *Spectator) Close() {
+	s.conn.Close()
+}
+
+// receive reads snapshots from the feed, keeping only the latest, until it is closed
+func (s *Spectator) receive() {
+	scanner := bufio.NewScanner(s.conn)
+	scanner.Buffer(make([]byte, 64*1024), spectatorMaxLine)
+	for scanner.Scan() {
+		snap, err := sim.UnmarshalSnapshot(scanner.Bytes())
+		if err != nil {
+			fmt.Printf("Unable to decode spectator snapshot: %v\n", err)
+			continue
+		}
+		s.mu.Lock()
+		s.latest = snap
+		s.mu.Unlock()
+	}
+
+	err := scanner.Err()
+	if err == nil {
+		err = fmt.Errorf("spectator feed closed")
+	}
+	s.mu.Lock()
+	s.err = err
+	s.mu.Unlock()
+}
+
+// take returns the snapshot received since the last call, nil if there is none
+func (s *Spectator) take() *sim.Snapshot {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	snap := s.latest
+	s.latest = nil
+	return snap
+}
+
+// updateSpectator applies the latest world state and renders the view without moving the camera through
+// gravity, tile and status effects of its own
+func (g *Game) updateSpectator() {
+	s := g.spectator
+	if snap := s.take(); snap != nil {
+		policy := sim.RestoreAll
+		if s.free {
+			policy &^= sim.RestoreCamera
+		}
+		g.camera.Restore(snap, policy)
+	}
+	g.camera.Render()
+}
+
+// handleSpectatorInput toggles the free camera with the use key and flies it through walls with the
+// movement keys
+func (g *Game) handleSpectatorInput() {
+	s := g.spectator
+	if g.actionJustPressed(ActionUse) {
+		s.SetFreeCamera(!s.free)
+		g.camera.SetNoClip(s.free)
+	}
+	if !s.free {
+		return
+	}
+
+	if g.actionPressed(ActionForward) {
+		g.camera.Move(spectatorMoveSpeed)
+	} else if g.actionPressed(ActionBackward) {
+		g.camera.Move(-spectatorMoveSpeed)
+	}
+	if g.actionPressed(ActionTurnLeft) {
+		g.camera.Rotate(spectatorTurnSpeed)
+	} else if g.actionPressed(ActionTurnRight) {
+		g.camera.Rotate(-spectatorTurnSpeed)
+	}
+	if g.actionPressed(ActionLookUp) {
+		g.camera.Pitch(1)
+	} else if g.actionPressed(ActionLookDown) {
+		g.camera.Pitch(-1)
+	}
+}
+
+// drawSpectator shows whether the view follows the player or flies freely, or that the feed was lost
+func (g *Game) drawSpectator() {
+	text := g.tr("hud.spectating")
+	if err := g.spectator.Err(); err != nil {
+		text = g.tr("hud.feedlost")
+	} else if g.spectator.free {
+		text = g.tr("hud.freecam")
+	}
+	g.drawText(text, g.width/2-g.textWidth(text)/2, dialogueMargin)
+}
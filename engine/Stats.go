@@ -0,0 +1,153 @@
+package engine
+
+import (
+	"fmt"
+	"raycaster-go/engine/sim"
+
+	"github.com/hajimehoshi/ebiten/ebitenutil"
+)
+
+const (
+	// intermission panel layout
+	intermissionWidth = 260
+)
+
+// LevelStats is the tally of a level kept while it is played, shown on the intermission screen when all
+// its objectives are complete and handed to OnLevelComplete
+type LevelStats struct {
+	// Map --name of the map played--//
+	Map string `json:"map"`
+
+	// Kills, TotalKills --enemies killed, out of the hostile sprites in the map and those its spawners create--//
+	Kills      int `json:"kills"`
+	TotalKills int `json:"totalKills"`
+
+	// Items, TotalItems --items picked up, out of the sprites marked as items--//
+	Items      int `json:"items"`
+	TotalItems int `json:"totalItems"`
+
+	// Secrets, TotalSecrets --secret cells found, out of the cells with a secret tile type--//
+	Secrets      int `json:"secrets"`
+	TotalSecrets int `json:"totalSecrets"`
+
+	// DamageTaken --damage dealt to the player--//
+	DamageTaken float64 `json:"damageTaken"`
+
+	// Time --map clock seconds from the start of the level to its completion--//
+	Time float64 `json:"time"`
+
+	// Complete --all objectives were completed--//
+	Complete bool `json:"complete"`
+
+	start float64
+	found map[[2]int]bool
+}
+
+// newLevelStats starts the tally for a map, counting what there is to find in it
+func newLevelStats(name string, m *sim.Map) *LevelStats {
+	s := &LevelStats{Map: name, start: m.GetClock().Now(), found: make(map[[2]int]bool)}
+	for _, sprite := range m.GetSprites() {
+		if sprite.Item {
+			s.TotalItems++
+		}
+		if m.GetRelation(sprite.Faction, sim.PlayerFaction) == sim.RelationHostile {
+			s.TotalKills++
+		}
+	}
+	for _, sp := range m.GetSpawners() {
+		for _, w := range sp.Waves {
+			s.TotalKills += w.Count
+		}
+	}
+
+	w, h := m.GetSize()
+	for x := 0; x < w; x++ {
+		for y := 0; y < h; y++ {
+			if t := m.GetTileType(x, y); t != nil && t.Secret {
+				s.TotalSecrets++
+			}
+		}
+	}
+	return s
+}
+
+// AddKill counts an enemy killed
+func (s *LevelStats) AddKill() {
+	s.Kills++
+}
+
+// AddItem counts an item picked up
+func (s *LevelStats) AddItem() {
+	s.Items++
+}
+
+// addDamage counts damage taken
+func (s *LevelStats) addDamage(damage float64) {
+	s.DamageTaken += damage
+}
+
+// enterTile counts a secret the first time its cell is entered
+func (s *LevelStats) enterTile(x, y int, tile *sim.TileType) {
+	if tile.Secret && !s.found[[2]int{x, y}] {
+		s.found[[2]int{x, y}] = true
+		s.Secrets++
+	}
+}
+
+// lines returns the stats as text for the intermission screen, in the selected language
+func (s *LevelStats) lines(l *Localizer) []string {
+	minutes, seconds := int(s.Time)/60, int(s.Time)%60
+	return []string{
+		l.Translate("stats.title"),
+		"",
+		fmt.Sprintf("%-10s%d / %d", l.Translate("stats.kills"), s.Kills, s.TotalKills),
+		fmt.Sprintf("%-10s%d / %d", l.Translate("stats.items"), s.Items, s.TotalItems),
+		fmt.Sprintf("%-10s%d / %d", l.Translate("stats.secrets"), s.Secrets, s.TotalSecrets),
+		fmt.Sprintf("%-10s%.0f", l.Translate("stats.damage"), s.DamageTaken),
+		fmt.Sprintf("%-10s%d:%02d", l.Translate("stats.time"), minutes, seconds),
+	}
+}
+
+// GetStats returns the tally of the level being played
+func (g *Game) GetStats() *LevelStats {
+	return g.stats
+}
+
+// startLevelStats begins a new tally for the current map and completes it when its objectives are done
+func (g *Game) startLevelStats() {
+	g.stats = newLevelStats(g.mapName, g.mapObj)
+	if objectives := g.mapObj.GetObjectives(); objectives != nil {
+		objectives.OnAllComplete = g.completeLevel
+	}
+}
+
+// completeLevel stops the clock on the level tally, shows the intermission screen and passes the
+// stats on to OnLevelComplete
+func (g *Game) completeLevel() {
+	s := g.stats
+	if s.Complete {
+		return
+	}
+	s.Time = g.mapObj.GetClock().Now() - s.start
+	s.Complete = true
+	g.intermission = s
+	g.saveProgress(s)
+
+	if g.OnLevelComplete != nil {
+		g.OnLevelComplete(s)
+	}
+}
+
+// drawIntermission shows the stats of the completed level in the middle of the screen
+func (g *Game) drawIntermission() {
+	lines := append(g.intermission.lines(g.locale), "", g.tr("stats.continue"))
+	w := float64(intermissionWidth)
+	h := float64(len(lines)*lineHeight + 2*dialoguePadding)
+	x, y := float64(g.width)/2-w/2, float64(g.height)/2-h/2
+
+	ebitenutil.DrawRect(g.view, x-1, y-1, w+2, h+2, dialogueBorder)
+	ebitenutil.DrawRect(g.view, x, y, w, h, dialogueBack)
+	for i, line := range lines {
+		g.drawText(line, int(x)+dialoguePadding, int(y)+dialoguePadding+i*lineHeight)
+	}
+}
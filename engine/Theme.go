@@ -0,0 +1,89 @@
+package engine
+
+import (
+	"image/color"
+	"raycaster-go/engine/raycaster"
+	"raycaster-go/engine/sim"
+
+	"github.com/hajimehoshi/ebiten"
+)
+
+// seconds the demo theme toggle crossfades over
+const themeFadeSeconds = 1.5
+
+// snow colors for the winter demo theme
+var paletteSnow = raycaster.TexturePalette{
+	Base:   color.RGBA{230, 235, 240, 255},
+	Accent: color.RGBA{205, 215, 228, 255},
+	Shadow: color.RGBA{170, 180, 195, 255},
+}
+
+// themeFade is the world frame from before a theme change, fading out over the new one
+type themeFade struct {
+	img   *ebiten.Image
+	alpha float64
+	fade  *sim.Tween
+}
+
+// SetTheme swaps the wall and floor textures of the theme in for both the world and the portal, keeping
+// the map grids as they are. With fadeSeconds above 0 the last world frame crossfades into the new theme.
+// Returns a theme of the replaced textures, which can be passed back in to restore them.
+func (g *Game) SetTheme(theme *raycaster.TextureTheme, fadeSeconds float64) *raycaster.TextureTheme {
+	if fadeSeconds > 0 {
+		if g.themeFade == nil {
+			img, _ := ebiten.NewImage(g.width, g.height, ebiten.FilterNearest)
+			g.themeFade = &themeFade{img: img}
+		}
+		f := g.themeFade
+		f.img.Clear()
+		f.img.DrawImage(g.worldFrame, &ebiten.DrawImageOptions{})
+		f.alpha = 1
+		f.fade = sim.TweenFloat(&f.alpha, 0, fadeSeconds, sim.EaseInOutQuad)
+	}
+
+	prev := g.tex.ApplyTheme(theme)
+
+	// floor levels share the floor textures, so only the first apply sees the ones being replaced
+	floors := g.world.floorLvl.ApplyTheme(theme)
+	prev.Floors = floors.Floors
+	if g.portal != nil {
+		g.portal.floorLvl.ApplyTheme(theme)
+	}
+	return prev
+}
+
+// newWinterTheme creates the demo theme with snow on the ground and frosted stone walls
+func (g *Game) newWinterTheme() *raycaster.TextureTheme {
+	theme := raycaster.NewTextureTheme("winter")
+	if frost := g.tex.GetTexture("stone:gray"); frost != nil {
+		theme.SetWall(0, frost)
+	}
+	size := g.floorTex[0].Bounds().Dx()
+	theme.SetFloor(0, raycaster.GenerateStone(size, 7, paletteSnow))
+	return theme
+}
+
+// toggleWinterTheme swaps between the loaded textures and the winter demo theme
+func (g *Game) toggleWinterTheme() {
+	if g.themeRestore == nil {
+		g.themeRestore = g.SetTheme(g.newWinterTheme(), themeFadeSeconds)
+	} else {
+		g.SetTheme(g.themeRestore, themeFadeSeconds)
+		g.themeRestore = nil
+	}
+}
+
+// drawThemeFade blends the world frame from before the last theme change over the current one
+// while it fades out
+func (g *Game) drawThemeFade() {
+	f := g.themeFade
+	if f == nil || f.fade == nil {
+		return
+	}
+
+	op := &ebiten.DrawImageOptions{}
+	op.ColorM.Scale(1, 1, 1, f.alpha)
+	g.worldFrame.DrawImage(f.img, op)
+
+	f.fade = f.fade.Update(1.0 / float64(ebiten.MaxTPS()))
+}
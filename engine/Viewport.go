@@ -0,0 +1,330 @@
+package engine
+
+import (
+	"image"
+	"image/color"
+	"raycaster-go/engine/raycaster"
+	"raycaster-go/engine/sim"
+
+	"github.com/hajimehoshi/ebiten"
+	"github.com/hajimehoshi/ebiten/ebitenutil"
+)
+
+const (
+	// names of the built in viewports
+	minimapName = "minimap"
+	mirrorName  = "mirror"
+
+	// name of the full window viewport watching a security camera
+	observerName = "observer"
+
+	// narrow field of view of the rear-view mirror
+	mirrorFOV = 40.0
+)
+
+var (
+	minimapBorder = color.RGBA{200, 200, 200, 200}
+)
+
+// Anchor is the corner, edge or middle of the window a viewport is placed against
+type Anchor int
+
+const (
+	AnchorTopLeft Anchor = iota
+	AnchorTop
+	AnchorTopRight
+	AnchorLeft
+	AnchorCenter
+	AnchorRight
+	AnchorBottomLeft
+	AnchorBottom
+	AnchorBottomRight
+)
+
+// Viewport is a render target placed within the window on top of the main view, with its own size and
+// update rate (minimap, mirror, security camera)
+type Viewport struct {
+	// Name --looked up with Layout.Get--//
+	Name string
+
+	// Anchor --where in the window the viewport is placed--//
+	Anchor Anchor
+
+	// Width, Height --size as a fraction of the window, so viewports keep their share of it on resize--//
+	Width, Height float64
+
+	// Margin --pixels between the viewport and the window edges it is anchored to--//
+	Margin int
+
+	// Interval --ticks between renders, the last render is drawn in between, 1 or less renders every tick--//
+	Interval int
+
+	// Border --outline drawn around the viewport, none if fully transparent--//
+	Border color.RGBA
+
+	// Hidden --the viewport is neither rendered nor drawn--//
+	Hidden bool
+
+	// Render --draws the contents into the cleared viewport image--//
+	Render func(target *ebiten.Image)
+
+	// OnResize --called with the new pixel size when the viewport is added and whenever the window size changes--//
+	OnResize func(width, height int)
+
+	img   *ebiten.Image
+	rect  image.Rectangle
+	ticks int
+}
+
+// GetRect returns the pixel rectangle of the viewport within the window
+func (v *Viewport) GetRect() image.Rectangle {
+	return v.rect
+}
+
+// place works out the pixel rectangle of the viewport in a window of the given size, remaking its image and
+// calling OnResize if its size changed
+func (v *Viewport) place(width, height int) {
+	w, h := int(v.Width*float64(width)), int(v.Height*float64(height))
+	if w < 1 {
+		w = 1
+	}
+	if h < 1 {
+		h = 1
+	}
+
+	x, y := v.Margin, v.Margin
+	switch v.Anchor % 3 {
+	case 1:
+		x = (width - w) / 2
+	case 2:
+		x = width - w - v.Margin
+	}
+	switch v.Anchor / 3 {
+	case 1:
+		y = (height - h) / 2
+	case 2:
+		y = height - h - v.Margin
+	}
+
+	resized := v.img == nil || v.rect.Dx() != w || v.rect.Dy() != h
+	v.rect = image.Rect(x, y, x+w, y+h)
+	if !resized {
+		return
+	}
+	v.img, _ = ebiten.NewImage(w, h, ebiten.FilterNearest)
+	v.ticks = 0
+	if v.OnResize != nil {
+		v.OnResize(w, h)
+	}
+}
+
+// update renders the viewport when its interval has passed since the last render
+func (v *Viewport) update() {
+	if v.Hidden || v.Render == nil {
+		return
+	}
+	v.ticks--
+	if v.ticks > 0 {
+		return
+	}
+	v.ticks = v.Interval
+
+	v.img.Clear()
+	v.Render(v.img)
+}
+
+// draw puts the last render of the viewport and its border on the window image
+func (v *Viewport) draw(dst *ebiten.Image) {
+	if v.Hidden || v.img == nil {
+		return
+	}
+	x, y := float64(v.rect.Min.X), float64(v.rect.Min.Y)
+	w, h := float64(v.rect.Dx()), float64(v.rect.Dy())
+	if v.Border.A > 0 {
+		ebitenutil.DrawRect(dst, x-1, y-1, w+2, h+2, v.Border)
+	}
+
+	op := &ebiten.DrawImageOptions{}
+	op.GeoM.Translate(x, y)
+	dst.DrawImage(v.img, op)
+}
+
+// Layout places viewports within the window. The main view always fills the window and viewports are
+// drawn over it in the order they were added.
+type Layout struct {
+	viewports []*Viewport
+
+	//--window size the viewports are placed in--//
+	width  int
+	height int
+}
+
+// NewLayout creates an empty layout for a window of the given size
+func NewLayout(width, height int) *Layout {
+	return &Layout{width: width, height: height}
+}
+
+// Add places a viewport in the layout, replacing any viewport with the same name
+func (l *Layout) Add(v *Viewport) {
+	v.img = nil
+	v.place(l.width, l.height)
+	for i, existing := range l.viewports {
+		if existing.Name == v.Name {
+			l.viewports[i] = v
+			return
+		}
+	}
+	l.viewports = append(l.viewports, v)
+}
+
+// Remove takes the viewport with the given name out of the layout, returning false if there is none
+func (l *Layout) Remove(name string) bool {
+	for i, v := range l.viewports {
+		if v.Name == name {
+			l.viewports = append(l.viewports[:i], l.viewports[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// Get returns the viewport with the given name, nil if there is none
+func (l *Layout) Get(name string) *Viewport {
+	for _, v := range l.viewports {
+		if v.Name == name {
+			return v
+		}
+	}
+	return nil
+}
+
+// GetViewports returns the viewports in drawing order
+func (l *Layout) GetViewports() []*Viewport {
+	return l.viewports
+}
+
+// Resize places every viewport again for a new window size
+func (l *Layout) Resize(width, height int) {
+	l.width, l.height = width, height
+	for _, v := range l.viewports {
+		v.place(width, height)
+	}
+}
+
+// GetSize returns the window size the viewports are placed in
+func (l *Layout) GetSize() (int, int) {
+	return l.width, l.height
+}
+
+// update renders the viewports that are due
+func (l *Layout) update() {
+	for _, v := range l.viewports {
+		v.update()
+	}
+}
+
+// draw puts every visible viewport on the window image
+func (l *Layout) draw(dst *ebiten.Image) {
+	for _, v := range l.viewports {
+		v.draw(dst)
+	}
+}
+
+// GetLayout returns the layout of the viewports drawn over the main view
+func (g *Game) GetLayout() *Layout {
+	return g.layout
+}
+
+// NewMinimap creates a viewport showing the map from above in the top right corner, with the sprites and
+// the camera facing direction, redrawn every few ticks
+func (g *Game) NewMinimap() *Viewport {
+	return &Viewport{
+		Name: minimapName, Anchor: AnchorTopRight, Width: 0.25, Height: 0.33, Margin: dialogueMargin,
+		Interval: 4, Border: minimapBorder, Render: g.drawMinimap,
+	}
+}
+
+// drawMinimap draws the ground level walls, sprites and camera scaled to fit the target
+func (g *Game) drawMinimap(target *ebiten.Image) {
+	mapW, mapH := g.mapObj.GetSize()
+	tw, th := target.Size()
+	cell := float64(tw) / float64(mapW)
+	if ch := float64(th) / float64(mapH); ch < cell {
+		cell = ch
+	}
+	left, top := (float64(tw)-cell*float64(mapW))/2, (float64(th)-cell*float64(mapH))/2
+
+	toView := func(p sim.Vector2) (float64, float64) {
+		return left + p.X*cell, top + p.Y*cell
+	}
+
+	target.Fill(navBack)
+	if levels := g.mapObj.GetLevels(); len(levels) > 0 {
+		for x := 0; x < mapW; x++ {
+			for y := 0; y < mapH; y++ {
+				if levels[0][x][y] > 0 {
+					ebitenutil.DrawRect(target, left+float64(x)*cell, top+float64(y)*cell, cell, cell, navWall)
+				}
+			}
+		}
+	}
+
+	for _, s := range g.mapObj.GetSprites() {
+		sx, sy := toView(sim.Vector2{X: s.X, Y: s.Y})
+		ebitenutil.DrawRect(target, sx-1, sy-1, 2, 2, navSprite)
+	}
+
+	cx, cy := toView(g.camera.GetPosition())
+	dir := g.camera.GetDirection()
+	ebitenutil.DrawRect(target, cx-1.5, cy-1.5, 3, 3, navCamera)
+	ebitenutil.DrawLine(target, cx, cy, cx+dir.X*2*cell, cy+dir.Y*2*cell, navCamera)
+}
+
+// NewRearViewMirror creates a wide, short viewport at the top of the window showing what is behind the
+// player through a narrow camera facing the other way
+func (g *Game) NewRearViewMirror() *Viewport {
+	v := g.NewCameraViewport(mirrorName, func(player, view *raycaster.Camera) {
+		view.SetFOV(mirrorFOV)
+		view.FollowBehind(player.Camera)
+	})
+	v.Anchor, v.Width, v.Height, v.Border = AnchorTop, 0.3, 0.15, minimapBorder
+	return v
+}
+
+// SetRearViewMirror shows or hides the rear-view mirror
+func (g *Game) SetRearViewMirror(on bool) {
+	if v := g.layout.Get(mirrorName); v != nil {
+		v.Hidden = !on
+	}
+}
+
+// IsRearViewMirror returns true if the rear-view mirror is shown
+func (g *Game) IsRearViewMirror() bool {
+	v := g.layout.Get(mirrorName)
+	return v != nil && !v.Hidden
+}
+
+// NewCameraViewport creates a viewport rendering the current map from a second camera. Follow places the
+// view camera each time before it renders, nil keeps it on the player camera. The camera and its buffers
+// are made again when the viewport is resized or the map changes.
+func (g *Game) NewCameraViewport(name string, follow func(player, view *raycaster.Camera)) *Viewport {
+	var view *worldView
+	vp := &Viewport{Name: name, Anchor: AnchorTopLeft, Width: 0.33, Height: 0.33, Margin: dialogueMargin, Interval: 1}
+	vp.OnResize = func(width, height int) {
+		view = nil
+	}
+	vp.Render = func(target *ebiten.Image) {
+		if view == nil || view.mapObj != g.mapObj {
+			w, h := target.Size()
+			view = g.newWorldView(g.mapObj, w, h)
+		}
+		if follow != nil {
+			follow(g.camera, view.camera)
+		} else {
+			view.camera.Follow(g.camera.Camera)
+		}
+		view.camera.Render()
+		g.drawWorld(target, view)
+	}
+	return vp
+}
@@ -0,0 +1,144 @@
+package engine
+
+import (
+	"image"
+	"image/color"
+	"log"
+	"raycaster-go/engine/raycaster"
+	"raycaster-go/engine/sim"
+
+	"github.com/hajimehoshi/ebiten"
+)
+
+// fewest wall levels drawn for a map, so the top authored level of the sample maps repeats up into a taller border
+const minLevels = 4
+
+// worldView is a map together with the camera and render buffers used to draw it.
+// Each view is independent, so several maps can be updated and drawn side by side.
+type worldView struct {
+	mapObj *sim.Map
+	camera *raycaster.Camera
+
+	//--array of levels, levels reffer to "floors" of the world, sprite levels are kept by the camera--//
+	levels   []*raycaster.Level
+	floorLvl *raycaster.HorLevel
+
+	//--render size--//
+	width  int
+	height int
+}
+
+// newWorldView creates the render buffers and camera for a map with its sprites already loaded
+func (g *Game) newWorldView(mapObj *sim.Map, width, height int) *worldView {
+	v := &worldView{mapObj: mapObj, width: width, height: height}
+
+	//--inits the levels--//
+	numLevels := mapObj.GetNumLevels()
+	if numLevels < minLevels {
+		numLevels = minLevels
+	}
+	v.levels = raycaster.NewLevels(width, height, numLevels)
+	v.floorLvl = raycaster.NewHorLevel(width, height, g.floorTex)
+	v.floorLvl.GenerateMipmaps(mipLevels)
+	spriteLvls := raycaster.NewSpriteLevels(mapObj.GetNumSprites() + mapObj.SpawnCapacity())
+
+	//--init camera--//
+	v.camera = raycaster.NewCamera(width, height, texSize, mapObj, g.slices, v.levels, v.floorLvl, spriteLvls, g.tex,
+		raycaster.WithTargetTPS(targetTPS))
+
+	// floor distances and column workers of the quality preset
+	g.applyQuality(v.camera)
+
+	return v
+}
+
+// setMap replaces the map the player is in, keeping the camera event hooks and seed and starting its music
+func (g *Game) setMap(name string, mapObj *sim.Map) {
+	events := g.camera.Events
+
+	g.paths.CancelAll()
+	g.paths = sim.NewPathQueue(mapObj, g.scheduler)
+
+	g.mapObj, g.mapName = mapObj, name
+	g.mapObj.SetSeed(g.seed)
+	g.world = g.newWorldView(mapObj, g.width, g.height)
+	g.securityViews = nil
+	g.camera = g.world.camera
+	g.camera.Events = events
+
+	g.addMapListeners()
+	g.music.PlayMap(mapObj.GetMusic())
+	g.startLevelStats()
+	g.intermission = nil
+}
+
+// addMapListeners registers the listeners turning noises in the map into captions, sounds and rumble
+func (g *Game) addMapListeners() {
+	g.mapObj.AddListener(&captionListener{g: g})
+	g.mapObj.AddListener(&soundListener{g: g})
+	g.mapObj.AddListener(&hapticsListener{g: g})
+}
+
+// drawWorld renders the last raycast of the view into the target image
+func (g *Game) drawWorld(target *ebiten.Image, v *worldView) {
+	g.spriteBatch.target = target
+
+	//--draw basic sky and floor--//
+	texRect := image.Rect(0, 0, texSize, texSize)
+	whiteRGBA := &color.RGBA{255, 255, 255, 255}
+
+	// spriteBatch.Draw(floor,
+	//    new Rectangle(0, (int)(height * 0.5f), width, (int)(height * 0.5f)),
+	//    new Rectangle(0, 0, texSize, texSize),
+	//    Color.White);
+	horizon := v.camera.GetHorizon()
+	floorRect := image.Rect(0, horizon, v.width, v.height)
+	g.spriteBatch.draw(g.floor, &floorRect, &texRect, whiteRGBA)
+
+	// spriteBatch.Draw(sky,
+	//    new Rectangle(0, 0, width, (int)(height * 0.5f)),
+	//    new Rectangle(0, 0, texSize, texSize),
+	//    Color.White);
+	skyRect := image.Rect(0, 0, v.width, horizon)
+	g.spriteBatch.draw(g.sky, &skyRect, &texRect, whiteRGBA)
+
+	//--draw walls--//
+	for x := 0; x < v.width; x++ {
+		for i := cap(v.levels) - 1; i >= 0; i-- {
+			lvl := v.levels[i]
+			g.spriteBatch.drawHooked(lvl.CurrTex[x], lvl.Sv[x], lvl.Cts[x], lvl.St[x], lvl.Shade[x], nil)
+		}
+	}
+
+	// draw textured floor
+	floorImg, err := ebiten.NewImageFromImage(v.floorLvl.HorBuffer, ebiten.FilterLinear)
+	if err != nil || floorImg == nil {
+		log.Fatal(err)
+	} else {
+		op := &ebiten.DrawImageOptions{}
+		op.Filter = ebiten.FilterLinear
+		target.DrawImage(floorImg, op)
+	}
+
+	// outline the sprite that can be used, before any sprite so neighbouring slices draw over it
+	if highlight := g.highlightLevel(v); highlight != nil {
+		for x := 0; x < v.width; x++ {
+			g.spriteBatch.drawOutline(highlight.CurrTex[x], highlight.Sv[x], highlight.Cts[x], highlightColor, highlightWidth)
+		}
+	}
+
+	// draw sprites
+	spriteLvls := v.camera.GetSpriteLevels()
+	for x := 0; x < v.width; x++ {
+		for _, spriteLvl := range spriteLvls {
+			if spriteLvl == nil {
+				continue
+			}
+
+			texture := spriteLvl.CurrTex[x]
+			if texture != nil {
+				g.spriteBatch.drawHooked(texture, spriteLvl.Sv[x], spriteLvl.Cts[x], spriteLvl.St[x], spriteLvl.Shade[x], spriteLvl.DrawHook)
+			}
+		}
+	}
+}
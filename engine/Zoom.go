@@ -0,0 +1,99 @@
+package engine
+
+import (
+	"image"
+	"image/color"
+	"math"
+
+	"github.com/hajimehoshi/ebiten"
+)
+
+const (
+	// field of view scale while zoomed in
+	zoomScale = 0.35
+
+	// seconds to ease in and out of zoom
+	zoomSeconds = 0.25
+
+	// radius of each binocular lens as a fraction of the screen height
+	zoomLensRadius = 0.46
+
+	// horizontal offset of each lens from the screen center, as a fraction of the lens radius
+	zoomLensOffset = 0.6
+)
+
+var (
+	zoomMaskColor    = color.RGBA{0, 0, 0, 255}
+	zoomReticleColor = color.RGBA{0, 0, 0, 200}
+)
+
+// toggleZoom zooms in if not zoomed, otherwise eases back out
+func (g *Game) toggleZoom() {
+	if g.camera.IsZoomed() {
+		g.camera.Zoom(1, zoomSeconds)
+	} else {
+		g.camera.Zoom(zoomScale, zoomSeconds)
+	}
+}
+
+// drawZoom draws the binocular mask and reticle over the view, faded in with the zoom
+func (g *Game) drawZoom() {
+	progress := g.camera.GetZoomProgress()
+	if progress <= 0 {
+		return
+	}
+
+	if g.zoomImg == nil {
+		g.zoomImg = newZoomImage(g.width, g.height)
+	}
+
+	op := &ebiten.DrawImageOptions{}
+	op.ColorM.Scale(1, 1, 1, progress)
+	g.view.DrawImage(g.zoomImg, op)
+}
+
+// newZoomImage creates a binocular mask with two overlapping clear lenses and a reticle in the center
+func newZoomImage(width, height int) *ebiten.Image {
+	rgba := image.NewRGBA(image.Rect(0, 0, width, height))
+	cx, cy := float64(width)/2, float64(height)/2
+	radius := float64(height) * zoomLensRadius
+	offset := radius * zoomLensOffset
+
+	for x := 0; x < width; x++ {
+		for y := 0; y < height; y++ {
+			dy := float64(y) - cy
+			dist := math.Min(math.Hypot(float64(x)-(cx-offset), dy), math.Hypot(float64(x)-(cx+offset), dy))
+
+			// soft lens edge a few pixels wide
+			alpha := math.Max(0, math.Min(1, (dist-radius+3)/3))
+			if alpha > 0 {
+				clr := zoomMaskColor
+				clr.A = uint8(float64(clr.A) * alpha)
+				clr.R = uint8(float64(clr.R) * alpha)
+				clr.G = uint8(float64(clr.G) * alpha)
+				clr.B = uint8(float64(clr.B) * alpha)
+				rgba.SetRGBA(x, y, clr)
+			}
+		}
+	}
+
+	// reticle: thin cross with a gap in the middle and range ticks below
+	gap, arm := int(radius*0.05), int(radius*0.4)
+	icx, icy := int(cx), int(cy)
+	for i := gap; i < arm; i++ {
+		rgba.SetRGBA(icx-i, icy, zoomReticleColor)
+		rgba.SetRGBA(icx+i, icy, zoomReticleColor)
+		rgba.SetRGBA(icx, icy-i, zoomReticleColor)
+		rgba.SetRGBA(icx, icy+i, zoomReticleColor)
+	}
+	tick := int(radius * 0.04)
+	for n := 1; n <= 3; n++ {
+		y := icy + n*arm/4
+		for i := -tick; i <= tick; i++ {
+			rgba.SetRGBA(icx+i, y, zoomReticleColor)
+		}
+	}
+
+	img, _ := ebiten.NewImageFromImage(rgba, ebiten.FilterDefault)
+	return img
+}
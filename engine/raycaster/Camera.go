@@ -5,50 +5,35 @@ import (
 	"image"
 	"image/color"
 	"math"
-	"sync"
+	"raycaster-go/engine/sim"
 
 	"github.com/hajimehoshi/ebiten"
 )
 
 const (
-	//--move speed--//
-	moveSpeed = 0.06
-
-	//--rotate speed--//
-	rotSpeed = 0.03
-
-	// maximum number of concurrent tasks for large task sets (e.g. floor and sprite casting)
+	// maximum number of concurrent tasks for large task sets (e.g. sprite casting)
 	maxConcurrent = 100
-
-	// constant used for movement target framerate to prevent higher framerates from moving too fast
-	movementTPS = 60.0
 )
 
-// Camera Class that represents a camera in terms of raycasting.
-// Contains methods to move the camera, and handles projection to,
-// set the rectangle slice position and height,
+// Camera renders what a simulated camera sees in terms of raycasting: sets the rectangle slice position,
+// height and tint of each level for the game to draw. The embedded sim.Camera moves it through the map.
 type Camera struct {
-	//--camera position, init to start position--//
-	pos *Vector2
+	*sim.Camera
 
-	//--current facing direction, init to values coresponding to FOV--//
-	dir *Vector2
+	//--position, facing and feet height of the simulated camera, read at the start of each raycast--//
+	pos  sim.Vector2
+	dir  sim.Vector2
+	posZ float64
 
-	//--the 2d raycaster version of camera plane, adjust y component to change FOV (ratio between this and dir x resizes FOV)--//
-	plane *Vector2
+	//--camera plane used for rendering, the plane scaled by field of view modifiers--//
+	viewPlane sim.Vector2
 
 	//--viewport width and height--//
 	w int
 	h int
 
-	// target framerate reference
-	targetTPS int
-
-	//--world map--//
-	mapObj   *Map
-	worldMap [][]int
-	upMap    [][]int
-	midMap   [][]int
+	//--world map, grids are read from it on every raycast so runtime changes show at once--//
+	mapObj *sim.Map
 
 	//--texture width--//
 	texWidth int
@@ -56,17 +41,24 @@ type Camera struct {
 	//--slices--//
 	s []*image.Rectangle
 
-	//--cam x/y pre calc--//
+	//--cam x/y pre calc, camY covers rows a screen height above and below so it can be looked up sheared--//
 	camX []float64
 	camY []float64
 
+	//--rows the horizon is moved down by for the view pitch--//
+	shear int
+
 	//--structs that contain rects and tints for each level render--//
 	lvls []*Level
 
 	// zbuffer for sprite casting
 	zBuffer []float64
+	//perpendicular wall distance of each level by column, used to clip sprites behind upper levels
+	levelDepth [][]float64
 	// sprites
-	sprite []*Sprite
+	sprite []*sim.Sprite
+	//sprites only this camera draws, see SetViewSprites
+	viewSprites []*sim.Sprite
 	//arrays used to sort the sprites
 	spriteOrder    []int
 	spriteDistance []float64
@@ -76,34 +68,43 @@ type Camera struct {
 
 	horLvl *HorLevel
 
-	// used for concurrency
+	//--strength of the darkening band where walls meet the floor--//
+	occlusion float64
+
+	//--how distance lighting is applied--//
+	shading ShadingMode
+
+	// FloorFilterDistance --floor rows further than this are sampled bilinearly, 0 to always sample the nearest texel--//
+	FloorFilterDistance float64
+
+	// FloorDrawDistance --floor rows further than this are not textured and show the flat floor, 0 for no limit--//
+	FloorDrawDistance float64
+
+	// used for concurrency, limits the sprite goroutines in flight
 	semaphore chan struct{}
-}
 
-// Vector2 converted struct from C#
-type Vector2 struct {
-	X float64
-	Y float64
-}
+	//--goroutine owned state for the column passes--//
+	workers []*castWorker
 
-// NewCamera initalizes a Camera object
-func NewCamera(width int, height int, texWid int, mapObj *Map, slices []*image.Rectangle,
-	levels []*Level, horizontalLevel *HorLevel, spriteLvls []*Level, tex *TextureHandler) *Camera {
+	//--number of sprites cast in the last raycast--//
+	numSprites int
 
-	fmt.Printf("Initializing Camera\n")
+	//--grid cells the ground level rays passed through in the current frame--//
+	frame        int
+	visibleFrame [][]int
+	visibleTiles [][2]int
+}
 
-	c := &Camera{}
+// NewCamera initalizes a Camera object rendering into the given buffers, configured by any options.
+// It leaves global ebiten state such as the TPS to the caller.
+func NewCamera(width int, height int, texWid int, mapObj *sim.Map, slices []*image.Rectangle,
+	levels []*Level, horizontalLevel *HorLevel, spriteLvls []*Level, tex *TextureHandler, opts ...CameraOption) *Camera {
 
-	// set target FPS (TPS)
-	c.targetTPS = 60
-	ebiten.SetMaxTPS(c.targetTPS)
+	fmt.Printf("Initializing Camera\n")
 
-	//--camera position, init to start position--//
-	c.pos = &Vector2{X: 22.5, Y: 11.5}
-	//--current facing direction, init to values coresponding to FOV--//
-	c.dir = &Vector2{X: -1.0, Y: 0.0}
-	//--the 2d raycaster version of camera plane, adjust y component to change FOV (ratio between this and dir x resizes FOV)--//
-	c.plane = &Vector2{X: 0.0, Y: 0.66}
+	c := &Camera{Camera: sim.NewCamera(mapObj)}
+	c.mapObj = mapObj
+	c.occlusion = defaultOcclusion
 
 	c.w = width
 	c.h = height
@@ -120,21 +121,25 @@ func NewCamera(width int, height int, texWid int, mapObj *Map, slices []*image.R
 
 	// set zbuffer based on screen width
 	c.zBuffer = make([]float64, width)
-
-	c.mapObj = mapObj
-	c.worldMap = c.mapObj.getGrid()
-	c.upMap = c.mapObj.getGridUp()
-	c.midMap = c.mapObj.getGridMid()
-
-	c.sprite = c.mapObj.getSprites()
-	c.spriteOrder = make([]int, c.mapObj.numSprites)
-	c.spriteDistance = make([]float64, c.mapObj.numSprites)
+	c.levelDepth = make([][]float64, cap(levels))
+	for i := range c.levelDepth {
+		c.levelDepth[i] = make([]float64, width)
+	}
 
 	c.tex = tex
 
-	// initialize a pool of channels to limit concurrent floor and sprite casting
+	c.sprite = c.mapObj.GetSprites()
+	c.spriteOrder = make([]int, len(c.sprite))
+	c.spriteDistance = make([]float64, len(c.sprite))
+
+	// initialize a pool of channels to limit concurrent sprite casting
 	// from https://pocketgophers.com/limit-concurrent-use/
 	c.semaphore = make(chan struct{}, maxConcurrent)
+	c.SetWorkers(castWorkers)
+
+	for _, opt := range opts {
+		opt(c)
+	}
 
 	//do an initial raycast
 	c.raycast()
@@ -147,10 +152,32 @@ func (c *Camera) Update() {
 	// clear horizontal buffer by making a new one
 	c.horLvl.Clear(c.w, c.h)
 
+	c.Simulate()
+
 	//--do raycast--//
 	c.raycast()
 }
 
+// Render casts the view without moving the camera or applying gravity, tile and status effects, for
+// cameras that only look at a map another camera plays in
+func (c *Camera) Render() {
+	c.horLvl.Clear(c.w, c.h)
+	c.raycast()
+}
+
+// UpdateWithDelta updates the camera view for a tick lasting dt seconds
+func (c *Camera) UpdateWithDelta(dt float64) {
+	c.SetDeltaTime(dt)
+	c.Update()
+}
+
+// updateView reads the position, facing and field of view of the simulated camera for this frame
+func (c *Camera) updateView() {
+	c.pos, c.dir = c.GetPosition(), c.GetDirection()
+	c.posZ = c.GetHeight()
+	c.viewPlane = c.GetViewPlane()
+}
+
 // precalculates camera x coordinate
 func (c *Camera) preCalcCamX() {
 	c.camX = make([]float64, c.w)
@@ -161,26 +188,39 @@ func (c *Camera) preCalcCamX() {
 
 // precalculates camera y coordinate
 func (c *Camera) preCalcCamY() {
-	c.camY = make([]float64, c.h)
-	for y := 0; y < c.h; y++ {
-		c.camY[y] = float64(c.h) / (2.0*float64(y) - float64(c.h))
+	c.camY = make([]float64, 3*c.h)
+	for y := -c.h; y < 2*c.h; y++ {
+		c.camY[y+c.h] = float64(c.h) / (2.0*float64(y) - float64(c.h))
 	}
 }
 
 func (c *Camera) raycast() {
-	// cast level
-	numLevels := cap(c.lvls)
-	var wg sync.WaitGroup
-	for i := 0; i < numLevels; i++ {
-		wg.Add(1)
-		go c.asyncCastLevel(i, &wg)
-	}
+	c.updateView()
+	c.updateShear()
+	c.beginVisibleFrame()
+
+	if terrain := c.mapObj.TerrainAt(c.pos.X, c.pos.Y); terrain != nil {
+		// cast terrain in place of levels
+		c.castTerrain(terrain)
+	} else {
+		// cast levels bottom to top one column at a time, so everything writing a column runs in a fixed order
+		numLevels := cap(c.lvls)
+		c.castColumns(func(w *castWorker, x int) {
+			// nothing hides sprites if there are no levels
+			c.zBuffer[x] = math.MaxFloat64
+			for levelNum := 0; levelNum < numLevels; levelNum++ {
+				c.castLevel(w, x, c.levelGrid(levelNum), c.lvls[levelNum], levelNum)
+			}
+		})
+		c.mergeVisible()
 
-	wg.Wait()
+		// cast flat surfaces over the floor
+		c.castSurfaces()
+	}
 
 	//SPRITE CASTING
 	//sort sprites from far to close
-	numSprites := c.mapObj.numSprites
+	numSprites := c.syncSprites()
 	for i := 0; i < numSprites; i++ {
 		c.spriteOrder[i] = i
 		c.spriteDistance[i] = ((c.pos.X-c.sprite[i].X)*(c.pos.X-c.sprite[i].X) + (c.pos.Y-c.sprite[i].Y)*(c.pos.Y-c.sprite[i].Y)) //sqrt not taken, unneeded
@@ -188,45 +228,77 @@ func (c *Camera) raycast() {
 	combSort(c.spriteOrder, c.spriteDistance, numSprites)
 
 	//after sorting the sprites, do the projection and draw them
-	for i := 0; i < numSprites; i++ {
-		wg.Add(1)
-		go c.asyncCastSprite(i, &wg)
-	}
-
-	wg.Wait()
+	c.castSprites(numSprites)
 }
 
-func (c *Camera) asyncCastLevel(levelNum int, wg *sync.WaitGroup) {
-	defer wg.Done()
+// syncSprites picks up sprites added to or removed from the map since the last raycast and returns how
+// many of them are cast, adding sprite levels when there are more sprites than levels
+func (c *Camera) syncSprites() int {
+	c.sprite = c.mapObj.GetSprites()
+	numSprites := c.mapObj.GetNumSprites()
+	if numSprites > len(c.sprite) {
+		numSprites = len(c.sprite)
+	}
+	if len(c.viewSprites) > 0 {
+		// copied so the map sprites are never appended to
+		c.sprite = append(append([]*sim.Sprite(nil), c.sprite[:numSprites]...), c.viewSprites...)
+		numSprites = len(c.sprite)
+	}
+	if numSprites > len(c.spriteLvls) {
+		c.spriteLvls = append(c.spriteLvls, NewSpriteLevels(numSprites-len(c.spriteLvls))...)
+	}
 
-	var rMap [][]int
-	if levelNum == 0 {
-		rMap = c.worldMap
-	} else if levelNum == 1 {
-		rMap = c.midMap
-	} else {
-		rMap = c.upMap //if above lvl2 just keep extending up
+	if len(c.spriteOrder) < numSprites {
+		c.spriteOrder = make([]int, numSprites)
+		c.spriteDistance = make([]float64, numSprites)
 	}
 
-	for x := 0; x < c.w; x++ {
-		c.castLevel(x, rMap, c.lvls[levelNum], levelNum, wg)
+	// levels of sprites no longer cast must not be drawn
+	for i := numSprites; i < c.numSprites && i < len(c.spriteLvls); i++ {
+		c.spriteLvls[i] = nil
+	}
+	c.numSprites = numSprites
+	return numSprites
+}
+
+// GetSpriteLevel returns the level the sprite was cast into during the last raycast, or nil if it was not drawn
+func (c *Camera) GetSpriteLevel(s *sim.Sprite) *Level {
+	for i := 0; i < c.numSprites; i++ {
+		if c.sprite[c.spriteOrder[i]] == s {
+			return c.spriteLvls[i]
+		}
 	}
+	return nil
 }
 
-func (c *Camera) asyncCastSprite(spriteNum int, wg *sync.WaitGroup) {
-	defer wg.Done()
+// GetSpriteLevels returns the sprite levels cast into during the last raycast, nil entries are not drawn.
+// The slice grows when sprites are added to the map, so get it again for every frame drawn.
+func (c *Camera) GetSpriteLevels() []*Level {
+	return c.spriteLvls
+}
 
-	c.semaphore <- struct{}{} // Lock
-	defer func() {
-		<-c.semaphore // Unlock
-	}()
+// SetViewSprites sets sprites drawn by this camera only, over those of its map (ghosts of recorded players,
+// markers). They take no part in collision, ray queries or the map update. nil removes them.
+func (c *Camera) SetViewSprites(sprites []*sim.Sprite) {
+	c.viewSprites = sprites
+}
 
-	c.castSprite(spriteNum)
+// UpdateSprites picks up sprites moved, added or removed by game code straight away, for collision and
+// ray checks made before the next raycast. Sprite positions are read on every raycast either way.
+func (c *Camera) UpdateSprites() {
+	c.Camera.UpdateSprites()
+	c.syncSprites()
 }
 
 // credit : Raycast loop and setting up of vectors for matrix calculations
 // courtesy - http://lodev.org/cgtutor/raycasting.html
-func (c *Camera) castLevel(x int, grid [][]int, lvl *Level, levelNum int, wg *sync.WaitGroup) {
+func (c *Camera) castLevel(w *castWorker, x int, grid [][]int, lvl *Level, levelNum int) {
+	if grid == nil {
+		// empty level above the authored ones, nothing to draw
+		lvl.CurrTex[x] = nil
+		return
+	}
+
 	var _cts, _sv []*image.Rectangle
 	var _st []*color.RGBA
 
@@ -236,99 +308,79 @@ func (c *Camera) castLevel(x int, grid [][]int, lvl *Level, levelNum int, wg *sy
 
 	//calculate ray position and direction
 	cameraX := c.camX[x] //x-coordinate in camera space
-	rayDirX := c.dir.X + c.plane.X*cameraX
-	rayDirY := c.dir.Y + c.plane.Y*cameraX
+	rayDirX := c.dir.X + c.viewPlane.X*cameraX
+	rayDirY := c.dir.Y + c.viewPlane.Y*cameraX
 
 	//--rays start at camera position--//
 	rayPosX := c.pos.X
 	rayPosY := c.pos.Y
 
-	//which box of the map we're in
-	mapX := int(rayPosX)
-	mapY := int(rayPosY)
-
-	//length of ray from current position to next x or y-side
-	var sideDistX float64
-	var sideDistY float64
-
-	//length of ray from one x or y-side to next x or y-side
-	deltaDistX := math.Abs(1 / rayDirX)
-	deltaDistY := math.Abs(1 / rayDirY)
+	//which box of the map we're in, and the size of the map
+	rayPos, rayDir := sim.Vector2{X: rayPosX, Y: rayPosY}, sim.Vector2{X: rayDirX, Y: rayDirY}
+	dda := sim.NewDDA(rayPos, rayDir)
+	mapW, mapH := c.mapObj.GetSize()
 	var perpWallDist float64
 
-	//what direction to step in x or y-direction (either +1 or -1)
-	var stepX int
-	var stepY int
-
-	hit := 0   //was there a wall hit?
-	side := -1 //was a NS or a EW wall hit?
+	hit := 0 //was there a wall hit?
 
-	//calculate step and initial sideDist
-	if rayDirX < 0 {
-		stepX = -1
-		sideDistX = (rayPosX - float64(mapX)) * deltaDistX
-	} else {
-		stepX = 1
-		sideDistX = (float64(mapX) + 1.0 - rayPosX) * deltaDistX
-	}
+	//--sliding doors are hit halfway into their cell, where they are not open--//
+	var doorDist, doorWallX float64
+	doorHit := false
 
-	if rayDirY < 0 {
-		stepY = -1
-		sideDistY = (rayPosY - float64(mapY)) * deltaDistY
-	} else {
-		stepY = 1
-		sideDistY = (float64(mapY) + 1.0 - rayPosY) * deltaDistY
+	if levelNum == 0 {
+		w.markVisible(dda.MapX, dda.MapY)
 	}
 
 	//perform DDA
 	for hit == 0 {
 		//jump to next map square, OR in x-direction, OR in y-direction
-		if sideDistX < sideDistY {
-			sideDistX += deltaDistX
-			mapX += stepX
-			side = 0
-		} else {
-			sideDistY += deltaDistY
-			mapY += stepY
-			side = 1
-		}
+		dda.Step()
 
 		//Check if ray has hit a wall
-		if mapX < 24 && mapY < 24 && mapX > 0 && mapY > 0 {
-			if grid[mapX][mapY] > 0 {
-				hit = 1
+		if dda.MapX >= 0 && dda.MapY >= 0 && dda.MapX < mapW && dda.MapY < mapH {
+			if levelNum == 0 {
+				w.markVisible(dda.MapX, dda.MapY)
+			}
+			if sim.CellAt(grid, dda.MapX, dda.MapY) > 0 {
+				if door := c.mapObj.GetDoor(dda.MapX, dda.MapY); door == nil || levelNum > 0 {
+					hit = 1
+				} else if dist, doorSide, doorX, ok := door.Intersect(rayPosX, rayPosY, rayDirX, rayDirY, dda.Enter, dda.Exit()); ok {
+					hit, dda.Side, doorHit = 1, doorSide, true
+					doorDist, doorWallX = dist, doorX
+				}
 			}
 		} else {
 			//hit grid boundary
 			hit = 2
 
-			//prevent out of range errors, needs to be improved
-			if mapX < 0 {
-				mapX = 0
-			} else if mapX > 23 {
-				mapX = 23
+			//keep the hit on the edge cells of the map
+			if dda.MapX < 0 {
+				dda.MapX = 0
+			} else if dda.MapX >= mapW {
+				dda.MapX = mapW - 1
 			}
 
-			if mapY < 0 {
-				mapY = 0
-			} else if mapY > 23 {
-				mapY = 23
+			if dda.MapY < 0 {
+				dda.MapY = 0
+			} else if dda.MapY >= mapH {
+				dda.MapY = mapH - 1
 			}
 		}
 	}
+	mapX, mapY, side := dda.MapX, dda.MapY, dda.Side
 
-	//Calculate distance of perpendicular ray (oblique distance will give fisheye effect!)
-	if side == 0 {
-		perpWallDist = (float64(mapX) - rayPosX + (1.0-float64(stepX))/2.0) / rayDirX
+	//Calculate distance of perpendicular ray
+	if doorHit {
+		perpWallDist = doorDist
 	} else {
-		perpWallDist = (float64(mapY) - rayPosY + (1.0-float64(stepY))/2.0) / rayDirY
+		perpWallDist = dda.WallDist(rayPos, rayDir)
 	}
 
 	//Calculate height of line to draw on screen
 	lineHeight := int(float64(c.h) / perpWallDist)
 
-	//calculate lowest and highest pixel to fill in current stripe
-	drawStart := (-lineHeight/2 + c.h/2) - lineHeight*levelNum
+	//calculate lowest and highest pixel to fill in current stripe, shifted by the camera height and pitch
+	drawStart := (-lineHeight/2 + c.h/2 + c.shear) - lineHeight*levelNum + int(c.posZ*float64(c.h)/perpWallDist)
 	drawEnd := drawStart + lineHeight
 
 	//--due to modern way of drawing using quads this is removed to avoid glitches at the edges--//
@@ -336,27 +388,21 @@ func (c *Camera) castLevel(x int, grid [][]int, lvl *Level, levelNum int, wg *sy
 	// if drawEnd >= c.h { drawEnd = c.h - 1 }
 
 	//texturing calculations
-	texNum := grid[mapX][mapY] - 1 //1 subtracted from it so that texture 0 can be used
-	if texNum < 0 {
-		texNum = 0 //why?
-	}
-	//--some supid hacks to make the houses render correctly--//
-	// this corrects textures on two sides of house since the textures are not symmetrical
-	if side == 0 {
-		if texNum == 3 {
-			texNum = 4
-		} else if texNum == 4 {
-			texNum = 3
-		}
-
-		if texNum == 1 {
-			texNum = 4
-		} else if texNum == 2 {
-			texNum = 3
+	texNum := sim.WallTexture(sim.CellAt(grid, mapX, mapY), side)
+
+	//--lazy textures start loading the first time they are seen--//
+	c.tex.request(texNum)
+
+	//--far walls use a smaller mip of the texture when there are more texels than pixels--//
+	texture := c.tex.Textures[texNum]
+	texSlices, texWidth := c.s, c.texWidth
+	if c.tex.HasMipmaps() {
+		footprint := float64(c.texWidth) / math.Max(1, float64(lineHeight))
+		if level := mipLevel(footprint); level > 0 {
+			texture, texSlices, texWidth = c.tex.wallMip(texture, level)
 		}
 	}
-
-	c.lvls[levelNum].CurrTex[x] = c.tex.Textures[texNum]
+	c.lvls[levelNum].CurrTex[x] = texture
 
 	//calculate value of wallX
 	var wallX float64 //where exactly the wall was hit
@@ -366,6 +412,10 @@ func (c *Camera) castLevel(x int, grid [][]int, lvl *Level, levelNum int, wg *sy
 		wallX = rayPosX + perpWallDist*rayDirX
 	}
 	wallX -= math.Floor(wallX)
+	if doorHit {
+		// the open part of the door is slid into the frame
+		wallX = doorWallX
+	}
 
 	//x coordinate on the texture
 	texX := int(wallX * float64(c.texWidth))
@@ -378,7 +428,7 @@ func (c *Camera) castLevel(x int, grid [][]int, lvl *Level, levelNum int, wg *sy
 	}
 
 	//--set current texture slice to be slice x--//
-	_cts[x] = c.s[texX]
+	_cts[x] = texSlices[texX*texWidth/c.texWidth]
 
 	//--set height of slice--//
 	_sv[x].Min.Y = drawStart
@@ -409,76 +459,132 @@ func (c *Camera) castLevel(x int, grid [][]int, lvl *Level, levelNum int, wg *sy
 	_st[x].G = byte(Clamp(int(float64(_st[x].G)+shadowDepth+sunLight), 0, 255))
 	_st[x].B = byte(Clamp(int(float64(_st[x].B)+shadowDepth+sunLight), 0, 255))
 
+	//--float shading for the linear light path, ignored in classic shading--//
+	if side == 1 {
+		lvl.Shade[x] = c.sliceShade(255-12, perpWallDist)
+	} else {
+		lvl.Shade[x] = c.sliceShade(255, perpWallDist)
+	}
+
+	//// ROOF CASTING ////
+	//--tops of buildings are visible when the eye is above them--//
+	if hit == 1 && c.posZ+sim.EyeHeight > float64(levelNum+1) && perpWallDist < roofDrawDistance && c.isRoof(levelNum, mapX, mapY) {
+		// the roof continues across neighbouring roof cells the ray passes over
+		roof := dda
+		roofEnd := roof.Exit()
+		for roofEnd < roofDrawDistance {
+			roof.Step()
+			if !c.isRoof(levelNum, roof.MapX, roof.MapY) {
+				break
+			}
+			roofEnd = roof.Exit()
+		}
+
+		c.castRoof(x, levelNum, rayDirX, rayDirY, perpWallDist, roofEnd)
+	}
+
 	//SET THE ZBUFFER FOR THE SPRITE CASTING
+	c.levelDepth[levelNum][x] = perpWallDist
 	if levelNum == 0 {
 		// for now only rendering sprites on first level
 		c.zBuffer[x] = perpWallDist //perpendicular distance is used
 	}
 
+	//// CEILING CASTING ////
+	//--ceilings over the ground level, seen from below--//
+	if levelNum == 0 && c.mapObj.HasCeilings() {
+		c.castCeiling(x, rayDirX, rayDirY, drawStart, perpWallDist)
+	}
+
 	//// FLOOR CASTING ////
 	if levelNum == 0 {
 		// for now only rendering floor on first level
 		if drawEnd < 0 {
 			drawEnd = c.h //becomes < 0 when the integer overflows
 		}
-		wg.Add(1)
-		go func() {
-			defer wg.Done()
-
-			c.semaphore <- struct{}{} // Lock
-			defer func() {
-				<-c.semaphore // Unlock
-			}()
-
-			var floorXWall, floorYWall float64
-
-			//4 different wall directions possible
-			if side == 0 && rayDirX > 0 {
-				floorXWall = float64(mapX)
-				floorYWall = float64(mapY) + wallX
-			} else if side == 0 && rayDirX < 0 {
-				floorXWall = float64(mapX) + 1.0
-				floorYWall = float64(mapY) + wallX
-			} else if side == 1 && rayDirY > 0 {
-				floorXWall = float64(mapX) + wallX
-				floorYWall = float64(mapY)
-			} else {
-				floorXWall = float64(mapX) + wallX
-				floorYWall = float64(mapY) + 1.0
-			}
 
-			var distWall, distPlayer, currentDist float64
+		var floorXWall, floorYWall float64
+
+		//4 different wall directions possible
+		if side == 0 && rayDirX > 0 {
+			floorXWall = float64(mapX)
+			floorYWall = float64(mapY) + wallX
+		} else if side == 0 && rayDirX < 0 {
+			floorXWall = float64(mapX) + 1.0
+			floorYWall = float64(mapY) + wallX
+		} else if side == 1 && rayDirY > 0 {
+			floorXWall = float64(mapX) + wallX
+			floorYWall = float64(mapY)
+		} else {
+			floorXWall = float64(mapX) + wallX
+			floorYWall = float64(mapY) + 1.0
+		}
+		if doorHit {
+			// doors stand halfway into their cell
+			floorXWall = rayPosX + perpWallDist*rayDirX
+			floorYWall = rayPosY + perpWallDist*rayDirY
+		}
 
-			distWall = perpWallDist
-			distPlayer = 0.0
+		var distWall, distPlayer, currentDist float64
 
-			//draw the floor from drawEnd to the bottom of the screen
-			for y := drawEnd + 1; y < c.h; y++ {
-				currentDist = c.camY[y] //float64(c.h) / (2.0*float64(y) - float64(c.h))
+		distWall = perpWallDist
+		distPlayer = 0.0
 
-				weight := (currentDist - distPlayer) / (distWall - distPlayer)
+		// floor rows get further away as the eye rises
+		eyeScale := (c.posZ + sim.EyeHeight) / sim.EyeHeight
 
-				currentFloorX := weight*floorXWall + (1.0-weight)*rayPosX
-				currentFloorY := weight*floorYWall + (1.0-weight)*rayPosY
+		//draw the floor from drawEnd to the bottom of the screen
+		for y := drawEnd + 1; y < c.h; y++ {
+			currentDist = c.camY[y-c.shear+c.h] * eyeScale //float64(c.h) / (2.0*float64(y-c.shear) - float64(c.h))
+			if c.FloorDrawDistance > 0 && currentDist > c.FloorDrawDistance {
+				continue
+			}
 
-				var floorTexX, floorTexY int
-				floorTexX = int(currentFloorX*float64(c.texWidth)) % c.texWidth
-				floorTexY = int(currentFloorY*float64(c.texWidth)) % c.texWidth
+			weight := (currentDist - distPlayer) / (distWall - distPlayer)
 
-				//floor
-				// buffer[y][x] = (texture[3][texWidth * floorTexY + floorTexX] >> 1) & 8355711;
-				// the same vertical slice method cannot be used for floor rendering
-				floorTexNum := 0
-				floorTex := c.horLvl.TexRGBA[floorTexNum]
+			currentFloorX := weight*floorXWall + (1.0-weight)*rayPosX
+			currentFloorY := weight*floorYWall + (1.0-weight)*rayPosY
+
+			//floor
+			// buffer[y][x] = (texture[3][texWidth * floorTexY + floorTexX] >> 1) & 8355711;
+			// the same vertical slice method cannot be used for floor rendering
+			floorTexNum := c.floorTexture(currentFloorX, currentFloorY)
+			floorTex := c.horLvl.TexRGBA[floorTexNum]
+
+			//--distant rows use a smaller mip, one row covers 2*dist^2/h of floor depth--//
+			if c.horLvl.Mips != nil {
+				footprint := 2 * currentDist * currentDist / (float64(c.h) * eyeScale) * float64(c.texWidth)
+				floorTex = c.horLvl.floorMip(floorTexNum, mipLevel(footprint))
+			}
+			floorWidth := floorTex.Rect.Dx()
 
-				//pixel := floorTex.RGBAAt(floorTexX, floorTexY)
+			var floorTexX, floorTexY int
+			floorTexX = int(currentFloorX*float64(floorWidth)) % floorWidth
+			floorTexY = int(currentFloorY*float64(floorWidth)) % floorWidth
+			if floorTexX < 0 {
+				floorTexX += floorWidth //floor seen past the edge of an open map
+			}
+			if floorTexY < 0 {
+				floorTexY += floorWidth
+			}
+
+			//pixel := floorTex.RGBAAt(floorTexX, floorTexY)
+			var pixel color.RGBA
+			if c.FloorFilterDistance > 0 && currentDist > c.FloorFilterDistance {
+				//--distant rows blend the 4 nearest texels so the floor does not shimmer--//
+				pixel = sampleBilinear(floorTex, currentFloorX*float64(floorWidth), currentFloorY*float64(floorWidth))
+			} else {
 				pxOffset := floorTex.PixOffset(floorTexX, floorTexY)
-				pixel := color.RGBA{floorTex.Pix[pxOffset],
+				pixel = color.RGBA{floorTex.Pix[pxOffset],
 					floorTex.Pix[pxOffset+1],
 					floorTex.Pix[pxOffset+2],
 					floorTex.Pix[pxOffset+3]}
+			}
 
-				// lighting
+			// lighting
+			if c.shading == ShadingLinear {
+				pixel = c.shadePixel(pixel, distanceLight(255, currentDist), x, y)
+			} else {
 				shadowDepth = math.Sqrt(currentDist) * lightFalloff
 				pixelSt := &color.RGBA{255, 255, 255, 255}
 				pixelSt.R = byte(Clamp(int(float64(pixelSt.R)+shadowDepth+sunLight), 0, 255))
@@ -487,15 +593,21 @@ func (c *Camera) castLevel(x int, grid [][]int, lvl *Level, levelNum int, wg *sy
 				pixel.R = uint8(float64(pixel.R) * float64(pixelSt.R) / 256)
 				pixel.G = uint8(float64(pixel.G) * float64(pixelSt.G) / 256)
 				pixel.B = uint8(float64(pixel.B) * float64(pixelSt.B) / 256)
-
-				//c.horLvl.HorBuffer.SetRGBA(x, y, pixel)
-				pxOffset = c.horLvl.HorBuffer.PixOffset(x, y)
-				c.horLvl.HorBuffer.Pix[pxOffset] = pixel.R
-				c.horLvl.HorBuffer.Pix[pxOffset+1] = pixel.G
-				c.horLvl.HorBuffer.Pix[pxOffset+2] = pixel.B
-				c.horLvl.HorBuffer.Pix[pxOffset+3] = pixel.A
 			}
-		}()
+
+			// light shafts and lit tiles
+			pixel = applyFloorLight(pixel, c.mapObj.FloorLight(currentFloorX, currentFloorY))
+
+			//c.horLvl.HorBuffer.SetRGBA(x, y, pixel)
+			pxOffset := c.horLvl.HorBuffer.PixOffset(x, y)
+			c.horLvl.HorBuffer.Pix[pxOffset] = pixel.R
+			c.horLvl.HorBuffer.Pix[pxOffset+1] = pixel.G
+			c.horLvl.HorBuffer.Pix[pxOffset+2] = pixel.B
+			c.horLvl.HorBuffer.Pix[pxOffset+3] = pixel.A
+		}
+
+		// darken the seam where the wall meets the floor
+		c.castOcclusion(x, drawEnd, lineHeight)
 	}
 }
 
@@ -510,7 +622,12 @@ func (c *Camera) castSprite(spriteOrdIndex int) {
 	spriteX := c.sprite[c.spriteOrder[spriteOrdIndex]].X - rayPosX
 	spriteY := c.sprite[c.spriteOrder[spriteOrdIndex]].Y - rayPosY
 
-	spriteTex := c.sprite[c.spriteOrder[spriteOrdIndex]].GetTexture()
+	spriteTex, _ := c.sprite[c.spriteOrder[spriteOrdIndex]].GetTextureFrom(c.pos).(*ebiten.Image)
+	if spriteTex == nil {
+		// sprites of maps loaded without textures have nothing to draw
+		c.clearSpriteLevel(spriteOrdIndex)
+		return
+	}
 	spriteW, spriteH := spriteTex.Size()
 
 	//transform sprite with the inverse camera matrix
@@ -518,18 +635,18 @@ func (c *Camera) castSprite(spriteOrdIndex int) {
 	// [               ]       =  1/(planeX*dirY-dirX*planeY) *   [                 ]
 	// [ planeY   dirY ]                                          [ -planeY  planeX ]
 
-	invDet := 1.0 / (c.plane.X*c.dir.Y - c.dir.X*c.plane.Y) //required for correct matrix multiplication
+	invDet := 1.0 / (c.viewPlane.X*c.dir.Y - c.dir.X*c.viewPlane.Y) //required for correct matrix multiplication
 
 	transformX := invDet * (c.dir.Y*spriteX - c.dir.X*spriteY)
-	transformY := invDet * (-c.plane.Y*spriteX + c.plane.X*spriteY) //this is actually the depth inside the screen, that what Z is in 3D
+	transformY := invDet * (-c.viewPlane.Y*spriteX + c.viewPlane.X*spriteY) //this is actually the depth inside the screen, that what Z is in 3D
 
 	spriteScreenX := int(float64(c.w) / 2 * (1 + transformX/transformY))
 
 	//parameters for scaling and moving the sprites
 	var uDiv = 1
 	var vDiv = 1
-	var vMove = 0.0
-	vMoveScreen := int(vMove / transformY)
+	var vMove = c.posZ * float64(c.h)
+	vMoveScreen := int(vMove/transformY) + c.shear
 
 	//calculate height of the sprite on screen
 	spriteHeight := int(math.Abs(float64(c.h)/transformY) / float64(vDiv)) //using "transformY" instead of the real distance prevents fisheye
@@ -548,11 +665,12 @@ func (c *Camera) castSprite(spriteOrdIndex int) {
 	drawStartX := -spriteWidth/2 + spriteScreenX
 	drawEndX := spriteWidth/2 + spriteScreenX
 
+	//--stripes run from drawStartX up to but not including drawEndX, so both screen edges are drawn--//
 	if drawStartX < 0 {
 		drawStartX = 0
 	}
-	if drawEndX >= c.w {
-		drawEndX = c.w - 1
+	if drawEndX > c.w {
+		drawEndX = c.w
 	}
 
 	var spriteSlices []*image.Rectangle
@@ -571,7 +689,13 @@ func (c *Camera) castSprite(spriteOrdIndex int) {
 		//2) it's on the screen (left)
 		//3) it's on the screen (right)
 		//4) ZBuffer, with perpendicular distance
-		if transformY > 0 && stripe > 0 && stripe < c.w && transformY < c.zBuffer[stripe] {
+		//5) not hidden behind upper level walls
+		if transformY > 0 && stripe >= 0 && stripe < c.w && transformY < c.zBuffer[stripe] {
+			top, bottom, visible := c.clipSpriteStripe(stripe, transformY, drawStartY, drawEndY)
+			if !visible {
+				continue
+			}
+
 			var spriteLvl *Level
 			if !renderSprite {
 				renderSprite = true
@@ -581,20 +705,21 @@ func (c *Camera) castSprite(spriteOrdIndex int) {
 				spriteLvl = c.spriteLvls[spriteOrdIndex]
 			}
 
-			texX := int(256*(stripe-(-spriteWidth/2+spriteScreenX))*c.texWidth/spriteWidth) / 256
+			//--texture column from the stripe offset into the full (unclipped) sprite width, in sprite texture pixels--//
+			texX := int(256*(stripe-(-spriteWidth/2+spriteScreenX))*spriteW/spriteWidth) / 256
 
 			if texX < 0 || texX >= cap(spriteSlices) {
 				continue
 			}
 
 			// modify tex startY and endY based on distance
-			d := (drawStartY-vMoveScreen)*256 - c.h*128 + spriteHeight*128 //256 and 128 factors to avoid floats
-			texStartY := ((d * c.texWidth) / spriteHeight) / 256
+			d := (top-vMoveScreen)*256 - c.h*128 + spriteHeight*128 //256 and 128 factors to avoid floats
+			texStartY := ((d * spriteH) / spriteHeight) / 256
 
-			d = (drawEndY-1-vMoveScreen)*256 - c.h*128 + spriteHeight*128
-			texEndY := ((d * c.texWidth) / spriteHeight) / 256
+			d = (bottom-1-vMoveScreen)*256 - c.h*128 + spriteHeight*128
+			texEndY := ((d * spriteH) / spriteHeight) / 256
 
-			if texStartY < 0 || texStartY >= texEndY || texEndY >= c.texWidth {
+			if texStartY < 0 || texStartY >= texEndY || texEndY >= spriteH {
 				continue
 			}
 
@@ -606,10 +731,10 @@ func (c *Camera) castSprite(spriteOrdIndex int) {
 			spriteLvl.CurrTex[stripe] = spriteTex
 
 			//--set height of slice--//
-			spriteLvl.Sv[stripe].Min.Y = drawStartY + 1
+			spriteLvl.Sv[stripe].Min.Y = top + 1
 
 			//--set draw start of slice--//
-			spriteLvl.Sv[stripe].Max.Y = drawEndY
+			spriteLvl.Sv[stripe].Max.Y = bottom
 
 			// distance based lighting/shading
 			spriteLvl.St[stripe] = &color.RGBA{255, 255, 255, 255}
@@ -620,6 +745,7 @@ func (c *Camera) castSprite(spriteOrdIndex int) {
 			spriteLvl.St[stripe].R = byte(Clamp(int(float64(spriteLvl.St[stripe].R)+shadowDepth+sunLight), 0, 255))
 			spriteLvl.St[stripe].G = byte(Clamp(int(float64(spriteLvl.St[stripe].G)+shadowDepth+sunLight), 0, 255))
 			spriteLvl.St[stripe].B = byte(Clamp(int(float64(spriteLvl.St[stripe].B)+shadowDepth+sunLight), 0, 255))
+			spriteLvl.Shade[stripe] = c.sliceShade(255, transformY)
 		}
 	}
 
@@ -634,6 +760,8 @@ func (c *Camera) makeSpriteLevel(spriteOrdIndex int) *Level {
 	spriteLvl.Cts = make([]*image.Rectangle, c.w)
 	spriteLvl.St = make([]*color.RGBA, c.w)
 	spriteLvl.CurrTex = make([]*ebiten.Image, c.w)
+	spriteLvl.Shade = make([]float64, c.w)
+	spriteLvl.DrawHook = c.tex.GetSpriteDrawHook(c.sprite[c.spriteOrder[spriteOrdIndex]])
 
 	c.spriteLvls[spriteOrdIndex] = spriteLvl
 
@@ -671,48 +799,6 @@ func combSort(order []int, dist []float64, amount int) {
 	}
 }
 
-// normalize speed based on a constant input rate
-func (c *Camera) getNormalSpeed(speed float64) float64 {
-	return speed * movementTPS / float64(c.targetTPS)
-}
-
-// Move camera by move speed
-func (c *Camera) Move(mSpeed float64) {
-	mSpeed = c.getNormalSpeed(mSpeed)
-
-	if c.worldMap[int(c.pos.X+c.dir.X*mSpeed*12)][int(c.pos.Y)] <= 0 {
-		c.pos.X += (c.dir.X * mSpeed)
-	}
-	if c.worldMap[int(c.pos.X)][int(c.pos.Y+c.dir.Y*mSpeed*12)] <= 0 {
-		c.pos.Y += (c.dir.Y * mSpeed)
-	}
-}
-
-// Strafe camera by strafe speed
-func (c *Camera) Strafe(sSpeed float64) {
-	sSpeed = c.getNormalSpeed(sSpeed)
-
-	if c.worldMap[int(c.pos.X+c.plane.X*sSpeed*12)][int(c.pos.Y)] <= 0 {
-		c.pos.X += (c.plane.X * sSpeed)
-	}
-	if c.worldMap[int(c.pos.X)][int(c.pos.Y+c.plane.Y*sSpeed*12)] <= 0 {
-		c.pos.Y += (c.plane.Y * sSpeed)
-	}
-}
-
-// Rotate camera by rotate speed
-func (c *Camera) Rotate(rSpeed float64) {
-	rSpeed = c.getNormalSpeed(rSpeed)
-
-	//both camera direction and camera plane must be rotated
-	oldDirX := c.dir.X
-	c.dir.X = (c.dir.X*math.Cos(rSpeed) - c.dir.Y*math.Sin(rSpeed))
-	c.dir.Y = (oldDirX*math.Sin(rSpeed) + c.dir.Y*math.Cos(rSpeed))
-	oldPlaneX := c.plane.X
-	c.plane.X = (c.plane.X*math.Cos(rSpeed) - c.plane.Y*math.Sin(rSpeed))
-	c.plane.Y = (oldPlaneX*math.Sin(rSpeed) + c.plane.Y*math.Cos(rSpeed))
-}
-
 // Clamp - converted C# method MathHelper.Clamp
 // Restricts a value to be within a specified range.
 func Clamp(value int, min int, max int) int {
@@ -0,0 +1,37 @@
+package raycaster
+
+import "raycaster-go/engine/sim"
+
+// CameraOption configures a camera as NewCamera makes it
+type CameraOption func(c *Camera)
+
+// WithTargetTPS sets the tick rate movement speeds are normalized against, see sim.WithTargetTPS
+func WithTargetTPS(tps int) CameraOption {
+	return withSim(sim.WithTargetTPS(tps))
+}
+
+// WithStartPosition places the camera at a grid position instead of the default start, see
+// sim.WithStartPosition
+func WithStartPosition(pos sim.Vector2) CameraOption {
+	return withSim(sim.WithStartPosition(pos))
+}
+
+// WithFOV sets the base horizontal field of view in degrees, sim.DefaultFOV by default
+func WithFOV(degrees float64) CameraOption {
+	return withSim(sim.WithFOV(degrees))
+}
+
+// WithConcurrency sets the number of goroutines the column passes are split over, castWorkers by default.
+// See SetWorkers.
+func WithConcurrency(workers int) CameraOption {
+	return func(c *Camera) {
+		c.SetWorkers(workers)
+	}
+}
+
+// withSim applies an option of the simulated camera
+func withSim(opt sim.CameraOption) CameraOption {
+	return func(c *Camera) {
+		opt(c.Camera)
+	}
+}
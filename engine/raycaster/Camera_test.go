@@ -0,0 +1,162 @@
+package raycaster
+
+import (
+	"fmt"
+	"image"
+	"math"
+	"testing"
+
+	"raycaster-go/engine/sim"
+
+	"github.com/hajimehoshi/ebiten"
+)
+
+const (
+	testWidth    = 64
+	testHeight   = 48
+	testTexWidth = 16
+)
+
+// newTestCamera creates a camera rendering the map with plain textures into small buffers
+func newTestCamera(t *testing.T, m *sim.Map, numLevels int, opts ...CameraOption) *Camera {
+	t.Helper()
+	img, err := ebiten.NewImage(testTexWidth, testTexWidth, ebiten.FilterNearest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tex := NewTextureHandler(testTexWidth)
+	tex.Textures = []*ebiten.Image{img, img, img, img, img, img}
+	floor := []*image.RGBA{image.NewRGBA(image.Rect(0, 0, testTexWidth, testTexWidth))}
+
+	return NewCamera(testWidth, testHeight, testTexWidth, m, tex.GetSlices(), NewLevels(testWidth, testHeight, numLevels),
+		NewHorLevel(testWidth, testHeight, floor), NewSpriteLevels(m.GetNumSprites()), tex, opts...)
+}
+
+// newWalledMap creates a square map with a wall all around its edge
+func newWalledMap(size int) *sim.Map {
+	m := sim.NewEmptyMap(nil, size, size)
+	for i := 0; i < size; i++ {
+		m.SetTile(i, 0, 0, 1)
+		m.SetTile(i, size-1, 0, 1)
+		m.SetTile(0, i, 0, 1)
+		m.SetTile(size-1, i, 0, 1)
+	}
+	return m
+}
+
+func TestRaycastBounds(t *testing.T) {
+	tests := []struct {
+		name      string
+		m         *sim.Map
+		numLevels int
+		pos       sim.Vector2
+		walls     bool
+	}{
+		{name: "no sprites", m: newWalledMap(8), numLevels: 2, pos: sim.Vector2{X: 4.5, Y: 4.5}, walls: true},
+		{name: "zero size map", m: sim.NewEmptyMap(nil, 0, 0), numLevels: 2, pos: sim.Vector2{X: 0.5, Y: 0.5}},
+		{name: "no levels", m: sim.NewMapFromLevels(nil), numLevels: 2, pos: sim.Vector2{X: 1.5, Y: 1.5}},
+		{name: "single level", m: sim.NewMapFromLevels(nil, newWalledMap(8).LevelGrid(0)), numLevels: 3,
+			pos: sim.Vector2{X: 4.5, Y: 4.5}, walls: true},
+		{name: "empty grid", m: sim.NewEmptyMap(nil, 8, 8), numLevels: 2, pos: sim.Vector2{X: 4.5, Y: 4.5}},
+		{name: "left of the map", m: newWalledMap(8), numLevels: 2, pos: sim.Vector2{X: -3.5, Y: 4.5}},
+		{name: "below the map", m: newWalledMap(8), numLevels: 2, pos: sim.Vector2{X: 4.5, Y: 20.5}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newTestCamera(t, tt.m, tt.numLevels, WithStartPosition(tt.pos))
+			c.Update()
+
+			for i, lvl := range c.GetSpriteLevels() {
+				if lvl != nil {
+					t.Errorf("sprite level %d drawn without sprites", i)
+				}
+			}
+
+			drawn := 0
+			for x := 0; x < testWidth; x++ {
+				if c.lvls[0].CurrTex[x] != nil {
+					drawn++
+				}
+			}
+			if tt.walls && drawn != testWidth {
+				t.Errorf("walls drawn in %d of %d columns", drawn, testWidth)
+			}
+		})
+	}
+}
+
+func TestRaycastAfterSpritesRemoved(t *testing.T) {
+	img, err := ebiten.NewImage(testTexWidth, testTexWidth, ebiten.FilterNearest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := newWalledMap(8)
+	sprites := []*sim.Sprite{sim.NewSprite(3.5, 4.5, img), sim.NewSprite(3.5, 3.5, img)}
+	for _, s := range sprites {
+		m.AddSprite(s)
+	}
+
+	c := newTestCamera(t, m, 2, WithStartPosition(sim.Vector2{X: 6.5, Y: 4}))
+	c.Update()
+	if c.GetSpriteLevel(sprites[0]) == nil {
+		t.Fatal("sprite in front of the camera not drawn")
+	}
+
+	for _, s := range sprites {
+		m.RemoveSprite(s)
+	}
+	c.UpdateSprites()
+	c.Update()
+	for i, lvl := range c.GetSpriteLevels() {
+		if lvl != nil {
+			t.Errorf("sprite level %d still drawn after its sprite was removed", i)
+		}
+	}
+}
+
+func TestCastSpriteScreenEdges(t *testing.T) {
+	img, err := ebiten.NewImage(testTexWidth, testTexWidth, ebiten.FilterNearest)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, fov := range []float64{30, 66, 90, 120, 160} {
+		for _, edge := range []struct {
+			name   string
+			side   float64
+			column int
+		}{
+			{name: "left", side: -1, column: 0},
+			{name: "right", side: 1, column: testWidth - 1},
+		} {
+			t.Run(fmt.Sprintf("%s edge at %.0f degrees", edge.name, fov), func(t *testing.T) {
+				m := newWalledMap(40)
+				s := sim.NewSprite(0, 0, img)
+				m.AddSprite(s)
+				c := newTestCamera(t, m, 2, WithStartPosition(sim.Vector2{X: 20.5, Y: 20.5}), WithFOV(fov))
+
+				//--centered on the edge of the view two cells deep, so half the sprite is on screen at any FOV--//
+				pos, dir, plane := c.GetPosition(), c.GetDirection(), c.GetViewPlane()
+				depth := 2 / math.Hypot(dir.X, dir.Y)
+				s.X = pos.X + (dir.X+edge.side*plane.X)*depth
+				s.Y = pos.Y + (dir.Y+edge.side*plane.Y)*depth
+				c.UpdateSprites()
+				c.Update()
+
+				lvl := c.GetSpriteLevel(s)
+				if lvl == nil {
+					t.Fatal("sprite on the edge of the view not drawn")
+				}
+				if len(lvl.Cts) != testWidth || len(lvl.CurrTex) != testWidth {
+					t.Fatalf("sprite level has %d columns, want %d", len(lvl.Cts), testWidth)
+				}
+				if lvl.CurrTex[edge.column] == nil || lvl.Cts[edge.column] == nil {
+					t.Errorf("edge column %d not drawn", edge.column)
+				}
+				if other := testWidth - 1 - edge.column; lvl.CurrTex[other] != nil {
+					t.Errorf("opposite edge column %d drawn", other)
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package raycaster
+
+import (
+	"image/color"
+	"math"
+	"raycaster-go/engine/sim"
+)
+
+// castCeiling textures the rows above the top of the ground level wall in screen column x with the ceiling
+// of the cells the ray passes under, leaving open sky cells clear so the sky shows through
+func (c *Camera) castCeiling(x int, rayDirX, rayDirY float64, drawStart int, perpWallDist float64) {
+	//--height of the ceiling over the eye, in screen rows at distance 1 (negative)--//
+	above := (c.posZ + sim.EyeHeight - 1) * float64(c.h)
+	if above >= 0 {
+		return
+	}
+	half := float64(c.GetHorizon())
+	yEnd := Clamp(int(math.Min(float64(drawStart), half)), 0, c.h)
+
+	for y := 0; y < yEnd; y++ {
+		dist := above / (float64(y) + 0.5 - half)
+		if dist > perpWallDist {
+			continue
+		}
+
+		ceilX := c.pos.X + rayDirX*dist
+		ceilY := c.pos.Y + rayDirY*dist
+		texNum := c.mapObj.GetCeiling(int(ceilX), int(ceilY)) - 1
+		if texNum < 0 || texNum >= len(c.horLvl.TexRGBA) || c.horLvl.TexRGBA[texNum] == nil {
+			continue
+		}
+
+		//--distant rows use a smaller mip, like the floor--//
+		tex := c.horLvl.TexRGBA[texNum]
+		if c.horLvl.Mips != nil {
+			footprint := dist * dist / -above * float64(c.texWidth)
+			tex = c.horLvl.floorMip(texNum, mipLevel(footprint))
+		}
+		texWidth := tex.Rect.Dx()
+
+		texX := int((ceilX-math.Floor(ceilX))*float64(texWidth)) % texWidth
+		texY := int((ceilY-math.Floor(ceilY))*float64(texWidth)) % texWidth
+
+		pxOffset := tex.PixOffset(texX, texY)
+		pixel := color.RGBA{tex.Pix[pxOffset], tex.Pix[pxOffset+1], tex.Pix[pxOffset+2], 255}
+
+		pixel = c.shadePixel(pixel, distanceLight(255, dist), x, y)
+
+		pxOffset = c.horLvl.HorBuffer.PixOffset(x, y)
+		c.horLvl.HorBuffer.Pix[pxOffset] = pixel.R
+		c.horLvl.HorBuffer.Pix[pxOffset+1] = pixel.G
+		c.horLvl.HorBuffer.Pix[pxOffset+2] = pixel.B
+		c.horLvl.HorBuffer.Pix[pxOffset+3] = pixel.A
+	}
+}
@@ -0,0 +1,83 @@
+package raycaster
+
+import (
+	"image"
+	"image/color"
+	"path/filepath"
+
+	"github.com/hajimehoshi/ebiten"
+	"github.com/hajimehoshi/ebiten/ebitenutil"
+)
+
+// ColorKeyMagenta is the transparency color used by most legacy assets
+var ColorKeyMagenta = color.RGBA{255, 0, 255, 255}
+
+// SetColorKey makes pixels of the key color transparent in the named image file (e.g. "tree_09.png")
+// when it is loaded, in place of the global ColorKey
+func (t *TextureHandler) SetColorKey(name string, key color.RGBA) {
+	if t.colorKeys == nil {
+		t.colorKeys = make(map[string]color.RGBA)
+	}
+	t.colorKeys[name] = key
+}
+
+// ClearColorKey removes the color key of the named image file so it uses the global ColorKey again
+func (t *TextureHandler) ClearColorKey(name string) {
+	delete(t.colorKeys, name)
+}
+
+// colorKey returns the key color for the named image file, false if it has none
+func (t *TextureHandler) colorKey(name string) (color.RGBA, bool) {
+	if key, ok := t.colorKeys[name]; ok {
+		return key, true
+	}
+	if t.ColorKey != nil {
+		return *t.ColorKey, true
+	}
+	return color.RGBA{}, false
+}
+
+// ApplyColorKey returns a copy of the image with pixels matching the color key of the named image file
+// turned transparent, or the image itself if the file has no color key
+func (t *TextureHandler) ApplyColorKey(name string, img image.Image) image.Image {
+	key, ok := t.colorKey(name)
+	if !ok {
+		return img
+	}
+
+	tol := int(t.ColorKeyTolerance)
+	near := func(a, b uint8) bool {
+		d := int(a) - int(b)
+		return d <= tol && d >= -tol
+	}
+
+	b := img.Bounds()
+	keyed := image.NewRGBA(b)
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			c := color.RGBAModel.Convert(img.At(x, y)).(color.RGBA)
+			if near(c.R, key.R) && near(c.G, key.G) && near(c.B, key.B) {
+				c = color.RGBA{}
+			}
+			keyed.SetRGBA(x, y, c)
+		}
+	}
+	return keyed
+}
+
+// LoadImage loads an image file as a texture, applying the color key of the file if it has one.
+// Returns the texture along with the decoded (and keyed) image.
+func (t *TextureHandler) LoadImage(path string) (*ebiten.Image, image.Image, error) {
+	eImg, img, err := ebitenutil.NewImageFromFile(path, ebiten.FilterNearest)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if _, ok := t.colorKey(filepath.Base(path)); !ok {
+		return eImg, img, nil
+	}
+
+	img = t.ApplyColorKey(filepath.Base(path), img)
+	eImg, err = ebiten.NewImageFromImage(img, ebiten.FilterNearest)
+	return eImg, img, err
+}
@@ -0,0 +1,34 @@
+package raycaster
+
+// clipSpriteStripe clips the screen rows top to bottom of a sprite stripe at the given depth against the walls
+// of the upper levels in front of it, which the level 0 zbuffer knows nothing about. Returns the rows left
+// showing, false if the stripe is hidden. A wall covering only the middle of the stripe leaves the larger
+// of the parts above and below it, since a stripe is drawn as a single slice.
+func (c *Camera) clipSpriteStripe(x int, depth float64, top, bottom int) (int, int, bool) {
+	for levelNum := 1; levelNum < len(c.levelDepth); levelNum++ {
+		lvl := c.lvls[levelNum]
+		if lvl.CurrTex[x] == nil || c.levelDepth[levelNum][x] >= depth {
+			continue
+		}
+
+		wallTop, wallBottom := lvl.Sv[x].Min.Y, lvl.Sv[x].Max.Y
+		if wallBottom <= top || wallTop >= bottom {
+			// no overlap
+			continue
+		}
+
+		switch {
+		case wallTop <= top && wallBottom >= bottom:
+			return top, bottom, false
+		case wallTop <= top:
+			top = wallBottom
+		case wallBottom >= bottom:
+			bottom = wallTop
+		case wallTop-top >= bottom-wallBottom:
+			bottom = wallTop
+		default:
+			top = wallBottom
+		}
+	}
+	return top, bottom, top < bottom
+}
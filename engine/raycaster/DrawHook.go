@@ -0,0 +1,47 @@
+package raycaster
+
+import (
+	"raycaster-go/engine/sim"
+
+	"github.com/hajimehoshi/ebiten"
+)
+
+// DrawHook is called as each screen column x of a wall texture or sprite is drawn, and may change the
+// draw options (color matrix, geometry, composite mode) for effects such as a cloak shimmer or a frozen tint.
+// The options already hold the slice placement and distance shading.
+type DrawHook func(op *ebiten.DrawImageOptions, x int)
+
+// SetDrawHook sets the hook applied whenever the texture is drawn, nil removes it
+func (t *TextureHandler) SetDrawHook(tex *ebiten.Image, hook DrawHook) {
+	if t.drawHooks == nil {
+		t.drawHooks = make(map[*ebiten.Image]DrawHook)
+	}
+	if hook == nil {
+		delete(t.drawHooks, tex)
+		return
+	}
+	t.drawHooks[tex] = hook
+}
+
+// GetDrawHook returns the hook applied whenever the texture is drawn, or nil
+func (t *TextureHandler) GetDrawHook(tex *ebiten.Image) DrawHook {
+	return t.drawHooks[tex]
+}
+
+// SetSpriteDrawHook sets a custom draw effect for one sprite only, applied over any hook of its texture,
+// nil removes it
+func (t *TextureHandler) SetSpriteDrawHook(s *sim.Sprite, hook DrawHook) {
+	if t.spriteHooks == nil {
+		t.spriteHooks = make(map[*sim.Sprite]DrawHook)
+	}
+	if hook == nil {
+		delete(t.spriteHooks, s)
+		return
+	}
+	t.spriteHooks[s] = hook
+}
+
+// GetSpriteDrawHook returns the hook applied to a sprite over the hook of its texture, or nil
+func (t *TextureHandler) GetSpriteDrawHook(s *sim.Sprite) DrawHook {
+	return t.spriteHooks[s]
+}
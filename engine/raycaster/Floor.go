@@ -0,0 +1,11 @@
+package raycaster
+
+// floorTexture returns the floor texture index of the cell a floor sample point is in, falling back to the
+// first texture for indexes with no texture loaded
+func (c *Camera) floorTexture(x, y float64) int {
+	texNum := c.mapObj.GetFloorTexture(int(x), int(y))
+	if texNum < 0 || texNum >= len(c.horLvl.TexRGBA) || c.horLvl.TexRGBA[texNum] == nil {
+		return 0
+	}
+	return texNum
+}
@@ -0,0 +1,183 @@
+package raycaster
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"os"
+	"path/filepath"
+	"raycaster-go/engine/sim"
+	"sync/atomic"
+
+	// decoders for lazily loaded image files
+	_ "image/jpeg"
+	_ "image/png"
+
+	"github.com/hajimehoshi/ebiten"
+)
+
+const (
+	lazyUnloaded int32 = iota
+	lazyLoading
+	lazyLoaded
+)
+
+// lazyTexture is an image file registered as a wall texture that is decoded on first use
+type lazyTexture struct {
+	path     string
+	state    int32
+	lastUsed int64
+}
+
+// decodedTexture is a lazy texture decoded in the background, waiting to be swapped in by Update
+type decodedTexture struct {
+	texNum int
+	path   string
+	img    image.Image
+	err    error
+}
+
+// LoadLazy registers an image file as wall texture texNum without reading it. A shared placeholder is
+// drawn in its place until the first time a wall with the texture is cast, which starts decoding the file
+// in the background, and the next Update after that swaps the texture in.
+// Sprites keep the texture they were created with, so only use this for wall textures.
+func (t *TextureHandler) LoadLazy(texNum int, path string) {
+	for len(t.Textures) <= texNum {
+		t.Textures = append(t.Textures, nil)
+	}
+	for len(t.lazy) <= texNum {
+		t.lazy = append(t.lazy, nil)
+	}
+	if t.lazyReady == nil {
+		t.lazyReady = make(chan decodedTexture, 64)
+	}
+
+	t.Textures[texNum] = t.placeholder()
+	t.lazy[texNum] = &lazyTexture{path: path}
+}
+
+// Preload starts decoding every lazy texture that has not been used yet in the background
+func (t *TextureHandler) Preload() {
+	for texNum := range t.lazy {
+		t.request(texNum)
+	}
+}
+
+// PendingTextures returns the number of lazy textures not swapped in yet
+func (t *TextureHandler) PendingTextures() int {
+	pending := 0
+	for _, l := range t.lazy {
+		if l != nil && atomic.LoadInt32(&l.state) != lazyLoaded {
+			pending++
+		}
+	}
+	return pending
+}
+
+// Update swaps lazy textures decoded since the last call in for their placeholders and unloads textures
+// when over the memory budget, called once per tick from the game loop while no camera is casting.
+// With a scheduler set the textures are handed to it instead, one slice each.
+func (t *TextureHandler) Update() {
+	t.frame++
+	defer t.evict()
+
+	for {
+		select {
+		case d := <-t.lazyReady:
+			if t.scheduler == nil {
+				t.swapIn(d)
+				continue
+			}
+			t.scheduler.Schedule("texture "+d.path, sim.PriorityNormal, func() bool {
+				t.swapIn(d)
+				return true
+			})
+		default:
+			return
+		}
+	}
+}
+
+// SetScheduler spreads swapping in decoded lazy textures over the frame time the scheduler has left,
+// instead of uploading all textures decoded since the last tick at once. Nil swaps them in Update.
+func (t *TextureHandler) SetScheduler(s *sim.Scheduler) {
+	t.scheduler = s
+}
+
+// swapIn uploads a decoded lazy texture in place of its placeholder
+func (t *TextureHandler) swapIn(d decodedTexture) {
+	l := t.lazy[d.texNum]
+	if l == nil {
+		// replaced by a theme while decoding
+		return
+	}
+	atomic.StoreInt32(&l.state, lazyLoaded)
+	if d.err != nil {
+		fmt.Printf("Unable to load texture %s: %v\n", d.path, d.err)
+		return
+	}
+
+	tex, _ := ebiten.NewImageFromImage(d.img, ebiten.FilterNearest)
+	t.Textures[d.texNum] = tex
+	if t.mipLevels > 0 {
+		t.mips[tex] = mipChain(tex, t.mipLevels)
+	}
+}
+
+// request starts decoding a lazy texture in the background the first time it is asked for,
+// safe to call from cast workers
+func (t *TextureHandler) request(texNum int) {
+	if texNum < 0 || texNum >= len(t.lazy) {
+		return
+	}
+	l := t.lazy[texNum]
+	if l == nil {
+		return
+	}
+	l.markUsed(t.frame)
+	if !atomic.CompareAndSwapInt32(&l.state, lazyUnloaded, lazyLoading) {
+		return
+	}
+
+	go func() {
+		img, err := decodeImageFile(l.path)
+		if err == nil {
+			img = t.ApplyColorKey(filepath.Base(l.path), img)
+		}
+		t.lazyReady <- decodedTexture{texNum: texNum, path: l.path, img: img, err: err}
+	}()
+}
+
+// decodeImageFile reads and decodes an image file
+func decodeImageFile(path string) (image.Image, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	img, _, err := image.Decode(f)
+	return img, err
+}
+
+// placeholder returns the checkered texture drawn in place of lazy textures that are not loaded yet
+func (t *TextureHandler) placeholder() *ebiten.Image {
+	if t.placeholderTex != nil {
+		return t.placeholderTex
+	}
+
+	size := len(t.slices)
+	img := image.NewRGBA(image.Rect(0, 0, size, size))
+	check := maxInt(1, size/8)
+	for x := 0; x < size; x++ {
+		for y := 0; y < size; y++ {
+			clr := color.RGBA{90, 90, 90, 255}
+			if (x/check+y/check)%2 == 1 {
+				clr = color.RGBA{60, 60, 60, 255}
+			}
+			img.SetRGBA(x, y, clr)
+		}
+	}
+	t.placeholderTex, _ = ebiten.NewImageFromImage(img, ebiten.FilterNearest)
+	return t.placeholderTex
+}
@@ -20,6 +20,12 @@ type Level struct {
 
 	// CurrTex --the texture to use as source
 	CurrTex []*ebiten.Image
+
+	// Shade --color scale of the slice in linear shading, used instead of the St tint when above 0--//
+	Shade []float64
+
+	// DrawHook --custom draw effect for every slice of the level, used by sprites--//
+	DrawHook DrawHook
 }
 
 // SliceView Creates rectangle slices for each x in width.
@@ -35,6 +41,37 @@ func SliceView(width, height int) []*image.Rectangle {
 	return arr
 }
 
+// NewLevels returns initialised Level structs for each wall level of a view
+func NewLevels(width, height, numLevels int) []*Level {
+	var levelArr []*Level
+	levelArr = make([]*Level, numLevels)
+
+	for i := 0; i < numLevels; i++ {
+		levelArr[i] = new(Level)
+		levelArr[i].Sv = SliceView(width, height)
+		levelArr[i].Cts = make([]*image.Rectangle, width)
+		levelArr[i].St = make([]*color.RGBA, width)
+		levelArr[i].CurrTex = make([]*ebiten.Image, width)
+		levelArr[i].Shade = make([]float64, width)
+	}
+
+	return levelArr
+}
+
+// NewSpriteLevels returns an empty "level" for each sprite, filled in by the camera as sprites render
+// using similar slice methods as walls
+func NewSpriteLevels(numSprites int) []*Level {
+	return make([]*Level, numSprites)
+}
+
+// NewHorLevel returns a cleared HorLevel using the given textures as floor casting sources
+func NewHorLevel(width, height int, texRGBA []*image.RGBA) *HorLevel {
+	h := new(HorLevel)
+	h.TexRGBA = texRGBA
+	h.Clear(width, height)
+	return h
+}
+
 // HorLevel is for handling horizontal renders that cannot use vertical slices (e.g. floor, ceiling)
 type HorLevel struct {
 	// HorBuffer is the image representing the pixels to render during the update
@@ -42,6 +79,9 @@ type HorLevel struct {
 
 	// TexRGBA contains image.RGBA textures used as sources for the HorBuffer
 	TexRGBA []*image.RGBA
+
+	// Mips --halved copies of each TexRGBA texture for distant floor rows, nil to always use full size--//
+	Mips [][]*image.RGBA
 }
 
 func (h *HorLevel) Clear(width, height int) {
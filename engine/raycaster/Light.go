@@ -0,0 +1,24 @@
+package raycaster
+
+import "image/color"
+
+const (
+	// how far a fully lit floor pixel is blended towards the light color
+	shaftStrength = 0.6
+)
+
+// shaftColor is the color light shafts blend the floor towards
+var shaftColor = color.RGBA{255, 244, 214, 255}
+
+// applyFloorLight blends a floor pixel towards the light shaft color by the light amount
+func applyFloorLight(pixel color.RGBA, light float64) color.RGBA {
+	if light <= 0 {
+		return pixel
+	}
+
+	blend := light * shaftStrength
+	pixel.R = uint8(float64(pixel.R) + (float64(shaftColor.R)-float64(pixel.R))*blend)
+	pixel.G = uint8(float64(pixel.G) + (float64(shaftColor.G)-float64(pixel.G))*blend)
+	pixel.B = uint8(float64(pixel.B) + (float64(shaftColor.B)-float64(pixel.B))*blend)
+	return pixel
+}
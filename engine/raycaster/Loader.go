@@ -0,0 +1,35 @@
+package raycaster
+
+import (
+	"raycaster-go/engine/sim"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// how often the texture loader checks on textures decoding in the background
+	texturePollInterval = 10 * time.Millisecond
+)
+
+// Load starts decoding every lazy texture and waits until they have all been swapped in by Update, which
+// the game loop keeps calling while the loading screen is up
+func (t *TextureHandler) Load(progress sim.Progress) error {
+	t.Preload()
+	for {
+		total, loading := 0, 0
+		for _, l := range t.lazy {
+			if l == nil {
+				continue
+			}
+			total++
+			if atomic.LoadInt32(&l.state) == lazyLoading {
+				loading++
+			}
+		}
+		progress(total-loading, total)
+		if loading == 0 {
+			return nil
+		}
+		time.Sleep(texturePollInterval)
+	}
+}
@@ -0,0 +1,181 @@
+package raycaster
+
+import (
+	"image"
+	"image/color"
+	"math"
+
+	"github.com/hajimehoshi/ebiten"
+)
+
+// GenerateMipmaps creates up to levels successively halved copies of every wall texture, used in place of
+// the full texture on walls far enough away that several texels fall in one screen pixel.
+// Textures that glow or have a draw hook are always drawn at full size since those are keyed by texture.
+func (t *TextureHandler) GenerateMipmaps(levels int) {
+	t.mips = make(map[*ebiten.Image][]*ebiten.Image)
+	t.mipSlices = nil
+	t.mipLevels = levels
+	if levels < 1 {
+		return
+	}
+
+	for _, tex := range t.Textures {
+		if tex != nil && t.mips[tex] == nil {
+			t.mips[tex] = mipChain(tex, levels)
+		}
+	}
+
+	//--slices for each mip size, level 0 is the full size slices--//
+	size := len(t.slices)
+	t.mipSlices = [][]*image.Rectangle{t.slices}
+	for level := 1; level <= levels && size > 1; level++ {
+		size /= 2
+		t.mipSlices = append(t.mipSlices, MakeSlices(size, size))
+	}
+}
+
+// mipChain returns up to levels successively halved copies of the texture
+func mipChain(tex *ebiten.Image, levels int) []*ebiten.Image {
+	w, h := tex.Size()
+	src := tex
+	var chain []*ebiten.Image
+	for level := 1; level <= levels && w > 1 && h > 1; level++ {
+		w, h = w/2, h/2
+		mip, _ := ebiten.NewImage(w, h, ebiten.FilterNearest)
+
+		//--a linear filtered half scale draw averages each 2x2 block of texels--//
+		op := &ebiten.DrawImageOptions{}
+		op.GeoM.Scale(0.5, 0.5)
+		op.Filter = ebiten.FilterLinear
+		mip.DrawImage(src, op)
+
+		chain = append(chain, mip)
+		src = mip
+	}
+	return chain
+}
+
+// HasMipmaps returns true if wall textures have mip levels
+func (t *TextureHandler) HasMipmaps() bool {
+	return len(t.mipSlices) > 1
+}
+
+// wallMip returns the mip of the texture for the level along with its slices and texture width,
+// falling back to the nearest level that exists
+func (t *TextureHandler) wallMip(tex *ebiten.Image, level int) (*ebiten.Image, []*image.Rectangle, int) {
+	if level <= 0 || t.emissive[tex] != nil || t.drawHooks[tex] != nil {
+		return tex, t.slices, len(t.slices)
+	}
+
+	chain := t.mips[tex]
+	if level > len(chain) {
+		level = len(chain)
+	}
+	if level >= len(t.mipSlices) {
+		level = len(t.mipSlices) - 1
+	}
+	if level <= 0 {
+		return tex, t.slices, len(t.slices)
+	}
+	slices := t.mipSlices[level]
+	return chain[level-1], slices, len(slices)
+}
+
+// mipLevel returns the mip level to use where one screen pixel covers footprint texels of the full texture
+func mipLevel(footprint float64) int {
+	if footprint < 2 {
+		return 0
+	}
+	return int(math.Log2(footprint))
+}
+
+// GenerateMipmaps creates up to levels successively halved copies of every floor texture by averaging
+// each 2x2 block of texels, used in place of the full texture for distant floor rows
+func (h *HorLevel) GenerateMipmaps(levels int) {
+	h.Mips = make([][]*image.RGBA, len(h.TexRGBA))
+	for i, tex := range h.TexRGBA {
+		if tex != nil {
+			h.Mips[i] = floorMipChain(tex, levels)
+		}
+	}
+}
+
+// floorMipChain returns the floor texture followed by up to levels successively halved copies
+func floorMipChain(tex *image.RGBA, levels int) []*image.RGBA {
+	chain := []*image.RGBA{tex}
+	for level := 1; level <= levels; level++ {
+		src := chain[len(chain)-1]
+		b := src.Bounds()
+		if b.Dx() < 2 || b.Dy() < 2 {
+			break
+		}
+		chain = append(chain, halveRGBA(src))
+	}
+	return chain
+}
+
+// floorMip returns the floor texture for the mip level, falling back to the nearest level that exists
+func (h *HorLevel) floorMip(texNum, level int) *image.RGBA {
+	if texNum >= len(h.Mips) || h.Mips[texNum] == nil {
+		return h.TexRGBA[texNum]
+	}
+	chain := h.Mips[texNum]
+	if level >= len(chain) {
+		level = len(chain) - 1
+	}
+	return chain[level]
+}
+
+// halveRGBA returns the image at half size, each pixel the average of a 2x2 block
+func halveRGBA(src *image.RGBA) *image.RGBA {
+	b := src.Bounds()
+	dst := image.NewRGBA(image.Rect(0, 0, b.Dx()/2, b.Dy()/2))
+	for y := 0; y < dst.Rect.Dy(); y++ {
+		for x := 0; x < dst.Rect.Dx(); x++ {
+			var sum [4]int
+			for _, p := range [4]image.Point{{X: 0, Y: 0}, {X: 1, Y: 0}, {X: 0, Y: 1}, {X: 1, Y: 1}} {
+				off := src.PixOffset(b.Min.X+2*x+p.X, b.Min.Y+2*y+p.Y)
+				for c := 0; c < 4; c++ {
+					sum[c] += int(src.Pix[off+c])
+				}
+			}
+			off := dst.PixOffset(x, y)
+			for c := 0; c < 4; c++ {
+				dst.Pix[off+c] = uint8(sum[c] / 4)
+			}
+		}
+	}
+	return dst
+}
+
+// sampleBilinear returns the texture color at texel position u, v blended from the 4 nearest texels,
+// wrapping around the edges so tiled floors blend across cells
+func sampleBilinear(tex *image.RGBA, u, v float64) color.RGBA {
+	w, h := tex.Rect.Dx(), tex.Rect.Dy()
+	u, v = u-0.5, v-0.5
+	fx, fy := math.Floor(u), math.Floor(v)
+	tx, ty := u-fx, v-fy
+
+	x0, y0 := wrapIndex(int(fx), w), wrapIndex(int(fy), h)
+	x1, y1 := wrapIndex(x0+1, w), wrapIndex(y0+1, h)
+
+	a, b := tex.PixOffset(x0, y0), tex.PixOffset(x1, y0)
+	c, d := tex.PixOffset(x0, y1), tex.PixOffset(x1, y1)
+
+	var out [4]uint8
+	for i := 0; i < 4; i++ {
+		top := float64(tex.Pix[a+i]) + (float64(tex.Pix[b+i])-float64(tex.Pix[a+i]))*tx
+		bottom := float64(tex.Pix[c+i]) + (float64(tex.Pix[d+i])-float64(tex.Pix[c+i]))*tx
+		out[i] = uint8(top + (bottom-top)*ty + 0.5)
+	}
+	return color.RGBA{out[0], out[1], out[2], out[3]}
+}
+
+// wrapIndex wraps i into the range 0 to n-1
+func wrapIndex(i, n int) int {
+	i %= n
+	if i < 0 {
+		i += n
+	}
+	return i
+}
@@ -0,0 +1,62 @@
+package raycaster
+
+const (
+	// default darkness of the ambient occlusion band, 0 disables it
+	defaultOcclusion = 0.5
+
+	// height of the band on either side of the seam, as a fraction of the wall slice height
+	occlusionBand = 0.12
+)
+
+// SetOcclusion sets the strength of the darkening band where walls meet the floor, from 0 (off) to 1
+func (c *Camera) SetOcclusion(strength float64) {
+	if strength < 0 {
+		strength = 0
+	} else if strength > 1 {
+		strength = 1
+	}
+	c.occlusion = strength
+}
+
+// GetOcclusion returns the strength of the darkening band where walls meet the floor
+func (c *Camera) GetOcclusion() float64 {
+	return c.occlusion
+}
+
+// castOcclusion darkens screen column x around the seam at drawEnd where a wall slice meets the floor.
+// The wall side is covered with translucent black in the horizontal buffer (which draws over walls),
+// the floor side darkens the floor pixels already cast. Must run after the floor of the column is cast.
+func (c *Camera) castOcclusion(x, drawEnd, lineHeight int) {
+	if c.occlusion <= 0 {
+		return
+	}
+
+	band := int(float64(lineHeight) * occlusionBand)
+	if band < 1 {
+		return
+	}
+
+	for y := drawEnd - band; y < drawEnd+band; y++ {
+		if y < 0 || y >= c.h {
+			continue
+		}
+
+		// darkest at the seam, fading out towards the edges of the band
+		dist := y - drawEnd
+		if dist < 0 {
+			dist = -dist - 1
+		}
+		dark := c.occlusion * (1 - float64(dist)/float64(band))
+
+		pxOffset := c.horLvl.HorBuffer.PixOffset(x, y)
+		pix := c.horLvl.HorBuffer.Pix[pxOffset : pxOffset+4]
+		if y < drawEnd {
+			//--premultiplied alpha, so translucent black is all zero but alpha--//
+			pix[0], pix[1], pix[2], pix[3] = 0, 0, 0, uint8(255*dark)
+		} else {
+			pix[0] = uint8(float64(pix[0]) * (1 - dark))
+			pix[1] = uint8(float64(pix[1]) * (1 - dark))
+			pix[2] = uint8(float64(pix[2]) * (1 - dark))
+		}
+	}
+}
@@ -0,0 +1,14 @@
+package raycaster
+
+import "math"
+
+// GetHorizon returns the screen row of the horizon, the middle of the screen unless the view is pitched
+func (c *Camera) GetHorizon() int {
+	return c.h/2 + c.shear
+}
+
+// updateShear works out how many rows the horizon moves down for the pitch, at the vertical scale walls
+// are projected with
+func (c *Camera) updateShear() {
+	c.shear = int(math.Tan(c.GetPitch()*math.Pi/180) * float64(c.h))
+}
@@ -0,0 +1,245 @@
+package raycaster
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"math/rand"
+
+	"github.com/hajimehoshi/ebiten"
+)
+
+// TexturePalette are the colors a procedural texture is built from
+type TexturePalette struct {
+	// Base --main surface color (brick face, stone, first checker square)--//
+	Base color.RGBA
+
+	// Accent --secondary color (mortar, second checker square, grime)--//
+	Accent color.RGBA
+
+	// Shadow --color of cracks, edges and dirt--//
+	Shadow color.RGBA
+}
+
+// palettes for quick prototypes
+var (
+	PaletteRedBrick  = TexturePalette{Base: color.RGBA{150, 60, 45, 255}, Accent: color.RGBA{170, 165, 150, 255}, Shadow: color.RGBA{60, 30, 25, 255}}
+	PaletteGrayStone = TexturePalette{Base: color.RGBA{120, 120, 115, 255}, Accent: color.RGBA{95, 95, 90, 255}, Shadow: color.RGBA{45, 45, 45, 255}}
+	PaletteMossGreen = TexturePalette{Base: color.RGBA{70, 100, 50, 255}, Accent: color.RGBA{100, 120, 60, 255}, Shadow: color.RGBA{30, 45, 25, 255}}
+)
+
+// GenerateBricks creates a size by size tileable texture of offset brick rows with mortar between them
+func GenerateBricks(size int, seed int64, p TexturePalette) *image.RGBA {
+	rnd := rand.New(rand.NewSource(seed))
+	img := image.NewRGBA(image.Rect(0, 0, size, size))
+	noise := valueNoise(rnd, size, 16, 3)
+
+	rows := 8
+	brickH := maxInt(1, size/rows)
+	brickW := brickH * 2
+	mortar := maxInt(1, size/64)
+
+	//--each brick gets its own shade--//
+	cols := size/brickW + 1
+	shades := make([]float64, rows*(cols+1))
+	for i := range shades {
+		shades[i] = 0.85 + rnd.Float64()*0.3
+	}
+
+	for y := 0; y < size; y++ {
+		row := y / brickH
+		offset := 0
+		if row%2 == 1 {
+			offset = brickW / 2
+		}
+		for x := 0; x < size; x++ {
+			bx := (x + offset) % size
+			col := bx / brickW
+			n := noise[x][y]
+
+			if y%brickH < mortar || bx%brickW < mortar {
+				img.SetRGBA(x, y, shade(p.Accent, 0.8+n*0.3))
+				continue
+			}
+			clr := shade(p.Base, shades[row*(cols+1)+col]*(0.85+n*0.3))
+			if y%brickH == brickH-1 || bx%brickW == brickW-1 {
+				clr = mixColor(clr, p.Shadow, 0.5)
+			}
+			img.SetRGBA(x, y, clr)
+		}
+	}
+	return img
+}
+
+// GenerateStone creates a size by size tileable texture of irregular stones, from the cells of
+// randomly placed points with shadowed cracks where the cells meet
+func GenerateStone(size int, seed int64, p TexturePalette) *image.RGBA {
+	rnd := rand.New(rand.NewSource(seed))
+	img := image.NewRGBA(image.Rect(0, 0, size, size))
+	noise := valueNoise(rnd, size, 8, 4)
+
+	type stone struct {
+		x, y  float64
+		shade float64
+	}
+	stones := make([]stone, 24)
+	for i := range stones {
+		stones[i] = stone{rnd.Float64() * float64(size), rnd.Float64() * float64(size), 0.8 + rnd.Float64()*0.4}
+	}
+
+	for x := 0; x < size; x++ {
+		for y := 0; y < size; y++ {
+			//--nearest two stones, wrapping around the edges so the texture tiles--//
+			d1, d2 := math.MaxFloat64, math.MaxFloat64
+			nearest := 0
+			for i, s := range stones {
+				dx := wrapDistance(float64(x)-s.x, float64(size))
+				dy := wrapDistance(float64(y)-s.y, float64(size))
+				d := math.Sqrt(dx*dx + dy*dy)
+				if d < d1 {
+					d1, d2, nearest = d, d1, i
+				} else if d < d2 {
+					d2 = d
+				}
+			}
+
+			n := noise[x][y]
+			clr := mixColor(p.Base, p.Accent, n)
+			clr = shade(clr, stones[nearest].shade)
+			if edge := d2 - d1; edge < float64(size)/64+1 {
+				clr = mixColor(clr, p.Shadow, 0.8)
+			}
+			img.SetRGBA(x, y, clr)
+		}
+	}
+	return img
+}
+
+// GenerateChecker creates a size by size texture of squares by squares alternating checks
+func GenerateChecker(size, squares int, p TexturePalette) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, size, size))
+	if squares < 1 {
+		squares = 1
+	}
+	check := maxInt(1, size/squares)
+	for x := 0; x < size; x++ {
+		for y := 0; y < size; y++ {
+			clr := p.Base
+			if (x/check+y/check)%2 == 1 {
+				clr = p.Accent
+			}
+			img.SetRGBA(x, y, clr)
+		}
+	}
+	return img
+}
+
+// GenerateGrime creates a size by size tileable texture of the base color with blotches of
+// accent and shadow from layered noise
+func GenerateGrime(size int, seed int64, p TexturePalette) *image.RGBA {
+	rnd := rand.New(rand.NewSource(seed))
+	img := image.NewRGBA(image.Rect(0, 0, size, size))
+	blotches := valueNoise(rnd, size, 4, 4)
+	dirt := valueNoise(rnd, size, 32, 2)
+
+	for x := 0; x < size; x++ {
+		for y := 0; y < size; y++ {
+			clr := mixColor(p.Base, p.Accent, smoothStep(0.45, 0.7, blotches[x][y]))
+			clr = mixColor(clr, p.Shadow, smoothStep(0.6, 0.9, dirt[x][y])*0.7)
+			img.SetRGBA(x, y, clr)
+		}
+	}
+	return img
+}
+
+// RegisterGenerated adds a generated texture under a name for walls, returning its texture number
+func (t *TextureHandler) RegisterGenerated(name string, img *image.RGBA) int {
+	tex, _ := ebiten.NewImageFromImage(img, ebiten.FilterNearest)
+	return t.RegisterTexture(name, tex)
+}
+
+// valueNoise returns size by size fractal value noise in the range 0 to 1, starting from a lattice of
+// cells by cells random values and adding octaves of twice the frequency at half the strength.
+// The lattice wraps so the noise tiles.
+func valueNoise(rnd *rand.Rand, size, cells, octaves int) [][]float64 {
+	out := make([][]float64, size)
+	for x := range out {
+		out[x] = make([]float64, size)
+	}
+
+	total := 0.0
+	strength := 1.0
+	for o := 0; o < octaves; o++ {
+		lattice := make([][]float64, cells)
+		for i := range lattice {
+			lattice[i] = make([]float64, cells)
+			for j := range lattice[i] {
+				lattice[i][j] = rnd.Float64()
+			}
+		}
+
+		for x := 0; x < size; x++ {
+			fx := float64(x) * float64(cells) / float64(size)
+			x0 := int(fx)
+			tx := smoothStep(0, 1, fx-float64(x0))
+			for y := 0; y < size; y++ {
+				fy := float64(y) * float64(cells) / float64(size)
+				y0 := int(fy)
+				ty := smoothStep(0, 1, fy-float64(y0))
+
+				a := lattice[x0%cells][y0%cells]
+				b := lattice[(x0+1)%cells][y0%cells]
+				c := lattice[x0%cells][(y0+1)%cells]
+				d := lattice[(x0+1)%cells][(y0+1)%cells]
+				top := a + (b-a)*tx
+				bottom := c + (d-c)*tx
+				out[x][y] += (top + (bottom-top)*ty) * strength
+			}
+		}
+
+		total += strength
+		strength /= 2
+		cells *= 2
+	}
+
+	for x := range out {
+		for y := range out[x] {
+			out[x][y] /= total
+		}
+	}
+	return out
+}
+
+// wrapDistance returns the shortest distance along one axis of a texture that wraps at size
+func wrapDistance(d, size float64) float64 {
+	d = math.Abs(d)
+	if d > size/2 {
+		d = size - d
+	}
+	return d
+}
+
+func smoothStep(edge0, edge1, v float64) float64 {
+	t := clamp01((v - edge0) / (edge1 - edge0))
+	return t * t * (3 - 2*t)
+}
+
+// shade scales the brightness of a color
+func shade(c color.RGBA, scale float64) color.RGBA {
+	ch := func(v uint8) uint8 { return uint8(math.Min(255, float64(v)*scale)) }
+	return color.RGBA{ch(c.R), ch(c.G), ch(c.B), c.A}
+}
+
+// mixColor blends from a to b by t from 0 to 1
+func mixColor(a, b color.RGBA, t float64) color.RGBA {
+	t = clamp01(t)
+	ch := func(x, y uint8) uint8 { return uint8(float64(x) + (float64(y)-float64(x))*t) }
+	return color.RGBA{ch(a.R, b.R), ch(a.G, b.G), ch(a.B, b.B), ch(a.A, b.A)}
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
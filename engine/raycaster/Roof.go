@@ -0,0 +1,65 @@
+package raycaster
+
+import (
+	"image/color"
+	"math"
+	"raycaster-go/engine/sim"
+)
+
+const (
+	// index into the HorLevel textures used for roofs, falls back to the floor texture if not loaded
+	roofTexNum = 1
+
+	// roofs further than this are left hollow
+	roofDrawDistance = 16.0
+)
+
+// levelGrid returns the grid a wall level is cast from
+func (c *Camera) levelGrid(levelNum int) [][]int {
+	return c.mapObj.LevelGrid(levelNum)
+}
+
+// isRoof returns true if the grid cell is occupied on the level and nothing is built on top of it
+func (c *Camera) isRoof(levelNum, x, y int) bool {
+	if sim.CellAt(c.levelGrid(levelNum), x, y) <= 0 {
+		return false
+	}
+	return levelNum == cap(c.lvls)-1 || sim.CellAt(c.levelGrid(levelNum+1), x, y) <= 0
+}
+
+// castRoof draws the top of a wall level into the horizontal buffer for screen column x, across the part
+// of the ray from near to far (perpendicular distances) that passes over roof cells
+func (c *Camera) castRoof(x, levelNum int, rayDirX, rayDirY, near, far float64) {
+	roofTex := c.horLvl.TexRGBA[0]
+	if len(c.horLvl.TexRGBA) > roofTexNum && c.horLvl.TexRGBA[roofTexNum] != nil {
+		roofTex = c.horLvl.TexRGBA[roofTexNum]
+	}
+
+	//--height of the eye over the roof, in screen rows at distance 1--//
+	above := (c.posZ + sim.EyeHeight - float64(levelNum+1)) * float64(c.h)
+	half := float64(c.GetHorizon())
+
+	yStart := Clamp(int(half+above/far), 0, c.h)
+	yEnd := Clamp(int(half+above/near), 0, c.h)
+
+	for y := yStart; y < yEnd; y++ {
+		dist := above / (float64(y) + 0.5 - half)
+
+		roofX := c.pos.X + rayDirX*dist
+		roofY := c.pos.Y + rayDirY*dist
+
+		texX := int((roofX-math.Floor(roofX))*float64(c.texWidth)) % c.texWidth
+		texY := int((roofY-math.Floor(roofY))*float64(c.texWidth)) % c.texWidth
+
+		pxOffset := roofTex.PixOffset(texX, texY)
+		pixel := color.RGBA{roofTex.Pix[pxOffset], roofTex.Pix[pxOffset+1], roofTex.Pix[pxOffset+2], 255}
+
+		pixel = c.shadePixel(pixel, distanceLight(255, dist), x, y)
+
+		pxOffset = c.horLvl.HorBuffer.PixOffset(x, y)
+		c.horLvl.HorBuffer.Pix[pxOffset] = pixel.R
+		c.horLvl.HorBuffer.Pix[pxOffset+1] = pixel.G
+		c.horLvl.HorBuffer.Pix[pxOffset+2] = pixel.B
+		c.horLvl.HorBuffer.Pix[pxOffset+3] = pixel.A
+	}
+}
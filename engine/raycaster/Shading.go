@@ -0,0 +1,117 @@
+package raycaster
+
+import (
+	"image/color"
+	"math"
+)
+
+// ShadingMode selects how distance lighting is applied to walls, floors and sprites
+type ShadingMode int
+
+const (
+	// ShadingClassic --light is added to and multiplied with 8-bit sRGB values, fast but bands in the distance--//
+	ShadingClassic ShadingMode = iota
+
+	// ShadingLinear --light is applied in linear light with float math and converted to sRGB once at the end--//
+	ShadingLinear
+)
+
+const (
+	//--simulates torch light, as if player was carrying a radial light--//
+	torchFalloff = -100.0
+
+	//--sun brightness, illuminates whole level--//
+	sunBrightness = 300.0
+)
+
+var (
+	// sRGB byte to linear light
+	srgbToLinear [256]float64
+
+	// linear light to sRGB, finely sampled so nearby light levels don't collapse onto the same byte
+	linearToSRGB [4096]float64
+)
+
+func init() {
+	for i := range srgbToLinear {
+		v := float64(i) / 255
+		if v <= 0.04045 {
+			srgbToLinear[i] = v / 12.92
+		} else {
+			srgbToLinear[i] = math.Pow((v+0.055)/1.055, 2.4)
+		}
+	}
+	for i := range linearToSRGB {
+		v := float64(i) / float64(len(linearToSRGB)-1)
+		if v <= 0.0031308 {
+			linearToSRGB[i] = 255 * v * 12.92
+		} else {
+			linearToSRGB[i] = 255 * (1.055*math.Pow(v, 1/2.4) - 0.055)
+		}
+	}
+}
+
+// SetShading sets how distance lighting is applied
+func (c *Camera) SetShading(mode ShadingMode) {
+	c.shading = mode
+}
+
+// GetShading returns how distance lighting is applied
+func (c *Camera) GetShading() ShadingMode {
+	return c.shading
+}
+
+// distanceLight returns the light from 0 to 1 at the given distance for a surface of the given base
+// brightness (0-255), the same curve the classic shading adds up in bytes
+func distanceLight(base, dist float64) float64 {
+	return math.Max(0, math.Min(1, (base+math.Sqrt(dist)*torchFalloff+sunBrightness)/255))
+}
+
+// sliceShade returns the color scale to draw a wall or sprite slice with in linear shading,
+// 0 in classic shading where the slice tint is used instead
+func (c *Camera) sliceShade(base, dist float64) float64 {
+	if c.shading != ShadingLinear {
+		return 0
+	}
+	// scaling linear light by l scales sRGB by about l^(1/2.2), which the GPU can do on the sRGB texture
+	return math.Max(math.Pow(distanceLight(base, dist), 1/2.2), 1.0/255)
+}
+
+// shadePixel applies light from 0 to 1 to a floor, roof or terrain pixel at screen position x, y
+func (c *Camera) shadePixel(pixel color.RGBA, light float64, x, y int) color.RGBA {
+	if c.shading != ShadingLinear {
+		shade := float64(Clamp(int(255*light), 0, 255))
+		pixel.R = uint8(float64(pixel.R) * shade / 256)
+		pixel.G = uint8(float64(pixel.G) * shade / 256)
+		pixel.B = uint8(float64(pixel.B) * shade / 256)
+		return pixel
+	}
+
+	// a half step of ordered dither hides what banding is left after rounding to bytes
+	dither := (bayer2[y&1][x&1] + 0.5) / 4
+
+	pixel.R = encodeSRGB(srgbToLinear[pixel.R]*light, dither)
+	pixel.G = encodeSRGB(srgbToLinear[pixel.G]*light, dither)
+	pixel.B = encodeSRGB(srgbToLinear[pixel.B]*light, dither)
+	return pixel
+}
+
+// 2x2 Bayer matrix
+var bayer2 = [2][2]float64{{0, 2}, {3, 1}}
+
+// encodeSRGB converts linear light to an sRGB byte, rounding up when the fraction is above the dither threshold
+func encodeSRGB(linear, dither float64) uint8 {
+	i := int(linear * float64(len(linearToSRGB)-1))
+	if i < 0 {
+		i = 0
+	} else if i >= len(linearToSRGB) {
+		i = len(linearToSRGB) - 1
+	}
+
+	v := linearToSRGB[i]
+	out := math.Floor(v)
+	if v-out > dither {
+		out++
+	}
+	return uint8(math.Min(255, out))
+}
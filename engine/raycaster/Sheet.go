@@ -0,0 +1,27 @@
+package raycaster
+
+import (
+	"image"
+	"raycaster-go/engine/sim"
+
+	"github.com/hajimehoshi/ebiten"
+)
+
+func init() {
+	// sprite sheet frames are cut into images the renderer can draw
+	sim.SetFrameCopier(copyFrame)
+}
+
+// copyFrame copies the part of a sprite sheet inside r into an image of its own, so the frame can be drawn in
+// slices from 0, 0
+func copyFrame(sheet image.Image, r image.Rectangle) image.Image {
+	img, ok := sheet.(*ebiten.Image)
+	if !ok {
+		img, _ = ebiten.NewImageFromImage(sheet, ebiten.FilterNearest)
+	}
+	cellImg := img.SubImage(r).(*ebiten.Image)
+
+	frame, _ := ebiten.NewImage(r.Dx(), r.Dy(), ebiten.FilterNearest)
+	frame.DrawImage(cellImg, &ebiten.DrawImageOptions{})
+	return frame
+}
@@ -0,0 +1,103 @@
+package raycaster
+
+import (
+	"image/color"
+	"math"
+	"raycaster-go/engine/sim"
+	"sort"
+)
+
+// castSurfaces draws the flat surfaces into the horizontal buffer, far to near, clipped by the walls
+// of the first level. Must run after the level casting has filled the z-buffer.
+func (c *Camera) castSurfaces() {
+	surfaces := c.mapObj.GetSurfaces()
+	if len(surfaces) == 0 {
+		return
+	}
+
+	order := make([]*sim.Surface, len(surfaces))
+	copy(order, surfaces)
+	sort.Slice(order, func(i, j int) bool {
+		return c.surfaceDist(order[i]) > c.surfaceDist(order[j])
+	})
+
+	for x := 0; x < c.w; x++ {
+		for _, s := range order {
+			c.castSurface(x, s)
+		}
+	}
+}
+
+// surfaceDist returns the squared distance from the camera to the center of the surface
+func (c *Camera) surfaceDist(s *sim.Surface) float64 {
+	dx := (s.Min.X+s.Max.X)/2 - c.pos.X
+	dy := (s.Min.Y+s.Max.Y)/2 - c.pos.Y
+	return dx*dx + dy*dy
+}
+
+// castSurface draws the part of the surface seen by screen column x, its top when the eye is above it
+// and its underside when below
+func (c *Camera) castSurface(x int, s *sim.Surface) {
+	cameraX := c.camX[x]
+	rayDirX := c.dir.X + c.viewPlane.X*cameraX
+	rayDirY := c.dir.Y + c.viewPlane.Y*cameraX
+
+	near, far, ok := s.Intersect(c.pos.X, c.pos.Y, rayDirX, rayDirY)
+	if !ok {
+		return
+	}
+	far = math.Min(far, c.zBuffer[x])
+	if near <= 0 {
+		near = 0.01
+	}
+	if near >= far {
+		return
+	}
+
+	//--height of the eye over the surface, in screen rows at distance 1--//
+	above := (c.posZ + sim.EyeHeight - s.Height) * float64(c.h)
+	if above == 0 {
+		return
+	}
+	half := float64(c.GetHorizon())
+
+	yStart := Clamp(int(half+above/far), 0, c.h)
+	yEnd := Clamp(int(half+above/near), 0, c.h)
+	if above < 0 {
+		yStart, yEnd = Clamp(int(half+above/near), 0, c.h), Clamp(int(half+above/far), 0, c.h)
+	}
+
+	tex := c.horLvl.TexRGBA[0]
+	if s.TexNum < len(c.horLvl.TexRGBA) && c.horLvl.TexRGBA[s.TexNum] != nil {
+		tex = c.horLvl.TexRGBA[s.TexNum]
+	}
+
+	for y := yStart; y < yEnd; y++ {
+		dist := above / (float64(y) + 0.5 - half)
+		if dist < near || dist > far {
+			continue
+		}
+
+		surfX := c.pos.X + rayDirX*dist
+		surfY := c.pos.Y + rayDirY*dist
+
+		texX := int((surfX-math.Floor(surfX))*float64(c.texWidth)) % c.texWidth
+		texY := int((surfY-math.Floor(surfY))*float64(c.texWidth)) % c.texWidth
+
+		pxOffset := tex.PixOffset(texX, texY)
+		pixel := color.RGBA{tex.Pix[pxOffset], tex.Pix[pxOffset+1], tex.Pix[pxOffset+2], 255}
+
+		light := distanceLight(255, dist)
+		if above < 0 {
+			//--undersides are in shadow--//
+			light /= 2
+		}
+		pixel = c.shadePixel(pixel, light, x, y)
+
+		pxOffset = c.horLvl.HorBuffer.PixOffset(x, y)
+		c.horLvl.HorBuffer.Pix[pxOffset] = pixel.R
+		c.horLvl.HorBuffer.Pix[pxOffset+1] = pixel.G
+		c.horLvl.HorBuffer.Pix[pxOffset+2] = pixel.B
+		c.horLvl.HorBuffer.Pix[pxOffset+3] = pixel.A
+	}
+}
@@ -0,0 +1,77 @@
+package raycaster
+
+import (
+	"image/color"
+	"raycaster-go/engine/sim"
+)
+
+const (
+	// how far terrain is drawn, in grid cells
+	terrainDrawDistance = 48.0
+
+	// distance between terrain samples along a ray close to the camera, grows with distance
+	terrainStep = 0.02
+)
+
+// castTerrain renders the terrain into the horizontal buffer in place of walls and floor.
+// credit : voxel space rendering, front to back with a y-buffer per column
+// courtesy - https://github.com/s-macke/VoxelSpace
+func (c *Camera) castTerrain(t *sim.Terrain) {
+	//--walls are not drawn in terrain mode--//
+	for _, lvl := range c.lvls {
+		for x := 0; x < c.w; x++ {
+			lvl.CurrTex[x] = nil
+		}
+	}
+
+	c.castColumns(func(w *castWorker, x int) {
+		c.castTerrainColumn(t, x)
+	})
+}
+
+// castTerrainColumn marches a ray across the terrain for one screen column, filling pixels from the
+// bottom of the screen up as nearer samples rise above the ones already drawn
+func (c *Camera) castTerrainColumn(t *sim.Terrain, x int) {
+	cameraX := c.camX[x]
+	rayDirX := c.dir.X + c.viewPlane.X*cameraX
+	rayDirY := c.dir.Y + c.viewPlane.Y*cameraX
+
+	eyeZ := c.posZ + sim.EyeHeight
+	h := float64(c.h)
+	yBuffer := c.h
+	c.zBuffer[x] = terrainDrawDistance
+
+	dz := terrainStep
+	for z := terrainStep; z < terrainDrawDistance && yBuffer > 0; z += dz {
+		//--z is the perpendicular distance, the ray direction is one unit along the camera direction--//
+		px := c.pos.X + rayDirX*z
+		py := c.pos.Y + rayDirY*z
+		sx, sy, ok := t.Sample(px, py)
+		if ok {
+			top := int(h/2 + float64(c.shear) + (eyeZ-t.Heights[sx][sy])*h/z)
+			if top < 0 {
+				top = 0
+			}
+			if top < yBuffer {
+				pxOffset := t.Colors.PixOffset(sx, sy)
+				pixel := color.RGBA{t.Colors.Pix[pxOffset], t.Colors.Pix[pxOffset+1], t.Colors.Pix[pxOffset+2], 255}
+
+				// lighting
+				light := distanceLight(255, z)
+
+				for y := top; y < yBuffer; y++ {
+					pixel := c.shadePixel(pixel, light, x, y)
+					pxOffset = c.horLvl.HorBuffer.PixOffset(x, y)
+					c.horLvl.HorBuffer.Pix[pxOffset] = pixel.R
+					c.horLvl.HorBuffer.Pix[pxOffset+1] = pixel.G
+					c.horLvl.HorBuffer.Pix[pxOffset+2] = pixel.B
+					c.horLvl.HorBuffer.Pix[pxOffset+3] = pixel.A
+				}
+				yBuffer = top
+			}
+		}
+
+		//--samples further away can be sparser--//
+		dz = terrainStep * (1 + z/4)
+	}
+}
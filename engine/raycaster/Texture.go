@@ -2,6 +2,8 @@ package raycaster
 
 import (
 	"image"
+	"image/color"
+	"raycaster-go/engine/sim"
 
 	"github.com/hajimehoshi/ebiten"
 )
@@ -9,6 +11,44 @@ import (
 type TextureHandler struct {
 	slices   []*image.Rectangle
 	Textures []*ebiten.Image
+
+	// ColorKey --color turned transparent in every image loaded by the handler, nil for none--//
+	ColorKey *color.RGBA
+
+	// ColorKeyTolerance --how far each channel may be from the key color and still be transparent--//
+	ColorKeyTolerance uint8
+
+	//--color keys of individual image files, used in place of ColorKey--//
+	colorKeys map[string]color.RGBA
+
+	//--texture numbers by name, including generated variants--//
+	names map[string]int
+
+	//--halved copies of each texture for distant walls, and the slices for each mip size--//
+	mips      map[*ebiten.Image][]*ebiten.Image
+	mipSlices [][]*image.Rectangle
+	mipLevels int
+
+	//--wall textures decoded in the background on first use, by texture number--//
+	lazy           []*lazyTexture
+	lazyReady      chan decodedTexture
+	placeholderTex *ebiten.Image
+
+	//--scheduler swapping decoded textures in one at a time, nil to swap them all in Update--//
+	scheduler *sim.Scheduler
+
+	//--bytes of texture memory to stay under (0 for no limit) and the tick count for least recently used--//
+	memoryBudget int
+	frame        int64
+
+	//--glow of emissive textures, keyed by the texture they belong to--//
+	emissive map[*ebiten.Image]*ebiten.Image
+
+	//--custom draw effects, keyed by texture--//
+	drawHooks map[*ebiten.Image]DrawHook
+
+	//--hooks applied to single sprites over the hooks of their textures--//
+	spriteHooks map[*sim.Sprite]DrawHook
 }
 
 func NewTextureHandler(texWidth int) *TextureHandler {
@@ -39,3 +79,60 @@ func MakeSlices(width, height int) []*image.Rectangle {
 func (t *TextureHandler) GetSlices() []*image.Rectangle {
 	return t.slices
 }
+
+// SetEmissive sets the emissive channel of a texture, an image of the same size where only the parts
+// that give off light are not black. Passing the texture itself as emissive makes it fullbright.
+func (t *TextureHandler) SetEmissive(tex, emissive *ebiten.Image) {
+	if t.emissive == nil {
+		t.emissive = make(map[*ebiten.Image]*ebiten.Image)
+	}
+	if emissive == nil {
+		delete(t.emissive, tex)
+		return
+	}
+	t.emissive[tex] = emissive
+}
+
+// GetImage returns the texture of a texture number for the sprites of a map, nil if there is none
+func (t *TextureHandler) GetImage(texNum int) image.Image {
+	if texNum < 0 || texNum >= len(t.Textures) || t.Textures[texNum] == nil {
+		return nil
+	}
+	return t.Textures[texNum]
+}
+
+// GetNumTextures returns the number of texture numbers, with or without a texture loaded
+func (t *TextureHandler) GetNumTextures() int {
+	return len(t.Textures)
+}
+
+// SetSpriteFullbright makes every animation frame of the sprite fullbright
+func (t *TextureHandler) SetSpriteFullbright(s *sim.Sprite) {
+	for _, frame := range s.GetFrames() {
+		t.setFullbright(frame)
+	}
+	if a := s.GetAnimationSet(); a != nil {
+		for _, frames := range a.Directions {
+			for _, frame := range frames {
+				t.setFullbright(frame)
+			}
+		}
+	}
+}
+
+// setFullbright makes a sprite frame its own emissive channel
+func (t *TextureHandler) setFullbright(frame image.Image) {
+	if tex, ok := frame.(*ebiten.Image); ok && tex != nil {
+		t.SetEmissive(tex, tex)
+	}
+}
+
+// GetEmissive returns the emissive channel of a texture, or nil if it does not give off light
+func (t *TextureHandler) GetEmissive(tex *ebiten.Image) *ebiten.Image {
+	return t.emissive[tex]
+}
+
+// HasEmissive returns true if any texture gives off light
+func (t *TextureHandler) HasEmissive() bool {
+	return len(t.emissive) > 0
+}
@@ -0,0 +1,92 @@
+package raycaster
+
+import (
+	"sort"
+	"sync/atomic"
+
+	"github.com/hajimehoshi/ebiten"
+)
+
+// SetMemoryBudget sets how many bytes of texture memory the handler aims to stay under, 0 for no limit.
+// When over budget, Update unloads the least recently seen lazy textures back to the placeholder,
+// to be decoded again from their file the next time they come into view. Textures seen in the last
+// frame and textures without a source file are never unloaded.
+func (t *TextureHandler) SetMemoryBudget(bytes int) {
+	t.memoryBudget = bytes
+}
+
+// GetMemoryBudget returns the texture memory budget in bytes, 0 for no limit
+func (t *TextureHandler) GetMemoryBudget() int {
+	return t.memoryBudget
+}
+
+// MemoryUsed returns the estimated bytes of texture memory used by the textures, their mips and glow maps
+func (t *TextureHandler) MemoryUsed() int {
+	seen := make(map[*ebiten.Image]bool)
+	used := 0
+	add := func(img *ebiten.Image) {
+		if img == nil || seen[img] {
+			return
+		}
+		seen[img] = true
+		w, h := img.Size()
+		used += w * h * 4
+	}
+
+	for _, tex := range t.Textures {
+		add(tex)
+		for _, mip := range t.mips[tex] {
+			add(mip)
+		}
+		add(t.emissive[tex])
+	}
+	return used
+}
+
+// markUsed records the texture as seen in the current frame, safe to call from cast workers
+func (l *lazyTexture) markUsed(frame int64) {
+	if atomic.LoadInt64(&l.lastUsed) != frame {
+		atomic.StoreInt64(&l.lastUsed, frame)
+	}
+}
+
+// evict unloads the least recently seen lazy textures until memory is under budget
+func (t *TextureHandler) evict() {
+	if t.memoryBudget <= 0 {
+		return
+	}
+	used := t.MemoryUsed()
+	if used <= t.memoryBudget {
+		return
+	}
+
+	var candidates []int
+	for texNum, l := range t.lazy {
+		if l != nil && atomic.LoadInt32(&l.state) == lazyLoaded && t.Textures[texNum] != t.placeholderTex &&
+			atomic.LoadInt64(&l.lastUsed) < t.frame-1 {
+			candidates = append(candidates, texNum)
+		}
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		return atomic.LoadInt64(&t.lazy[candidates[i]].lastUsed) < atomic.LoadInt64(&t.lazy[candidates[j]].lastUsed)
+	})
+
+	for _, texNum := range candidates {
+		if used <= t.memoryBudget {
+			return
+		}
+		tex := t.Textures[texNum]
+		w, h := tex.Size()
+		used -= w * h * 4
+		for _, mip := range t.mips[tex] {
+			mw, mh := mip.Size()
+			used -= mw * mh * 4
+			mip.Dispose()
+		}
+		delete(t.mips, tex)
+		tex.Dispose()
+
+		t.Textures[texNum] = t.placeholder()
+		atomic.StoreInt32(&t.lazy[texNum].state, lazyUnloaded)
+	}
+}
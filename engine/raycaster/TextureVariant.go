@@ -0,0 +1,207 @@
+package raycaster
+
+import (
+	"image"
+	"image/color"
+	"math/rand"
+
+	"github.com/hajimehoshi/ebiten"
+)
+
+// TextureVariant is a programmatic change applied to a copy of a base texture, registered under
+// the base name followed by a colon and the suffix (e.g. "stone:dark")
+type TextureVariant struct {
+	// Suffix --added to the base name to name the variant--//
+	Suffix string
+
+	// Apply --changes the pixels of the copy in place--//
+	Apply func(img *image.RGBA)
+}
+
+// VariantDarkened scales the brightness of the texture by 1-amount
+func VariantDarkened(amount float64) TextureVariant {
+	return TextureVariant{Suffix: "dark", Apply: func(img *image.RGBA) {
+		scale := clamp01(1 - amount)
+		eachPixel(img, func(c color.RGBA) color.RGBA {
+			return color.RGBA{uint8(float64(c.R) * scale), uint8(float64(c.G) * scale), uint8(float64(c.B) * scale), c.A}
+		})
+	}}
+}
+
+// VariantDesaturated blends the texture towards gray by amount from 0 (unchanged) to 1 (grayscale)
+func VariantDesaturated(amount float64) TextureVariant {
+	return TextureVariant{Suffix: "gray", Apply: func(img *image.RGBA) {
+		amount := clamp01(amount)
+		eachPixel(img, func(c color.RGBA) color.RGBA {
+			gray := 0.299*float64(c.R) + 0.587*float64(c.G) + 0.114*float64(c.B)
+			mix := func(v uint8) uint8 { return uint8(float64(v) + (gray-float64(v))*amount) }
+			return color.RGBA{mix(c.R), mix(c.G), mix(c.B), c.A}
+		})
+	}}
+}
+
+// VariantDamaged overlays cracks and chipped patches generated from the seed, coverage from 0 to 1
+// sets how much of the texture is damaged
+func VariantDamaged(seed int64, coverage float64) TextureVariant {
+	return TextureVariant{Suffix: "damaged", Apply: func(img *image.RGBA) {
+		rnd := rand.New(rand.NewSource(seed))
+		b := img.Bounds()
+		size := float64(b.Dx())
+
+		//--chipped patches are darkened blotches--//
+		patches := int(coverage * 12)
+		for i := 0; i < patches; i++ {
+			cx, cy := b.Min.X+rnd.Intn(b.Dx()), b.Min.Y+rnd.Intn(b.Dy())
+			r := int(size * (0.03 + rnd.Float64()*0.05))
+			darkenCircle(img, cx, cy, r, 0.55)
+		}
+
+		//--cracks are random walks of dark pixels--//
+		cracks := int(coverage * 8)
+		for i := 0; i < cracks; i++ {
+			x, y := float64(b.Min.X+rnd.Intn(b.Dx())), float64(b.Min.Y+rnd.Intn(b.Dy()))
+			dx, dy := rnd.Float64()*2-1, rnd.Float64()*2-1
+			for step := 0; step < int(size*0.4); step++ {
+				darkenCircle(img, int(x), int(y), 0, 0.3)
+				dx += (rnd.Float64() - 0.5) * 0.6
+				dy += (rnd.Float64() - 0.5) * 0.6
+				x, y = x+clampUnit(dx), y+clampUnit(dy)
+			}
+		}
+	}}
+}
+
+// VariantMossy grows patches of green from the bottom of the texture generated from the seed,
+// coverage from 0 to 1 sets how far up the moss reaches
+func VariantMossy(seed int64, coverage float64) TextureVariant {
+	return TextureVariant{Suffix: "mossy", Apply: func(img *image.RGBA) {
+		rnd := rand.New(rand.NewSource(seed))
+		b := img.Bounds()
+		moss := color.RGBA{60, 110, 40, 255}
+		for x := b.Min.X; x < b.Max.X; x++ {
+			for y := b.Min.Y; y < b.Max.Y; y++ {
+				//--denser towards the bottom, speckled by noise--//
+				height := float64(b.Max.Y-y) / float64(b.Dy())
+				chance := clamp01(coverage - height + rnd.Float64()*0.3)
+				if chance <= 0 {
+					continue
+				}
+				c := img.RGBAAt(x, y)
+				if c.A == 0 {
+					continue
+				}
+				mix := func(v, m uint8) uint8 { return uint8(float64(v) + (float64(m)-float64(v))*chance) }
+				img.SetRGBA(x, y, color.RGBA{mix(c.R, moss.R), mix(c.G, moss.G), mix(c.B, moss.B), c.A})
+			}
+		}
+	}}
+}
+
+// RegisterTexture adds a texture under a name, returning its texture number.
+// A texture already registered under the name is replaced in place.
+func (t *TextureHandler) RegisterTexture(name string, img *ebiten.Image) int {
+	if t.names == nil {
+		t.names = make(map[string]int)
+	}
+	if texNum, ok := t.names[name]; ok {
+		t.Textures[texNum] = img
+		return texNum
+	}
+	t.Textures = append(t.Textures, img)
+	t.names[name] = len(t.Textures) - 1
+	return t.names[name]
+}
+
+// NameTexture registers a name for an already loaded texture number
+func (t *TextureHandler) NameTexture(name string, texNum int) {
+	if t.names == nil {
+		t.names = make(map[string]int)
+	}
+	t.names[name] = texNum
+}
+
+// GetTextureNumber returns the texture number registered under the name, false if there is none
+func (t *TextureHandler) GetTextureNumber(name string) (int, bool) {
+	texNum, ok := t.names[name]
+	return texNum, ok
+}
+
+// GetTexture returns the texture registered under the name, or nil if there is none
+func (t *TextureHandler) GetTexture(name string) *ebiten.Image {
+	if texNum, ok := t.names[name]; ok {
+		return t.Textures[texNum]
+	}
+	return nil
+}
+
+// GenerateVariants creates a copy of the named texture for each variant, registered as "name:suffix",
+// and returns their texture numbers in order. Meant to be called at load time, reading the base texture
+// back from the image is slow.
+func (t *TextureHandler) GenerateVariants(name string, variants ...TextureVariant) []int {
+	base := t.GetTexture(name)
+	if base == nil {
+		return nil
+	}
+
+	b := base.Bounds()
+	src := image.NewRGBA(b)
+	for x := b.Min.X; x < b.Max.X; x++ {
+		for y := b.Min.Y; y < b.Max.Y; y++ {
+			src.Set(x, y, base.At(x, y))
+		}
+	}
+
+	texNums := make([]int, 0, len(variants))
+	for _, v := range variants {
+		img := image.NewRGBA(b)
+		copy(img.Pix, src.Pix)
+		v.Apply(img)
+
+		variant, _ := ebiten.NewImageFromImage(img, ebiten.FilterNearest)
+		texNums = append(texNums, t.RegisterTexture(name+":"+v.Suffix, variant))
+	}
+	return texNums
+}
+
+// eachPixel replaces every pixel of the image with the result of fn
+func eachPixel(img *image.RGBA, fn func(c color.RGBA) color.RGBA) {
+	b := img.Bounds()
+	for x := b.Min.X; x < b.Max.X; x++ {
+		for y := b.Min.Y; y < b.Max.Y; y++ {
+			img.SetRGBA(x, y, fn(img.RGBAAt(x, y)))
+		}
+	}
+}
+
+// darkenCircle scales the brightness of the pixels within radius of cx, cy
+func darkenCircle(img *image.RGBA, cx, cy, radius int, scale float64) {
+	for x := cx - radius; x <= cx+radius; x++ {
+		for y := cy - radius; y <= cy+radius; y++ {
+			if (x-cx)*(x-cx)+(y-cy)*(y-cy) > radius*radius || !(image.Point{X: x, Y: y}).In(img.Bounds()) {
+				continue
+			}
+			c := img.RGBAAt(x, y)
+			img.SetRGBA(x, y, color.RGBA{uint8(float64(c.R) * scale), uint8(float64(c.G) * scale), uint8(float64(c.B) * scale), c.A})
+		}
+	}
+}
+
+func clamp01(v float64) float64 {
+	if v < 0 {
+		return 0
+	}
+	if v > 1 {
+		return 1
+	}
+	return v
+}
+
+func clampUnit(v float64) float64 {
+	if v < -1 {
+		return -1
+	}
+	if v > 1 {
+		return 1
+	}
+	return v
+}
@@ -0,0 +1,94 @@
+package raycaster
+
+import (
+	"image"
+
+	"github.com/hajimehoshi/ebiten"
+)
+
+// TextureTheme is a set of wall and floor textures that replace the current ones at the same texture
+// numbers, so a map can be reskinned (seasons, dream sequences) without touching its grids.
+// Texture numbers missing from the theme keep their current texture.
+type TextureTheme struct {
+	// Name --name of the theme, for display--//
+	Name string
+
+	// Walls --wall textures by texture number--//
+	Walls map[int]*ebiten.Image
+
+	// Floors --floor textures by floor texture number--//
+	Floors map[int]*image.RGBA
+}
+
+// NewTextureTheme creates an empty theme
+func NewTextureTheme(name string) *TextureTheme {
+	return &TextureTheme{
+		Name:   name,
+		Walls:  make(map[int]*ebiten.Image),
+		Floors: make(map[int]*image.RGBA),
+	}
+}
+
+// SetWall sets the wall texture of the theme for a texture number
+func (th *TextureTheme) SetWall(texNum int, tex *ebiten.Image) {
+	th.Walls[texNum] = tex
+}
+
+// SetFloor sets the floor texture of the theme for a floor texture number
+func (th *TextureTheme) SetFloor(texNum int, tex *image.RGBA) {
+	th.Floors[texNum] = tex
+}
+
+// ApplyTheme replaces the wall textures at the texture numbers of the theme and returns a theme of
+// the textures it replaced, which can be applied to swap back. Mips are rebuilt for the new textures.
+// Glow maps and draw hooks are keyed by texture so they stay with the replaced textures, and lazy
+// textures replaced by the theme are no longer loaded from their file.
+func (t *TextureHandler) ApplyTheme(theme *TextureTheme) *TextureTheme {
+	prev := NewTextureTheme(theme.Name)
+	for texNum, tex := range theme.Walls {
+		if texNum < 0 || tex == nil {
+			continue
+		}
+		for len(t.Textures) <= texNum {
+			t.Textures = append(t.Textures, nil)
+		}
+
+		old := t.Textures[texNum]
+		if texNum < len(t.lazy) && t.lazy[texNum] != nil {
+			t.lazy[texNum] = nil
+			if old == t.placeholderTex {
+				old = nil
+			}
+		}
+		if old != nil {
+			prev.Walls[texNum] = old
+		}
+
+		t.Textures[texNum] = tex
+		if t.mipLevels > 0 && t.mips[tex] == nil {
+			t.mips[tex] = mipChain(tex, t.mipLevels)
+		}
+	}
+	return prev
+}
+
+// ApplyTheme replaces the floor textures at the texture numbers of the theme and returns a theme of
+// the textures it replaced. Floor levels created from the same texture slice share their textures,
+// but each one keeps its own mips, so call this on every floor level.
+func (h *HorLevel) ApplyTheme(theme *TextureTheme) *TextureTheme {
+	prev := NewTextureTheme(theme.Name)
+	for texNum, tex := range theme.Floors {
+		if texNum < 0 || texNum >= len(h.TexRGBA) || tex == nil {
+			continue
+		}
+		if h.TexRGBA[texNum] != nil {
+			prev.Floors[texNum] = h.TexRGBA[texNum]
+		}
+		h.TexRGBA[texNum] = tex
+
+		if texNum < len(h.Mips) && h.Mips[texNum] != nil {
+			h.Mips[texNum] = floorMipChain(tex, len(h.Mips[texNum])-1)
+		}
+	}
+	return prev
+}
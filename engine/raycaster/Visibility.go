@@ -0,0 +1,57 @@
+package raycaster
+
+// markVisible records that a ground level ray of the worker passed through the grid cell during the current frame
+func (w *castWorker) markVisible(x, y int) {
+	w.visible = append(w.visible, [2]int{x, y})
+}
+
+// mergeVisible gathers the cells the workers marked visible into the visible tiles of the frame, once each
+func (c *Camera) mergeVisible() {
+	if w, h := c.mapObj.GetSize(); len(c.visibleFrame) != w || w > 0 && len(c.visibleFrame[0]) != h {
+		c.visibleFrame = make([][]int, w)
+		for i := range c.visibleFrame {
+			c.visibleFrame[i] = make([]int, h)
+		}
+	}
+
+	for _, w := range c.workers {
+		for _, cell := range w.visible {
+			x, y := cell[0], cell[1]
+			if x < 0 || y < 0 || x >= len(c.visibleFrame) || y >= len(c.visibleFrame[x]) {
+				continue
+			}
+			if c.visibleFrame[x][y] == c.frame {
+				continue
+			}
+			c.visibleFrame[x][y] = c.frame
+			c.visibleTiles = append(c.visibleTiles, cell)
+		}
+		w.visible = w.visible[:0]
+	}
+}
+
+// beginVisibleFrame starts recording visible tiles for a new frame
+func (c *Camera) beginVisibleFrame() {
+	// frame numbers start at 1 so the zeroed grid never counts as visible
+	c.frame++
+	c.visibleTiles = c.visibleTiles[:0]
+	for _, w := range c.workers {
+		w.visible = w.visible[:0]
+	}
+}
+
+// VisibleTiles calls fn for every grid cell intersected by the view frustum during the last raycast,
+// i.e. cells the rays passed through up to and including the walls they hit
+func (c *Camera) VisibleTiles(fn func(x, y int)) {
+	for _, cell := range c.visibleTiles {
+		fn(cell[0], cell[1])
+	}
+}
+
+// IsTileVisible returns true if the grid cell was intersected by the view frustum during the last raycast
+func (c *Camera) IsTileVisible(x, y int) bool {
+	if x < 0 || y < 0 || x >= len(c.visibleFrame) || y >= len(c.visibleFrame[x]) {
+		return false
+	}
+	return c.visibleFrame[x][y] == c.frame
+}
@@ -0,0 +1,21 @@
+package raycaster
+
+import "raycaster-go/engine/sim"
+
+// ProjectPoint returns the screen position of a world point at height z using the sprite transform,
+// along with its depth into the screen. The depth is zero or negative for points behind the camera.
+func (c *Camera) ProjectPoint(x, y, z float64) (float64, float64, float64) {
+	relX, relY := x-c.pos.X, y-c.pos.Y
+
+	invDet := 1.0 / (c.viewPlane.X*c.dir.Y - c.dir.X*c.viewPlane.Y)
+	transformX := invDet * (c.dir.Y*relX - c.dir.X*relY)
+	transformY := invDet * (-c.viewPlane.Y*relX + c.viewPlane.X*relY)
+	if transformY <= 0 {
+		return 0, 0, transformY
+	}
+
+	w, h := float64(c.w), float64(c.h)
+	screenX := w / 2 * (1 + transformX/transformY)
+	screenY := h/2 + float64(c.shear) + (c.posZ+sim.EyeHeight-z)*h/transformY
+	return screenX, screenY, transformY
+}
@@ -0,0 +1,73 @@
+package raycaster
+
+import "sync"
+
+const (
+	// default number of goroutines splitting the screen columns during the level and terrain passes
+	castWorkers = 4
+)
+
+// castWorker holds the state one goroutine of a column pass writes to, so no two goroutines share it
+type castWorker struct {
+	// visible --ground cells the rays of this worker passed through, merged into the visible tiles after the pass--//
+	visible [][2]int
+}
+
+// SetWorkers sets the number of goroutines the column passes are split over, at least 1. One avoids the
+// goroutine overhead where there is only one thread to run them, as in browsers.
+func (c *Camera) SetWorkers(n int) {
+	if n < 1 {
+		n = 1
+	}
+	c.workers = make([]*castWorker, n)
+	for i := range c.workers {
+		c.workers[i] = &castWorker{}
+	}
+}
+
+// GetWorkers returns the number of goroutines the column passes are split over
+func (c *Camera) GetWorkers() int {
+	return len(c.workers)
+}
+
+// castColumns splits the screen into contiguous bands of columns, one per worker, and calls fn for every
+// column of a band from the goroutine of that band. Each column is owned by exactly one goroutine for the
+// whole pass, so fn may write anything indexed by its column (level slices, the zbuffer, pixels of the
+// horizontal buffer in that column) and its own worker without locking. Returns once every column is done.
+func (c *Camera) castColumns(fn func(w *castWorker, x int)) {
+	var wg sync.WaitGroup
+	band := (c.w + len(c.workers) - 1) / len(c.workers)
+
+	// all goroutines are counted before any of them starts
+	wg.Add(len(c.workers))
+	for i, w := range c.workers {
+		go func(w *castWorker, from, to int) {
+			defer wg.Done()
+			for x := from; x < to && x < c.w; x++ {
+				fn(w, x)
+			}
+		}(w, i*band, (i+1)*band)
+	}
+	wg.Wait()
+}
+
+// castSprites casts every sprite into its own sprite level, each from its own goroutine.
+// Sprites only read the zbuffer finished by the level pass and write their own level.
+func (c *Camera) castSprites(numSprites int) {
+	var wg sync.WaitGroup
+
+	wg.Add(numSprites)
+	for i := 0; i < numSprites; i++ {
+		go func(spriteOrdIndex int) {
+			defer wg.Done()
+
+			c.semaphore <- struct{}{} // Lock
+			defer func() {
+				<-c.semaphore // Unlock
+			}()
+
+			c.castSprite(spriteOrdIndex)
+		}(i)
+	}
+	wg.Wait()
+}
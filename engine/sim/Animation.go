@@ -0,0 +1,166 @@
+package sim
+
+import "image"
+
+// LoopMode is how an animation carries on after its last frame
+type LoopMode int
+
+const (
+	// LoopRepeat --starts over from the first frame--//
+	LoopRepeat LoopMode = iota
+	// LoopOnce --holds the last frame, or plays the next animation if one is set--//
+	LoopOnce
+	// LoopPingPong --plays back to the first frame, then forwards again--//
+	LoopPingPong
+)
+
+// Animation is a named sequence of the frames of a sprite (walk, attack, death)
+type Animation struct {
+	Name string
+
+	// Frames --indexes into the sprite frames, sheets are numbered left to right then top to bottom--//
+	Frames []int
+
+	// FPS --frames shown per clock second--//
+	FPS float64
+
+	// Loop --what happens after the last frame--//
+	Loop LoopMode `json:",omitempty"`
+
+	// Next --animation played once a LoopOnce animation ends, empty to hold its last frame--//
+	Next string `json:",omitempty"`
+}
+
+// animState is the animation a sprite is playing and how far it has got
+type animState struct {
+	current *Animation
+	frame   int
+	time    float64
+	back    bool
+	done    bool
+}
+
+// NewSpriteFromFrames creates a sprite from separate frame images, shown with animations added to it
+func NewSpriteFromFrames(x, y float64, frames []image.Image) *Sprite {
+	s := &Sprite{}
+	s.X, s.Y = x, y
+	s.textures = frames
+	s.lenTex = len(frames)
+	return s
+}
+
+// AddAnimation adds an animation to the sprite, replacing any with the same name. Frames outside the
+// frames of the sprite are ignored.
+func (s *Sprite) AddAnimation(a *Animation) {
+	if s.animations == nil {
+		s.animations = make(map[string]*Animation)
+	}
+	s.animations[a.Name] = a
+}
+
+// GetAnimations returns the animations added to the sprite by name
+func (s *Sprite) GetAnimations() map[string]*Animation {
+	return s.animations
+}
+
+// Play starts an animation from its first frame, doing nothing if it is already playing.
+// Returns false if the sprite has no animation with the name.
+func (s *Sprite) Play(name string) bool {
+	a := s.animations[name]
+	if a == nil {
+		return false
+	}
+	if s.anim.current == a && !s.anim.done {
+		return true
+	}
+	s.anim = animState{current: a}
+	s.showFrame()
+	return true
+}
+
+// Stop stops the animation playing, holding the frame it is on
+func (s *Sprite) Stop() {
+	s.anim = animState{}
+}
+
+// GetAnimation returns the name of the animation playing, empty if none
+func (s *Sprite) GetAnimation() string {
+	if s.anim.current == nil {
+		return ""
+	}
+	return s.anim.current.Name
+}
+
+// IsAnimationDone returns true once a LoopOnce animation has shown its last frame
+func (s *Sprite) IsAnimationDone() bool {
+	return s.anim.done
+}
+
+// stepAnimation advances the animation playing by the clock seconds that passed, returning false if there
+// is none so the whole sheet is cycled instead
+func (s *Sprite) stepAnimation(dt float64) bool {
+	st := &s.anim
+	a := st.current
+	if a == nil {
+		return false
+	}
+	if st.done || a.FPS <= 0 || len(a.Frames) == 0 {
+		return true
+	}
+
+	st.time += dt * a.FPS
+	for st.time >= 1 && !st.done {
+		st.time--
+		s.advanceFrame()
+	}
+	s.showFrame()
+	return true
+}
+
+// advanceFrame moves to the next frame of the animation playing, following its loop mode at the ends
+func (s *Sprite) advanceFrame() {
+	st := &s.anim
+	a := st.current
+	last := len(a.Frames) - 1
+
+	if st.back {
+		st.frame--
+		if st.frame <= 0 {
+			st.frame, st.back = 0, false
+		}
+		return
+	}
+	if st.frame < last {
+		st.frame++
+		return
+	}
+
+	switch a.Loop {
+	case LoopRepeat:
+		st.frame = 0
+	case LoopPingPong:
+		if last > 0 {
+			st.frame, st.back = last-1, last-1 > 0
+		}
+	case LoopOnce:
+		if next := s.animations[a.Next]; next != nil && a.Next != "" {
+			*st = animState{current: next}
+			return
+		}
+		st.done = true
+		if s.OnAnimationEnd != nil {
+			s.OnAnimationEnd(s, a.Name)
+		}
+	}
+}
+
+// showFrame sets the texture of the sprite to the current frame of the animation playing
+func (s *Sprite) showFrame() {
+	a := s.anim.current
+	if a == nil || len(a.Frames) == 0 {
+		return
+	}
+	if f := a.Frames[s.anim.frame]; f >= 0 && f < s.lenTex {
+		s.texNum = f
+	}
+}
@@ -0,0 +1,198 @@
+package sim
+
+// ArenaDoor is a doorway sealed with a wall while a boss fight is on
+type ArenaDoor struct {
+	X, Y int
+
+	// Texture --wall texture the doorway is sealed with--//
+	Texture int
+}
+
+// BossPhase is a stage of a boss fight that begins when the boss health falls to a fraction of its maximum
+type BossPhase struct {
+	Name string
+
+	// Threshold --fraction of the maximum health at or below which the phase begins--//
+	Threshold float64
+}
+
+// Arena ties together the usual boss fight pattern: entering it seals the doors, the boss health is shown on
+// the HUD, phase events fire as the health drops and the doors open again once the boss is defeated
+type Arena struct {
+	// Name --looked up with Map.GetArena--//
+	Name string
+
+	// Trigger --tile trigger id that starts the fight--//
+	Trigger string
+
+	// Doors --doorways sealed for the fight--//
+	Doors []ArenaDoor
+
+	// Boss --sprite of the boss, removed from the map when defeated if set--//
+	Boss *Sprite `json:"-"`
+
+	// BossName --shown over the health bar--//
+	BossName string
+
+	// MaxHealth --health the boss starts the fight with--//
+	MaxHealth float64
+
+	// Phases --later phases have lower thresholds, the fight starts in phase -1 until the first is reached--//
+	Phases []BossPhase `json:",omitempty"`
+
+	// OnStart --called when the fight starts and the doors are sealed--//
+	OnStart func(a *Arena) `json:"-"`
+
+	// OnPhase --called when the boss health falls to the threshold of a phase, with its index--//
+	OnPhase func(a *Arena, phase int) `json:"-"`
+
+	// OnDefeat --called when the boss health reaches zero, after the doors open--//
+	OnDefeat func(a *Arena) `json:"-"`
+
+	mapObj   *Map
+	health   float64
+	phase    int
+	active   bool
+	defeated bool
+
+	// walls the doorways had before they were sealed
+	sealed []int
+}
+
+// Start seals the doors and starts the fight at full health, doing nothing if it already started
+func (a *Arena) Start() {
+	if a.active || a.defeated {
+		return
+	}
+	a.active = true
+	a.health = a.MaxHealth
+	a.phase = -1
+	a.lockDoors()
+
+	if a.OnStart != nil {
+		a.OnStart(a)
+	}
+	a.checkPhase()
+}
+
+// Damage takes health from the boss, firing phase events for every threshold passed and ending the fight
+// when the health runs out
+func (a *Arena) Damage(amount float64) {
+	if !a.active {
+		return
+	}
+	a.health -= amount
+	if a.health < 0 {
+		a.health = 0
+	}
+	a.checkPhase()
+
+	if a.health == 0 {
+		a.active, a.defeated = false, true
+		a.unlockDoors()
+		if a.Boss != nil && a.mapObj != nil {
+			a.mapObj.RemoveSprite(a.Boss)
+		}
+		if a.OnDefeat != nil {
+			a.OnDefeat(a)
+		}
+	}
+}
+
+// Abort ends the fight without a winner and opens the doors, so it can be started again (e.g. the player died)
+func (a *Arena) Abort() {
+	if !a.active {
+		return
+	}
+	a.active = false
+	a.unlockDoors()
+}
+
+// IsActive returns true while the fight is on
+func (a *Arena) IsActive() bool {
+	return a.active
+}
+
+// IsDefeated returns true once the boss has been defeated
+func (a *Arena) IsDefeated() bool {
+	return a.defeated
+}
+
+// GetHealth returns the boss health and maximum health
+func (a *Arena) GetHealth() (float64, float64) {
+	return a.health, a.MaxHealth
+}
+
+// GetPhase returns the index of the current phase, -1 before the first
+func (a *Arena) GetPhase() int {
+	return a.phase
+}
+
+// checkPhase moves through every phase whose threshold the health has fallen to
+func (a *Arena) checkPhase() {
+	for a.phase+1 < len(a.Phases) && a.MaxHealth > 0 && a.health/a.MaxHealth <= a.Phases[a.phase+1].Threshold {
+		a.phase++
+		if a.OnPhase != nil {
+			a.OnPhase(a, a.phase)
+		}
+	}
+}
+
+// lockDoors seals the doorways with walls on the ground level, keeping what was there
+func (a *Arena) lockDoors() {
+	if a.mapObj == nil {
+		return
+	}
+	a.sealed = make([]int, len(a.Doors))
+	for i, d := range a.Doors {
+		if !a.mapObj.inBounds(d.X, d.Y) {
+			continue
+		}
+		a.sealed[i] = a.mapObj.worldMap[d.X][d.Y]
+		a.mapObj.worldMap[d.X][d.Y] = d.Texture
+	}
+}
+
+// unlockDoors puts back what the doorways had before they were sealed
+func (a *Arena) unlockDoors() {
+	if a.mapObj == nil || a.sealed == nil {
+		return
+	}
+	for i, d := range a.Doors {
+		if a.mapObj.inBounds(d.X, d.Y) {
+			a.mapObj.worldMap[d.X][d.Y] = a.sealed[i]
+		}
+	}
+	a.sealed = nil
+}
+
+// AddArena adds a boss arena to the map, started when the camera enters a tile with its trigger
+func (m *Map) AddArena(a *Arena) {
+	a.mapObj = m
+	m.arenas = append(m.arenas, a)
+}
+
+// GetArena returns the arena with the given name, nil if there is none
+func (m *Map) GetArena(name string) *Arena {
+	for _, a := range m.arenas {
+		if a.Name == name {
+			return a
+		}
+	}
+	return nil
+}
+
+// GetArenas returns the boss arenas in the map
+func (m *Map) GetArenas() []*Arena {
+	return m.arenas
+}
+
+// GetActiveArena returns the arena with a fight on, nil if there is none
+func (m *Map) GetActiveArena() *Arena {
+	for _, a := range m.arenas {
+		if a.active {
+			return a
+		}
+	}
+	return nil
+}
@@ -0,0 +1,485 @@
+package sim
+
+import (
+	"math"
+	"math/rand"
+)
+
+const (
+	//--move speed--//
+	moveSpeed = 0.06
+
+	//--rotate speed--//
+	rotSpeed = 0.03
+
+	// constant used for movement target framerate to prevent higher framerates from moving too fast
+	movementTPS = 60.0
+
+	// EyeHeight is the height of the camera eye above its feet, in level units
+	EyeHeight = 0.5
+
+	// downward acceleration applied to vertical velocity each tick
+	gravity = 0.01
+
+	// highest ledge the camera can walk onto without climbing, in level units
+	stepHeight = 0.25
+
+	// fraction of the remaining distance covered each tick at the movement target framerate when easing the eye up onto a ledge
+	stepEase = 0.3
+
+	// distance kept between the camera and walls when pushed around
+	collisionRadius = 0.2
+)
+
+// Camera is a viewpoint moving through a map: the player, a spectator or a security camera. It holds the
+// position, facing, physics and status of the camera, the raycaster renders what it sees.
+type Camera struct {
+	//--camera position, init to start position--//
+	pos *Vector2
+
+	//--current facing direction, init to values coresponding to FOV--//
+	dir *Vector2
+
+	//--the 2d raycaster version of camera plane, adjust y component to change FOV (ratio between this and dir x resizes FOV)--//
+	plane *Vector2
+
+	//--active status effects--//
+	effects []*StatusEffect
+
+	//--height of the camera feet above the ground and vertical velocity, in level units--//
+	posZ float64
+	velZ float64
+
+	//--external horizontal velocity (wind, knockback), decays by friction each tick--//
+	vel Vector2
+
+	//--debug cheats--//
+	invulnerable bool
+	noClip       bool
+
+	//--grid cell occupied at the end of the last update--//
+	cellX, cellY int
+
+	//--state to respawn at--//
+	checkpoint *Snapshot
+
+	// RespawnPolicy --which parts of the checkpoint are restored on Respawn--//
+	RespawnPolicy RestorePolicy
+
+	//--air meter and whether the eye is currently below water--//
+	air       float64
+	submerged bool
+
+	// Events --optional hooks fired as the camera interacts with the world--//
+	Events Events
+
+	// target framerate reference
+	targetTPS int
+	// seconds the current tick covers, speeds are scaled by it
+	dt float64
+
+	//--world map, grids are read from it on every update so runtime changes apply at once--//
+	mapObj *Map
+
+	//--view pitch in degrees--//
+	pitch float64
+
+	//--temporary field of view changes layered over the base--//
+	fovKicks   []*FOVKick
+	sprintKick *FOVKick
+	zoomKick   *FOVKick
+	zoomScale  float64
+
+	// ZoomScalesTurning --turn slower while zoomed in, in proportion to the zoom--//
+	ZoomScalesTurning bool
+
+	//--motion comfort options and the view motion they limit--//
+	comfort    *Comfort
+	bobPhase   float64
+	shake      float64
+	shakeDecay float64
+	turnSpeed  float64
+	turning    bool
+
+	//--random shake offsets, apart from the map's so drawing never changes the simulation--//
+	viewRand *rand.Rand
+
+	//--distance walked towards the next footstep and which foot it is on--//
+	stepDistance float64
+	stepLeft     bool
+}
+
+// Vector2 converted struct from C#
+type Vector2 struct {
+	X float64
+	Y float64
+}
+
+// NewCamera creates a camera at the start position in a map, configured by any options. It moves, collides
+// and fires tile events like the player camera without any render buffers: step it with Simulate.
+func NewCamera(mapObj *Map, opts ...CameraOption) *Camera {
+	c := &Camera{}
+
+	// target FPS (TPS) movement is normalized against, the game loop rate is left to the caller
+	c.SetTargetTPS(60)
+
+	//--camera position, init to start position--//
+	c.pos = &Vector2{X: 22.5, Y: 11.5}
+	//--current facing direction, init to values coresponding to FOV--//
+	c.dir = &Vector2{X: -1.0, Y: 0.0}
+	//--the 2d raycaster version of camera plane, perpendicular to dir, its length sets the FOV--//
+	c.plane = &Vector2{X: 0.0, Y: 1.0}
+	c.SetFOV(DefaultFOV)
+
+	c.air = 1.0
+	c.comfort = NewComfort()
+	c.viewRand = rand.New(rand.NewSource(1))
+	c.ZoomScalesTurning = true
+	c.cellX, c.cellY = int(c.pos.X), int(c.pos.Y)
+	c.RespawnPolicy = RestoreCamera | RestoreSolids
+
+	c.mapObj = mapObj
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	return c
+}
+
+// Simulate moves the camera through gravity, moving solids, tile and status effects. The raycaster calls it
+// before casting the view, headless cameras call it on their own.
+func (c *Camera) Simulate() {
+	//--apply gravity or buoyancy--//
+	c.updateVertical()
+
+	//--ride and collide with moving solids--//
+	c.updateSolids()
+
+	//--apply status and floor tile effects--//
+	c.updateEffects()
+	c.updateTileDamage()
+	c.updatePush()
+	c.updateTileEnter()
+	c.holdDoor()
+
+	//--fade shake, settle turning, ease field of view kicks--//
+	c.updateComfort()
+	c.updateFOVKicks()
+}
+
+// SetTargetTPS sets the tick rate the game loop runs at. Ticks are taken to last 1/tps seconds until a
+// delta time is given with SetDeltaTime, UpdateWithDelta or MoveWithDelta.
+func (c *Camera) SetTargetTPS(tps int) {
+	if tps > 0 {
+		c.targetTPS = tps
+		c.dt = 1.0 / float64(tps)
+	}
+}
+
+// SetDeltaTime sets the seconds the current tick covers, scaling the movement, turning and physics of the
+// camera from then on, for uncapped or variable tick rates. Speeds stay in grid cells per 1/60 second.
+func (c *Camera) SetDeltaTime(dt float64) {
+	if dt > 0 {
+		c.dt = dt
+	}
+}
+
+// GetDeltaTime returns the seconds the current tick covers
+func (c *Camera) GetDeltaTime() float64 {
+	return c.dt
+}
+
+// MoveWithDelta moves the camera by a speed in grid cells per 1/60 second over a tick lasting dt seconds.
+// Strafe and Rotate use the same tick duration once it is set.
+func (c *Camera) MoveWithDelta(mSpeed, dt float64) {
+	c.SetDeltaTime(dt)
+	c.Move(mSpeed)
+}
+
+// SimulateWithDelta simulates the camera for a tick lasting dt seconds
+func (c *Camera) SimulateWithDelta(dt float64) {
+	c.SetDeltaTime(dt)
+	c.Simulate()
+}
+
+// GetTargetTPS returns the tick rate movement speeds are normalized against
+func (c *Camera) GetTargetTPS() int {
+	return c.targetTPS
+}
+
+// GetPosition returns the grid position of the camera
+func (c *Camera) GetPosition() Vector2 {
+	return *c.pos
+}
+
+// GetDirection returns the facing direction of the camera
+func (c *Camera) GetDirection() Vector2 {
+	return *c.dir
+}
+
+// GetHeight returns the height of the camera feet above the ground, in level units
+func (c *Camera) GetHeight() float64 {
+	return c.posZ
+}
+
+// GetViewPlane returns the camera plane the view is cast with, the plane scaled by the field of view
+// modifiers for this tick
+func (c *Camera) GetViewPlane() Vector2 {
+	fovScale := c.comfortFOVScale(c.effectFOVScale() * c.fovKickScale())
+	return Vector2{X: c.plane.X * fovScale, Y: c.plane.Y * fovScale}
+}
+
+// SetPosition moves the camera straight to a grid position (teleporters, loading a save, cutscenes),
+// keeping its height and velocity. The tile there counts as entered on the next update.
+func (c *Camera) SetPosition(pos Vector2) {
+	c.pos.X, c.pos.Y = pos.X, pos.Y
+}
+
+// GetHeading returns the facing of the camera in degrees from 0 up to 360, 0 along +x and 90 along +y
+func (c *Camera) GetHeading() float64 {
+	heading := math.Atan2(c.dir.Y, c.dir.X) * 180 / math.Pi
+	if heading < 0 {
+		heading += 360
+	}
+	return heading
+}
+
+// SetHeading turns the camera to face an angle in degrees, 0 along +x and 90 along +y, at once and
+// keeping its field of view
+func (c *Camera) SetHeading(degrees float64) {
+	turn := (degrees - c.GetHeading()) * math.Pi / 180
+	sin, cos := math.Sin(turn), math.Cos(turn)
+
+	//both camera direction and camera plane must be rotated
+	*c.dir = Vector2{X: c.dir.X*cos - c.dir.Y*sin, Y: c.dir.X*sin + c.dir.Y*cos}
+	*c.plane = Vector2{X: c.plane.X*cos - c.plane.Y*sin, Y: c.plane.X*sin + c.plane.Y*cos}
+}
+
+// updateVertical moves the camera up or down under gravity, or buoyancy while in water
+func (c *Camera) updateVertical() {
+	if c.onLadder() {
+		// hold position while hanging on a ladder
+		c.velZ = 0
+	} else if t := c.waterTile(); t != nil && c.posZ < t.WaterLevel {
+		c.applyBuoyancy(t)
+	} else {
+		c.velZ -= c.getNormalSpeed(gravity)
+	}
+
+	c.posZ += c.getNormalSpeed(c.velZ)
+
+	ground := c.ground()
+	if c.posZ <= ground {
+		// ease the eye up onto ledges instead of snapping to them
+		c.posZ += (ground - c.posZ) * (1 - math.Pow(1-stepEase, c.dt*movementTPS))
+		if ground-c.posZ < 0.001 {
+			c.posZ = ground
+		}
+		c.velZ = 0
+	}
+
+	c.updateAir()
+}
+
+// updateTileDamage fires the tile damage event while standing on the floor of a damaging tile
+func (c *Camera) updateTileDamage() {
+	x, y := int(c.pos.X), int(c.pos.Y)
+	t := c.mapObj.GetTileType(x, y)
+	if t == nil || t.DamagePerSecond <= 0 || c.Events.OnTileDamage == nil || c.invulnerable {
+		return
+	}
+
+	if !c.onFloor() || c.ground() > 0 {
+		// only the floor of the cell is damaging
+		return
+	}
+
+	c.Events.OnTileDamage(t.DamagePerSecond*c.dt, t)
+}
+
+// updateTileEnter fires the tile enter event when the camera has moved into a different grid cell
+func (c *Camera) updateTileEnter() {
+	x, y := int(c.pos.X), int(c.pos.Y)
+	if x == c.cellX && y == c.cellY {
+		return
+	}
+	c.cellX, c.cellY = x, y
+
+	t := c.mapObj.GetTileType(x, y)
+	if t == nil {
+		return
+	}
+	if t.Checkpoint {
+		c.SetCheckpoint()
+	}
+	if t.Effect != nil {
+		effect := *t.Effect
+		c.AddEffect(&effect)
+	}
+	if c.Events.OnEnterTile != nil {
+		c.Events.OnEnterTile(x, y, t)
+	}
+}
+
+// AddSprite adds a sprite (projectile, enemy, pickup) to the map of the camera, cast from the next raycast
+func (c *Camera) AddSprite(s *Sprite) {
+	c.mapObj.AddSprite(s)
+}
+
+// RemoveSprite removes a sprite from the map of the camera, no longer cast from the next raycast
+func (c *Camera) RemoveSprite(s *Sprite) {
+	c.mapObj.RemoveSprite(s)
+}
+
+// UpdateSprites picks up sprites moved, added or removed by game code straight away, for collision and
+// ray checks made before the next map update
+func (c *Camera) UpdateSprites() {
+	c.mapObj.indexSprites()
+}
+
+// normalize speed given per 1/60 second to the duration of the current tick
+func (c *Camera) getNormalSpeed(speed float64) float64 {
+	return speed * movementTPS * c.dt
+}
+
+// Move camera by move speed
+func (c *Camera) Move(mSpeed float64) {
+	mSpeed = c.getNormalSpeed(mSpeed) * c.effectSpeedScale()
+	if c.InWater() {
+		mSpeed *= waterSpeedFactor
+	}
+
+	if c.climb(mSpeed) {
+		return
+	}
+
+	oldX, oldY := c.pos.X, c.pos.Y
+	if c.canMoveTo(c.pos.X+c.dir.X*mSpeed*12, c.pos.Y) {
+		c.pos.X += (c.dir.X * mSpeed)
+	}
+	if c.canMoveTo(c.pos.X, c.pos.Y+c.dir.Y*mSpeed*12) {
+		c.pos.Y += (c.dir.Y * mSpeed)
+	}
+
+	// head bob and footsteps follow the distance walked
+	c.walk(oldX, oldY)
+}
+
+// Strafe camera by strafe speed
+func (c *Camera) Strafe(sSpeed float64) {
+	sSpeed = c.getNormalSpeed(sSpeed) * c.effectSpeedScale()
+	if c.InWater() {
+		sSpeed *= waterSpeedFactor
+	}
+
+	oldX, oldY := c.pos.X, c.pos.Y
+	if c.canMoveTo(c.pos.X+c.plane.X*sSpeed*12, c.pos.Y) {
+		c.pos.X += (c.plane.X * sSpeed)
+	}
+	if c.canMoveTo(c.pos.X, c.pos.Y+c.plane.Y*sSpeed*12) {
+		c.pos.Y += (c.plane.Y * sSpeed)
+	}
+	c.walk(oldX, oldY)
+}
+
+// translate moves the camera by the given offset, sliding along any walls in the way
+func (c *Camera) translate(dx, dy float64) {
+	if dx != 0 && c.canMoveTo(c.pos.X+dx+math.Copysign(collisionRadius, dx), c.pos.Y) {
+		c.pos.X += dx
+	}
+	if dy != 0 && c.canMoveTo(c.pos.X, c.pos.Y+dy+math.Copysign(collisionRadius, dy)) {
+		c.pos.Y += dy
+	}
+}
+
+// canMoveTo returns true if neither the static grid nor a moving solid obstructs the grid position,
+// positions off any edge of the map are always blocked
+func (c *Camera) canMoveTo(x, y float64) bool {
+	cellX, cellY := int(math.Floor(x)), int(math.Floor(y))
+	if c.noClip {
+		return c.mapObj.inBounds(cellX, cellY)
+	}
+	return !c.isBlocked(cellX, cellY) && c.solidBlocks(x, y) == nil
+}
+
+// ground returns the height the camera feet rest on, from wall levels, terrain or moving solids below it
+func (c *Camera) ground() float64 {
+	ground := c.groundHeight(int(c.pos.X), int(c.pos.Y))
+	if terrain := c.mapObj.TerrainAt(c.pos.X, c.pos.Y); terrain != nil {
+		ground = math.Max(ground, terrain.HeightAt(c.pos.X, c.pos.Y))
+	}
+	if solidTop, s := c.solidGround(); s != nil && solidTop > ground {
+		ground = solidTop
+	}
+	return ground
+}
+
+// levelGrids returns the level grids from the ground up, the authored ones and any repeated above them up to
+// the camera eye
+func (c *Camera) levelGrids() [][][]int {
+	numLevels := c.mapObj.GetNumLevels()
+	if eye := int(c.posZ+EyeHeight) + 1; c.mapObj.RepeatTopLevel && eye > numLevels {
+		numLevels = eye
+	}
+
+	grids := make([][][]int, numLevels)
+	for i := range grids {
+		grids[i] = c.mapObj.LevelGrid(i)
+	}
+	return grids
+}
+
+// isBlocked returns true if the grid cell has a wall level between the camera feet (less step height) and eye,
+// or is off the edge of the map
+func (c *Camera) isBlocked(x, y int) bool {
+	if !c.mapObj.inBounds(x, y) {
+		return true
+	}
+	for lvl, grid := range c.levelGrids() {
+		if CellAt(grid, x, y) <= 0 || lvl == 0 && c.mapObj.isOpenDoor(x, y) {
+			continue
+		}
+		if float64(lvl+1) > c.posZ+stepHeight && float64(lvl) < c.posZ+EyeHeight {
+			return true
+		}
+	}
+	return false
+}
+
+// groundHeight returns the height of the highest wall level top at or below the camera feet (plus step height)
+func (c *Camera) groundHeight(x, y int) float64 {
+	ground := 0.0
+	for lvl, grid := range c.levelGrids() {
+		top := float64(lvl + 1)
+		if CellAt(grid, x, y) > 0 && top <= c.posZ+stepHeight && !(lvl == 0 && c.mapObj.GetDoor(x, y) != nil) {
+			ground = top
+		}
+	}
+	return ground
+}
+
+// stackHeight returns the height of the contiguous wall levels built up from the ground in the grid cell
+func (c *Camera) stackHeight(x, y int) float64 {
+	height := 0.0
+	for lvl, grid := range c.levelGrids() {
+		if CellAt(grid, x, y) <= 0 {
+			break
+		}
+		height = float64(lvl + 1)
+	}
+	return height
+}
+
+// Rotate camera by rotate speed
+func (c *Camera) Rotate(rSpeed float64) {
+	rSpeed = c.comfortTurn(c.getNormalSpeed(rSpeed) * c.zoomTurnScale())
+
+	//both camera direction and camera plane must be rotated
+	oldDirX := c.dir.X
+	c.dir.X = (c.dir.X*math.Cos(rSpeed) - c.dir.Y*math.Sin(rSpeed))
+	c.dir.Y = (oldDirX*math.Sin(rSpeed) + c.dir.Y*math.Cos(rSpeed))
+	oldPlaneX := c.plane.X
+	c.plane.X = (c.plane.X*math.Cos(rSpeed) - c.plane.Y*math.Sin(rSpeed))
+	c.plane.Y = (oldPlaneX*math.Sin(rSpeed) + c.plane.Y*math.Cos(rSpeed))
+}
@@ -0,0 +1,28 @@
+package sim
+
+// CameraOption configures a camera as NewCamera makes it
+type CameraOption func(c *Camera)
+
+// WithTargetTPS sets the tick rate movement speeds are normalized against, 60 by default. The camera only
+// uses it for its own speeds; the game loop rate is left to the caller.
+func WithTargetTPS(tps int) CameraOption {
+	return func(c *Camera) {
+		c.SetTargetTPS(tps)
+	}
+}
+
+// WithStartPosition places the camera at a grid position instead of the default start, standing on the
+// ground there
+func WithStartPosition(pos Vector2) CameraOption {
+	return func(c *Camera) {
+		c.Warp(pos)
+		c.cellX, c.cellY = int(pos.X), int(pos.Y)
+	}
+}
+
+// WithFOV sets the base horizontal field of view in degrees, DefaultFOV by default
+func WithFOV(degrees float64) CameraOption {
+	return func(c *Camera) {
+		c.SetFOV(degrees)
+	}
+}
@@ -0,0 +1,60 @@
+package sim
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"testing"
+)
+
+// simulateAt runs a camera in an open map for the given seconds at a tick rate, after setup
+func simulateAt(tps int, seconds float64, setup func(m *Map, c *Camera)) *Camera {
+	m := NewEmptyMap(nil, 16, 16)
+	c := NewCamera(m, WithTargetTPS(tps), WithStartPosition(Vector2{X: 4.5, Y: 8.5}))
+	setup(m, c)
+	for i := 0; i < int(seconds*float64(tps)); i++ {
+		c.Simulate()
+	}
+	return c
+}
+
+func TestCameraTickRate(t *testing.T) {
+	tests := []struct {
+		name  string
+		setup func(m *Map, c *Camera)
+		value func(c *Camera) float64
+		tol   float64
+	}{
+		{
+			name:  "knockback distance",
+			setup: func(m *Map, c *Camera) { c.Push(Vector2{X: 0.05}) },
+			value: func(c *Camera) float64 { return c.GetPosition().X },
+			tol:   0.05,
+		},
+		{
+			name:  "knockback speed",
+			setup: func(m *Map, c *Camera) { c.Push(Vector2{X: 0.05}) },
+			value: func(c *Camera) float64 { return c.GetVelocity().X },
+			tol:   1e-9,
+		},
+		{
+			name: "ledge easing",
+			setup: func(m *Map, c *Camera) {
+				m.AddTerrain(NewTerrainFromFunc(image.Rect(0, 0, 16, 16), 1, func(x, y float64) (float64, color.RGBA) {
+					return 2, color.RGBA{}
+				}))
+			},
+			value: func(c *Camera) float64 { return c.posZ },
+			tol:   0.05,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			slow := tt.value(simulateAt(30, 0.1, tt.setup))
+			fast := tt.value(simulateAt(120, 0.1, tt.setup))
+			if math.Abs(slow-fast) > tt.tol {
+				t.Errorf("%v at 30 TPS, %v at 120 TPS", slow, fast)
+			}
+		})
+	}
+}
@@ -0,0 +1,32 @@
+package sim
+
+// SetCeiling sets the ceiling over cells whose tile type does not set one, as a 1 based index into the
+// floor textures, 0 for open sky
+func (m *Map) SetCeiling(texture int) {
+	m.ceiling = texture
+}
+
+// GetCeiling returns the ceiling texture over the grid cell at x, y as a 1 based index into the floor
+// textures: the ceiling of its tile type, otherwise the ceiling of the map. 0 is open sky.
+func (m *Map) GetCeiling(x, y int) int {
+	if t := m.GetTileType(x, y); t != nil && t.Ceiling > 0 {
+		return t.Ceiling
+	}
+	if !m.inBounds(x, y) {
+		return 0
+	}
+	return m.ceiling
+}
+
+// HasCeilings returns true if the map or any of its tile types has a ceiling
+func (m *Map) HasCeilings() bool {
+	if m.ceiling > 0 {
+		return true
+	}
+	for _, t := range m.tileTypes {
+		if t != nil && t.Ceiling > 0 {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,40 @@
+package sim
+
+// SetInvulnerable turns damage to the camera on or off, while invulnerable no damage events fire
+// and the air meter does not run out
+func (c *Camera) SetInvulnerable(invulnerable bool) {
+	c.invulnerable = invulnerable
+}
+
+// IsInvulnerable returns true if the camera takes no damage
+func (c *Camera) IsInvulnerable() bool {
+	return c.invulnerable
+}
+
+// SetNoClip lets the camera move through walls and moving solids, it still stays inside the map
+func (c *Camera) SetNoClip(noClip bool) {
+	c.noClip = noClip
+}
+
+// IsNoClip returns true if the camera moves through walls
+func (c *Camera) IsNoClip() bool {
+	return c.noClip
+}
+
+// Warp moves the camera straight to a grid position, standing on the ground there
+func (c *Camera) Warp(pos Vector2) {
+	c.pos.X, c.pos.Y = pos.X, pos.Y
+	c.vel = Vector2{}
+	c.posZ = c.ground()
+}
+
+// RefillAir fills the air meter
+func (c *Camera) RefillAir() {
+	if c.air == 1 {
+		return
+	}
+	c.air = 1
+	if c.Events.OnAirChange != nil {
+		c.Events.OnAirChange(c.air)
+	}
+}
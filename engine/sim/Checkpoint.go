@@ -0,0 +1,25 @@
+package sim
+
+// SetCheckpoint snapshots the current state as the point to respawn at
+func (c *Camera) SetCheckpoint() {
+	c.checkpoint = c.Snapshot()
+
+	if c.Events.OnCheckpoint != nil {
+		c.Events.OnCheckpoint()
+	}
+}
+
+// GetCheckpoint returns the last checkpoint snapshot, or nil if none has been reached
+func (c *Camera) GetCheckpoint() *Snapshot {
+	return c.checkpoint
+}
+
+// Respawn restores the last checkpoint using the camera RespawnPolicy.
+// Returns false if no checkpoint has been reached yet.
+func (c *Camera) Respawn() bool {
+	if c.checkpoint == nil {
+		return false
+	}
+	c.Restore(c.checkpoint, c.RespawnPolicy)
+	return true
+}
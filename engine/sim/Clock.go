@@ -0,0 +1,198 @@
+package sim
+
+// Clock is the engine simulation clock. It is advanced once per tick by the real tick duration,
+// stands still while paused and runs faster or slower with the time scale, so anything scheduled
+// on it stays in step with the simulation regardless of TPS.
+type Clock struct {
+	now    float64
+	scale  float64
+	paused bool
+
+	timers    []*Timer
+	sequences []*Sequence
+	tweens    []*Tween
+}
+
+// Timer is a callback scheduled on the clock
+type Timer struct {
+	at       float64
+	interval float64
+	fn       func()
+	stopped  bool
+}
+
+// Stop cancels the timer, it will not fire again
+func (t *Timer) Stop() {
+	t.stopped = true
+}
+
+// NewClock creates a running clock at time zero with normal time scale
+func NewClock() *Clock {
+	c := &Clock{}
+	c.scale = 1.0
+	return c
+}
+
+// Now returns the clock time in seconds
+func (c *Clock) Now() float64 {
+	return c.now
+}
+
+// setNow moves the clock to a time without firing anything, repeating timers are moved to their next call
+// after it as if the clock had run there
+func (c *Clock) setNow(now float64) {
+	c.now = now
+	for _, t := range c.timers {
+		if t.interval <= 0 {
+			continue
+		}
+		for t.at <= now {
+			t.at += t.interval
+		}
+		for t.at-t.interval > now {
+			t.at -= t.interval
+		}
+	}
+}
+
+// SetPaused stops or resumes the clock
+func (c *Clock) SetPaused(paused bool) {
+	c.paused = paused
+}
+
+// IsPaused returns true if the clock is stopped
+func (c *Clock) IsPaused() bool {
+	return c.paused
+}
+
+// SetTimeScale sets how fast clock time passes relative to real time (1 = normal)
+func (c *Clock) SetTimeScale(scale float64) {
+	if scale < 0 {
+		scale = 0
+	}
+	c.scale = scale
+}
+
+// GetTimeScale returns how fast clock time passes relative to real time
+func (c *Clock) GetTimeScale() float64 {
+	return c.scale
+}
+
+// After calls fn once after the given number of clock seconds
+func (c *Clock) After(seconds float64, fn func()) *Timer {
+	t := &Timer{at: c.now + seconds, fn: fn}
+	c.timers = append(c.timers, t)
+	return t
+}
+
+// Every calls fn repeatedly with the given number of clock seconds between calls
+func (c *Clock) Every(seconds float64, fn func()) *Timer {
+	t := &Timer{at: c.now + seconds, interval: seconds, fn: fn}
+	c.timers = append(c.timers, t)
+	return t
+}
+
+// Tick advances the clock by the real elapsed time, firing due timers and sequence steps.
+// Returns the scaled clock time that passed (zero while paused).
+func (c *Clock) Tick(realDt float64) float64 {
+	if c.paused {
+		return 0
+	}
+
+	dt := realDt * c.scale
+	c.now += dt
+
+	// timers may schedule more timers while firing, those wait for the next tick
+	timers := c.timers
+	c.timers = nil
+	for _, t := range timers {
+		for !t.stopped && t.at <= c.now {
+			t.fn()
+			if t.interval <= 0 {
+				t.stopped = true
+			} else {
+				t.at += t.interval
+			}
+		}
+		if !t.stopped {
+			c.timers = append(c.timers, t)
+		}
+	}
+
+	sequences := c.sequences
+	c.sequences = nil
+	for _, s := range sequences {
+		if s.advance(dt) {
+			c.sequences = append(c.sequences, s)
+		}
+	}
+
+	tweens := c.tweens
+	c.tweens = nil
+	for _, t := range tweens {
+		if current := t.Update(dt); current != nil {
+			c.tweens = append(c.tweens, current)
+		}
+	}
+
+	return dt
+}
+
+// Sequence runs steps one after another on the clock, each reporting its progress every tick
+type Sequence struct {
+	steps   []sequenceStep
+	current int
+	elapsed float64
+	stopped bool
+}
+
+type sequenceStep struct {
+	duration float64
+	fn       func(t float64)
+}
+
+// Sequence starts an empty sequence on the clock, add steps to it with Then
+func (c *Clock) Sequence() *Sequence {
+	s := &Sequence{}
+	c.sequences = append(c.sequences, s)
+	return s
+}
+
+// Then appends a step lasting the given clock seconds. fn is called every tick with the step progress
+// from 0 to 1, and always once with exactly 1 when the step ends. Zero length steps run immediately.
+func (s *Sequence) Then(seconds float64, fn func(t float64)) *Sequence {
+	s.steps = append(s.steps, sequenceStep{duration: seconds, fn: fn})
+	return s
+}
+
+// Wait appends a step that does nothing for the given clock seconds
+func (s *Sequence) Wait(seconds float64) *Sequence {
+	return s.Then(seconds, func(float64) {})
+}
+
+// Stop cancels the remaining steps of the sequence
+func (s *Sequence) Stop() {
+	s.stopped = true
+}
+
+// Done returns true if the sequence has finished or was stopped
+func (s *Sequence) Done() bool {
+	return s.stopped || s.current >= len(s.steps)
+}
+
+// advance moves the sequence forward by dt, returns false once it is done
+func (s *Sequence) advance(dt float64) bool {
+	s.elapsed += dt
+	for !s.Done() {
+		step := s.steps[s.current]
+		if s.elapsed < step.duration {
+			step.fn(s.elapsed / step.duration)
+			return true
+		}
+
+		step.fn(1)
+		s.elapsed -= step.duration
+		s.current++
+	}
+	return false
+}
@@ -0,0 +1,106 @@
+package sim
+
+import "math"
+
+const (
+	// head bob cycles per grid cell walked
+	bobFrequency = 1.6
+
+	// head bob height as a fraction of the view height
+	bobAmount = 0.012
+
+	// screen shake offset at full intensity, as a fraction of the view height
+	shakeAmount = 0.03
+)
+
+// Comfort groups the motion comfort options every camera effect checks before moving the view
+type Comfort struct {
+	// HeadBob --bob the view up and down while walking--//
+	HeadBob bool
+
+	// ShakeScale --multiplier for screen shake intensity, 0 disables shake--//
+	ShakeScale float64
+
+	// MaxTurnAccel --largest change in turn speed per tick in radians, 0 for instant turning--//
+	MaxTurnAccel float64
+
+	// MinFOVScale --lowest field of view multiplier effects may narrow the view to, 0 for no floor--//
+	MinFOVScale float64
+
+	// TurnVignette --darken the edges of the view while turning--//
+	TurnVignette bool
+}
+
+// NewComfort creates the default comfort options, with all effects at full strength
+func NewComfort() *Comfort {
+	return &Comfort{HeadBob: true, ShakeScale: 1}
+}
+
+// SetComfort sets the motion comfort options used by the camera
+func (c *Camera) SetComfort(comfort *Comfort) {
+	if comfort == nil {
+		comfort = NewComfort()
+	}
+	c.comfort = comfort
+}
+
+// GetComfort returns the motion comfort options used by the camera
+func (c *Camera) GetComfort() *Comfort {
+	return c.comfort
+}
+
+// Shake starts a screen shake of the given intensity (0 to 1) that fades out over the given seconds.
+// A weaker shake does not cut a stronger one short.
+func (c *Camera) Shake(intensity, seconds float64) {
+	if seconds <= 0 || intensity*c.comfort.ShakeScale <= c.shake {
+		return
+	}
+	c.shake = intensity * c.comfort.ShakeScale
+	c.shakeDecay = c.shake / seconds
+}
+
+// GetViewOffset returns how far the rendered view should be moved on screen for head bob and shake,
+// as fractions of the view height
+func (c *Camera) GetViewOffset() (float64, float64) {
+	var x, y float64
+	if c.comfort.HeadBob && c.onFloor() {
+		y += math.Sin(c.bobPhase*bobFrequency*2*math.Pi) * bobAmount
+	}
+	if c.shake > 0 {
+		x += (c.viewRand.Float64()*2 - 1) * c.shake * shakeAmount
+		y += (c.viewRand.Float64()*2 - 1) * c.shake * shakeAmount
+	}
+	return x, y
+}
+
+// GetTurnSpeed returns the rotation applied during the last tick in radians
+func (c *Camera) GetTurnSpeed() float64 {
+	return c.turnSpeed
+}
+
+// updateComfort fades screen shake, and stops turning if the camera was not rotated since the last update
+func (c *Camera) updateComfort() {
+	if c.shake > 0 {
+		c.shake = math.Max(0, c.shake-c.shakeDecay*c.dt)
+	}
+
+	if !c.turning {
+		c.turnSpeed = 0
+	}
+	c.turning = false
+}
+
+// comfortTurn limits how quickly the turn speed may change towards the requested speed
+func (c *Camera) comfortTurn(rSpeed float64) float64 {
+	if accel := c.comfort.MaxTurnAccel; accel > 0 {
+		rSpeed = math.Max(c.turnSpeed-accel, math.Min(c.turnSpeed+accel, rSpeed))
+	}
+	c.turnSpeed = rSpeed
+	c.turning = true
+	return rSpeed
+}
+
+// comfortFOVScale applies the field of view floor to an effect field of view multiplier
+func (c *Camera) comfortFOVScale(scale float64) float64 {
+	return math.Max(scale, c.comfort.MinFOVScale)
+}
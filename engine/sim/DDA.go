@@ -0,0 +1,99 @@
+package sim
+
+import "math"
+
+// DDA steps a ray through the grid one cell at a time. The renderer and the ray queries of the map share it,
+// so a ray cast for a shot or line of sight meets the same walls the screen shows.
+type DDA struct {
+	// MapX, MapY --grid cell the ray is in--//
+	MapX, MapY int
+
+	// Side --0 if the ray last crossed an x-side (NS) wall into the cell, 1 for a y-side (EW) wall--//
+	Side int
+
+	// Enter --distance to where the ray entered the cell, in lengths of the ray direction, 0 in the first cell--//
+	Enter float64
+
+	stepX, stepY           int
+	sideDistX, sideDistY   float64
+	deltaDistX, deltaDistY float64
+}
+
+// NewDDA starts a ray in the cell of its origin, going in a direction of any length
+func NewDDA(origin, dir Vector2) DDA {
+	d := DDA{MapX: int(origin.X), MapY: int(origin.Y), Side: -1}
+
+	//length of ray from one x or y-side to next x or y-side
+	d.deltaDistX = math.Abs(1 / dir.X)
+	d.deltaDistY = math.Abs(1 / dir.Y)
+
+	//calculate step and initial sideDist
+	if dir.X < 0 {
+		d.stepX = -1
+		d.sideDistX = (origin.X - float64(d.MapX)) * d.deltaDistX
+	} else {
+		d.stepX = 1
+		d.sideDistX = (float64(d.MapX) + 1.0 - origin.X) * d.deltaDistX
+	}
+	if dir.Y < 0 {
+		d.stepY = -1
+		d.sideDistY = (origin.Y - float64(d.MapY)) * d.deltaDistY
+	} else {
+		d.stepY = 1
+		d.sideDistY = (float64(d.MapY) + 1.0 - origin.Y) * d.deltaDistY
+	}
+	return d
+}
+
+// Step moves the ray on to the next map square, in x-direction or in y-direction
+func (d *DDA) Step() {
+	if d.sideDistX < d.sideDistY {
+		d.Enter = d.sideDistX
+		d.sideDistX += d.deltaDistX
+		d.MapX += d.stepX
+		d.Side = 0
+	} else {
+		d.Enter = d.sideDistY
+		d.sideDistY += d.deltaDistY
+		d.MapY += d.stepY
+		d.Side = 1
+	}
+}
+
+// Exit returns the distance to where the ray leaves the cell it is in
+func (d *DDA) Exit() float64 {
+	return math.Min(d.sideDistX, d.sideDistY)
+}
+
+// WallDist returns the distance along the ray, in lengths of its direction, to the near face of the cell it is
+// in on its side. For a camera ray this is the perpendicular distance (oblique distance will give fisheye effect!)
+func (d *DDA) WallDist(origin, dir Vector2) float64 {
+	if d.Side == 0 {
+		return (float64(d.MapX) - origin.X + (1.0-float64(d.stepX))/2.0) / dir.X
+	}
+	return (float64(d.MapY) - origin.Y + (1.0-float64(d.stepY))/2.0) / dir.Y
+}
+
+// WallTexture returns the wall texture index drawn for a grid cell value seen from a side
+func WallTexture(cell, side int) int {
+	texNum := cell - 1 //1 subtracted from it so that texture 0 can be used
+	if texNum < 0 {
+		texNum = 0
+	}
+	//--some supid hacks to make the houses render correctly--//
+	// this corrects textures on two sides of house since the textures are not symmetrical
+	if side == 0 {
+		if texNum == 3 {
+			texNum = 4
+		} else if texNum == 4 {
+			texNum = 3
+		}
+
+		if texNum == 1 {
+			texNum = 4
+		} else if texNum == 2 {
+			texNum = 3
+		}
+	}
+	return texNum
+}
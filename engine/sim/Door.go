@@ -0,0 +1,276 @@
+package sim
+
+import "math"
+
+const (
+	// fraction of the doorway opened or closed per second when a door has no speed set
+	defaultDoorSpeed = 1.0
+
+	// fraction a door must be open before the camera, sprites, rays and noises pass through its cell
+	doorClearance = 0.8
+
+	// loudness of the thunk a door makes when it finishes opening or closing
+	doorLoudness = 6.0
+)
+
+// DoorState is where a door is in its open and close cycle
+type DoorState int
+
+const (
+	DoorClosed DoorState = iota
+	DoorOpening
+	DoorOpen
+	DoorClosing
+)
+
+// Door is a ground level cell whose wall slides sideways into the frame when opened. It is drawn halfway into
+// its cell, across the doorway between the walls on either side, with the wall texture of the cell.
+type Door struct {
+	X, Y int
+
+	// Speed --fraction of the doorway opened or closed per second, 0 for the default--//
+	Speed float64 `json:",omitempty"`
+
+	// Hold --seconds an opened door stays open before closing on its own, 0 keeps it open--//
+	Hold float64 `json:",omitempty"`
+
+	// Locked --the door cannot be opened, a door that is already open can still close--//
+	Locked bool `json:",omitempty"`
+
+	// OnStateChange --called when the door starts or finishes opening or closing--//
+	OnStateChange func(d *Door, state DoorState) `json:"-"`
+
+	mapObj *Map
+	state  DoorState
+	open   float64
+	timer  float64
+
+	// the door runs along x, between walls to its left and right, rather than along y
+	alongX bool
+}
+
+// Open starts sliding the door open, doing nothing if it is locked, open or already opening
+func (d *Door) Open() {
+	if d.Locked || d.state == DoorOpen || d.state == DoorOpening {
+		return
+	}
+	d.setState(DoorOpening)
+}
+
+// Close starts sliding the door shut, doing nothing if it is closed or already closing
+func (d *Door) Close() {
+	if d.state == DoorClosed || d.state == DoorClosing {
+		return
+	}
+	d.setState(DoorClosing)
+}
+
+// Toggle opens a closed or closing door and closes an open or opening one
+func (d *Door) Toggle() {
+	if d.state == DoorClosed || d.state == DoorClosing {
+		d.Open()
+	} else {
+		d.Close()
+	}
+}
+
+// GetState returns where the door is in its open and close cycle
+func (d *Door) GetState() DoorState {
+	return d.state
+}
+
+// GetOpen returns the fraction of the doorway that is open, 0 closed to 1 fully open
+func (d *Door) GetOpen() float64 {
+	return d.open
+}
+
+// IsPassable returns true if the door is open far enough for the camera, sprites, rays and noises to pass
+func (d *Door) IsPassable() bool {
+	return d.open >= doorClearance
+}
+
+// setState changes the state and lets the game know, thunking when the door comes to rest open or shut
+func (d *Door) setState(state DoorState) {
+	d.state = state
+	if state == DoorOpen {
+		d.timer = d.Hold
+	}
+	if (state == DoorOpen || state == DoorClosed) && d.mapObj != nil {
+		thunk := RumbleThunk
+		d.mapObj.EmitNoise(Noise{
+			Pos:      Vector2{X: float64(d.X) + 0.5, Y: float64(d.Y) + 0.5},
+			Loudness: doorLoudness,
+			Source:   d,
+			Caption:  "[door thunks]",
+			Rumble:   &thunk,
+		})
+	}
+	if d.OnStateChange != nil {
+		d.OnStateChange(d, state)
+	}
+}
+
+// update slides the door and closes it once its hold time is up, waiting while anything stands in the doorway
+func (d *Door) update(dt float64) {
+	speed := d.Speed
+	if speed <= 0 {
+		speed = defaultDoorSpeed
+	}
+
+	switch d.state {
+	case DoorOpening:
+		d.open = math.Min(1, d.open+speed*dt)
+		if d.open == 1 {
+			d.setState(DoorOpen)
+		}
+	case DoorOpen:
+		if d.Hold <= 0 {
+			return
+		}
+		d.timer -= dt
+		if d.timer <= 0 && !d.isOccupied() {
+			d.setState(DoorClosing)
+		}
+	case DoorClosing:
+		if d.isOccupied() {
+			// reopen rather than shut on whatever is in the way
+			d.setState(DoorOpening)
+			return
+		}
+		d.open = math.Max(0, d.open-speed*dt)
+		if d.open == 0 {
+			d.setState(DoorClosed)
+		}
+	}
+}
+
+// isOccupied returns true if a sprite or a camera that held the door stands in the doorway
+func (d *Door) isOccupied() bool {
+	m := d.mapObj
+	if m == nil {
+		return false
+	}
+	if m.occupied[[2]int{d.X, d.Y}] {
+		return true
+	}
+	return len(m.spriteGrid) > d.X && len(m.spriteGrid[d.X]) > d.Y && len(m.spriteGrid[d.X][d.Y]) > 0
+}
+
+// Intersect finds where a ray that entered the door cell at distance enter and leaves it at distance exit
+// hits the door, halfway into the cell. Returns the perpendicular distance, the wall side hit, the texture
+// coordinate with the open part slid into the frame, and false if the ray passes through the opening.
+func (d *Door) Intersect(posX, posY, rayDirX, rayDirY, enter, exit float64) (float64, int, float64, bool) {
+	var dist, along float64
+	side := 0
+	if d.alongX {
+		if rayDirY == 0 {
+			return 0, 0, 0, false
+		}
+		side = 1
+		dist = (float64(d.Y) + 0.5 - posY) / rayDirY
+		along = posX + dist*rayDirX
+	} else {
+		if rayDirX == 0 {
+			return 0, 0, 0, false
+		}
+		dist = (float64(d.X) + 0.5 - posX) / rayDirX
+		along = posY + dist*rayDirY
+	}
+	if dist < enter || dist > exit {
+		return 0, 0, 0, false
+	}
+
+	wallX := along - math.Floor(along)
+	if wallX < d.open {
+		return 0, 0, 0, false
+	}
+	return dist, side, wallX - d.open, true
+}
+
+// AddDoor adds a sliding door to a ground level cell, which should hold the wall texture it is drawn with.
+// The door runs across the doorway between the walls either side of the cell.
+func (m *Map) AddDoor(d *Door) {
+	if !m.inBounds(d.X, d.Y) {
+		return
+	}
+	if m.doorGrid == nil {
+		w, h := m.GetSize()
+		m.doorGrid = make([][]*Door, w)
+		for x := range m.doorGrid {
+			m.doorGrid[x] = make([]*Door, h)
+		}
+	}
+	d.mapObj = m
+	d.alongX = !m.IsWalkable(d.X-1, d.Y) && !m.IsWalkable(d.X+1, d.Y)
+	m.doorGrid[d.X][d.Y] = d
+	m.doors = append(m.doors, d)
+}
+
+// RemoveDoor takes the door out of a cell, leaving its wall in place
+func (m *Map) RemoveDoor(x, y int) {
+	d := m.GetDoor(x, y)
+	if d == nil {
+		return
+	}
+	m.doorGrid[x][y] = nil
+	for i, existing := range m.doors {
+		if existing == d {
+			m.doors = append(m.doors[:i], m.doors[i+1:]...)
+			break
+		}
+	}
+}
+
+// GetDoor returns the door in a cell, nil if there is none
+func (m *Map) GetDoor(x, y int) *Door {
+	if m.doorGrid == nil || !m.inBounds(x, y) {
+		return nil
+	}
+	return m.doorGrid[x][y]
+}
+
+// GetDoors returns the doors in the map
+func (m *Map) GetDoors() []*Door {
+	return m.doors
+}
+
+// isOpenDoor returns true if the cell holds a door open far enough to pass through
+func (m *Map) isOpenDoor(x, y int) bool {
+	d := m.GetDoor(x, y)
+	return d != nil && d.IsPassable()
+}
+
+// updateDoors slides the doors of the map, then forgets which doorways cameras stood in
+func (m *Map) updateDoors(dt float64) {
+	for _, d := range m.doors {
+		d.update(dt)
+	}
+	for k := range m.occupied {
+		delete(m.occupied, k)
+	}
+}
+
+// GetUsableDoor returns the door in the first wall cell in front of the camera within use range, or nil
+func (c *Camera) GetUsableDoor() *Door {
+	dirX, dirY := c.dir.X/c.dirLength(), c.dir.Y/c.dirLength()
+	for dist := 0.0; dist <= useRange; dist += 0.1 {
+		x, y := int(c.pos.X+dirX*dist), int(c.pos.Y+dirY*dist)
+		if d := c.mapObj.GetDoor(x, y); d != nil {
+			return d
+		}
+		if !c.mapObj.IsWalkable(x, y) {
+			return nil
+		}
+	}
+	return nil
+}
+
+// holdDoor keeps the door the camera stands in from closing on it
+func (c *Camera) holdDoor() {
+	if d := c.mapObj.GetDoor(int(c.pos.X), int(c.pos.Y)); d != nil {
+		if c.mapObj.occupied == nil {
+			c.mapObj.occupied = make(map[[2]int]bool)
+		}
+		c.mapObj.occupied[[2]int{d.X, d.Y}] = true
+	}
+}
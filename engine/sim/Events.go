@@ -0,0 +1,41 @@
+package sim
+
+// Events holds optional hooks the camera fires as it interacts with the world.
+// Any hook left nil is simply not called.
+type Events struct {
+	// OnSubmerge --called when the camera eye goes below a water surface--//
+	OnSubmerge func()
+
+	// OnSurface --called when the camera eye comes back above a water surface--//
+	OnSurface func()
+
+	// OnAirChange --called whenever the air meter changes, air ranges from 0 (empty) to 1 (full)--//
+	OnAirChange func(air float64)
+
+	// OnAirDepleted --called once when the air meter runs out while submerged--//
+	OnAirDepleted func()
+
+	// OnTileDamage --called each tick the camera stands on a damaging floor tile, with the damage for that tick--//
+	OnTileDamage func(damage float64, tile *TileType)
+
+	// OnDamageFrom --called when the camera is dealt damage from a position in the world, see Damage--//
+	OnDamageFrom func(damage float64, from Vector2)
+
+	// OnCrush --called each tick a moving solid pins the camera against a wall--//
+	OnCrush func(solid *Solid)
+
+	// OnEnterTile --called when the camera moves into a grid cell that has a tile type--//
+	OnEnterTile func(x, y int, tile *TileType)
+
+	// OnCheckpoint --called when a checkpoint is saved--//
+	OnCheckpoint func()
+
+	// OnEffectDamage --called each tick a status effect deals damage, with the damage for that tick--//
+	OnEffectDamage func(damage float64, effect *StatusEffect)
+
+	// OnEffectEnd --called when a status effect expires or is removed--//
+	OnEffectEnd func(effect *StatusEffect)
+
+	// OnFootstep --called at each step while walking on the floor, with the material stepped on--//
+	OnFootstep func(step Footstep)
+}
@@ -0,0 +1,131 @@
+package sim
+
+import "math"
+
+const (
+	// DefaultFOV is the horizontal field of view of a new camera in degrees
+	DefaultFOV = 66.0
+
+	// field of view multiplier while sprinting
+	sprintFOVScale = 1.12
+
+	// seconds to widen when a sprint starts and to settle back when it ends
+	sprintFOVIn  = 0.25
+	sprintFOVOut = 0.35
+)
+
+// FOVKick is a temporary field of view multiplier layered over the base field of view,
+// removed automatically once it has eased back to 1
+type FOVKick struct {
+	scale float64
+	tween *Tween
+
+	//--held kicks stay at their target until released, used by sprint and zoom--//
+	hold   bool
+	target float64
+}
+
+// Stop removes the kick at once, restoring the field of view it was changing
+func (k *FOVKick) Stop() {
+	k.scale = 1
+	k.tween = nil
+}
+
+// SetFOV sets the base horizontal field of view in degrees, clamped to 1 to 179, for FOV settings and
+// zoom. The camera plane is rescaled and the view plane follows on the next update.
+func (c *Camera) SetFOV(degrees float64) {
+	degrees = math.Max(1, math.Min(179, degrees))
+	length := math.Hypot(c.plane.X, c.plane.Y)
+	if length == 0 {
+		return
+	}
+
+	//--plane length relative to the direction length sets the view angle--//
+	target := math.Tan(degrees*math.Pi/360) * c.dirLength()
+	c.plane.X *= target / length
+	c.plane.Y *= target / length
+}
+
+// GetFOV returns the base horizontal field of view in degrees
+func (c *Camera) GetFOV() float64 {
+	return 2 * math.Atan(math.Hypot(c.plane.X, c.plane.Y)/c.dirLength()) * 180 / math.Pi
+}
+
+// KickFOV eases the field of view to scale times the base over in seconds, holds it, then eases it back
+// over out seconds
+func (c *Camera) KickFOV(scale, in, hold, out float64) *FOVKick {
+	k := &FOVKick{scale: 1}
+	k.tween = TweenFloat(&k.scale, scale, in, EaseOutQuad)
+	k.tween.
+		Then(NewTween(hold, Linear, func(float64) {})).
+		Then(TweenFloat(&k.scale, 1, out, EaseInOutQuad))
+
+	c.fovKicks = append(c.fovKicks, k)
+	return k
+}
+
+// PunchFOV briefly narrows (scale below 1) or widens the field of view, snapping to it and settling
+// back over the given seconds, for hits and explosions
+func (c *Camera) PunchFOV(scale, seconds float64) *FOVKick {
+	return c.KickFOV(scale, seconds*0.1, 0, seconds*0.9)
+}
+
+// SprintFOV widens the field of view while active and eases it back to the base once no longer active,
+// call it every tick with whether the camera is sprinting
+func (c *Camera) SprintFOV(active bool) {
+	if active {
+		c.sprintKick = c.holdKick(c.sprintKick, sprintFOVScale, sprintFOVIn, true)
+	} else {
+		c.sprintKick = c.holdKick(c.sprintKick, 1, sprintFOVOut, false)
+	}
+}
+
+// holdKick eases a held kick to the target scale, creating it if needed. Returns the kick,
+// or nil if there was no kick and it would only be released.
+func (c *Camera) holdKick(k *FOVKick, target, seconds float64, hold bool) *FOVKick {
+	if k == nil {
+		if !hold {
+			return nil
+		}
+		k = &FOVKick{scale: 1, target: 1}
+		c.fovKicks = append(c.fovKicks, k)
+	}
+	if k.hold == hold && k.target == target {
+		return k
+	}
+
+	k.hold, k.target = hold, target
+	k.tween = TweenFloat(&k.scale, target, seconds, EaseInOutSine)
+	return k
+}
+
+// fovKickScale returns the combined field of view multiplier of the active kicks
+func (c *Camera) fovKickScale() float64 {
+	scale := 1.0
+	for _, k := range c.fovKicks {
+		scale *= k.scale
+	}
+	return scale
+}
+
+// updateFOVKicks advances the kicks and removes those that have settled back to the base field of view
+func (c *Camera) updateFOVKicks() {
+	dt := c.dt
+
+	active := c.fovKicks[:0]
+	for _, k := range c.fovKicks {
+		if k.tween != nil {
+			k.tween = k.tween.Update(dt)
+		}
+		if k.tween == nil && !k.hold {
+			if k == c.sprintKick {
+				c.sprintKick = nil
+			} else if k == c.zoomKick {
+				c.zoomKick = nil
+			}
+			continue
+		}
+		active = append(active, k)
+	}
+	c.fovKicks = active
+}
@@ -0,0 +1,171 @@
+package sim
+
+import "math"
+
+// Relation is how one faction treats another
+type Relation string
+
+const (
+	RelationFriendly Relation = "friendly"
+	RelationNeutral  Relation = "neutral"
+	RelationHostile  Relation = "hostile"
+)
+
+// PlayerFaction is the faction of the camera
+const PlayerFaction = "player"
+
+// FactionRelation is the relation between two factions, the same both ways
+type FactionRelation struct {
+	A, B     string
+	Relation Relation
+}
+
+// Perception is what a sprite can notice of hostile factions, loaded from map data so different enemies
+// can be made more or less alert without code
+type Perception struct {
+	// ViewAngle --full angle of the view cone in degrees, centered on the sprite direction--//
+	ViewAngle float64 `json:",omitempty"`
+
+	// ViewDistance --furthest distance a target can be seen from--//
+	ViewDistance float64 `json:",omitempty"`
+
+	// HearingRadius --furthest distance a noise can be heard from--//
+	HearingRadius float64 `json:",omitempty"`
+
+	// Memory --seconds the last known position of a target is remembered after losing track of it--//
+	Memory float64 `json:",omitempty"`
+}
+
+// NewPerception creates the perception of an ordinary guard
+func NewPerception() *Perception {
+	return &Perception{ViewAngle: 90, ViewDistance: 12, HearingRadius: 8, Memory: 5}
+}
+
+// awareness is what a perceiving sprite knows of its target
+type awareness struct {
+	// last position the target was seen or heard at
+	pos Vector2
+
+	// seconds until the position is forgotten
+	remaining float64
+
+	// target in view during the last perception update
+	sees bool
+}
+
+// SetRelation sets how two factions treat each other
+func (m *Map) SetRelation(a, b string, r Relation) {
+	if m.relations == nil {
+		m.relations = make(map[[2]string]Relation)
+	}
+	m.relations[relationKey(a, b)] = r
+}
+
+// GetRelation returns how two factions treat each other. A faction is friendly to itself, sprites without
+// a faction are neutral to everyone and factions without a set relation are neutral.
+func (m *Map) GetRelation(a, b string) Relation {
+	if a == "" || b == "" {
+		return RelationNeutral
+	}
+	if a == b {
+		return RelationFriendly
+	}
+	if r, ok := m.relations[relationKey(a, b)]; ok {
+		return r
+	}
+	return RelationNeutral
+}
+
+// GetRelations returns every relation set between factions
+func (m *Map) GetRelations() []FactionRelation {
+	relations := make([]FactionRelation, 0, len(m.relations))
+	for k, r := range m.relations {
+		relations = append(relations, FactionRelation{A: k[0], B: k[1], Relation: r})
+	}
+	return relations
+}
+
+// relationKey orders a pair of factions so the relation is the same both ways
+func relationKey(a, b string) [2]string {
+	if b < a {
+		a, b = b, a
+	}
+	return [2]string{a, b}
+}
+
+// UpdatePerception lets every sprite with a Perception look for a target of the given faction, usually
+// the camera as PlayerFaction. Sprites hostile to the faction that see the target remember where it is,
+// and forget it once unseen for their memory time. Call once per tick after Update.
+func (m *Map) UpdatePerception(target Vector2, faction string) {
+	for _, s := range m.sprite {
+		p := s.Perception
+		if p == nil {
+			continue
+		}
+
+		a := &s.awareness
+		a.sees = m.GetRelation(s.Faction, faction) == RelationHostile && s.canSee(m, p, target)
+		if a.sees {
+			a.pos, a.remaining = target, p.Memory
+		} else if a.remaining > 0 {
+			a.remaining -= m.lastDt
+		}
+	}
+}
+
+// hearNoise makes sprites with a Perception within hearing range of a noise turn their attention to it,
+// unless it was made by a sprite of a friendly faction
+func (m *Map) hearNoise(n Noise, loudness [][]float64) {
+	source, _ := n.Source.(*Sprite)
+	for _, s := range m.sprite {
+		p := s.Perception
+		if p == nil || s == source || s.awareness.sees {
+			continue
+		}
+		if source != nil && m.GetRelation(s.Faction, source.Faction) == RelationFriendly {
+			continue
+		}
+		x, y := int(s.X), int(s.Y)
+		if !m.inBounds(x, y) || loudness[x][y] <= 0 || math.Hypot(n.Pos.X-s.X, n.Pos.Y-s.Y) > p.HearingRadius {
+			continue
+		}
+		s.awareness.pos, s.awareness.remaining = n.Pos, p.Memory
+	}
+}
+
+// hasPerceivers returns true if any sprite in the map has a Perception
+func (m *Map) hasPerceivers() bool {
+	for _, s := range m.sprite {
+		if s.Perception != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// canSee returns true if the target is within view distance, inside the view cone and in line of sight.
+// A sprite without a direction sees all around.
+func (s *Sprite) canSee(m *Map, p *Perception, target Vector2) bool {
+	dx, dy := target.X-s.X, target.Y-s.Y
+	dist := math.Hypot(dx, dy)
+	if dist > p.ViewDistance {
+		return false
+	}
+	if dirLen := math.Hypot(s.Dir.X, s.Dir.Y); dirLen > 0 && dist > 0 {
+		cos := (dx*s.Dir.X + dy*s.Dir.Y) / (dist * dirLen)
+		if cos < math.Cos(p.ViewAngle/2*math.Pi/180) {
+			return false
+		}
+	}
+	return m.HasLineOfSight(Vector2{X: s.X, Y: s.Y}, target)
+}
+
+// CanSeeTarget returns true if the sprite saw its target in the last perception update
+func (s *Sprite) CanSeeTarget() bool {
+	return s.awareness.sees
+}
+
+// GetLastKnown returns where the sprite last saw or heard its target, false once it has forgotten
+func (s *Sprite) GetLastKnown() (Vector2, bool) {
+	return s.awareness.pos, s.awareness.sees || s.awareness.remaining > 0
+}
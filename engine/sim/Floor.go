@@ -0,0 +1,30 @@
+package sim
+
+// SetFloorTexture sets the floor texture of the grid cell at x, y as an index into the floor textures
+func (m *Map) SetFloorTexture(x, y, texture int) {
+	if !m.inBounds(x, y) {
+		return
+	}
+	m.floorMap[x][y] = texture
+}
+
+// GetFloorTexture returns the floor texture of the grid cell at x, y as an index into the floor textures,
+// 0 (the first floor texture) outside the map
+func (m *Map) GetFloorTexture(x, y int) int {
+	if !m.inBounds(x, y) {
+		return 0
+	}
+	return m.floorMap[x][y]
+}
+
+// hasFloorTextures returns true if any cell has a floor other than the first texture
+func (m *Map) hasFloorTextures() bool {
+	for _, col := range m.floorMap {
+		for _, texture := range col {
+			if texture != 0 {
+				return true
+			}
+		}
+	}
+	return false
+}
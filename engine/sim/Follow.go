@@ -0,0 +1,19 @@
+package sim
+
+// Follow moves the camera to the position, facing, height and pitch of another camera in the same map,
+// keeping its own field of view, for second views of what the player sees
+func (c *Camera) Follow(other *Camera) {
+	fov := c.GetFOV()
+	*c.pos, *c.dir, *c.plane = *other.pos, *other.dir, *other.plane
+	c.posZ = other.posZ
+	c.cellX, c.cellY = int(c.pos.X), int(c.pos.Y)
+	c.pitch = other.pitch
+	c.SetFOV(fov)
+}
+
+// FollowBehind follows another camera looking the way it came from, flipped left to right like a
+// rear-view mirror so what is behind on the left shows on the left
+func (c *Camera) FollowBehind(other *Camera) {
+	c.Follow(other)
+	c.dir.X, c.dir.Y = -c.dir.X, -c.dir.Y
+}
@@ -0,0 +1,74 @@
+package sim
+
+import "math"
+
+// Material is what the floor of a grid cell is made of, setting the sound of footsteps on it
+type Material string
+
+// floor materials known to the engine, games can use any other name as well
+const (
+	MaterialStone Material = "stone"
+	MaterialWood  Material = "wood"
+	MaterialWater Material = "water"
+	MaterialMetal Material = "metal"
+	MaterialGrass Material = "grass"
+)
+
+// distance walked in grid cells between footsteps, two steps per head bob cycle
+const footstepDistance = 1 / (2 * bobFrequency)
+
+// Footstep is one step of the camera on the floor
+type Footstep struct {
+	// Pos --grid position of the step--//
+	Pos Vector2
+
+	// Material --material of the floor stepped on--//
+	Material Material
+
+	// Left --true for a step of the left foot, steps alternate feet--//
+	Left bool
+}
+
+// SetDefaultMaterial sets the floor material of cells whose tile type does not set one
+func (m *Map) SetDefaultMaterial(material Material) {
+	m.defaultMaterial = material
+}
+
+// GetMaterial returns the floor material of the grid cell at x, y: the material of its tile type,
+// water for water tiles, otherwise the default material of the map (stone if not set)
+func (m *Map) GetMaterial(x, y int) Material {
+	if t := m.GetTileType(x, y); t != nil {
+		if t.Material != "" {
+			return t.Material
+		}
+		if t.Water {
+			return MaterialWater
+		}
+	}
+	if m.defaultMaterial == "" {
+		return MaterialStone
+	}
+	return m.defaultMaterial
+}
+
+// walk adds to the distance walked for head bob and fires a footstep each time a step's distance
+// has been covered on the floor
+func (c *Camera) walk(oldX, oldY float64) {
+	dist := math.Hypot(c.pos.X-oldX, c.pos.Y-oldY)
+	c.bobPhase += dist
+
+	if !c.onFloor() {
+		return
+	}
+	c.stepDistance += dist
+	if c.stepDistance < footstepDistance {
+		return
+	}
+	c.stepDistance = math.Mod(c.stepDistance, footstepDistance)
+	c.stepLeft = !c.stepLeft
+
+	if c.Events.OnFootstep != nil {
+		x, y := int(c.pos.X), int(c.pos.Y)
+		c.Events.OnFootstep(Footstep{Pos: *c.pos, Material: c.mapObj.GetMaterial(x, y), Left: c.stepLeft})
+	}
+}
@@ -0,0 +1,141 @@
+package sim
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+)
+
+// HitscanOptions describes a shot fired with Map.Hitscan
+type HitscanOptions struct {
+	// Pellets --number of rays fired, at least one (e.g. 8 for a shotgun)--//
+	Pellets int
+
+	// Spread --full angle of the cone the pellets are scattered in, in radians, 0 fires straight--//
+	Spread float64
+
+	// Range --furthest distance a pellet can hit, 0 for no limit--//
+	Range float64
+
+	// Damage --damage a pellet deals to the first thing it hits--//
+	Damage float64
+
+	// Penetration --number of walls or sprites a pellet passes through after its first hit--//
+	Penetration int
+
+	// Falloff --fraction of the damage a pellet keeps after passing through each hit--//
+	Falloff float64
+
+	// Rand --source of the pellet scatter, nil to use the map's own--//
+	Rand *rand.Rand
+}
+
+// ShotHit is one thing a pellet of a hitscan shot hit
+type ShotHit struct {
+	RayHit
+
+	// Pellet --index of the pellet that made the hit--//
+	Pellet int
+
+	// Damage --damage dealt by the pellet at this hit, after falloff from earlier penetrations--//
+	Damage float64
+}
+
+// Hitscan fires the pellets of a shot from origin scattered around dir and returns everything they hit,
+// ordered by pellet and then by distance. Each pellet stops at its first wall or ray-blocking sprite
+// unless it can still penetrate, in which case it carries on with reduced damage.
+func (m *Map) Hitscan(origin, dir Vector2, opts HitscanOptions) []ShotHit {
+	pellets := opts.Pellets
+	if pellets < 1 {
+		pellets = 1
+	}
+	maxDist := opts.Range
+	if maxDist <= 0 {
+		maxDist = math.Inf(1)
+	}
+	random := m.rand.Float64
+	if opts.Rand != nil {
+		random = opts.Rand.Float64
+	}
+
+	var hits []ShotHit
+	for p := 0; p < pellets; p++ {
+		pelletDir := dir
+		if opts.Spread > 0 {
+			angle := (random() - 0.5) * opts.Spread
+			sin, cos := math.Sin(angle), math.Cos(angle)
+			pelletDir = Vector2{X: dir.X*cos - dir.Y*sin, Y: dir.X*sin + dir.Y*cos}
+		}
+
+		damage := opts.Damage
+		left := opts.Penetration
+		m.traceRay(origin, pelletDir, maxDist, func(hit RayHit) bool {
+			hits = append(hits, ShotHit{RayHit: hit, Pellet: p, Damage: damage})
+			damage *= opts.Falloff
+			left--
+			return left >= 0
+		})
+	}
+	return hits
+}
+
+// traceRay steps the DDA from origin for up to maxDist and calls fn with every wall and ray-blocking sprite
+// the ray passes, nearest first, until fn returns false. A run of neighbouring wall cells counts as one wall.
+func (m *Map) traceRay(origin, dir Vector2, maxDist float64, fn func(hit RayHit) bool) {
+	length := math.Sqrt(dir.X*dir.X + dir.Y*dir.Y)
+	if length == 0 {
+		return
+	}
+	rayDirX, rayDirY := dir.X/length, dir.Y/length
+	point := func(d float64) Vector2 {
+		return Vector2{X: origin.X + rayDirX*d, Y: origin.Y + rayDirY*d}
+	}
+
+	dda := NewDDA(origin, Vector2{X: rayDirX, Y: rayDirY})
+
+	//--sprites found but not yet reported, a sprite is found no later than the cell its hit point is in--//
+	var pending []RayHit
+	seen := make(map[*Sprite]bool)
+	flush := func(upTo float64) bool {
+		sort.Slice(pending, func(i, j int) bool { return pending[i].Distance < pending[j].Distance })
+		for len(pending) > 0 && pending[0].Distance <= upTo {
+			if !fn(pending[0]) {
+				return false
+			}
+			pending = pending[1:]
+		}
+		return true
+	}
+
+	inWall := m.inBounds(dda.MapX, dda.MapY) && m.worldMap[dda.MapX][dda.MapY] > 0 && !m.isOpenDoor(dda.MapX, dda.MapY)
+	for m.inBounds(dda.MapX, dda.MapY) && dda.Enter < maxDist {
+		for _, s := range m.spritesInCell(dda.MapX, dda.MapY) {
+			if !s.BlocksRays || seen[s] {
+				continue
+			}
+			seen[s] = true
+			if d, ok := rayCircle(origin, rayDirX, rayDirY, s.X, s.Y, s.Radius); ok && d <= maxDist {
+				pending = append(pending, RayHit{MapX: dda.MapX, MapY: dda.MapY, Point: point(d), Distance: d, Texture: -1, Sprite: s})
+			}
+		}
+
+		dda.Step()
+		if !flush(math.Min(dda.Enter, maxDist)) || dda.Enter > maxDist {
+			return
+		}
+
+		mapX, mapY := dda.MapX, dda.MapY
+		wall := m.inBounds(mapX, mapY) && m.worldMap[mapX][mapY] > 0 && !m.isOpenDoor(mapX, mapY)
+		if wall && !inWall {
+			hit := RayHit{
+				MapX: mapX, MapY: mapY, Side: dda.Side, Point: point(dda.Enter), Distance: dda.Enter,
+				Texture: WallTexture(m.worldMap[mapX][mapY], dda.Side), Door: m.GetDoor(mapX, mapY),
+			}
+			if !fn(hit) {
+				return
+			}
+		}
+		inWall = wall
+	}
+	flush(maxDist)
+}
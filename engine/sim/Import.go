@@ -0,0 +1,59 @@
+package sim
+
+import "image"
+
+// Spawn is where an imported level starts the player
+type Spawn struct {
+	Pos Vector2
+	Dir Vector2
+}
+
+// ImportTextures maps the walls and objects of a level from another game to engine textures
+type ImportTextures struct {
+	// Walls --texture number for each Wolf3D wall tile--//
+	Walls map[int]int
+
+	// Named --texture number for each WAD wall texture name--//
+	Named map[string]int
+
+	// Default --texture number for walls not in the table, 1 if not set--//
+	Default int
+
+	// Door --texture number for Wolf3D door tiles, 0 leaves doorways open--//
+	Door int
+
+	// Objects --sprite texture for each Wolf3D object tile or WAD thing type, others are skipped--//
+	Objects map[int]image.Image
+}
+
+// wall returns the texture number for a Wolf3D wall tile
+func (t *ImportTextures) wall(tile int) int {
+	if texNum, ok := t.Walls[tile]; ok {
+		return texNum
+	}
+	return t.defaultWall()
+}
+
+// named returns the texture number for a WAD wall texture name
+func (t *ImportTextures) named(name string) int {
+	if texNum, ok := t.Named[name]; ok {
+		return texNum
+	}
+	return t.defaultWall()
+}
+
+func (t *ImportTextures) defaultWall() int {
+	if t.Default == 0 {
+		return 1
+	}
+	return t.Default
+}
+
+// importedMap creates a single level map from an imported grid with sprites for the objects found in it
+func importedMap(tex Textures, grid [][]int, sprites []*Sprite) *Map {
+	m := NewMapFromLevels(tex, grid)
+	m.sprite = sprites
+	m.numSprites = len(m.sprite)
+	m.indexSprites()
+	return m
+}
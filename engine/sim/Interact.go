@@ -0,0 +1,73 @@
+package sim
+
+import "math"
+
+const (
+	// maximum distance a sprite can be used from
+	useRange = 1.5
+
+	// cosine of the widest angle off the view direction a sprite can be used at
+	useCone = 0.85
+)
+
+// Interaction is the result of using an interactable sprite
+type Interaction struct {
+	// Dialogue --lines of text to show one after another, if any--//
+	Dialogue []string
+
+	// Action --game defined action id to perform, if any--//
+	Action string
+}
+
+// GetUsable returns the nearest interactable sprite in front of the camera within use range, or nil
+func (c *Camera) GetUsable() *Sprite {
+	var nearest *Sprite
+	nearestDist := useRange
+
+	for _, s := range c.mapObj.SpritesNear(c.pos.X, c.pos.Y, useRange) {
+		if s.OnUse == nil {
+			continue
+		}
+
+		dx, dy := s.X-c.pos.X, s.Y-c.pos.Y
+		dist := math.Sqrt(dx*dx + dy*dy)
+		if dist >= nearestDist {
+			continue
+		}
+		if dist > 0 && (dx*c.dir.X+dy*c.dir.Y)/(dist*c.dirLength()) < useCone {
+			// not facing it
+			continue
+		}
+		if hit := c.mapObj.castRay(*c.pos, Vector2{X: dx, Y: dy}, dist); hit != nil && hit.Sprite != s {
+			// something in the way
+			continue
+		}
+
+		nearest = s
+		nearestDist = dist
+	}
+
+	return nearest
+}
+
+// Use interacts with the nearest usable sprite in front of the camera.
+// Returns the sprite and its interaction, or nil if there was nothing to use.
+func (c *Camera) Use() (*Sprite, *Interaction) {
+	s := c.GetUsable()
+	if s == nil {
+		return nil, nil
+	}
+	return s, s.OnUse(c)
+}
+
+// Damage deals damage to the camera from a position in the world, such as an attacking sprite,
+// by firing the OnDamageFrom event
+func (c *Camera) Damage(damage float64, from Vector2) {
+	if c.Events.OnDamageFrom != nil && !c.invulnerable {
+		c.Events.OnDamageFrom(damage, from)
+	}
+}
+
+func (c *Camera) dirLength() float64 {
+	return math.Sqrt(c.dir.X*c.dir.X + c.dir.Y*c.dir.Y)
+}
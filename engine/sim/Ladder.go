@@ -0,0 +1,60 @@
+package sim
+
+const (
+	// distance in front of the camera a ladder can be grabbed from
+	ladderReach = 0.6
+
+	// climbing speed relative to walking speed
+	climbSpeedFactor = 0.5
+)
+
+// facingLadder returns the grid cell of the ladder in reach in front of the camera
+func (c *Camera) facingLadder() (x, y int, ok bool) {
+	x = int(c.pos.X + c.dir.X*ladderReach)
+	y = int(c.pos.Y + c.dir.Y*ladderReach)
+	if x == int(c.pos.X) && y == int(c.pos.Y) {
+		return x, y, false
+	}
+
+	t := c.mapObj.GetTileType(x, y)
+	return x, y, t != nil && t.Ladder
+}
+
+// onLadder returns true if the camera is hanging on a ladder above the ground
+func (c *Camera) onLadder() bool {
+	x, y, ok := c.facingLadder()
+	if !ok {
+		return false
+	}
+	return c.posZ > c.groundHeight(int(c.pos.X), int(c.pos.Y)) && c.posZ < c.stackHeight(x, y)
+}
+
+// climb moves the camera up (positive speed) or down (negative speed) a ladder it is facing.
+// Returns false when there is no ladder to climb so the caller can move normally instead.
+func (c *Camera) climb(speed float64) bool {
+	x, y, ok := c.facingLadder()
+	if !ok {
+		return false
+	}
+
+	top := c.stackHeight(x, y)
+	ground := c.groundHeight(int(c.pos.X), int(c.pos.Y))
+	if speed > 0 && !c.isBlocked(x, y) {
+		// reached the top, step off onto the ledge
+		return false
+	}
+	if speed < 0 && c.posZ <= ground {
+		// reached the bottom, back away from the ladder
+		return false
+	}
+
+	c.posZ += speed * climbSpeedFactor
+	if c.posZ > top {
+		c.posZ = top
+	} else if c.posZ < ground {
+		c.posZ = ground
+	}
+	c.velZ = 0
+
+	return true
+}
@@ -0,0 +1,119 @@
+package sim
+
+// historyFrame is where the sprites of a map were at a clock time
+type historyFrame struct {
+	time      float64
+	positions map[*Sprite]Vector2
+}
+
+// positionHistory is the sprite positions of the last few clock seconds, oldest first
+type positionHistory struct {
+	length float64
+	frames []historyFrame
+}
+
+// SetHistoryLength keeps the positions of the sprites for the given clock seconds, recorded every update,
+// so shots can be checked against where a client saw them. 0 stops recording and drops the history.
+func (m *Map) SetHistoryLength(seconds float64) {
+	if seconds <= 0 {
+		m.history = nil
+		return
+	}
+	if m.history == nil {
+		m.history = &positionHistory{}
+	}
+	m.history.length = seconds
+}
+
+// GetHistoryLength returns the clock seconds of sprite positions kept, 0 when not recording
+func (m *Map) GetHistoryLength() float64 {
+	if m.history == nil {
+		return 0
+	}
+	return m.history.length
+}
+
+// recordHistory stores where the sprites are now and drops the frames older than the history length
+func (m *Map) recordHistory() {
+	h := m.history
+	if h == nil {
+		return
+	}
+	now := m.clock.Now()
+	f := historyFrame{time: now, positions: make(map[*Sprite]Vector2, len(m.sprite))}
+	for _, s := range m.sprite {
+		f.positions[s] = Vector2{X: s.X, Y: s.Y}
+	}
+	h.frames = append(h.frames, f)
+
+	// keep one frame at or before the oldest time so it can still be interpolated to
+	drop := 0
+	for drop+1 < len(h.frames) && h.frames[drop+1].time <= now-h.length {
+		drop++
+	}
+	h.frames = h.frames[drop:]
+}
+
+// PositionAt returns where a sprite was at a past clock time, interpolated between the recorded updates.
+// Times before the history are clamped to its oldest frame and later times give the current position.
+// Returns false if the sprite was not in the map at that time.
+func (m *Map) PositionAt(s *Sprite, time float64) (Vector2, bool) {
+	h := m.history
+	if h == nil || len(h.frames) == 0 || time >= h.frames[len(h.frames)-1].time {
+		return Vector2{X: s.X, Y: s.Y}, true
+	}
+
+	if time <= h.frames[0].time {
+		pos, ok := h.frames[0].positions[s]
+		return pos, ok
+	}
+	for i := len(h.frames) - 1; i > 0; i-- {
+		a, b := h.frames[i-1], h.frames[i]
+		if time < a.time {
+			continue
+		}
+		from, okA := a.positions[s]
+		to, okB := b.positions[s]
+		if !okA {
+			// spawned after the time
+			return to, false
+		}
+		if !okB {
+			return from, true
+		}
+		t := (time - a.time) / (b.time - a.time)
+		return Vector2{X: from.X + (to.X-from.X)*t, Y: from.Y + (to.Y-from.Y)*t}, true
+	}
+	return Vector2{X: s.X, Y: s.Y}, true
+}
+
+// HitscanAt fires a shot against the sprites as they were at a past clock time, for a server checking a
+// shot a client fired at what it saw, usually the current time less the latency of the client. Sprites
+// are moved back for the shot only, those not in the map at that time cannot be hit, and walls are as
+// they are now.
+func (m *Map) HitscanAt(time float64, origin, dir Vector2, opts HitscanOptions) []ShotHit {
+	if m.history == nil || len(m.history.frames) == 0 {
+		return m.Hitscan(origin, dir, opts)
+	}
+
+	//--sprites not there yet stop blocking the shot--//
+	current := make([]Vector2, len(m.sprite))
+	blocks := make([]bool, len(m.sprite))
+	for i, s := range m.sprite {
+		current[i], blocks[i] = Vector2{X: s.X, Y: s.Y}, s.BlocksRays
+		if pos, ok := m.PositionAt(s, time); ok {
+			s.X, s.Y = pos.X, pos.Y
+		} else {
+			s.BlocksRays = false
+		}
+	}
+	m.indexSprites()
+
+	hits := m.Hitscan(origin, dir, opts)
+
+	for i, s := range m.sprite {
+		s.X, s.Y, s.BlocksRays = current[i].X, current[i].Y, blocks[i]
+	}
+	m.indexSprites()
+	return hits
+}
@@ -0,0 +1,39 @@
+package sim
+
+import "math"
+
+const (
+	// width of the soft edge around light shafts, in grid cells
+	shaftSoftness = 0.3
+)
+
+// FloorLight returns the extra light on the floor at the grid position from 0 (none) to 1 (full),
+// from the Light of the tile type it is on and the shafts cast by nearby skylight tiles
+func (m *Map) FloorLight(x, y float64) float64 {
+	cx, cy := int(math.Floor(x)), int(math.Floor(y))
+
+	light := 0.0
+	if tile := m.GetTileType(cx, cy); tile != nil {
+		light = tile.Light
+	}
+
+	//--shafts spill past their tile by the soft edge, so neighbouring skylights count too--//
+	for nx := cx - 1; nx <= cx+1; nx++ {
+		for ny := cy - 1; ny <= cy+1; ny++ {
+			tile := m.GetTileType(nx, ny)
+			if tile == nil || !tile.Skylight {
+				continue
+			}
+
+			// signed distance to the tile edge, negative inside
+			dx := math.Abs(x-(float64(nx)+0.5)) - 0.5
+			dy := math.Abs(y-(float64(ny)+0.5)) - 0.5
+			dist := math.Max(dx, dy)
+
+			shaft := 0.5 - dist/shaftSoftness
+			light = math.Max(light, math.Min(1, math.Max(0, shaft)))
+		}
+	}
+
+	return math.Min(1, light)
+}
@@ -0,0 +1,45 @@
+package sim
+
+import "os"
+
+// Progress is told how many items a loader has done out of its total, called from the loading goroutine
+type Progress func(done, total int)
+
+// Loader is content loaded on a background goroutine while a loading screen is up
+type Loader interface {
+	// Load loads the content, calling progress as items are done, and returns the first error
+	Load(progress Progress) error
+}
+
+// LoaderFunc lets a function be used as a Loader
+type LoaderFunc func(progress Progress) error
+
+// Load calls the function
+func (f LoaderFunc) Load(progress Progress) error {
+	return f(progress)
+}
+
+// MapLoader reads a map JSON file, Map is set once Load returns without an error
+type MapLoader struct {
+	Path string
+	Tex  Textures
+	Map  *Map
+}
+
+// Load reads and decodes the map file
+func (l *MapLoader) Load(progress Progress) error {
+	progress(0, 1)
+	f, err := os.Open(l.Path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	m, err := LoadMapJSON(l.Tex, f)
+	if err != nil {
+		return err
+	}
+	l.Map = m
+	progress(1, 1)
+	return nil
+}
@@ -0,0 +1,530 @@
+package sim
+
+import (
+	"image"
+	"math"
+	"math/rand"
+)
+
+type Map struct {
+	//--wall level grids from the ground up, worldMap is the ground level--//
+	levels   [][][]int
+	worldMap [][]int
+
+	// RepeatTopLevel --levels above the authored ones repeat the top grid, otherwise they are empty--//
+	RepeatTopLevel bool
+
+	// OnTileChange --called after SetTile changes a cell, with the level and the old and new values--//
+	OnTileChange func(x, y, level, old, value int)
+
+	//--cells changed with SetTile, by cell and level, kept for snapshots--//
+	tileChanges map[[3]int]*TileChange
+
+	//--special floor tile behaviors (water, etc.) by grid cell--//
+	tileMap   [][]int
+	tileTypes map[int]*TileType
+
+	//--floor texture index of each grid cell--//
+	floorMap [][]int
+
+	//--moving platforms and walls--//
+	solids []*Solid
+
+	//--named waypoint paths for patrols and moving solids--//
+	paths map[string]*Path
+
+	//--simulation clock for timers and sequences--//
+	clock *Clock
+
+	//--mission goals--//
+	objectives *Objectives
+
+	//--markers guiding the player to objectives--//
+	waypoints []*Waypoint
+
+	//--entities listening for noises--//
+	listeners []NoiseListener
+
+	//--sprite spawners and their waves--//
+	spawners []*Spawner
+
+	//--boss fights and the doors they seal--//
+	arenas []*Arena
+
+	//--placed cameras whose views can be rendered into textures--//
+	securityCameras []*SecurityCamera
+
+	//--sliding doors, by grid cell, and the doorways cameras stood in since the last update--//
+	doors    []*Door
+	doorGrid [][]*Door
+	occupied map[[2]int]bool
+
+	//--relations between the factions of sprites and the player--//
+	relations map[[2]string]Relation
+
+	//--clock seconds that passed in the last update--//
+	lastDt float64
+
+	//--random numbers drawn by the simulation, seeded so games can repeat it--//
+	rand    *rand.Rand
+	randSrc *randSource
+
+	//--past sprite positions kept for rewinding shots, nil when not recording--//
+	history *positionHistory
+
+	//--flat surfaces at any height (table tops, platforms)--//
+	surfaces []*Surface
+
+	//--heightmap regions rendered voxel-space style--//
+	terrain []*Terrain
+
+	//--music tracks by intensity--//
+	music MapMusic
+
+	//--echo heard in each part of the map--//
+	defaultReverb ReverbPreset
+	reverbRegions []*ReverbRegion
+
+	//--floor material of cells whose tile type does not set one--//
+	defaultMaterial Material
+
+	//--ceiling texture of cells whose tile type does not set one, 1 based, 0 for open sky--//
+	ceiling int
+
+	//--sprites overlapping each grid cell, rebuilt every update--//
+	spriteGrid [][][]*Sprite
+
+	sprite     []*Sprite
+	numSprites int
+
+	//--highest sprite id given out, see Sprite.GetID--//
+	lastSpriteID int
+
+	tex Textures
+}
+
+// NewMapFromGrids creates a map from the ground, middle and upper level grids with no sprites or special tiles.
+// The upper grid keeps extending up for any levels above it.
+func NewMapFromGrids(tex Textures, worldMap, midMap, upMap [][]int) *Map {
+	m := NewMapFromLevels(tex, worldMap, midMap, upMap)
+	m.RepeatTopLevel = true
+	return m
+}
+
+// NewMapFromLevels creates a map from any number of wall level grids listed from the ground up,
+// with no sprites or special tiles. Levels above the last grid are empty unless RepeatTopLevel is set.
+func NewMapFromLevels(tex Textures, levels ...[][]int) *Map {
+	m := &Map{}
+	m.tex = tex
+
+	m.levels = levels
+	if len(levels) > 0 {
+		m.worldMap = levels[0]
+	}
+
+	m.tileMap = makeGrid(m.GetSize())
+	m.floorMap = makeGrid(m.GetSize())
+	m.clock = NewClock()
+	m.SetSeed(1)
+
+	return m
+}
+
+// NewMap creates the sample map
+func NewMap(tex Textures) *Map {
+	worldMap := [][]int{
+		{1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1},
+		{1, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 1},
+		{1, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 1},
+		{1, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 2, 3, 2, 3, 1},
+		{1, 0, 0, 0, 0, 0, 0, 0, 0, 0, 1, 0, 0, 0, 0, 0, 0, 0, 0, 3, 2, 3, 2, 1},
+		{1, 0, 0, 0, 0, 0, 0, 0, 0, 1, 1, 1, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 1},
+		{1, 0, 0, 0, 0, 0, 0, 0, 0, 0, 1, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 1},
+		{1, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 1},
+		{1, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 1},
+		{1, 0, 0, 0, 0, 0, 0, 1, 1, 1, 0, 0, 2, 3, 0, 0, 0, 0, 0, 0, 0, 0, 0, 1},
+		{1, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 3, 2, 0, 0, 0, 0, 0, 0, 0, 0, 0, 1},
+		{1, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 1},
+		{1, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 1},
+		{1, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 1, 0, 0, 0, 0, 0, 0, 0, 0, 1},
+		{1, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 1, 0, 0, 0, 0, 0, 0, 0, 1},
+		{1, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 1, 0, 0, 0, 0, 0, 0, 1},
+		{1, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 1, 0, 0, 0, 0, 0, 1},
+		{1, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 1},
+		{1, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 1},
+		{1, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 1},
+		{1, 0, 0, 1, 0, 0, 0, 0, 0, 1, 1, 0, 1, 1, 0, 0, 0, 0, 0, 1, 1, 0, 1, 1},
+		{1, 0, 1, 0, 1, 0, 0, 0, 0, 1, 1, 0, 1, 1, 0, 0, 1, 0, 0, 1, 0, 0, 0, 1},
+		{1, 0, 0, 0, 0, 0, 0, 0, 0, 1, 1, 0, 1, 1, 0, 0, 0, 0, 0, 1, 0, 0, 0, 1},
+		{1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1},
+	}
+
+	midMap := [][]int{
+		{1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1},
+		{1, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 1},
+		{1, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 1},
+		{1, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 4, 5, 4, 3, 1},
+		{1, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 5, 4, 5, 2, 1},
+		{1, 0, 0, 0, 0, 0, 0, 0, 0, 0, 1, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 1},
+		{1, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 1},
+		{1, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 1},
+		{1, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 1},
+		{1, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 4, 5, 0, 0, 0, 0, 0, 0, 0, 0, 0, 1},
+		{1, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 5, 4, 0, 0, 0, 0, 0, 0, 0, 0, 0, 1},
+		{1, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 1},
+		{1, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 1},
+		{1, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 1},
+		{1, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 1},
+		{1, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 1},
+		{1, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 1},
+		{1, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 1},
+		{1, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 1},
+		{1, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 1},
+		{1, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 1, 1, 0, 1, 1},
+		{1, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 1, 0, 0, 0, 1},
+		{1, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 1, 0, 0, 0, 1},
+		{1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1},
+	}
+
+	upMap := [][]int{
+		{1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1},
+		{1, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 1},
+		{1, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 1},
+		{1, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 1},
+		{1, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 1},
+		{1, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 1},
+		{1, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 1},
+		{1, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 1},
+		{1, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 1},
+		{1, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 1},
+		{1, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 1},
+		{1, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 1},
+		{1, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 1},
+		{1, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 1},
+		{1, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 1},
+		{1, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 1},
+		{1, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 1},
+		{1, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 1},
+		{1, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 1},
+		{1, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 1},
+		{1, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 1},
+		{1, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 1},
+		{1, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 1},
+		{1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1},
+	}
+
+	m := NewMapFromGrids(tex, worldMap, midMap, upMap)
+
+	//--the portal hums now and then--//
+	m.clock.Every(8, func() {
+		m.EmitNoise(Noise{Pos: Vector2{X: 22.5, Y: 11.5}, Loudness: 10, Caption: "[portal hums]"})
+	})
+
+	//--open air, except the passage leading to the portal which echoes like a hall--//
+	m.SetDefaultReverb(ReverbOutdoor)
+	m.AddReverbRegion(image.Rect(19, 11, 23, 12), ReverbHall)
+	m.SetDefaultMaterial(MaterialGrass)
+
+	//--a wooden table near the spawn point--//
+	m.AddSurface(NewSurface(Vector2{X: 21.2, Y: 12.6}, Vector2{X: 21.8, Y: 13.4}, 0.35, 1))
+
+	m.objectives = NewObjectives()
+	m.objectives.Add("talk", "Talk to the sorcerer")
+	m.objectives.Add("roof", "Climb onto the house roof")
+
+	talk := NewWaypoint(20, 11.5, 0.8, "Sorcerer")
+	talk.Objective = "talk"
+	m.AddWaypoint(talk)
+	roof := NewWaypoint(10.5, 12.5, 1.2, "Ladder")
+	roof.Objective = "roof"
+	m.AddWaypoint(roof)
+
+	//--waypoint paths--//
+	m.AddPath(&Path{Name: "raft", Points: []Vector2{{X: 13.1, Y: 3.1}, {X: 12.1, Y: 1.1}}})
+
+	//--a small pond to swim in--//
+	m.RegisterTileType(1, &TileType{Water: true, WaterLevel: 0.8})
+	for x := 17; x <= 19; x++ {
+		for y := 3; y <= 6; y++ {
+			m.SetTileType(x, y, 1)
+		}
+	}
+
+	//--a ladder up the side of the house--//
+	m.RegisterTileType(2, &TileType{Ladder: true})
+	m.SetTileType(10, 12, 2)
+	m.RegisterTileType(6, &TileType{Trigger: "roof"})
+	m.SetTileType(9, 12, 6)
+	m.SetTileType(9, 13, 6)
+	m.SetTileType(10, 13, 6)
+
+	//--checkpoint at the edge of the forest--//
+	m.RegisterTileType(7, &TileType{Checkpoint: true})
+	m.SetTileType(14, 8, 7)
+
+	//--a skylight lighting up the floor--//
+	m.RegisterTileType(8, &TileType{Skylight: true})
+	m.SetTileType(18, 8, 8)
+
+	//--a patch of lava--//
+	m.RegisterTileType(3, &TileType{DamagePerSecond: 10, Effect: NewPoisonEffect(3, 2)})
+	m.SetTileType(12, 2, 3)
+	m.SetTileType(12, 3, 3)
+	m.SetTileType(13, 2, 3)
+	m.SetTileType(13, 3, 3)
+
+	//--a moving walkway along the west wall and a gust of wind past the house--//
+	m.RegisterTileType(4, &TileType{Conveyor: &Vector2{X: -0.03, Y: 0}})
+	for x := 14; x <= 19; x++ {
+		m.SetTileType(x, 1, 4)
+	}
+	m.RegisterTileType(5, &TileType{Wind: &Vector2{X: 0, Y: 0.004}})
+	for x := 7; x <= 8; x++ {
+		for y := 12; y <= 14; y++ {
+			m.SetTileType(x, y, 5)
+		}
+	}
+
+	return m
+}
+
+// CellAt returns the value of the grid cell, 0 if the cell is outside the grid or the grid is empty
+func CellAt(grid [][]int, x, y int) int {
+	if x < 0 || x >= len(grid) || y < 0 || y >= len(grid[x]) {
+		return 0
+	}
+	return grid[x][y]
+}
+
+// makeGrid creates an empty grid of the given dimensions
+func makeGrid(width, height int) [][]int {
+	grid := make([][]int, width)
+	for x := range grid {
+		grid[x] = make([]int, height)
+	}
+	return grid
+}
+
+func (m *Map) LoadSprites() {
+	sorcerer := NewSpriteFromSheet(20, 11.5, m.texture(15), 10, 1)
+	sorcerer.BlocksRays = true
+	sorcerer.Radius = 0.3
+	sorcerer.OnUse = func(user *Camera) *Interaction {
+		return &Interaction{Dialogue: []string{
+			"Greetings, traveler.",
+			"Mind the lava in the forest, and the raft that drifts across it.",
+		}, Action: "talk"}
+	}
+
+	m.sprite = []*Sprite{
+		// // sorcerer
+		sorcerer,
+
+		// // line of trees for testing in front of initial view
+		NewSprite(19.5, 11.5, m.texture(10)),
+		NewSprite(17.5, 11.5, m.texture(14)),
+		NewSprite(15.5, 11.5, m.texture(9)),
+		// // render a forest!
+		NewSprite(11.5, 1.5, m.texture(9)),
+		NewSprite(12.5, 1.5, m.texture(9)),
+		NewSprite(132.5, 1.5, m.texture(9)),
+		NewSprite(11.5, 2, m.texture(9)),
+		NewSprite(12.5, 2, m.texture(9)),
+		NewSprite(13.5, 2, m.texture(9)),
+		NewSprite(11.5, 2.5, m.texture(9)),
+		NewSprite(12.25, 2.5, m.texture(9)),
+		NewSprite(13.5, 2.25, m.texture(9)),
+		NewSprite(11.5, 3, m.texture(9)),
+		NewSprite(12.5, 3, m.texture(9)),
+		NewSprite(13.25, 3, m.texture(9)),
+		NewSprite(10.5, 3.5, m.texture(9)),
+		NewSprite(11.5, 3.25, m.texture(9)),
+		NewSprite(12.5, 3.5, m.texture(9)),
+		NewSprite(13.25, 3.5, m.texture(14)),
+		NewSprite(10.5, 4, m.texture(9)),
+		NewSprite(11.5, 4, m.texture(9)),
+		NewSprite(12.5, 4, m.texture(9)),
+		NewSprite(13.5, 4, m.texture(14)),
+		NewSprite(10.5, 4.5, m.texture(9)),
+		NewSprite(11.25, 4.5, m.texture(9)),
+		NewSprite(12.5, 4.5, m.texture(14)),
+		NewSprite(13.5, 4.5, m.texture(10)),
+		NewSprite(14.5, 4.25, m.texture(14)),
+		NewSprite(10.5, 5, m.texture(9)),
+		NewSprite(11.5, 5, m.texture(9)),
+		NewSprite(12.5, 5, m.texture(14)),
+		NewSprite(13.25, 5, m.texture(10)),
+		NewSprite(14.5, 5, m.texture(14)),
+		NewSprite(11.5, 5.5, m.texture(14)),
+		NewSprite(12.5, 5.25, m.texture(10)),
+		NewSprite(13.5, 5.25, m.texture(10)),
+		NewSprite(14.5, 5.5, m.texture(10)),
+		NewSprite(15.5, 5.5, m.texture(14)),
+		NewSprite(11.5, 6, m.texture(14)),
+		NewSprite(12.5, 6, m.texture(10)),
+		NewSprite(13.25, 6, m.texture(10)),
+		NewSprite(14.25, 6, m.texture(10)),
+		NewSprite(15.5, 6, m.texture(14)),
+		NewSprite(12.5, 6.5, m.texture(14)),
+		NewSprite(13.5, 6.25, m.texture(10)),
+		NewSprite(14.5, 6.5, m.texture(14)),
+		NewSprite(12.5, 7, m.texture(14)),
+		NewSprite(13.5, 7, m.texture(10)),
+		NewSprite(14.5, 7, m.texture(14)),
+		NewSprite(13.5, 7.5, m.texture(14)),
+		NewSprite(13.5, 8, m.texture(14)),
+	}
+
+	//--a raft drifting back and forth across the lava--//
+	raft := NewSolid(Vector2{X: 12.1, Y: 1.1}, Vector2{X: 12.9, Y: 1.9}, 0, 0.2)
+	raft.Follow(m.GetPath("raft"), 0.01)
+	raft.Sprite = NewSprite(12.5, 1.5, m.texture(0))
+	raft.Sprite.NavDebug = true
+	m.AddSolid(raft)
+	m.sprite = append(m.sprite, raft.Sprite)
+
+	m.numSprites = len(m.sprite)
+	m.indexSprites()
+}
+
+// Update advances the map clock by the real tick duration in seconds, then moves the map solids,
+// patrols and sprite animations by the clock time that passed, refreshes the sprite index and runs
+// the spawners, once per tick
+func (m *Map) Update(dt float64) {
+	frames := m.clock.Tick(dt) * movementTPS
+	m.lastDt = frames / movementTPS
+	if frames <= 0 {
+		return
+	}
+
+	for _, s := range m.solids {
+		s.update(frames)
+	}
+	for _, s := range m.sprite {
+		s.animate(frames / movementTPS)
+		if s.Patrol != nil {
+			pos := Vector2{X: s.X, Y: s.Y}
+			s.Patrol.Advance(&pos, s.Patrol.Speed*frames)
+			if s.Steering != nil {
+				pos = m.steer(s, pos, s.Patrol.Speed*frames)
+			}
+			if dx, dy := pos.X-s.X, pos.Y-s.Y; dx != 0 || dy != 0 {
+				l := math.Hypot(dx, dy)
+				s.Dir = Vector2{X: dx / l, Y: dy / l}
+			}
+			s.X, s.Y = pos.X, pos.Y
+		}
+	}
+	m.indexSprites()
+	m.recordHistory()
+
+	for _, sp := range m.spawners {
+		sp.update(m, m.lastDt)
+	}
+	m.updateDoors(m.lastDt)
+	for _, sc := range m.securityCameras {
+		sc.update(m.lastDt)
+	}
+}
+
+// GetClock returns the map simulation clock
+func (m *Map) GetClock() *Clock {
+	return m.clock
+}
+
+func (m *Map) getSprites() []*Sprite {
+	return m.sprite
+}
+
+// GetSprites returns the sprites in the map
+func (m *Map) GetSprites() []*Sprite {
+	return m.sprite
+}
+
+func (m *Map) GetNumSprites() int {
+	return m.numSprites
+}
+
+// AddLevel stacks another wall level grid on top of the authored levels
+func (m *Map) AddLevel(grid [][]int) {
+	m.levels = append(m.levels, grid)
+	if len(m.levels) == 1 {
+		m.worldMap = grid
+	}
+}
+
+// GetLevels returns the authored wall level grids from the ground up
+func (m *Map) GetLevels() [][][]int {
+	return m.levels
+}
+
+// GetNumLevels returns the number of authored wall levels
+func (m *Map) GetNumLevels() int {
+	return len(m.levels)
+}
+
+// LevelGrid returns the grid of the wall level, levels above the authored ones repeat the top grid
+// if RepeatTopLevel is set and are nil (empty) otherwise
+func (m *Map) LevelGrid(levelNum int) [][]int {
+	if levelNum < 0 || len(m.levels) == 0 {
+		return nil
+	}
+	if levelNum < len(m.levels) {
+		return m.levels[levelNum]
+	}
+	if m.RepeatTopLevel {
+		return m.levels[len(m.levels)-1]
+	}
+	return nil
+}
+
+// SetTile changes a wall cell while the map is running (switches, destructible walls, opening passages),
+// the value being a wall texture number or 0 for empty. Cameras, collision, line of sight and pathfinding
+// see the change from their next update. Levels above the authored ones are added empty as needed, they
+// are only drawn by cameras made with enough levels.
+func (m *Map) SetTile(x, y, level, value int) {
+	if level < 0 || !m.inBounds(x, y) {
+		return
+	}
+	w, h := m.GetSize()
+	for len(m.levels) <= level {
+		m.AddLevel(makeGrid(w, h))
+	}
+
+	// levels made from the same grid are split so only this one changes
+	grid := m.levels[level]
+	for i, other := range m.levels {
+		if i != level && len(other) > 0 && &other[0][0] == &grid[0][0] {
+			split := makeGrid(w, h)
+			for col := range split {
+				copy(split[col], grid[col])
+			}
+			grid = split
+			m.levels[level] = grid
+			if level == 0 {
+				m.worldMap = grid
+			}
+			break
+		}
+	}
+
+	old := grid[x][y]
+	if old == value {
+		return
+	}
+	grid[x][y] = value
+	m.recordTileChange(x, y, level, old, value)
+
+	// a wall knocked out of a doorway takes the door with it
+	if level == 0 && value <= 0 && m.GetDoor(x, y) != nil {
+		m.RemoveDoor(x, y)
+	}
+	if m.OnTileChange != nil {
+		m.OnTileChange(x, y, level, old, value)
+	}
+}
+
+// GetTile returns the value of a wall cell, 0 outside the map and on levels with no grid
+func (m *Map) GetTile(x, y, level int) int {
+	return CellAt(m.LevelGrid(level), x, y)
+}
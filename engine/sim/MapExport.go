@@ -0,0 +1,256 @@
+package sim
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"io"
+	"math"
+	"sort"
+	"strings"
+)
+
+const (
+	// pixel scale of the legend text, each glyph is 3x5 pixels before scaling
+	legendScale = 2
+
+	// spacing around legend entries
+	legendPad = 6
+)
+
+var (
+	exportFloor     = color.RGBA{24, 24, 28, 255}
+	exportUpper     = color.RGBA{70, 70, 90, 255}
+	exportWater     = color.RGBA{40, 110, 220, 255}
+	exportDamage    = color.RGBA{230, 70, 30, 255}
+	exportLadder    = color.RGBA{160, 110, 50, 255}
+	exportMover     = color.RGBA{60, 200, 200, 255}
+	exportTrigger   = color.RGBA{230, 80, 230, 255}
+	exportSprite    = color.RGBA{255, 255, 255, 255}
+	exportSolid     = color.RGBA{255, 150, 40, 255}
+	exportTerrain   = color.RGBA{80, 200, 80, 255}
+	exportLegendBg  = color.RGBA{0, 0, 0, 255}
+	exportLegendTxt = color.RGBA{220, 220, 220, 255}
+)
+
+// legendEntry is one color swatch and its label in the overview legend
+type legendEntry struct {
+	label string
+	color color.RGBA
+}
+
+// ExportPNG writes a top-down overview of the map with cellSize pixels per grid cell and a legend on the right.
+// Ground walls are colored by texture number, cells with walls only on upper levels are shaded as overhangs,
+// special tiles are marked with an inset square and sprites, solids, waypoints and terrain are drawn on top.
+func (m *Map) ExportPNG(w io.Writer, cellSize int) error {
+	if cellSize < 1 {
+		cellSize = 1
+	}
+	mw, mh := m.GetSize()
+	var legend []legendEntry
+	used := make(map[string]bool)
+	addLegend := func(label string, clr color.RGBA) {
+		if !used[label] {
+			used[label] = true
+			legend = append(legend, legendEntry{label, clr})
+		}
+	}
+
+	overview := image.NewRGBA(image.Rect(0, 0, mw*cellSize, mh*cellSize))
+	draw.Draw(overview, overview.Bounds(), &image.Uniform{exportFloor}, image.ZP, draw.Src)
+	cell := func(x, y, inset int, clr color.RGBA) {
+		r := image.Rect(x*cellSize+inset, y*cellSize+inset, (x+1)*cellSize-inset, (y+1)*cellSize-inset)
+		draw.Draw(overview, r, &image.Uniform{clr}, image.ZP, draw.Src)
+	}
+
+	//--walls--//
+	var texNums []int
+	for x := 0; x < mw; x++ {
+		for y := 0; y < mh; y++ {
+			if texNum := m.worldMap[x][y]; texNum > 0 {
+				cell(x, y, 0, textureColor(texNum))
+				if !used[fmt.Sprintf("WALL %d", texNum)] {
+					texNums = append(texNums, texNum)
+					used[fmt.Sprintf("WALL %d", texNum)] = true
+				}
+				continue
+			}
+			for _, grid := range m.levels[1:] {
+				if CellAt(grid, x, y) > 0 {
+					cell(x, y, 0, exportUpper)
+					addLegend("OVERHANG", exportUpper)
+					break
+				}
+			}
+		}
+	}
+	sort.Ints(texNums)
+	for _, texNum := range texNums {
+		legend = append(legend, legendEntry{fmt.Sprintf("WALL %d", texNum), textureColor(texNum)})
+	}
+
+	//--special tiles--//
+	inset := cellSize / 4
+	for x := 0; x < mw; x++ {
+		for y := 0; y < mh; y++ {
+			t := m.GetTileType(x, y)
+			if t == nil {
+				continue
+			}
+			switch {
+			case t.DamagePerSecond > 0:
+				cell(x, y, inset, exportDamage)
+				addLegend("DAMAGE", exportDamage)
+			case t.Water:
+				cell(x, y, inset, exportWater)
+				addLegend("WATER", exportWater)
+			case t.Ladder:
+				cell(x, y, inset, exportLadder)
+				addLegend("LADDER", exportLadder)
+			case t.Conveyor != nil || t.Wind != nil:
+				cell(x, y, inset, exportMover)
+				addLegend("CONVEYOR WIND", exportMover)
+			case t.Trigger != "" || t.Checkpoint:
+				cell(x, y, inset, exportTrigger)
+				addLegend("TRIGGER", exportTrigger)
+			}
+		}
+	}
+
+	toPixel := func(p Vector2) image.Point {
+		return image.Pt(int(p.X*float64(cellSize)), int(p.Y*float64(cellSize)))
+	}
+
+	//--terrain regions and solids as outlines--//
+	for _, t := range m.terrain {
+		r := image.Rect(t.Region.Min.X*cellSize, t.Region.Min.Y*cellSize, t.Region.Max.X*cellSize, t.Region.Max.Y*cellSize)
+		outline(overview, r, exportTerrain)
+		addLegend("TERRAIN", exportTerrain)
+	}
+	for _, s := range m.solids {
+		outline(overview, image.Rectangle{Min: toPixel(s.Min), Max: toPixel(s.Max)}, exportSolid)
+		addLegend("SOLID", exportSolid)
+	}
+
+	//--sprites and waypoints as dots--//
+	dot := func(p Vector2, size int, clr color.RGBA) {
+		c := toPixel(p)
+		r := image.Rect(c.X-size, c.Y-size, c.X+size+1, c.Y+size+1)
+		draw.Draw(overview, r, &image.Uniform{clr}, image.ZP, draw.Src)
+	}
+	for _, s := range m.sprite {
+		dot(Vector2{X: s.X, Y: s.Y}, cellSize/8, exportSprite)
+		addLegend("SPRITE", exportSprite)
+	}
+	for _, wp := range m.waypoints {
+		dot(Vector2{X: wp.X, Y: wp.Y}, cellSize/4, wp.Color)
+		addLegend(strings.ToUpper(wp.Label), wp.Color)
+	}
+
+	//--legend to the right of the overview--//
+	lineHeight := 5*legendScale + legendPad
+	legendWidth := 0
+	for _, e := range legend {
+		if lw := lineHeight + legendPad + textWidth(e.label); lw > legendWidth {
+			legendWidth = lw
+		}
+	}
+	legendWidth += 2 * legendPad
+	height := mh * cellSize
+	if lh := len(legend)*lineHeight + legendPad; lh > height {
+		height = lh
+	}
+
+	out := image.NewRGBA(image.Rect(0, 0, mw*cellSize+legendWidth, height))
+	draw.Draw(out, out.Bounds(), &image.Uniform{exportLegendBg}, image.ZP, draw.Src)
+	draw.Draw(out, overview.Bounds(), overview, image.ZP, draw.Src)
+
+	for i, e := range legend {
+		x := mw*cellSize + legendPad
+		y := legendPad + i*lineHeight
+		swatch := 5 * legendScale
+		draw.Draw(out, image.Rect(x, y, x+swatch, y+swatch), &image.Uniform{e.color}, image.ZP, draw.Src)
+		drawText(out, x+swatch+legendPad, y, e.label, exportLegendTxt)
+	}
+
+	return png.Encode(w, out)
+}
+
+// textureColor returns a distinct color for a wall texture number
+func textureColor(texNum int) color.RGBA {
+	//--golden angle steps around the hue circle keep neighbouring numbers apart--//
+	hue := math.Mod(float64(texNum)*137.508, 360)
+	return hsvColor(hue, 0.55, 0.85)
+}
+
+// hsvColor converts a hue in degrees, saturation and value to a color
+func hsvColor(h, s, v float64) color.RGBA {
+	c := v * s
+	hp := h / 60
+	x := c * (1 - math.Abs(math.Mod(hp, 2)-1))
+	var r, g, b float64
+	switch int(hp) {
+	case 0:
+		r, g, b = c, x, 0
+	case 1:
+		r, g, b = x, c, 0
+	case 2:
+		r, g, b = 0, c, x
+	case 3:
+		r, g, b = 0, x, c
+	case 4:
+		r, g, b = x, 0, c
+	default:
+		r, g, b = c, 0, x
+	}
+	mv := v - c
+	return color.RGBA{uint8((r + mv) * 255), uint8((g + mv) * 255), uint8((b + mv) * 255), 255}
+}
+
+// outline draws a one pixel rectangle outline
+func outline(img *image.RGBA, r image.Rectangle, clr color.RGBA) {
+	for x := r.Min.X; x < r.Max.X; x++ {
+		img.SetRGBA(x, r.Min.Y, clr)
+		img.SetRGBA(x, r.Max.Y-1, clr)
+	}
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		img.SetRGBA(r.Min.X, y, clr)
+		img.SetRGBA(r.Max.X-1, y, clr)
+	}
+}
+
+// legendGlyphs is a 3x5 pixel font for legend text, each glyph is five rows of three pixels from the top
+var legendGlyphs = map[rune]string{
+	'A': "010101111101101", 'B': "110101110101110", 'C': "011100100100011", 'D': "110101101101110",
+	'E': "111100110100111", 'F': "111100110100100", 'G': "011100101101011", 'H': "101101111101101",
+	'I': "111010010010111", 'J': "001001001101010", 'K': "101101110101101", 'L': "100100100100111",
+	'M': "101111111101101", 'N': "110101101101101", 'O': "010101101101010", 'P': "110101110100100",
+	'Q': "010101101110011", 'R': "110101110101101", 'S': "011100010001110", 'T': "111010010010010",
+	'U': "101101101101111", 'V': "101101101101010", 'W': "101101111111101", 'X': "101101010101101",
+	'Y': "101101010010010", 'Z': "111001010100111",
+	'0': "111101101101111", '1': "010110010010111", '2': "110001010100111", '3': "110001010001110",
+	'4': "101101111001001", '5': "111100110001110", '6': "011100111101111", '7': "111001010010010",
+	'8': "111101111101111", '9': "111101111001110", '-': "000000111000000", '.': "000000000000010",
+}
+
+// textWidth returns the width in pixels of legend text
+func textWidth(text string) int {
+	return len(text) * 4 * legendScale
+}
+
+// drawText draws legend text with its top left corner at x, y, characters missing from the font are left blank
+func drawText(img *image.RGBA, x, y int, text string, clr color.RGBA) {
+	for i, ch := range strings.ToUpper(text) {
+		glyph := legendGlyphs[ch]
+		for p, bit := range glyph {
+			if bit != '1' {
+				continue
+			}
+			gx := x + (i*4+p%3)*legendScale
+			gy := y + (p/3)*legendScale
+			draw.Draw(img, image.Rect(gx, gy, gx+legendScale, gy+legendScale), &image.Uniform{clr}, image.ZP, draw.Src)
+		}
+	}
+}
@@ -0,0 +1,298 @@
+package sim
+
+import (
+	"encoding/json"
+	"fmt"
+	"image"
+	"io"
+	"sort"
+)
+
+// MapJSONVersion is the current map JSON format version
+const MapJSONVersion = 1
+
+// mapJSON is the exported form of a map. Textures are referred to by their index in the texture handler.
+// Callbacks (sprite OnUse, draw hooks, spawner New and wave events, arena events, door state changes), surfaces, terrain, objectives and listeners are not exported.
+type mapJSON struct {
+	Version         int               `json:"version"`
+	Levels          [][][]int         `json:"levels"`
+	RepeatTopLevel  bool              `json:"repeatTopLevel,omitempty"`
+	Tiles           [][]int           `json:"tiles,omitempty"`
+	Floors          [][]int           `json:"floors,omitempty"`
+	TileTypes       map[int]*TileType `json:"tileTypes,omitempty"`
+	Paths           []*Path           `json:"paths,omitempty"`
+	Sprites         []spriteJSON      `json:"sprites,omitempty"`
+	Solids          []solidJSON       `json:"solids,omitempty"`
+	Waypoints       []*Waypoint       `json:"waypoints,omitempty"`
+	Music           *MapMusic         `json:"music,omitempty"`
+	Reverb          *ReverbPreset     `json:"reverb,omitempty"`
+	ReverbRegions   []*ReverbRegion   `json:"reverbRegions,omitempty"`
+	Material        Material          `json:"material,omitempty"`
+	Ceiling         int               `json:"ceiling,omitempty"`
+	Factions        []FactionRelation `json:"factions,omitempty"`
+	Spawners        []*Spawner        `json:"spawners,omitempty"`
+	Arenas          []arenaJSON       `json:"arenas,omitempty"`
+	Doors           []*Door           `json:"doors,omitempty"`
+	SecurityCameras []*SecurityCamera `json:"securityCameras,omitempty"`
+}
+
+type spriteJSON struct {
+	X, Y       float64
+	Texture    int
+	Columns    int           `json:",omitempty"`
+	Rows       int           `json:",omitempty"`
+	Directions bool          `json:",omitempty"` // rows of the sheet are the directions the sprite is seen from
+	AnimFPS    float64       `json:",omitempty"`
+	Animations []*Animation  `json:",omitempty"`
+	Animation  string        `json:",omitempty"`
+	BlocksRays bool          `json:",omitempty"`
+	Radius     float64       `json:",omitempty"`
+	NavDebug   bool          `json:",omitempty"`
+	Patrol     *followerJSON `json:",omitempty"`
+	Steering   *Steering     `json:",omitempty"`
+	Dir        Vector2
+	Faction    string      `json:",omitempty"`
+	Perception *Perception `json:",omitempty"`
+	Item       bool        `json:",omitempty"`
+}
+
+type arenaJSON struct {
+	*Arena
+	Boss int `json:",omitempty"` // 1 based index of the boss sprite, 0 for none
+}
+
+type solidJSON struct {
+	Min, Max  Vector2
+	Base, Top float64
+	Sprite    int           `json:",omitempty"` // 1 based index of the sprite on the box, 0 for none
+	Path      *followerJSON `json:",omitempty"`
+}
+
+// followerJSON refers to a named map path, unnamed paths are stored inline
+type followerJSON struct {
+	Name  string `json:",omitempty"`
+	Path  *Path  `json:",omitempty"`
+	Speed float64
+}
+
+// ExportJSON writes the grids, tile types, paths, sprites, solids, waypoints, music and reverb of the map as JSON
+// that can be read back with LoadMapJSON using the same textures
+func (m *Map) ExportJSON(w io.Writer) error {
+	texIndex := make(map[image.Image]int)
+	if m.tex != nil {
+		for i := 0; i < m.tex.GetNumTextures(); i++ {
+			if t := m.tex.GetImage(i); t != nil {
+				texIndex[t] = i
+			}
+		}
+	}
+
+	follower := func(f *PathFollower) *followerJSON {
+		if f == nil || f.Path == nil {
+			return nil
+		}
+		if f.Path.Name != "" && m.paths[f.Path.Name] == f.Path {
+			return &followerJSON{Name: f.Path.Name, Speed: f.Speed}
+		}
+		return &followerJSON{Path: f.Path, Speed: f.Speed}
+	}
+
+	mj := &mapJSON{
+		Version:        MapJSONVersion,
+		Levels:         m.levels,
+		RepeatTopLevel: m.RepeatTopLevel,
+		Tiles:          m.tileMap,
+		TileTypes:      m.tileTypes,
+		Waypoints:      m.waypoints,
+	}
+	if m.music != (MapMusic{}) {
+		mj.Music = &m.music
+	}
+	if m.defaultReverb.Name != "" {
+		mj.Reverb = &m.defaultReverb
+	}
+	mj.ReverbRegions = m.reverbRegions
+	if m.hasFloorTextures() {
+		mj.Floors = m.floorMap
+	}
+	mj.Material = m.defaultMaterial
+	mj.Ceiling = m.ceiling
+	mj.Spawners = m.spawners
+	mj.Doors = m.doors
+	mj.SecurityCameras = m.securityCameras
+	mj.Factions = m.GetRelations()
+	sort.Slice(mj.Factions, func(i, j int) bool {
+		a, b := mj.Factions[i], mj.Factions[j]
+		return a.A < b.A || a.A == b.A && a.B < b.B
+	})
+	for _, p := range m.paths {
+		mj.Paths = append(mj.Paths, p)
+	}
+	sort.Slice(mj.Paths, func(i, j int) bool { return mj.Paths[i].Name < mj.Paths[j].Name })
+
+	spriteIndex := make(map[*Sprite]int)
+	for i, s := range m.sprite {
+		sj := spriteJSON{
+			X: s.X, Y: s.Y, Texture: -1,
+			BlocksRays: s.BlocksRays, Radius: s.Radius, NavDebug: s.NavDebug,
+			Patrol: follower(s.Patrol), Steering: s.Steering,
+			Dir: s.Dir, Faction: s.Faction, Perception: s.Perception, Item: s.Item,
+			Animation: s.GetAnimation(),
+		}
+		for _, a := range s.animations {
+			sj.Animations = append(sj.Animations, a)
+		}
+		sort.Slice(sj.Animations, func(i, j int) bool { return sj.Animations[i].Name < sj.Animations[j].Name })
+		img := s.sheet
+		if img == nil && len(s.textures) > 0 {
+			img = s.textures[0]
+		} else if img != nil {
+			sj.Columns, sj.Rows, sj.AnimFPS = s.columns, s.rows, s.AnimFPS
+			sj.Directions = s.directions != nil
+		}
+		if n, ok := texIndex[img]; ok {
+			sj.Texture = n
+		}
+		mj.Sprites = append(mj.Sprites, sj)
+		spriteIndex[s] = i + 1
+	}
+
+	for _, s := range m.solids {
+		mj.Solids = append(mj.Solids, solidJSON{
+			Min: s.Min, Max: s.Max, Base: s.Base, Top: s.Top,
+			Sprite: spriteIndex[s.Sprite],
+			Path:   follower(s.follower),
+		})
+	}
+	for _, a := range m.arenas {
+		mj.Arenas = append(mj.Arenas, arenaJSON{Arena: a, Boss: spriteIndex[a.Boss]})
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(mj)
+}
+
+// LoadMapJSON reads a map written by ExportJSON, textures are looked up by index in the texture handler
+func LoadMapJSON(tex Textures, r io.Reader) (*Map, error) {
+	mj := new(mapJSON)
+	if err := json.NewDecoder(r).Decode(mj); err != nil {
+		return nil, err
+	}
+	if mj.Version > MapJSONVersion {
+		return nil, fmt.Errorf("map version %d is newer than supported version %d", mj.Version, MapJSONVersion)
+	}
+	if len(mj.Levels) == 0 {
+		return nil, fmt.Errorf("map has no levels")
+	}
+
+	m := NewMapFromLevels(tex, mj.Levels...)
+	m.RepeatTopLevel = mj.RepeatTopLevel
+	w, h := m.GetSize()
+	for x := 0; x < w && x < len(mj.Tiles); x++ {
+		for y := 0; y < h && y < len(mj.Tiles[x]); y++ {
+			m.tileMap[x][y] = mj.Tiles[x][y]
+		}
+	}
+	for x := 0; x < w && x < len(mj.Floors); x++ {
+		for y := 0; y < h && y < len(mj.Floors[x]); y++ {
+			m.floorMap[x][y] = mj.Floors[x][y]
+		}
+	}
+	for id, t := range mj.TileTypes {
+		m.RegisterTileType(id, t)
+	}
+	for _, p := range mj.Paths {
+		m.AddPath(p)
+	}
+	m.waypoints = mj.Waypoints
+	if mj.Music != nil {
+		m.music = *mj.Music
+	}
+	if mj.Reverb != nil {
+		m.defaultReverb = *mj.Reverb
+	}
+	m.reverbRegions = mj.ReverbRegions
+	m.defaultMaterial = mj.Material
+	m.ceiling = mj.Ceiling
+	for _, r := range mj.Factions {
+		m.SetRelation(r.A, r.B, r.Relation)
+	}
+	for _, sp := range mj.Spawners {
+		m.AddSpawner(sp)
+	}
+
+	follower := func(f *followerJSON) *PathFollower {
+		if f == nil {
+			return nil
+		}
+		path := f.Path
+		if f.Name != "" {
+			path = m.GetPath(f.Name)
+		}
+		if path == nil {
+			return nil
+		}
+		return NewPathFollower(path, f.Speed)
+	}
+
+	texture := func(i int) image.Image {
+		if tex == nil {
+			return nil
+		}
+		return tex.GetImage(i)
+	}
+
+	for _, sj := range mj.Sprites {
+		var s *Sprite
+		if img := texture(sj.Texture); img != nil && sj.Directions && sj.Rows > 1 && sj.Columns > 0 {
+			b := img.Bounds()
+			s = NewSpriteFromAnimationSet(sj.X, sj.Y, NewAnimationSet(img, b.Dx()/sj.Columns, b.Dy()/sj.Rows, SheetRowsAreDirections))
+			s.sheet, s.columns, s.rows = img, sj.Columns, sj.Rows
+			s.AnimFPS = sj.AnimFPS
+		} else if img != nil && sj.Columns*sj.Rows > 1 {
+			s = NewSpriteFromSheet(sj.X, sj.Y, img, sj.Columns, sj.Rows)
+			s.AnimFPS = sj.AnimFPS
+		} else {
+			s = NewSprite(sj.X, sj.Y, img)
+		}
+		s.BlocksRays, s.Radius, s.NavDebug = sj.BlocksRays, sj.Radius, sj.NavDebug
+		s.Patrol, s.Steering = follower(sj.Patrol), sj.Steering
+		s.Dir, s.Faction, s.Perception, s.Item = sj.Dir, sj.Faction, sj.Perception, sj.Item
+		for _, a := range sj.Animations {
+			s.AddAnimation(a)
+		}
+		s.Play(sj.Animation)
+		m.sprite = append(m.sprite, s)
+	}
+
+	for _, sj := range mj.Solids {
+		s := NewSolid(sj.Min, sj.Max, sj.Base, sj.Top)
+		s.follower = follower(sj.Path)
+		if sj.Sprite > 0 && sj.Sprite <= len(m.sprite) {
+			s.Sprite = m.sprite[sj.Sprite-1]
+		}
+		m.AddSolid(s)
+	}
+
+	for _, aj := range mj.Arenas {
+		if aj.Arena == nil {
+			continue
+		}
+		if aj.Boss > 0 && aj.Boss <= len(m.sprite) {
+			aj.Arena.Boss = m.sprite[aj.Boss-1]
+		}
+		m.AddArena(aj.Arena)
+	}
+
+	for _, d := range mj.Doors {
+		m.AddDoor(d)
+	}
+	for _, sc := range mj.SecurityCameras {
+		m.AddSecurityCamera(sc)
+	}
+
+	m.numSprites = len(m.sprite)
+	m.indexSprites()
+	return m, nil
+}
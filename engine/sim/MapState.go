@@ -0,0 +1,133 @@
+package sim
+
+import "sort"
+
+// MapState is the serializable state of the parts of a map that change as it is played: doors, cells changed
+// with SetTile, spawner progress and the clock
+type MapState struct {
+	Time         float64        `json:"time"`
+	LastSpriteID int            `json:"lastSpriteId"`
+	Doors        []DoorSnapshot `json:"doors"`
+	Tiles        []TileChange   `json:"tiles"`
+	Spawners     []SpawnerState `json:"spawners"`
+}
+
+// DoorSnapshot is the serializable state of a sliding door, by grid cell
+type DoorSnapshot struct {
+	X      int       `json:"x"`
+	Y      int       `json:"y"`
+	State  DoorState `json:"state"`
+	Open   float64   `json:"open"`
+	Timer  float64   `json:"timer"`
+	Locked bool      `json:"locked"`
+}
+
+// TileChange is a wall level cell changed with SetTile, with the value it had before its first change
+type TileChange struct {
+	X        int `json:"x"`
+	Y        int `json:"y"`
+	Level    int `json:"level"`
+	Original int `json:"original"`
+	Value    int `json:"value"`
+}
+
+// SpawnerState is the serializable progress of a spawner, by index in the map spawner list. The sprites it
+// has alive are the snapshot sprites it spawned.
+type SpawnerState struct {
+	Started   bool    `json:"started"`
+	Completed bool    `json:"completed"`
+	Wave      int     `json:"wave"`
+	Spawned   int     `json:"spawned"`
+	Timer     float64 `json:"timer"`
+}
+
+// recordTileChange keeps a cell changed with SetTile, and the value it had before it was first changed
+func (m *Map) recordTileChange(x, y, level, old, value int) {
+	if m.tileChanges == nil {
+		m.tileChanges = make(map[[3]int]*TileChange)
+	}
+	key := [3]int{x, y, level}
+	if c, ok := m.tileChanges[key]; ok {
+		c.Value = value
+		return
+	}
+	m.tileChanges[key] = &TileChange{X: x, Y: y, Level: level, Original: old, Value: value}
+}
+
+// captureState copies the state of the doors, changed cells, spawners and clock
+func (m *Map) captureState() *MapState {
+	s := &MapState{Time: m.clock.Now(), LastSpriteID: m.lastSpriteID}
+
+	for _, d := range m.doors {
+		s.Doors = append(s.Doors, DoorSnapshot{X: d.X, Y: d.Y, State: d.state, Open: d.open, Timer: d.timer, Locked: d.Locked})
+	}
+
+	for _, c := range m.tileChanges {
+		s.Tiles = append(s.Tiles, *c)
+	}
+	sort.Slice(s.Tiles, func(i, j int) bool {
+		a, b := s.Tiles[i], s.Tiles[j]
+		if a.Level != b.Level {
+			return a.Level < b.Level
+		}
+		if a.X != b.X {
+			return a.X < b.X
+		}
+		return a.Y < b.Y
+	})
+
+	for _, sp := range m.spawners {
+		s.Spawners = append(s.Spawners, SpawnerState{
+			Started: sp.started, Completed: sp.completed, Wave: sp.wave, Spawned: sp.spawned, Timer: sp.timer,
+		})
+	}
+
+	return s
+}
+
+// restoreState puts the doors, changed cells, spawners and clock back as they were captured, cells changed
+// since are set back to what they were before. Spawners keep the sprites they spawned that are in the map.
+func (m *Map) restoreState(s *MapState) {
+	saved := make(map[[3]int]bool, len(s.Tiles))
+	for _, c := range s.Tiles {
+		saved[[3]int{c.X, c.Y, c.Level}] = true
+	}
+	for key, c := range m.tileChanges {
+		if !saved[key] {
+			m.SetTile(c.X, c.Y, c.Level, c.Original)
+			delete(m.tileChanges, key)
+		}
+	}
+	for _, c := range s.Tiles {
+		m.SetTile(c.X, c.Y, c.Level, c.Value)
+		change := c
+		if m.tileChanges == nil {
+			m.tileChanges = make(map[[3]int]*TileChange)
+		}
+		m.tileChanges[[3]int{c.X, c.Y, c.Level}] = &change
+	}
+
+	for _, state := range s.Doors {
+		if d := m.GetDoor(state.X, state.Y); d != nil {
+			d.state, d.open, d.timer, d.Locked = state.State, state.Open, state.Timer, state.Locked
+		}
+	}
+
+	for i, state := range s.Spawners {
+		if i >= len(m.spawners) {
+			break
+		}
+		sp := m.spawners[i]
+		sp.started, sp.completed = state.Started, state.Completed
+		sp.wave, sp.spawned, sp.timer = state.Wave, state.Spawned, state.Timer
+		sp.alive = sp.alive[:0]
+		for _, sprite := range m.sprite {
+			if sprite.spawner == sp {
+				sp.alive = append(sp.alive, sprite)
+			}
+		}
+	}
+
+	m.lastSpriteID = s.LastSpriteID
+	m.clock.setNow(s.Time)
+}
@@ -0,0 +1,289 @@
+package sim
+
+import "math"
+
+// mapTransform describes how a map is remapped onto a new grid
+type mapTransform struct {
+	// width, height --dimensions of the new grid--//
+	width, height int
+
+	// cell --returns the source cell a new grid cell is copied from--//
+	cell func(x, y int) (int, int)
+
+	// point --moves a source grid position to its new position--//
+	point func(p Vector2) Vector2
+
+	// dir --turns a source direction (conveyors, wind) to its new direction--//
+	dir func(d Vector2) Vector2
+}
+
+// NewEmptyMap creates an open map of the given size, used as a canvas to stitch room templates into
+func NewEmptyMap(tex Textures, width, height int) *Map {
+	return NewMapFromGrids(tex, makeGrid(width, height), makeGrid(width, height), makeGrid(width, height))
+}
+
+// GetSize returns the number of grid cells along x and y
+func (m *Map) GetSize() (int, int) {
+	if len(m.worldMap) == 0 {
+		return 0, 0
+	}
+	return len(m.worldMap), len(m.worldMap[0])
+}
+
+// GetWidth returns the number of grid cells along x
+func (m *Map) GetWidth() int {
+	w, _ := m.GetSize()
+	return w
+}
+
+// GetHeight returns the number of grid cells along y
+func (m *Map) GetHeight() int {
+	_, h := m.GetSize()
+	return h
+}
+
+// Rotate returns a copy of the map turned clockwise by the given number of quarter turns,
+// with sprites, solids, paths and tile directions turned along with the grids
+func (m *Map) Rotate(turns int) *Map {
+	turns = ((turns % 4) + 4) % 4
+	if turns == 0 {
+		return m.transform(m.identity(0, 0))
+	}
+
+	r := m
+	for i := 0; i < turns; i++ {
+		w, h := r.GetSize()
+		r = r.transform(mapTransform{
+			width: h, height: w,
+			cell:  func(x, y int) (int, int) { return y, h - 1 - x },
+			point: func(p Vector2) Vector2 { return Vector2{X: float64(h) - p.Y, Y: p.X} },
+			dir:   func(d Vector2) Vector2 { return Vector2{X: -d.Y, Y: d.X} },
+		})
+	}
+	return r
+}
+
+// MirrorX returns a copy of the map flipped along the x axis, so cell x becomes width-1-x
+func (m *Map) MirrorX() *Map {
+	w, h := m.GetSize()
+	return m.transform(mapTransform{
+		width: w, height: h,
+		cell:  func(x, y int) (int, int) { return w - 1 - x, y },
+		point: func(p Vector2) Vector2 { return Vector2{X: float64(w) - p.X, Y: p.Y} },
+		dir:   func(d Vector2) Vector2 { return Vector2{X: -d.X, Y: d.Y} },
+	})
+}
+
+// MirrorY returns a copy of the map flipped along the y axis, so cell y becomes height-1-y
+func (m *Map) MirrorY() *Map {
+	w, h := m.GetSize()
+	return m.transform(mapTransform{
+		width: w, height: h,
+		cell:  func(x, y int) (int, int) { return x, h - 1 - y },
+		point: func(p Vector2) Vector2 { return Vector2{X: p.X, Y: float64(h) - p.Y} },
+		dir:   func(d Vector2) Vector2 { return Vector2{X: d.X, Y: -d.Y} },
+	})
+}
+
+// Crop returns the width by height region of the map starting at cell x, y.
+// Sprites and solids outside the region are dropped, cells outside the source map are left empty.
+func (m *Map) Crop(x, y, width, height int) *Map {
+	t := m.identity(x, y)
+	t.width, t.height = width, height
+	return m.transform(t)
+}
+
+// Stitch copies the grids, tile types, sprites, solids, waypoints and paths of src into the map with its
+// cell 0, 0 placed at cell x, y. Empty src cells leave the map untouched so rooms can overlap at
+// shared walls, tile type ids already used by a different type in the map are given new ids.
+func (m *Map) Stitch(src *Map, x, y int) {
+	ids := make(map[int]int)
+	for id, t := range src.tileTypes {
+		newID := id
+		for existing := m.tileTypes[newID]; existing != nil && existing != t; existing = m.tileTypes[newID] {
+			newID++
+		}
+		m.RegisterTileType(newID, t)
+		ids[id] = newID
+	}
+
+	//--src is copied into place on a grid the size of the map, so the template can be stitched again--//
+	w, h := m.GetSize()
+	placed := src.Crop(-x, -y, w, h)
+
+	//--templates taller than the map add levels to it--//
+	for len(m.levels) < len(placed.levels) {
+		m.AddLevel(makeGrid(w, h))
+	}
+
+	for cx := 0; cx < w; cx++ {
+		for cy := 0; cy < h; cy++ {
+			for i, grid := range placed.levels {
+				if grid[cx][cy] != 0 {
+					m.levels[i][cx][cy] = grid[cx][cy]
+				}
+			}
+			if id := placed.tileMap[cx][cy]; id != 0 {
+				m.tileMap[cx][cy] = ids[id]
+			}
+			if floor := placed.floorMap[cx][cy]; floor != 0 {
+				m.floorMap[cx][cy] = floor
+			}
+		}
+	}
+
+	for _, p := range placed.paths {
+		m.AddPath(p)
+	}
+	m.solids = append(m.solids, placed.solids...)
+	m.waypoints = append(m.waypoints, placed.waypoints...)
+	m.sprite = append(m.sprite, placed.sprite...)
+	m.numSprites = len(m.sprite)
+	m.indexSprites()
+}
+
+// identity returns a transform that shifts the map so cell x, y becomes cell 0, 0
+func (m *Map) identity(x, y int) mapTransform {
+	w, h := m.GetSize()
+	ox, oy := float64(x), float64(y)
+	return mapTransform{
+		width: w, height: h,
+		cell:  func(cx, cy int) (int, int) { return cx + x, cy + y },
+		point: func(p Vector2) Vector2 { return Vector2{X: p.X - ox, Y: p.Y - oy} },
+		dir:   func(d Vector2) Vector2 { return d },
+	}
+}
+
+// transform builds a new map by remapping the grids, tile types, sprites, solids, waypoints and paths of the map.
+// The clock is new, objectives are shared with the source map.
+func (m *Map) transform(t mapTransform) *Map {
+	levels := make([][][]int, len(m.levels))
+	if len(levels) == 0 {
+		levels = make([][][]int, 1)
+	}
+	for i := range levels {
+		levels[i] = makeGrid(t.width, t.height)
+	}
+	n := NewMapFromLevels(m.tex, levels...)
+	n.RepeatTopLevel = m.RepeatTopLevel
+	n.objectives = m.objectives
+
+	//--grids--//
+	for x := 0; x < t.width; x++ {
+		for y := 0; y < t.height; y++ {
+			sx, sy := t.cell(x, y)
+			if !m.inBounds(sx, sy) {
+				continue
+			}
+			for i, grid := range m.levels {
+				n.levels[i][x][y] = CellAt(grid, sx, sy)
+			}
+			n.tileMap[x][y] = m.tileMap[sx][sy]
+			n.floorMap[x][y] = m.floorMap[sx][sy]
+		}
+	}
+
+	//--tile types, directional tiles are copied so the source keeps its directions--//
+	for id, tile := range m.tileTypes {
+		if tile.Conveyor != nil || tile.Wind != nil {
+			copied := *tile
+			if tile.Conveyor != nil {
+				d := t.dir(*tile.Conveyor)
+				copied.Conveyor = &d
+			}
+			if tile.Wind != nil {
+				d := t.dir(*tile.Wind)
+				copied.Wind = &d
+			}
+			tile = &copied
+		}
+		n.RegisterTileType(id, tile)
+	}
+
+	//--paths, shared by followers so each is only transformed once--//
+	paths := make(map[*Path]*Path)
+	path := func(p *Path) *Path {
+		if p == nil {
+			return nil
+		}
+		if np, ok := paths[p]; ok {
+			return np
+		}
+		np := &Path{Name: p.Name, Loop: p.Loop, Points: make([]Vector2, len(p.Points))}
+		for i, pt := range p.Points {
+			np.Points[i] = t.point(pt)
+		}
+		paths[p] = np
+		return np
+	}
+	follower := func(f *PathFollower) *PathFollower {
+		if f == nil {
+			return nil
+		}
+		nf := *f
+		nf.Path = path(f.Path)
+		return &nf
+	}
+	for _, p := range m.paths {
+		n.AddPath(path(p))
+	}
+
+	inside := func(p Vector2) bool {
+		return p.X >= 0 && p.Y >= 0 && p.X <= float64(t.width) && p.Y <= float64(t.height)
+	}
+
+	//--sprites--//
+	sprites := make(map[*Sprite]*Sprite)
+	for _, s := range m.sprite {
+		pos := t.point(Vector2{X: s.X, Y: s.Y})
+		if !inside(pos) {
+			continue
+		}
+		ns := *s
+		ns.X, ns.Y = pos.X, pos.Y
+		ns.Patrol = follower(s.Patrol)
+		sprites[s] = &ns
+		n.sprite = append(n.sprite, &ns)
+	}
+
+	//--waypoints--//
+	for _, w := range m.waypoints {
+		pos := t.point(Vector2{X: w.X, Y: w.Y})
+		if !inside(pos) {
+			continue
+		}
+		nw := *w
+		nw.X, nw.Y = pos.X, pos.Y
+		n.waypoints = append(n.waypoints, &nw)
+	}
+
+	//--solids, the box is rebuilt from its turned corners--//
+	for _, s := range m.solids {
+		a, b := t.point(s.Min), t.point(s.Max)
+		min := Vector2{X: math.Min(a.X, b.X), Y: math.Min(a.Y, b.Y)}
+		max := Vector2{X: math.Max(a.X, b.X), Y: math.Max(a.Y, b.Y)}
+		if !inside(min) || !inside(max) {
+			continue
+		}
+		ns := *s
+		ns.Min, ns.Max = min, max
+		ns.Sprite = sprites[s.Sprite]
+		ns.follower = follower(s.follower)
+
+		//--the path is for the Min corner, shift it onto the corner that became Min--//
+		corner := Vector2{X: min.X - a.X, Y: min.Y - a.Y}
+		if ns.follower != nil && ns.follower.Path != nil && (corner.X != 0 || corner.Y != 0) {
+			p := ns.follower.Path
+			shifted := &Path{Name: p.Name, Loop: p.Loop, Points: make([]Vector2, len(p.Points))}
+			for i, pt := range p.Points {
+				shifted.Points[i] = Vector2{X: pt.X + corner.X, Y: pt.Y + corner.Y}
+			}
+			ns.follower.Path = shifted
+		}
+		n.AddSolid(&ns)
+	}
+
+	n.numSprites = len(n.sprite)
+	n.indexSprites()
+	return n
+}
@@ -0,0 +1,87 @@
+package sim
+
+import "testing"
+
+func TestCellAt(t *testing.T) {
+	grid := [][]int{{1, 2}, {3, 4}, {5}}
+	tests := []struct {
+		name string
+		grid [][]int
+		x, y int
+		want int
+	}{
+		{name: "inside", grid: grid, x: 1, y: 1, want: 4},
+		{name: "left of the grid", grid: grid, x: -1, y: 0},
+		{name: "above the grid", grid: grid, x: 0, y: -1},
+		{name: "right of the grid", grid: grid, x: 3, y: 0},
+		{name: "below the grid", grid: grid, x: 0, y: 2},
+		{name: "past a short column", grid: grid, x: 2, y: 1},
+		{name: "empty grid", grid: [][]int{}, x: 0, y: 0},
+		{name: "nil grid", grid: nil, x: 0, y: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := CellAt(tt.grid, tt.x, tt.y); got != tt.want {
+				t.Errorf("CellAt(%d, %d) = %d, want %d", tt.x, tt.y, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCameraOutsideMap(t *testing.T) {
+	walled := NewEmptyMap(nil, 4, 4)
+	for i := 0; i < 4; i++ {
+		walled.SetTile(i, 0, 0, 1)
+		walled.SetTile(i, 3, 0, 1)
+		walled.SetTile(0, i, 0, 1)
+		walled.SetTile(3, i, 0, 1)
+	}
+
+	tests := []struct {
+		name string
+		m    *Map
+		pos  Vector2
+	}{
+		{name: "zero size map", m: NewEmptyMap(nil, 0, 0), pos: Vector2{X: 0.5, Y: 0.5}},
+		{name: "no levels", m: NewMapFromLevels(nil), pos: Vector2{X: 1.5, Y: 1.5}},
+		{name: "single level", m: NewMapFromLevels(nil, makeGrid(4, 4)), pos: Vector2{X: 1.5, Y: 1.5}},
+		{name: "left of the map", m: walled, pos: Vector2{X: -2.5, Y: 1.5}},
+		{name: "below the map", m: walled, pos: Vector2{X: 1.5, Y: 9.5}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewCamera(tt.m, WithStartPosition(tt.pos))
+			for i := 0; i < 30; i++ {
+				c.Move(0.2)
+				c.Strafe(0.2)
+				c.Rotate(0.1)
+				tt.m.Update(1.0 / 60)
+				c.Simulate()
+			}
+
+			pos := c.GetPosition()
+			if x, y := int(pos.X), int(pos.Y); !tt.m.inBounds(x, y) && !c.isBlocked(x, y) {
+				t.Errorf("cell %d, %d off the map is not blocked", x, y)
+			}
+			if tt.m.IsWalkable(-1, -1) {
+				t.Error("cell off the map is walkable")
+			}
+		})
+	}
+}
+
+func TestMapWithoutSprites(t *testing.T) {
+	m := NewEmptyMap(nil, 8, 8)
+	c := NewCamera(m, WithStartPosition(Vector2{X: 4.5, Y: 4.5}))
+	m.Update(1.0 / 60)
+	c.UpdateSprites()
+	c.Simulate()
+
+	if n := m.GetNumSprites(); n != 0 {
+		t.Errorf("%d sprites in an empty map", n)
+	}
+	// nothing to hit, the pellets leave the open map
+	if hits := m.Hitscan(c.GetPosition(), Vector2{X: 1}, HitscanOptions{Pellets: 3, Spread: 0.5}); len(hits) != 0 {
+		t.Errorf("hits in an empty map: %v", hits)
+	}
+}
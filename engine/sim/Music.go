@@ -0,0 +1,47 @@
+package sim
+
+// MusicIntensity is how tense the music of a map should be
+type MusicIntensity int
+
+const (
+	// MusicAmbient --exploring, nothing going on--//
+	MusicAmbient MusicIntensity = iota
+
+	// MusicCombat --taking or dealing damage--//
+	MusicCombat
+
+	// MusicDanger --close to death--//
+	MusicDanger
+)
+
+// MapMusic is the music tracks of a map for each intensity, as file names the game resolves.
+// An intensity without a track plays the next calmer one.
+type MapMusic struct {
+	Ambient string `json:"ambient,omitempty"`
+	Combat  string `json:"combat,omitempty"`
+	Danger  string `json:"danger,omitempty"`
+}
+
+// Track returns the track to play at an intensity, empty if the map has no music
+func (mm MapMusic) Track(intensity MusicIntensity) string {
+	tracks := []string{mm.Ambient, mm.Combat, mm.Danger}
+	if intensity > MusicDanger {
+		intensity = MusicDanger
+	}
+	for i := intensity; i >= MusicAmbient; i-- {
+		if tracks[i] != "" {
+			return tracks[i]
+		}
+	}
+	return ""
+}
+
+// SetMusic sets the music tracks of the map
+func (m *Map) SetMusic(music MapMusic) {
+	m.music = music
+}
+
+// GetMusic returns the music tracks of the map
+func (m *Map) GetMusic() MapMusic {
+	return m.music
+}
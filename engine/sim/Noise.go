@@ -0,0 +1,119 @@
+package sim
+
+// NoiseListener is anything that can hear noises propagating through the map (e.g. AI entities)
+type NoiseListener interface {
+	// ListenPosition --grid position of the listener--//
+	ListenPosition() Vector2
+
+	// HearingThreshold --quietest loudness the listener can still hear--//
+	HearingThreshold() float64
+
+	// OnNoise --called when a noise reaches the listener, with the loudness that arrived--//
+	OnNoise(n Noise, loudness float64)
+}
+
+// Noise is a sound event at a position in the map (gunshot, footstep, door)
+type Noise struct {
+	Pos Vector2
+
+	// Loudness --starting loudness, reduced by one per cell travelled--//
+	Loudness float64
+
+	// Source --optional game defined source of the noise--//
+	Source interface{}
+
+	// Caption --optional text describing the sound for captions (e.g. "[door creaks]")--//
+	Caption string
+
+	// Sound --optional sound effect file played where the noise is heard--//
+	Sound string
+
+	// Rumble --optional controller rumble felt where the noise is heard, weaker with distance (explosions, doors)--//
+	Rumble *RumbleEnvelope
+}
+
+const (
+	// extra loudness lost when a noise passes through a wall cell
+	wallAttenuation = 4.0
+)
+
+// AddListener registers a noise listener with the map
+func (m *Map) AddListener(l NoiseListener) {
+	m.listeners = append(m.listeners, l)
+}
+
+// RemoveListener unregisters a noise listener from the map
+func (m *Map) RemoveListener(l NoiseListener) {
+	for i, other := range m.listeners {
+		if other == l {
+			m.listeners = append(m.listeners[:i], m.listeners[i+1:]...)
+			return
+		}
+	}
+}
+
+// EmitNoise propagates a noise through the grid and notifies every listener it reaches loud enough to hear,
+// and every sprite with a Perception that hears it
+func (m *Map) EmitNoise(n Noise) {
+	perceivers := m.hasPerceivers()
+	if len(m.listeners) == 0 && !perceivers {
+		return
+	}
+
+	loudness := m.propagateNoise(n)
+	if perceivers {
+		m.hearNoise(n, loudness)
+	}
+	for _, l := range m.listeners {
+		pos := l.ListenPosition()
+		x, y := int(pos.X), int(pos.Y)
+		if !m.inBounds(x, y) {
+			continue
+		}
+		if heard := loudness[x][y]; heard > 0 && heard >= l.HearingThreshold() {
+			l.OnNoise(n, heard)
+		}
+	}
+}
+
+// propagateNoise flood fills the loudness of a noise outwards through the ground level grid,
+// losing one per cell and more through walls
+func (m *Map) propagateNoise(n Noise) [][]float64 {
+	loudness := make([][]float64, len(m.worldMap))
+	for x := range loudness {
+		loudness[x] = make([]float64, len(m.worldMap[x]))
+	}
+
+	startX, startY := int(n.Pos.X), int(n.Pos.Y)
+	if !m.inBounds(startX, startY) {
+		return loudness
+	}
+	loudness[startX][startY] = n.Loudness
+
+	// cells are revisited whenever a louder path reaches them, so walls can be routed around
+	queue := [][2]int{{startX, startY}}
+	neighbors := [4][2]int{{1, 0}, {-1, 0}, {0, 1}, {0, -1}}
+	for len(queue) > 0 {
+		cell := queue[0]
+		queue = queue[1:]
+		current := loudness[cell[0]][cell[1]]
+
+		for _, d := range neighbors {
+			x, y := cell[0]+d[0], cell[1]+d[1]
+			if !m.inBounds(x, y) {
+				continue
+			}
+
+			next := current - 1
+			if m.worldMap[x][y] > 0 && !m.isOpenDoor(x, y) {
+				next -= wallAttenuation
+			}
+			if next > loudness[x][y] {
+				loudness[x][y] = next
+				queue = append(queue, [2]int{x, y})
+			}
+		}
+	}
+
+	return loudness
+}
@@ -0,0 +1,88 @@
+package sim
+
+// Objective is a single goal of a mission
+type Objective struct {
+	ID          string
+	Description string
+	Complete    bool
+}
+
+// Objectives tracks mission goals in the order they were added
+type Objectives struct {
+	list []*Objective
+	byID map[string]*Objective
+
+	// OnComplete --called when an objective is first marked complete--//
+	OnComplete func(o *Objective)
+
+	// OnAllComplete --called when the last incomplete objective is marked complete--//
+	OnAllComplete func()
+}
+
+// NewObjectives creates an empty objective tracker
+func NewObjectives() *Objectives {
+	o := &Objectives{}
+	o.byID = make(map[string]*Objective)
+	return o
+}
+
+// Add registers a new incomplete objective, replacing the description of an existing one with the same id
+func (o *Objectives) Add(id, description string) *Objective {
+	if existing, ok := o.byID[id]; ok {
+		existing.Description = description
+		return existing
+	}
+
+	obj := &Objective{ID: id, Description: description}
+	o.list = append(o.list, obj)
+	o.byID[id] = obj
+	return obj
+}
+
+// Complete marks the objective complete, returns false if there is no such objective or it was already complete
+func (o *Objectives) Complete(id string) bool {
+	obj, ok := o.byID[id]
+	if !ok || obj.Complete {
+		return false
+	}
+	obj.Complete = true
+
+	if o.OnComplete != nil {
+		o.OnComplete(obj)
+	}
+	if o.AllComplete() && o.OnAllComplete != nil {
+		o.OnAllComplete()
+	}
+	return true
+}
+
+// Get returns the objective with the given id, or nil
+func (o *Objectives) Get(id string) *Objective {
+	return o.byID[id]
+}
+
+// IsComplete returns true if the objective exists and is complete
+func (o *Objectives) IsComplete(id string) bool {
+	obj, ok := o.byID[id]
+	return ok && obj.Complete
+}
+
+// AllComplete returns true if there is at least one objective and all of them are complete
+func (o *Objectives) AllComplete() bool {
+	for _, obj := range o.list {
+		if !obj.Complete {
+			return false
+		}
+	}
+	return len(o.list) > 0
+}
+
+// List returns the objectives in the order they were added
+func (o *Objectives) List() []*Objective {
+	return o.list
+}
+
+// GetObjectives returns the objective tracker of the map
+func (m *Map) GetObjectives() *Objectives {
+	return m.objectives
+}
@@ -0,0 +1,97 @@
+package sim
+
+import "math"
+
+// Path is a named series of waypoints authored in the map, used for patrol routes and moving solids
+type Path struct {
+	Name   string
+	Points []Vector2
+
+	// Loop --return to the first point after the last, otherwise turn around at either end--//
+	Loop bool
+}
+
+// PathFollower tracks progress along a path at a constant speed per tick
+type PathFollower struct {
+	Path  *Path
+	Speed float64
+
+	target  int
+	reverse bool
+}
+
+// NewPathFollower creates a follower heading for the first point of the path
+func NewPathFollower(path *Path, speed float64) *PathFollower {
+	return &PathFollower{Path: path, Speed: speed}
+}
+
+// Target returns the waypoint currently being headed for
+func (f *PathFollower) Target() Vector2 {
+	return f.Path.Points[f.target]
+}
+
+// Step moves pos towards the current waypoint by the follower speed, moving on to the next one on arrival.
+// Returns the movement made.
+func (f *PathFollower) Step(pos *Vector2) Vector2 {
+	return f.Advance(pos, f.Speed)
+}
+
+// Advance moves pos towards the current waypoint by the given distance, moving on to the next one on arrival.
+// Returns the movement made.
+func (f *PathFollower) Advance(pos *Vector2, distance float64) Vector2 {
+	if f.Path == nil || len(f.Path.Points) == 0 || distance <= 0 {
+		return Vector2{}
+	}
+
+	target := f.Target()
+	dx, dy := target.X-pos.X, target.Y-pos.Y
+	dist := math.Sqrt(dx*dx + dy*dy)
+	if dist <= distance {
+		f.next()
+	} else {
+		dx, dy = dx/dist*distance, dy/dist*distance
+	}
+
+	pos.X += dx
+	pos.Y += dy
+	return Vector2{X: dx, Y: dy}
+}
+
+func (f *PathFollower) next() {
+	n := len(f.Path.Points)
+	if n < 2 {
+		return
+	}
+
+	if f.Path.Loop {
+		f.target = (f.target + 1) % n
+		return
+	}
+
+	if f.reverse && f.target == 0 || !f.reverse && f.target == n-1 {
+		f.reverse = !f.reverse
+	}
+	if f.reverse {
+		f.target--
+	} else {
+		f.target++
+	}
+}
+
+// AddPath adds a named waypoint path to the map, replacing any path with the same name
+func (m *Map) AddPath(p *Path) {
+	if m.paths == nil {
+		m.paths = make(map[string]*Path)
+	}
+	m.paths[p.Name] = p
+}
+
+// GetPath returns the named waypoint path, or nil if the map has none by that name
+func (m *Map) GetPath(name string) *Path {
+	return m.paths[name]
+}
+
+// GetPaths returns all waypoint paths in the map by name
+func (m *Map) GetPaths() map[string]*Path {
+	return m.paths
+}
@@ -0,0 +1,327 @@
+package sim
+
+import (
+	"container/heap"
+	"math"
+)
+
+const (
+	// nodes a queued path search expands per scheduler slice
+	defaultPathNodesPerSlice = 64
+
+	// how far a requester may move from where it asked for a path before the request is dropped as stale
+	defaultPathMoveTolerance = 1.0
+)
+
+// PathStatus is the state of a path request
+type PathStatus int
+
+const (
+	PathPending PathStatus = iota
+	PathFound
+	PathNotFound
+	PathCancelled
+)
+
+// IsWalkable returns true if the grid cell is inside the map and has no ground level wall
+func (m *Map) IsWalkable(x, y int) bool {
+	return m.inBounds(x, y) && (CellAt(m.worldMap, x, y) <= 0 || m.isOpenDoor(x, y))
+}
+
+// FindPath searches for a walkable route between two grid positions straight away, returning the cell
+// centers to pass through ending at to, or nil if there is no route
+func (m *Map) FindPath(from, to Vector2) []Vector2 {
+	search := newPathSearch(m, from, to)
+	search.step(math.MaxInt32)
+	return search.points
+}
+
+// PathRequest is a path search queued on a PathQueue and run in slices of the frame budget
+type PathRequest struct {
+	From, To Vector2
+
+	// Requester --sprite the path is for, the request is dropped if it moves away from From--//
+	Requester *Sprite
+
+	// OnDone --called once the search finishes, fails or is cancelled--//
+	OnDone func(r *PathRequest)
+
+	status PathStatus
+	points []Vector2
+	search *pathSearch
+	task   *Task
+}
+
+// Cancel drops the request, OnDone is called with the status PathCancelled if it was still pending
+func (r *PathRequest) Cancel() {
+	r.finish(PathCancelled)
+}
+
+// GetStatus returns whether the request is still pending, found a path, found none or was cancelled
+func (r *PathRequest) GetStatus() PathStatus {
+	return r.status
+}
+
+// GetPoints returns the cell centers of the path found, ending at the requested position
+func (r *PathRequest) GetPoints() []Vector2 {
+	return r.points
+}
+
+// GetPath returns the path found for following with a PathFollower, nil if none was found
+func (r *PathRequest) GetPath() *Path {
+	if r.status != PathFound {
+		return nil
+	}
+	return &Path{Points: r.points}
+}
+
+// finish ends a pending request with the given status
+func (r *PathRequest) finish(status PathStatus) {
+	if r.status != PathPending {
+		return
+	}
+	r.status = status
+	r.search = nil
+	if r.task != nil {
+		r.task.Cancel()
+	}
+	if r.OnDone != nil {
+		r.OnDone(r)
+	}
+}
+
+// PathQueue answers path requests asynchronously using the frame time a scheduler has left, so many
+// sprites can ask for routes in the same tick without making it late
+type PathQueue struct {
+	mapObj    *Map
+	scheduler *Scheduler
+
+	// NodesPerSlice --nodes a search expands in one slice, smaller slices fit the frame budget more tightly--//
+	NodesPerSlice int
+
+	// MoveTolerance --distance a requester may move before its pending request is dropped--//
+	MoveTolerance float64
+
+	pending []*PathRequest
+}
+
+// NewPathQueue creates a queue searching the map for paths with the time the scheduler has left
+func NewPathQueue(m *Map, s *Scheduler) *PathQueue {
+	return &PathQueue{
+		mapObj:        m,
+		scheduler:     s,
+		NodesPerSlice: defaultPathNodesPerSlice,
+		MoveTolerance: defaultPathMoveTolerance,
+	}
+}
+
+// Request queues a path search from one grid position to another for the requester, which may be nil.
+// onDone is called once the search finds a path, fails or the request is cancelled.
+func (q *PathQueue) Request(from, to Vector2, requester *Sprite, onDone func(r *PathRequest)) *PathRequest {
+	r := &PathRequest{From: from, To: to, Requester: requester, OnDone: onDone}
+	r.search = newPathSearch(q.mapObj, from, to)
+	r.task = q.scheduler.Schedule("path", PriorityLow, func() bool {
+		return q.step(r)
+	})
+	q.pending = append(q.pending, r)
+	return r
+}
+
+// CancelRequester cancels every pending request of a sprite, for when it dies or is removed
+func (q *PathQueue) CancelRequester(s *Sprite) {
+	for _, r := range q.pending {
+		if r.Requester == s {
+			r.Cancel()
+		}
+	}
+	q.prune()
+}
+
+// CancelAll cancels every pending request, for when the map is replaced
+func (q *PathQueue) CancelAll() {
+	for _, r := range q.pending {
+		r.Cancel()
+	}
+	q.prune()
+}
+
+// Pending returns the number of requests still searching
+func (q *PathQueue) Pending() int {
+	q.prune()
+	return len(q.pending)
+}
+
+// step runs one slice of a request search, returning true once the request is no longer pending
+func (q *PathQueue) step(r *PathRequest) bool {
+	if r.status != PathPending {
+		return true
+	}
+	if s := r.Requester; s != nil && math.Hypot(s.X-r.From.X, s.Y-r.From.Y) > q.MoveTolerance {
+		r.Cancel()
+		q.prune()
+		return true
+	}
+
+	if !r.search.step(q.NodesPerSlice) {
+		return false
+	}
+	r.points = r.search.points
+	if r.points == nil {
+		r.finish(PathNotFound)
+	} else {
+		r.finish(PathFound)
+	}
+	q.prune()
+	return true
+}
+
+// prune drops requests that are no longer pending
+func (q *PathQueue) prune() {
+	pending := q.pending[:0]
+	for _, r := range q.pending {
+		if r.status == PathPending {
+			pending = append(pending, r)
+		}
+	}
+	for i := len(pending); i < len(q.pending); i++ {
+		q.pending[i] = nil
+	}
+	q.pending = pending
+}
+
+// pathNode is a cell in the open set of a search
+type pathNode struct {
+	cell int
+	f    float64
+}
+
+// pathHeap orders open cells by estimated total cost
+type pathHeap []pathNode
+
+func (h pathHeap) Len() int            { return len(h) }
+func (h pathHeap) Less(i, j int) bool  { return h[i].f < h[j].f }
+func (h pathHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *pathHeap) Push(x interface{}) { *h = append(*h, x.(pathNode)) }
+func (h *pathHeap) Pop() interface{} {
+	old := *h
+	n := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return n
+}
+
+// pathSearch is an A* search over walkable cells that can be run a few nodes at a time.
+// It moves in eight directions but never cuts the corner of a wall.
+type pathSearch struct {
+	mapObj *Map
+	h      int
+	goal   int
+	to     Vector2
+
+	open     pathHeap
+	cost     map[int]float64
+	cameFrom map[int]int
+	closed   map[int]bool
+
+	done   bool
+	points []Vector2
+}
+
+// newPathSearch starts a search from the cell of from to the cell of to
+func newPathSearch(m *Map, from, to Vector2) *pathSearch {
+	_, h := m.GetSize()
+	s := &pathSearch{
+		mapObj:   m,
+		h:        h,
+		to:       to,
+		cost:     make(map[int]float64),
+		cameFrom: make(map[int]int),
+		closed:   make(map[int]bool),
+	}
+
+	sx, sy, gx, gy := int(from.X), int(from.Y), int(to.X), int(to.Y)
+	if !m.IsWalkable(sx, sy) || !m.IsWalkable(gx, gy) {
+		s.done = true
+		return s
+	}
+	start := s.cell(sx, sy)
+	s.goal = s.cell(gx, gy)
+	s.cost[start] = 0
+	heap.Push(&s.open, pathNode{cell: start, f: s.estimate(start)})
+	return s
+}
+
+// step expands up to n cells, returning true once the search is over with points set if a path was found
+func (s *pathSearch) step(n int) bool {
+	for i := 0; i < n && !s.done; i++ {
+		if s.open.Len() == 0 {
+			s.done = true
+			break
+		}
+
+		node := heap.Pop(&s.open).(pathNode)
+		if s.closed[node.cell] {
+			continue
+		}
+		if node.cell == s.goal {
+			s.points = s.trace()
+			s.done = true
+			break
+		}
+		s.closed[node.cell] = true
+
+		x, y := s.xy(node.cell)
+		for dx := -1; dx <= 1; dx++ {
+			for dy := -1; dy <= 1; dy++ {
+				nx, ny := x+dx, y+dy
+				if dx == 0 && dy == 0 || !s.mapObj.IsWalkable(nx, ny) {
+					continue
+				}
+				if dx != 0 && dy != 0 && (!s.mapObj.IsWalkable(x+dx, y) || !s.mapObj.IsWalkable(x, y+dy)) {
+					continue
+				}
+
+				next := s.cell(nx, ny)
+				cost := s.cost[node.cell] + math.Hypot(float64(dx), float64(dy))
+				if old, ok := s.cost[next]; ok && old <= cost {
+					continue
+				}
+				s.cost[next] = cost
+				s.cameFrom[next] = node.cell
+				heap.Push(&s.open, pathNode{cell: next, f: cost + s.estimate(next)})
+			}
+		}
+	}
+	return s.done
+}
+
+// trace walks back from the goal to build the path, ending at the exact requested position
+func (s *pathSearch) trace() []Vector2 {
+	var cells []int
+	for cell, ok := s.goal, true; ok; cell, ok = s.cameFrom[cell] {
+		cells = append(cells, cell)
+	}
+
+	points := make([]Vector2, 0, len(cells))
+	// the first cell is where the requester already is
+	for i := len(cells) - 2; i > 0; i-- {
+		x, y := s.xy(cells[i])
+		points = append(points, Vector2{X: float64(x) + 0.5, Y: float64(y) + 0.5})
+	}
+	return append(points, s.to)
+}
+
+// estimate returns the octile distance from a cell to the goal
+func (s *pathSearch) estimate(cell int) float64 {
+	x, y := s.xy(cell)
+	gx, gy := s.xy(s.goal)
+	dx, dy := math.Abs(float64(x-gx)), math.Abs(float64(y-gy))
+	return math.Max(dx, dy) + (math.Sqrt2-1)*math.Min(dx, dy)
+}
+
+func (s *pathSearch) cell(x, y int) int {
+	return x*s.h + y
+}
+
+func (s *pathSearch) xy(cell int) (int, int) {
+	return cell / s.h, cell % s.h
+}
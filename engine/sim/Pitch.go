@@ -0,0 +1,24 @@
+package sim
+
+import "math"
+
+const (
+	// furthest the view can be pitched up or down in degrees, y-shearing distorts walls past this
+	maxPitch = 25.0
+)
+
+// SetPitch sets how far the view looks up (positive) or down (negative) in degrees, clamped to 25 either way.
+// Pitch is done by y-shearing: the horizon moves up and down the screen and walls stay vertical.
+func (c *Camera) SetPitch(degrees float64) {
+	c.pitch = math.Max(-maxPitch, math.Min(maxPitch, degrees))
+}
+
+// Pitch looks up or down by delta degrees, e.g. from vertical mouse movement
+func (c *Camera) Pitch(delta float64) {
+	c.SetPitch(c.pitch + delta)
+}
+
+// GetPitch returns how far the view looks up or down in degrees
+func (c *Camera) GetPitch() float64 {
+	return c.pitch
+}
@@ -0,0 +1,55 @@
+package sim
+
+import "math"
+
+const (
+	// fraction of external velocity kept each tick at the movement target framerate
+	pushFriction = 0.9
+
+	// external velocity below which the camera is considered at rest
+	pushRestSpeed = 0.0005
+)
+
+// Push adds an impulse to the camera external velocity (e.g. knockback)
+func (c *Camera) Push(impulse Vector2) {
+	c.vel.X += impulse.X
+	c.vel.Y += impulse.Y
+}
+
+// GetVelocity returns the camera external velocity per tick
+func (c *Camera) GetVelocity() Vector2 {
+	return c.vel
+}
+
+// onFloor returns true if the camera feet are resting on the floor of its grid cell
+func (c *Camera) onFloor() bool {
+	return c.posZ-c.ground() < 0.01
+}
+
+// updatePush applies wind and conveyor tiles and the external velocity to the camera position
+func (c *Camera) updatePush() {
+	t := c.mapObj.GetTileType(int(c.pos.X), int(c.pos.Y))
+
+	var dx, dy float64
+	if t != nil {
+		if t.Wind != nil {
+			c.vel.X += c.getNormalSpeed(t.Wind.X)
+			c.vel.Y += c.getNormalSpeed(t.Wind.Y)
+		}
+		if t.Conveyor != nil && c.onFloor() && c.ground() == 0 {
+			dx += c.getNormalSpeed(t.Conveyor.X)
+			dy += c.getNormalSpeed(t.Conveyor.Y)
+		}
+	}
+
+	dx += c.getNormalSpeed(c.vel.X)
+	dy += c.getNormalSpeed(c.vel.Y)
+	c.translate(dx, dy)
+
+	friction := math.Pow(pushFriction, c.dt*movementTPS)
+	c.vel.X *= friction
+	c.vel.Y *= friction
+	if c.vel.X*c.vel.X+c.vel.Y*c.vel.Y < pushRestSpeed*pushRestSpeed {
+		c.vel = Vector2{}
+	}
+}
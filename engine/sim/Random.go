@@ -0,0 +1,77 @@
+package sim
+
+import (
+	"encoding/binary"
+	"hash/fnv"
+	"math"
+	"math/rand"
+)
+
+// randSource is a splitmix64 generator. All of its state is one number, so games that start from the same
+// seed draw the same values in the same order.
+type randSource struct {
+	state uint64
+}
+
+func (s *randSource) Seed(seed int64) {
+	s.state = uint64(seed)
+}
+
+func (s *randSource) Uint64() uint64 {
+	s.state += 0x9E3779B97F4A7C15
+	z := s.state
+	z = (z ^ (z >> 30)) * 0xBF58476D1CE4E5B9
+	z = (z ^ (z >> 27)) * 0x94D049BB133111EB
+	return z ^ (z >> 31)
+}
+
+func (s *randSource) Int63() int64 {
+	return int64(s.Uint64() >> 1)
+}
+
+// SetSeed restarts the random numbers the map simulation draws (spawn scatter, hitscan spread) from a seed.
+// Games simulating the same map together must use the same seed.
+func (m *Map) SetSeed(seed int64) {
+	m.randSrc = &randSource{}
+	m.randSrc.Seed(seed)
+	m.rand = rand.New(m.randSrc)
+}
+
+// GetRand returns the random source of the map simulation. Anything that changes the simulation must draw
+// from it rather than from the global math/rand source, and anything that does not must leave it alone.
+func (m *Map) GetRand() *rand.Rand {
+	return m.rand
+}
+
+// Checksum hashes the positions of the cameras given, the sprites, doors, spawner progress, clock time and
+// random source of the map, which are the first things to drift apart when two games stop simulating the
+// same world
+func (m *Map) Checksum(cams ...*Camera) uint64 {
+	h := fnv.New64a()
+	var buf [8]byte
+	put := func(v uint64) {
+		binary.LittleEndian.PutUint64(buf[:], v)
+		h.Write(buf[:])
+	}
+	for _, c := range cams {
+		put(math.Float64bits(c.pos.X))
+		put(math.Float64bits(c.pos.Y))
+	}
+	for _, s := range m.sprite {
+		put(uint64(s.id))
+		put(math.Float64bits(s.X))
+		put(math.Float64bits(s.Y))
+	}
+	for _, d := range m.doors {
+		put(uint64(d.state))
+		put(math.Float64bits(d.open))
+	}
+	for _, sp := range m.spawners {
+		put(uint64(sp.wave))
+		put(uint64(sp.spawned))
+		put(math.Float64bits(sp.timer))
+	}
+	put(math.Float64bits(m.clock.Now()))
+	put(m.randSrc.state)
+	return h.Sum64()
+}
@@ -0,0 +1,135 @@
+package sim
+
+import "math"
+
+// RayHit describes where a ray cast through the map stopped
+type RayHit struct {
+	// MapX, MapY --grid cell of the wall that was hit--//
+	MapX, MapY int
+
+	// Side --0 if an x-side (NS) wall was hit, 1 for a y-side (EW) wall--//
+	Side int
+
+	// Point --exact position where the ray stopped--//
+	Point Vector2
+
+	// Distance --distance from the ray origin to Point--//
+	Distance float64
+
+	// Texture --wall texture index drawn for the side of the cell that was hit, -1 if the ray stopped at a sprite--//
+	Texture int
+
+	// Door --the sliding door that was hit, nil if the ray stopped at a wall or sprite--//
+	Door *Door
+
+	// Sprite --the ray-blocking sprite that was hit, nil if the ray stopped at a wall--//
+	Sprite *Sprite
+}
+
+// CastRay traces a ray along the ground level from origin in direction dir until it hits a wall
+// or a ray-blocking sprite. Returns nil if the ray leaves the map without hitting anything.
+func (m *Map) CastRay(origin, dir Vector2) *RayHit {
+	return m.castRay(origin, dir, math.Inf(1))
+}
+
+// CastRay traces a ray through the map of the camera with the DDA used for rendering, stopping at walls,
+// the closed part of sliding doors and ray-blocking sprites, for hitscan weapons, line of sight and
+// interaction checks. Returns nil if the ray leaves the map without hitting anything.
+func (c *Camera) CastRay(origin, dir Vector2) *RayHit {
+	return c.mapObj.castRay(origin, dir, math.Inf(1))
+}
+
+// CastForward casts a ray from the camera along its facing direction, see CastRay
+func (c *Camera) CastForward() *RayHit {
+	return c.CastRay(*c.pos, *c.dir)
+}
+
+// HasLineOfSight returns true if nothing blocks a ray between the two grid positions
+func (m *Map) HasLineOfSight(from, to Vector2) bool {
+	dx, dy := to.X-from.X, to.Y-from.Y
+	dist := math.Sqrt(dx*dx + dy*dy)
+	if dist == 0 {
+		return true
+	}
+	return m.castRay(from, Vector2{X: dx, Y: dy}, dist) == nil
+}
+
+// castRay steps the DDA from origin for up to maxDist, checking indexed ray-blocking sprites in each cell passed
+func (m *Map) castRay(origin, dir Vector2, maxDist float64) *RayHit {
+	length := math.Sqrt(dir.X*dir.X + dir.Y*dir.Y)
+	if length == 0 {
+		return nil
+	}
+	rayDirX, rayDirY := dir.X/length, dir.Y/length
+
+	dda := NewDDA(origin, Vector2{X: rayDirX, Y: rayDirY})
+
+	var spriteHit *RayHit
+	for m.inBounds(dda.MapX, dda.MapY) && dda.Enter < maxDist {
+		//--nearest blocking sprite overlapping this cell--//
+		for _, s := range m.spritesInCell(dda.MapX, dda.MapY) {
+			if !s.BlocksRays {
+				continue
+			}
+			if d, ok := rayCircle(origin, rayDirX, rayDirY, s.X, s.Y, s.Radius); ok && d <= maxDist {
+				if spriteHit == nil || d < spriteHit.Distance {
+					spriteHit = &RayHit{MapX: dda.MapX, MapY: dda.MapY, Distance: d, Sprite: s}
+				}
+			}
+		}
+		if spriteHit != nil && spriteHit.Distance <= dda.Enter {
+			// nothing further along can be closer
+			break
+		}
+
+		dda.Step()
+		if dda.Enter > maxDist {
+			break
+		}
+		mapX, mapY := dda.MapX, dda.MapY
+		if !m.inBounds(mapX, mapY) || m.worldMap[mapX][mapY] <= 0 {
+			continue
+		}
+
+		//--doors are hit halfway into their cell, where they are not open--//
+		hitDist, side, door := dda.Enter, dda.Side, m.GetDoor(mapX, mapY)
+		if door != nil {
+			dist, doorSide, _, ok := door.Intersect(origin.X, origin.Y, rayDirX, rayDirY, dda.Enter, dda.Exit())
+			if !ok || dist > maxDist {
+				continue
+			}
+			hitDist, side = dist, doorSide
+		}
+
+		if spriteHit != nil && spriteHit.Distance < hitDist {
+			break
+		}
+		return &RayHit{
+			MapX: mapX, MapY: mapY, Side: side,
+			Point:    Vector2{X: origin.X + rayDirX*hitDist, Y: origin.Y + rayDirY*hitDist},
+			Distance: hitDist,
+			Texture:  WallTexture(m.worldMap[mapX][mapY], side),
+			Door:     door,
+		}
+	}
+
+	if spriteHit != nil {
+		spriteHit.Texture = -1
+		spriteHit.Point = Vector2{X: origin.X + rayDirX*spriteHit.Distance, Y: origin.Y + rayDirY*spriteHit.Distance}
+	}
+	return spriteHit
+}
+
+// rayCircle returns the distance along a normalized ray to where it enters the circle, if it does
+func rayCircle(origin Vector2, dirX, dirY, cx, cy, radius float64) (float64, bool) {
+	ox, oy := cx-origin.X, cy-origin.Y
+	t := ox*dirX + oy*dirY
+	if t < 0 {
+		return 0, false
+	}
+	d2 := ox*ox + oy*oy - t*t
+	if d2 > radius*radius {
+		return 0, false
+	}
+	return t - math.Sqrt(radius*radius-d2), true
+}
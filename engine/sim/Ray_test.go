@@ -0,0 +1,32 @@
+package sim
+
+import "testing"
+
+func TestRayTextureMatchesRendering(t *testing.T) {
+	m := NewEmptyMap(nil, 8, 8)
+	m.SetTile(6, 4, 0, 2)
+	m.SetTile(4, 6, 0, 2)
+	origin := Vector2{X: 4.5, Y: 4.5}
+
+	tests := []struct {
+		name string
+		dir  Vector2
+		side int
+	}{
+		{name: "x-side", dir: Vector2{X: 1}, side: 0},
+		{name: "y-side", dir: Vector2{Y: 1}, side: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			want := WallTexture(2, tt.side)
+			hit := m.CastRay(origin, tt.dir)
+			if hit == nil || hit.Side != tt.side || hit.Texture != want {
+				t.Fatalf("CastRay hit %+v, want side %d texture %d", hit, tt.side, want)
+			}
+			shots := m.Hitscan(origin, tt.dir, HitscanOptions{})
+			if len(shots) != 1 || shots[0].Side != tt.side || shots[0].Texture != want {
+				t.Errorf("Hitscan hit %+v, want side %d texture %d", shots, tt.side, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,68 @@
+package sim
+
+import "image"
+
+// ReverbPreset is a simple echo applied to sounds heard in a part of the map
+type ReverbPreset struct {
+	// Name --name of the preset, also what sounds processed with it are cached by--//
+	Name string `json:"name"`
+
+	// Delay --seconds between echoes--//
+	Delay float64 `json:"delay,omitempty"`
+
+	// Feedback --how much of each echo carries into the next, from 0 to below 1--//
+	Feedback float64 `json:"feedback,omitempty"`
+
+	// Mix --loudness of the echoes against the dry sound, from 0 to 1--//
+	Mix float64 `json:"mix,omitempty"`
+}
+
+var (
+	// ReverbDry --no echo--//
+	ReverbDry = ReverbPreset{Name: "dry"}
+
+	// ReverbOutdoor --a faint, late slap back--//
+	ReverbOutdoor = ReverbPreset{Name: "outdoor", Delay: 0.14, Feedback: 0.15, Mix: 0.15}
+
+	// ReverbHall --a long smooth tail--//
+	ReverbHall = ReverbPreset{Name: "hall", Delay: 0.08, Feedback: 0.55, Mix: 0.35}
+
+	// ReverbCave --dense, ringing echoes--//
+	ReverbCave = ReverbPreset{Name: "cave", Delay: 0.045, Feedback: 0.7, Mix: 0.5}
+)
+
+// ReverbRegion assigns a reverb preset to a rectangle of grid cells
+type ReverbRegion struct {
+	Region image.Rectangle `json:"region"`
+	Preset ReverbPreset    `json:"preset"`
+}
+
+// SetDefaultReverb sets the reverb heard outside every reverb region
+func (m *Map) SetDefaultReverb(preset ReverbPreset) {
+	m.defaultReverb = preset
+}
+
+// AddReverbRegion assigns a reverb preset to the grid cells of the region,
+// where regions overlap the one added last is heard
+func (m *Map) AddReverbRegion(region image.Rectangle, preset ReverbPreset) {
+	m.reverbRegions = append(m.reverbRegions, &ReverbRegion{Region: region, Preset: preset})
+}
+
+// GetReverbRegions returns the reverb regions of the map
+func (m *Map) GetReverbRegions() []*ReverbRegion {
+	return m.reverbRegions
+}
+
+// GetReverb returns the reverb preset heard at a grid position
+func (m *Map) GetReverb(pos Vector2) ReverbPreset {
+	cell := image.Pt(int(pos.X), int(pos.Y))
+	for i := len(m.reverbRegions) - 1; i >= 0; i-- {
+		if r := m.reverbRegions[i]; cell.In(r.Region) {
+			return r.Preset
+		}
+	}
+	if m.defaultReverb.Name == "" {
+		return ReverbDry
+	}
+	return m.defaultReverb
+}
@@ -0,0 +1,46 @@
+package sim
+
+var (
+	// RumbleDamage --a short hard jolt when the player is hurt--//
+	RumbleDamage = RumbleEnvelope{Strength: 0.8, Attack: 0, Hold: 0.1, Release: 0.2}
+
+	// RumbleExplosion --a strong, slowly fading shudder--//
+	RumbleExplosion = RumbleEnvelope{Strength: 1, Attack: 0.02, Hold: 0.2, Release: 0.8}
+
+	// RumbleThunk --a brief knock (doors closing, heavy landings)--//
+	RumbleThunk = RumbleEnvelope{Strength: 0.5, Attack: 0, Hold: 0.05, Release: 0.1}
+)
+
+// RumbleEnvelope is how the strength of a rumble changes over time: it ramps up to Strength over Attack
+// seconds, stays there for Hold seconds, then fades out over Release seconds
+type RumbleEnvelope struct {
+	Strength float64
+	Attack   float64
+	Hold     float64
+	Release  float64
+}
+
+// Duration returns the total seconds of the rumble
+func (e RumbleEnvelope) Duration() float64 {
+	return e.Attack + e.Hold + e.Release
+}
+
+// At returns the strength of the rumble t seconds after it started
+func (e RumbleEnvelope) At(t float64) float64 {
+	switch {
+	case t < 0 || t >= e.Duration():
+		return 0
+	case t < e.Attack:
+		return e.Strength * t / e.Attack
+	case t < e.Attack+e.Hold:
+		return e.Strength
+	default:
+		return e.Strength * (1 - (t-e.Attack-e.Hold)/e.Release)
+	}
+}
+
+// Scaled returns the envelope with its strength multiplied by scale
+func (e RumbleEnvelope) Scaled(scale float64) RumbleEnvelope {
+	e.Strength *= scale
+	return e
+}
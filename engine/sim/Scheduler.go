@@ -0,0 +1,158 @@
+package sim
+
+import "time"
+
+const (
+	// weight of the newest slice in the running estimate of a task's slice time
+	sliceCostWeight = 0.25
+)
+
+// TaskPriority orders background tasks, higher priority tasks get the frame time left over first
+type TaskPriority int
+
+const (
+	PriorityLow TaskPriority = iota
+	PriorityNormal
+	PriorityHigh
+
+	numPriorities
+)
+
+// Task is a piece of low priority work split into slices that the scheduler runs when frame time is left over
+type Task struct {
+	// Name --shown when debugging the scheduler--//
+	Name string
+
+	// Priority --tasks of higher priority run first, tasks of the same priority take turns--//
+	Priority TaskPriority
+
+	// Step --does one slice of the work and returns true once all of it is done--//
+	Step func() bool
+
+	done      bool
+	cancelled bool
+
+	// running estimate of how long one slice takes
+	cost time.Duration
+}
+
+// Cancel stops the task before its next slice
+func (t *Task) Cancel() {
+	t.cancelled = true
+}
+
+// IsDone returns true once the task has finished all its work
+func (t *Task) IsDone() bool {
+	return t.done
+}
+
+// IsCancelled returns true if the task was cancelled before finishing
+func (t *Task) IsCancelled() bool {
+	return t.cancelled
+}
+
+// Scheduler runs background tasks in slices within the time a frame has left, so work such as swapping
+// in decoded textures or answering path requests never makes a tick late
+type Scheduler struct {
+	queues [numPriorities][]*Task
+
+	// slices run and time spent in the last Run
+	lastSlices int
+	lastTime   time.Duration
+}
+
+// NewScheduler creates a scheduler with no tasks
+func NewScheduler() *Scheduler {
+	return &Scheduler{}
+}
+
+// Schedule queues work to be done in slices, step is called once per slice until it returns true
+func (s *Scheduler) Schedule(name string, priority TaskPriority, step func() bool) *Task {
+	if priority < PriorityLow {
+		priority = PriorityLow
+	} else if priority >= numPriorities {
+		priority = numPriorities - 1
+	}
+
+	t := &Task{Name: name, Priority: priority, Step: step}
+	s.queues[priority] = append(s.queues[priority], t)
+	return t
+}
+
+// Run does slices of the queued tasks until the budget is used up and returns the number of slices run.
+// The first slice always runs when there is any budget, after that a slice only starts if the estimate of
+// how long it takes fits in the time left. Call it from the game loop while no camera is casting.
+func (s *Scheduler) Run(budget time.Duration) int {
+	s.lastSlices, s.lastTime = 0, 0
+	if budget <= 0 {
+		return 0
+	}
+
+	start := time.Now()
+	deadline := start.Add(budget)
+	for {
+		t := s.next()
+		if t == nil {
+			break
+		}
+		if s.lastSlices > 0 && time.Until(deadline) < t.cost {
+			break
+		}
+
+		sliceStart := time.Now()
+		t.done = t.Step()
+		s.lastSlices++
+		if slice := time.Since(sliceStart); t.cost == 0 {
+			t.cost = slice
+		} else {
+			t.cost = time.Duration(float64(t.cost)*(1-sliceCostWeight) + float64(slice)*sliceCostWeight)
+		}
+
+		// take turns with the other tasks of the same priority
+		q := s.queues[t.Priority]
+		s.queues[t.Priority] = q[1:]
+		if !t.done {
+			s.queues[t.Priority] = append(s.queues[t.Priority], t)
+		}
+
+		if !time.Now().Before(deadline) {
+			break
+		}
+	}
+
+	s.lastTime = time.Since(start)
+	return s.lastSlices
+}
+
+// next returns the task at the head of the highest priority queue, dropping cancelled tasks
+func (s *Scheduler) next() *Task {
+	for p := numPriorities - 1; p >= PriorityLow; p-- {
+		q := s.queues[p]
+		for len(q) > 0 && q[0].cancelled {
+			q = q[1:]
+		}
+		s.queues[p] = q
+		if len(q) > 0 {
+			return q[0]
+		}
+	}
+	return nil
+}
+
+// Pending returns the number of tasks not finished or cancelled yet
+func (s *Scheduler) Pending() int {
+	pending := 0
+	for _, q := range s.queues {
+		for _, t := range q {
+			if !t.cancelled {
+				pending++
+			}
+		}
+	}
+	return pending
+}
+
+// GetLastRun returns the number of slices run and the time spent in the last Run
+func (s *Scheduler) GetLastRun() (int, time.Duration) {
+	return s.lastSlices, s.lastTime
+}
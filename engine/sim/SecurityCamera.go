@@ -0,0 +1,102 @@
+package sim
+
+import "math"
+
+// SecurityCamera is a fixed or sweeping camera placed in a map whose view the game can render into a
+// texture on demand, shown on the HUD or on wall tiles used as screens (surveillance puzzles, observers)
+type SecurityCamera struct {
+	// Name --looked up with Map.GetSecurityCamera--//
+	Name string
+
+	// Pos --grid position of the camera--//
+	Pos Vector2
+
+	// Angle --facing in degrees, 0 along +x and 90 along +y--//
+	Angle float64
+
+	// Height --eye height above the standing eye height of the player--//
+	Height float64 `json:",omitempty"`
+
+	// Pitch --degrees the view looks up (positive) or down (negative)--//
+	Pitch float64 `json:",omitempty"`
+
+	// FOV --horizontal field of view in degrees, 0 for the default--//
+	FOV float64 `json:",omitempty"`
+
+	// Sweep --degrees the camera turns to either side of Angle and back, 0 for a fixed camera--//
+	Sweep float64 `json:",omitempty"`
+
+	// SweepSpeed --degrees per second the camera turns while sweeping--//
+	SweepSpeed float64 `json:",omitempty"`
+
+	// Screen --wall texture number (as in the level grids) the view is shown on, 0 for none--//
+	Screen int `json:",omitempty"`
+
+	// Disabled --the camera is off or destroyed and its screens show static--//
+	Disabled bool `json:",omitempty"`
+
+	// sweep phase in radians
+	phase float64
+}
+
+// GetAngle returns the facing of the camera in degrees, including its sweep
+func (sc *SecurityCamera) GetAngle() float64 {
+	return sc.Angle + sc.Sweep*math.Sin(sc.phase)
+}
+
+// GetDirection returns the facing of the camera as a unit vector
+func (sc *SecurityCamera) GetDirection() Vector2 {
+	a := sc.GetAngle() * math.Pi / 180
+	return Vector2{X: math.Cos(a), Y: math.Sin(a)}
+}
+
+// update turns a sweeping camera, slowing at either end of its sweep
+func (sc *SecurityCamera) update(dt float64) {
+	if sc.Sweep <= 0 || sc.SweepSpeed <= 0 || sc.Disabled {
+		return
+	}
+	// a full sweep from side to side and back covers four times the sweep angle
+	sc.phase += 2 * math.Pi * sc.SweepSpeed * dt / (4 * sc.Sweep)
+	if sc.phase > 2*math.Pi {
+		sc.phase -= 2 * math.Pi
+	}
+}
+
+// AddSecurityCamera adds a security camera to the map
+func (m *Map) AddSecurityCamera(sc *SecurityCamera) {
+	m.securityCameras = append(m.securityCameras, sc)
+}
+
+// GetSecurityCamera returns the security camera with the given name, nil if there is none
+func (m *Map) GetSecurityCamera(name string) *SecurityCamera {
+	for _, sc := range m.securityCameras {
+		if sc.Name == name {
+			return sc
+		}
+	}
+	return nil
+}
+
+// GetSecurityCameras returns the security cameras in the map
+func (m *Map) GetSecurityCameras() []*SecurityCamera {
+	return m.securityCameras
+}
+
+// LookFrom moves the camera to the position, facing, height, pitch and field of view of a security camera
+// in its map, for rendering what the security camera sees
+func (c *Camera) LookFrom(sc *SecurityCamera) {
+	dir := sc.GetDirection()
+	*c.pos = sc.Pos
+	c.cellX, c.cellY = int(sc.Pos.X), int(sc.Pos.Y)
+	c.posZ = sc.Height
+
+	// plane at right angles to the direction, turned the same way as the plane of the player camera
+	fov := sc.FOV
+	if fov <= 0 {
+		fov = DefaultFOV
+	}
+	*c.dir = dir
+	*c.plane = Vector2{X: dir.Y, Y: -dir.X}
+	c.SetFOV(fov)
+	c.SetPitch(sc.Pitch)
+}
@@ -0,0 +1,167 @@
+package sim
+
+import (
+	"image"
+	"image/draw"
+	"math"
+)
+
+// SheetLayout tells which axis of a sprite sheet holds the facing directions
+type SheetLayout int
+
+const (
+	// SheetRowsAreDirections --each row is one facing direction, each column one animation frame--//
+	SheetRowsAreDirections SheetLayout = iota
+
+	// SheetColumnsAreDirections --each column is one facing direction, each row one animation frame--//
+	SheetColumnsAreDirections
+)
+
+// AnimationSet is the animation frames of a sprite for each direction it can be seen from.
+// Direction 0 is the sprite facing the camera, the following directions turn counter clockwise
+// in even steps around the sprite (8 directions are 45 degrees apart).
+type AnimationSet struct {
+	// Directions --animation frames for each direction, all with the same number of frames--//
+	Directions [][]image.Image
+}
+
+// FrameCopier copies the part of a sprite sheet inside r into an image of its own, starting at 0, 0
+type FrameCopier func(sheet image.Image, r image.Rectangle) image.Image
+
+// copyFrame copies the frames of sprite sheets, see SetFrameCopier
+var copyFrame FrameCopier = copyRGBA
+
+// SetFrameCopier sets how frames are copied out of sprite sheets. The raycaster sets one making images it
+// can draw, the default copies into an image.RGBA.
+func SetFrameCopier(fn FrameCopier) {
+	copyFrame = fn
+}
+
+// copyRGBA copies the part of a sheet inside r into an image.RGBA
+func copyRGBA(sheet image.Image, r image.Rectangle) image.Image {
+	frame := image.NewRGBA(image.Rect(0, 0, r.Dx(), r.Dy()))
+	draw.Draw(frame, frame.Bounds(), sheet, r.Min, draw.Src)
+	return frame
+}
+
+// SliceSheet cuts a sprite sheet into frames of the given size, returned by row then column.
+// Pixels past the last whole frame along either axis are ignored.
+func SliceSheet(img image.Image, frameWidth, frameHeight int) [][]image.Image {
+	if img == nil || frameWidth <= 0 || frameHeight <= 0 {
+		return nil
+	}
+	b := img.Bounds()
+	columns, rows := b.Dx()/frameWidth, b.Dy()/frameHeight
+
+	frames := make([][]image.Image, rows)
+	for r := 0; r < rows; r++ {
+		frames[r] = make([]image.Image, columns)
+		for c := 0; c < columns; c++ {
+			cellRect := image.Rect(c*frameWidth, r*frameHeight, (c+1)*frameWidth, (r+1)*frameHeight).Add(b.Min)
+
+			//--copied into its own image so the frame can be drawn in slices from 0, 0--//
+			frames[r][c] = copyFrame(img, cellRect)
+		}
+	}
+	return frames
+}
+
+// NewAnimationSet slices a sprite sheet of frameWidth by frameHeight frames into an animation set,
+// with directions along the rows or the columns of the sheet
+func NewAnimationSet(img image.Image, frameWidth, frameHeight int, layout SheetLayout) *AnimationSet {
+	frames := SliceSheet(img, frameWidth, frameHeight)
+	if layout == SheetRowsAreDirections {
+		return &AnimationSet{Directions: frames}
+	}
+
+	//--transpose so each direction is a column of the sheet--//
+	a := &AnimationSet{}
+	if len(frames) == 0 {
+		return a
+	}
+	a.Directions = make([][]image.Image, len(frames[0]))
+	for d := range a.Directions {
+		a.Directions[d] = make([]image.Image, len(frames))
+		for f := range frames {
+			a.Directions[d][f] = frames[f][d]
+		}
+	}
+	return a
+}
+
+// NumDirections returns the number of directions the sprite can be seen from
+func (a *AnimationSet) NumDirections() int {
+	return len(a.Directions)
+}
+
+// NumFrames returns the number of animation frames in each direction
+func (a *AnimationSet) NumFrames() int {
+	if len(a.Directions) == 0 {
+		return 0
+	}
+	return len(a.Directions[0])
+}
+
+// Frame returns the animation frame for a direction, both wrapping around past the last one
+func (a *AnimationSet) Frame(direction, frame int) image.Image {
+	if a.NumDirections() == 0 || a.NumFrames() == 0 {
+		return nil
+	}
+	frames := a.Directions[wrapIndex(direction, len(a.Directions))]
+	return frames[wrapIndex(frame, len(frames))]
+}
+
+// NewSpriteFromAnimationSet creates an animated sprite that shows the frames of the direction it is seen
+// from, turning with its Dir. Animations index the frames of a single direction.
+func NewSpriteFromAnimationSet(x, y float64, a *AnimationSet) *Sprite {
+	s := &Sprite{}
+	s.X, s.Y = x, y
+	if a.NumFrames() > 0 {
+		s.textures = append([]image.Image(nil), a.Directions[0]...)
+	} else {
+		s.textures = make([]image.Image, 1)
+	}
+	s.lenTex = len(s.textures)
+	s.AnimFPS = 10
+	if a.NumDirections() > 1 && a.NumFrames() > 0 {
+		s.directions = a
+	}
+	return s
+}
+
+// GetAnimationSet returns the frames of the sprite for each direction, nil if it looks the same from
+// every side
+func (s *Sprite) GetAnimationSet() *AnimationSet {
+	return s.directions
+}
+
+// ViewDirection returns the direction of the animation set the sprite is seen in from a viewer position,
+// 0 when the sprite faces the viewer or has no facing
+func (s *Sprite) ViewDirection(viewer Vector2) int {
+	if s.directions == nil || (s.Dir.X == 0 && s.Dir.Y == 0) {
+		return 0
+	}
+	n := s.directions.NumDirections()
+
+	//--how far the sprite is turned counter clockwise from facing the viewer, in steps of a direction--//
+	turn := math.Atan2(viewer.Y-s.Y, viewer.X-s.X) - math.Atan2(s.Dir.Y, s.Dir.X)
+	step := 2 * math.Pi / float64(n)
+	return wrapIndex(int(math.Floor(turn/step+0.5)), n)
+}
+
+// GetTextureFrom returns the current frame of the sprite as seen from a viewer position
+func (s *Sprite) GetTextureFrom(viewer Vector2) image.Image {
+	if s.directions == nil {
+		return s.GetTexture()
+	}
+	return s.directions.Frame(s.ViewDirection(viewer), s.texNum)
+}
+
+// wrapIndex wraps i into the range 0 to n-1
+func wrapIndex(i, n int) int {
+	i %= n
+	if i < 0 {
+		i += n
+	}
+	return i
+}
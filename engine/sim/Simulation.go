@@ -0,0 +1,87 @@
+package sim
+
+// Simulation runs a map and the cameras of the players in it at a fixed tick rate without drawing anything,
+// for dedicated servers and AI runs in CI. Maps for it can be loaded without textures.
+type Simulation struct {
+	mapObj  *Map
+	players []*Camera
+
+	tps  int
+	tick uint64
+}
+
+// NewSimulation creates a simulation of a map stepped tps times per simulated second
+func NewSimulation(mapObj *Map, tps int) *Simulation {
+	if tps <= 0 {
+		tps = int(movementTPS)
+	}
+	return &Simulation{mapObj: mapObj, tps: tps}
+}
+
+// GetMap returns the simulated map
+func (s *Simulation) GetMap() *Map {
+	return s.mapObj
+}
+
+// AddPlayer adds a camera, configured by any options, moved by its caller between steps
+func (s *Simulation) AddPlayer(opts ...CameraOption) *Camera {
+	c := NewCamera(s.mapObj, append([]CameraOption{WithTargetTPS(s.tps)}, opts...)...)
+	s.players = append(s.players, c)
+	return c
+}
+
+// RemovePlayer takes a camera out of the simulation, returning false if it was not in it
+func (s *Simulation) RemovePlayer(c *Camera) bool {
+	for i, p := range s.players {
+		if p == c {
+			s.players = append(s.players[:i], s.players[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// GetPlayers returns the cameras of the players in the simulation
+func (s *Simulation) GetPlayers() []*Camera {
+	return s.players
+}
+
+// GetTPS returns the ticks per simulated second
+func (s *Simulation) GetTPS() int {
+	return s.tps
+}
+
+// GetTick returns the number of ticks stepped so far
+func (s *Simulation) GetTick() uint64 {
+	return s.tick
+}
+
+// Checksum hashes the state of the map and players, games simulating the same world have the same checksum
+// after every step
+func (s *Simulation) Checksum() uint64 {
+	return s.mapObj.Checksum(s.players...)
+}
+
+// Step advances the simulation one tick in the same order the game does: the map, then the players, then
+// the sprites looking out for the first player. Nothing moves while the map clock is paused.
+func (s *Simulation) Step() {
+	s.mapObj.Update(1.0 / float64(s.tps))
+	if s.mapObj.GetClock().IsPaused() {
+		return
+	}
+	for _, c := range s.players {
+		c.SimulateWithDelta(1.0 / float64(s.tps))
+	}
+	// perception follows a single target, as it does for the player camera in the game
+	if len(s.players) > 0 {
+		s.mapObj.UpdatePerception(s.players[0].GetPosition(), PlayerFaction)
+	}
+	s.tick++
+}
+
+// Run steps the simulation a number of ticks as fast as it can
+func (s *Simulation) Run(ticks int) {
+	for i := 0; i < ticks; i++ {
+		s.Step()
+	}
+}
@@ -0,0 +1,158 @@
+package sim
+
+import (
+	"math"
+	"testing"
+)
+
+// peer is one of two games simulating the same walled map with a spawner, as in a co-op session
+type peer struct {
+	sim     *Simulation
+	players []*Camera
+}
+
+func newPeer(seed int64) *peer {
+	const size = 16
+	m := NewEmptyMap(nil, size, size)
+	for i := 0; i < size; i++ {
+		m.SetTile(i, 0, 0, 1)
+		m.SetTile(i, size-1, 0, 1)
+		m.SetTile(0, i, 0, 1)
+		m.SetTile(size-1, i, 0, 1)
+	}
+	m.SetSeed(seed)
+	m.AddSpawner(&Spawner{
+		Pos:     Vector2{X: 8, Y: 8},
+		Scatter: 5,
+		Waves:   []Wave{{Count: 6, Interval: 0.25}, {Count: 8, Interval: 0.1}},
+		New: func(sp *Spawner, pos Vector2) *Sprite {
+			s := NewSprite(pos.X, pos.Y, nil)
+			s.BlocksRays = true
+			s.Radius = 0.4
+			return s
+		},
+	})
+
+	s := NewSimulation(m, 60)
+	p := &peer{sim: s}
+	p.players = append(p.players, s.AddPlayer(WithStartPosition(Vector2{X: 3.5, Y: 3.5})))
+	p.players = append(p.players, s.AddPlayer(WithStartPosition(Vector2{X: 12.5, Y: 12.5})))
+	return p
+}
+
+// play applies the same input to both players of a peer: walking, turning and firing a spread shot at the
+// spawner that removes the sprites it hits. Returns how many sprites were removed.
+func (p *peer) play(tick int) int {
+	removed := 0
+	m := p.sim.GetMap()
+	for i, c := range p.players {
+		c.Move(0.04)
+		c.Rotate(0.02 * float64(i*2-1))
+		if tick%10 == 0 {
+			pos := c.GetPosition()
+			aim := Vector2{X: 8 - pos.X, Y: 8 - pos.Y}
+			hits := m.Hitscan(pos, aim, HitscanOptions{Pellets: 4, Spread: 0.6, Damage: 10})
+			for _, hit := range hits {
+				if hit.Sprite != nil {
+					m.RemoveSprite(hit.Sprite)
+					removed++
+				}
+			}
+		}
+	}
+	return removed
+}
+
+func TestSimulationPeersStayInSync(t *testing.T) {
+	a, b := newPeer(42), newPeer(42)
+
+	// only one game shakes its view and draws it, which must not change what it simulates
+	b.players[0].Shake(1, 10)
+
+	removed := 0
+	for tick := 0; tick < 600; tick++ {
+		removed += a.play(tick)
+		b.play(tick)
+		a.sim.Step()
+		b.sim.Step()
+		b.players[0].GetViewOffset()
+
+		if sa, sb := a.sim.Checksum(), b.sim.Checksum(); sa != sb {
+			t.Fatalf("peers out of sync at tick %d: %x != %x", tick, sa, sb)
+		}
+	}
+
+	spawned := 0
+	for _, sp := range a.sim.GetMap().spawners {
+		spawned += sp.spawned
+	}
+	if spawned == 0 || removed == 0 {
+		t.Errorf("%d sprites spawned and %d shot, want both", spawned, removed)
+	}
+}
+
+func TestSimulationSeedsDiverge(t *testing.T) {
+	a, b := newPeer(1), newPeer(2)
+	for tick := 0; tick < 60; tick++ {
+		a.play(tick)
+		b.play(tick)
+		a.sim.Step()
+		b.sim.Step()
+	}
+	if a.sim.Checksum() == b.sim.Checksum() {
+		t.Error("peers with different seeds have the same checksum")
+	}
+
+	// the spawn scatter comes from the seed, so the sprites stand in different places
+	pa, pb := a.sim.GetMap().GetSprites(), b.sim.GetMap().GetSprites()
+	same := len(pa) == len(pb)
+	for i := 0; same && i < len(pa); i++ {
+		same = math.Abs(pa[i].X-pb[i].X) < 1e-9 && math.Abs(pa[i].Y-pb[i].Y) < 1e-9
+	}
+	if same {
+		t.Error("sprites spawned at the same spots with different seeds")
+	}
+}
+
+func TestSimulationPeerJoinsMidWave(t *testing.T) {
+	a := newPeer(42)
+	for tick := 0; tick < 200; tick++ {
+		a.play(tick)
+		a.sim.Step()
+	}
+	sp := a.sim.GetMap().spawners[0]
+	if !sp.IsStarted() || sp.IsComplete() || len(sp.GetAlive()) == 0 {
+		t.Fatalf("spawner on wave %d with %d alive, want mid-wave", sp.GetWave(), len(sp.GetAlive()))
+	}
+
+	//--the joining peer starts fresh and takes the state of the first from JSON, as the hello frame sends it--//
+	b := newPeer(42)
+	for i, c := range a.players {
+		data, err := c.Snapshot().Marshal()
+		if err != nil {
+			t.Fatal(err)
+		}
+		snap, err := UnmarshalSnapshot(data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		policy := RestoreCamera
+		if i == 0 {
+			policy = RestoreAll
+		}
+		b.players[i].Restore(snap, policy)
+	}
+	if sa, sb := a.sim.Checksum(), b.sim.Checksum(); sa != sb {
+		t.Fatalf("peers out of sync after joining: %x != %x", sa, sb)
+	}
+
+	for tick := 200; tick < 600; tick++ {
+		a.play(tick)
+		b.play(tick)
+		a.sim.Step()
+		b.sim.Step()
+		if sa, sb := a.sim.Checksum(), b.sim.Checksum(); sa != sb {
+			t.Fatalf("peers out of sync at tick %d: %x != %x", tick, sa, sb)
+		}
+	}
+}
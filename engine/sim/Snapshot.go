@@ -0,0 +1,300 @@
+package sim
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// SnapshotVersion is the format version of snapshots written by this engine.
+// Bump it and register a migration from the previous version whenever the format changes.
+const SnapshotVersion = 5
+
+// SnapshotMigration upgrades decoded snapshot JSON by one version in place
+type SnapshotMigration func(data map[string]interface{}) error
+
+// snapshotMigrations are the registered migrations by the version they upgrade from
+var snapshotMigrations = map[int]SnapshotMigration{
+	// version 1 snapshots had no version field and are otherwise the same as version 2
+	1: func(data map[string]interface{}) error { return nil },
+	// version 2 snapshots had no random state, restoring them leaves the map's random source as it is
+	2: func(data map[string]interface{}) error { return nil },
+	// version 3 snapshots listed sprites by index, which are the ids a map gives its sprites as it loads
+	3: func(data map[string]interface{}) error {
+		sprites, _ := data["sprites"].([]interface{})
+		for i, sp := range sprites {
+			state, ok := sp.(map[string]interface{})
+			if !ok {
+				return fmt.Errorf("sprite %d is not an object", i)
+			}
+			state["id"] = i + 1
+		}
+		return nil
+	},
+	// version 4 snapshots had no map state, restoring them leaves doors, tiles, spawners and the clock as they are
+	4: func(data map[string]interface{}) error { return nil },
+}
+
+// RegisterSnapshotMigration registers the migration that upgrades snapshots of the given version to the next,
+// replacing any registered before. Games can use it for state they keep alongside engine snapshots.
+func RegisterSnapshotMigration(from int, fn SnapshotMigration) {
+	snapshotMigrations[from] = fn
+}
+
+// Snapshot is a serializable copy of the engine state that can be restored later (checkpoints, saves)
+type Snapshot struct {
+	Version    int             `json:"version"`
+	Camera     CameraState     `json:"camera"`
+	Sprites    []SpriteState   `json:"sprites"`
+	Solids     []SolidState    `json:"solids"`
+	Objectives map[string]bool `json:"objectives"`
+	Rand       *uint64         `json:"rand,string,omitempty"`
+	Map        *MapState       `json:"map,omitempty"`
+
+	//--sprites in the map when the snapshot was taken, to put back any removed before it is restored--//
+	sprites map[int]*Sprite
+}
+
+// CameraState is the serializable pose and vertical state of a camera
+type CameraState struct {
+	Pos   Vector2 `json:"pos"`
+	Dir   Vector2 `json:"dir"`
+	Plane Vector2 `json:"plane"`
+	PosZ  float64 `json:"posZ"`
+	VelZ  float64 `json:"velZ"`
+	Air   float64 `json:"air"`
+}
+
+// SpriteState is the serializable state of a map sprite, by sprite id
+type SpriteState struct {
+	ID     int     `json:"id"`
+	X      float64 `json:"x"`
+	Y      float64 `json:"y"`
+	TexNum int     `json:"texNum"`
+
+	// Spawner --1 based index in the map spawner list of the spawner that made the sprite, 0 for none--//
+	Spawner int `json:"spawner,omitempty"`
+}
+
+// SolidState is the serializable state of a moving solid, by index in the map solid list
+type SolidState struct {
+	Min         Vector2 `json:"min"`
+	Max         Vector2 `json:"max"`
+	PathTarget  int     `json:"pathTarget"`
+	PathReverse bool    `json:"pathReverse"`
+}
+
+// RestorePolicy selects which parts of a snapshot are restored
+type RestorePolicy int
+
+const (
+	// RestoreCamera --camera pose, height and air--//
+	RestoreCamera RestorePolicy = 1 << iota
+	// RestoreSprites --sprite positions and frames, and which sprites are in the map--//
+	RestoreSprites
+	// RestoreSolids --moving solid positions and path progress--//
+	RestoreSolids
+	// RestoreObjectives --objective completion--//
+	RestoreObjectives
+	// RestoreRand --random source of the map simulation--//
+	RestoreRand
+	// RestoreMap --doors, changed tiles, spawner progress and clock time--//
+	RestoreMap
+
+	// RestoreAll --everything in the snapshot--//
+	RestoreAll = RestoreCamera | RestoreSprites | RestoreSolids | RestoreObjectives | RestoreRand | RestoreMap
+)
+
+// Snapshot captures the current camera and map state
+func (c *Camera) Snapshot() *Snapshot {
+	s := &Snapshot{Version: SnapshotVersion}
+	s.Camera = CameraState{
+		Pos: *c.pos, Dir: *c.dir, Plane: *c.plane,
+		PosZ: c.posZ, VelZ: c.velZ, Air: c.air,
+	}
+
+	spawners := make(map[*Spawner]int, len(c.mapObj.spawners))
+	for i, sp := range c.mapObj.spawners {
+		spawners[sp] = i + 1
+	}
+	s.sprites = make(map[int]*Sprite, len(c.mapObj.sprite))
+	for _, sp := range c.mapObj.sprite {
+		s.Sprites = append(s.Sprites, SpriteState{
+			ID: sp.id, X: sp.X, Y: sp.Y, TexNum: sp.texNum, Spawner: spawners[sp.spawner],
+		})
+		s.sprites[sp.id] = sp
+	}
+
+	for _, so := range c.mapObj.solids {
+		state := SolidState{Min: so.Min, Max: so.Max}
+		if so.follower != nil {
+			state.PathTarget = so.follower.target
+			state.PathReverse = so.follower.reverse
+		}
+		s.Solids = append(s.Solids, state)
+	}
+
+	if c.mapObj.objectives != nil {
+		s.Objectives = make(map[string]bool)
+		for _, o := range c.mapObj.objectives.List() {
+			s.Objectives[o.ID] = o.Complete
+		}
+	}
+
+	state := c.mapObj.randSrc.state
+	s.Rand = &state
+	s.Map = c.mapObj.captureState()
+
+	return s
+}
+
+// Restore applies the parts of a snapshot selected by the policy to the camera and map
+func (c *Camera) Restore(s *Snapshot, policy RestorePolicy) {
+	if policy&RestoreCamera != 0 {
+		*c.pos, *c.dir, *c.plane = s.Camera.Pos, s.Camera.Dir, s.Camera.Plane
+		c.posZ, c.velZ, c.air = s.Camera.PosZ, s.Camera.VelZ, s.Camera.Air
+		c.vel = Vector2{}
+		c.cellX, c.cellY = int(c.pos.X), int(c.pos.Y)
+	}
+
+	if policy&RestoreSprites != 0 {
+		c.restoreSprites(s)
+	}
+
+	if policy&RestoreSolids != 0 {
+		for i, state := range s.Solids {
+			if i >= len(c.mapObj.solids) {
+				break
+			}
+			so := c.mapObj.solids[i]
+			so.Min, so.Max = state.Min, state.Max
+			so.delta = Vector2{}
+			if so.follower != nil && state.PathTarget < len(so.follower.Path.Points) {
+				so.follower.target = state.PathTarget
+				so.follower.reverse = state.PathReverse
+			}
+		}
+	}
+
+	if policy&RestoreObjectives != 0 && c.mapObj.objectives != nil {
+		for _, o := range c.mapObj.objectives.List() {
+			if complete, ok := s.Objectives[o.ID]; ok {
+				o.Complete = complete
+			}
+		}
+	}
+
+	if policy&RestoreRand != 0 && s.Rand != nil {
+		c.mapObj.randSrc.state = *s.Rand
+	}
+
+	if policy&RestoreMap != 0 && s.Map != nil {
+		c.mapObj.restoreState(s.Map)
+	}
+}
+
+// restoreSprites removes sprites added since the snapshot, puts back those removed since when the snapshot
+// still holds them or their spawner can make them again and restores the state of each by its id, in the
+// order they were listed
+func (c *Camera) restoreSprites(s *Snapshot) {
+	m := c.mapObj
+	saved := make(map[int]bool, len(s.Sprites))
+	for _, state := range s.Sprites {
+		saved[state.ID] = true
+	}
+
+	current := make(map[int]*Sprite, len(m.sprite))
+	for _, sp := range append([]*Sprite(nil), m.sprite...) {
+		if saved[sp.id] {
+			current[sp.id] = sp
+		} else {
+			m.RemoveSprite(sp)
+		}
+	}
+
+	sprites := make([]*Sprite, 0, len(s.Sprites))
+	for _, state := range s.Sprites {
+		sp := current[state.ID]
+		if sp == nil {
+			if sp = s.sprites[state.ID]; sp == nil {
+				sp = m.respawnSprite(state)
+			}
+			if sp == nil {
+				continue
+			}
+			sp.id = state.ID
+			if state.Spawner > 0 && state.Spawner <= len(m.spawners) {
+				sp.spawner = m.spawners[state.Spawner-1]
+				sp.spawner.alive = append(sp.spawner.alive, sp)
+			}
+		}
+		sp.X, sp.Y = state.X, state.Y
+		if state.TexNum < sp.lenTex {
+			sp.texNum = state.TexNum
+		}
+		sprites = append(sprites, sp)
+	}
+
+	m.sprite = sprites
+	m.numSprites = len(m.sprite)
+	m.indexSprites()
+}
+
+// respawnSprite makes a sprite read back from JSON again with the spawner that made it, nil if it was not
+// spawned or the spawner cannot make it
+func (m *Map) respawnSprite(state SpriteState) *Sprite {
+	if state.Spawner <= 0 || state.Spawner > len(m.spawners) {
+		return nil
+	}
+	sp := m.spawners[state.Spawner-1]
+	if sp.New == nil {
+		return nil
+	}
+	return sp.New(sp, Vector2{X: state.X, Y: state.Y})
+}
+
+// Marshal encodes the snapshot as JSON, stamped with the current format version
+func (s *Snapshot) Marshal() ([]byte, error) {
+	s.Version = SnapshotVersion
+	return json.Marshal(s)
+}
+
+// UnmarshalSnapshot decodes a snapshot from JSON, running the registered migrations to bring snapshots
+// written by older versions up to date. Snapshots without a version are treated as version 1.
+func UnmarshalSnapshot(data []byte) (*Snapshot, error) {
+	raw := make(map[string]interface{})
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+
+	version := 1
+	if v, ok := raw["version"].(float64); ok {
+		version = int(v)
+	}
+	if version > SnapshotVersion {
+		return nil, fmt.Errorf("snapshot version %d is newer than supported version %d", version, SnapshotVersion)
+	}
+
+	if version < SnapshotVersion {
+		for ; version < SnapshotVersion; version++ {
+			migrate, ok := snapshotMigrations[version]
+			if !ok {
+				return nil, fmt.Errorf("no migration from snapshot version %d", version)
+			}
+			if err := migrate(raw); err != nil {
+				return nil, fmt.Errorf("migrating snapshot from version %d: %v", version, err)
+			}
+		}
+		raw["version"] = SnapshotVersion
+
+		var err error
+		if data, err = json.Marshal(raw); err != nil {
+			return nil, err
+		}
+	}
+
+	s := &Snapshot{}
+	if err := json.Unmarshal(data, s); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
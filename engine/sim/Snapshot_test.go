@@ -0,0 +1,50 @@
+package sim
+
+import "testing"
+
+func TestRespawnSprites(t *testing.T) {
+	m := NewEmptyMap(nil, 8, 8)
+	a, b, d := NewSprite(1.5, 1.5, nil), NewSprite(2.5, 2.5, nil), NewSprite(3.5, 3.5, nil)
+	for _, s := range []*Sprite{a, b, d} {
+		m.AddSprite(s)
+	}
+	c := NewCamera(m, WithStartPosition(Vector2{X: 4.5, Y: 4.5}))
+	c.RespawnPolicy |= RestoreSprites
+	c.SetCheckpoint()
+
+	//--the first sprite dies, another is spawned and the last moves--//
+	m.RemoveSprite(a)
+	spawned := NewSprite(5.5, 5.5, nil)
+	m.AddSprite(spawned)
+	d.X, d.Y = 6.5, 6.5
+
+	c.Respawn()
+
+	want := []struct {
+		s    *Sprite
+		x, y float64
+	}{{a, 1.5, 1.5}, {b, 2.5, 2.5}, {d, 3.5, 3.5}}
+	if n := m.GetNumSprites(); n != len(want) {
+		t.Fatalf("%d sprites after respawn, want %d", n, len(want))
+	}
+	for i, w := range want {
+		if s := m.sprite[i]; s != w.s || s.X != w.x || s.Y != w.y {
+			t.Errorf("sprite %d is %d at %v, %v, want %d at %v, %v", i, s.GetID(), s.X, s.Y, w.s.GetID(), w.x, w.y)
+		}
+	}
+	if spawned.GetID() == a.GetID() || spawned.GetID() == d.GetID() {
+		t.Errorf("spawned sprite given id %d already in use", spawned.GetID())
+	}
+}
+
+func TestUnmarshalSnapshotSpriteIDs(t *testing.T) {
+	s, err := UnmarshalSnapshot([]byte(`{"version":3,"sprites":[{"x":1.5,"y":1.5},{"x":2.5,"y":2.5}]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, state := range s.Sprites {
+		if state.ID != i+1 {
+			t.Errorf("sprite %d given id %d, want %d", i, state.ID, i+1)
+		}
+	}
+}
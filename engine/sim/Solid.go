@@ -0,0 +1,125 @@
+package sim
+
+// Solid is a moving box (platform, crushing wall) that blocks movement and carries the camera standing on it
+type Solid struct {
+	// Min, Max --corners of the box on the grid--//
+	Min, Max Vector2
+
+	// Base, Top --bottom and top of the box in level units--//
+	Base, Top float64
+
+	// Sprite --optional sprite kept centered on the box--//
+	Sprite *Sprite
+
+	//--path the Min corner travels along--//
+	follower *PathFollower
+
+	//--movement during the last update, used to carry the camera--//
+	delta Vector2
+}
+
+// NewSolid creates a stationary solid box
+func NewSolid(min, max Vector2, base, top float64) *Solid {
+	s := &Solid{}
+	s.Min, s.Max = min, max
+	s.Base, s.Top = base, top
+	return s
+}
+
+// SetPath makes the solid travel back and forth through the given positions of its Min corner
+func (s *Solid) SetPath(speed float64, points ...Vector2) {
+	s.Follow(&Path{Points: points}, speed)
+}
+
+// Follow makes the solid travel along a map path with its Min corner
+func (s *Solid) Follow(path *Path, speed float64) {
+	s.follower = NewPathFollower(path, speed)
+}
+
+// GetDelta returns how far the solid moved during the last update
+func (s *Solid) GetDelta() Vector2 {
+	return s.delta
+}
+
+// GetFollower returns the path follower moving the solid, nil if it does not move
+func (s *Solid) GetFollower() *PathFollower {
+	return s.follower
+}
+
+// Center returns the middle of the box on the grid
+func (s *Solid) Center() Vector2 {
+	return Vector2{X: (s.Min.X + s.Max.X) / 2, Y: (s.Min.Y + s.Max.Y) / 2}
+}
+
+// Contains returns true if the grid position is inside the box footprint
+func (s *Solid) Contains(x, y float64) bool {
+	return x >= s.Min.X && x <= s.Max.X && y >= s.Min.Y && y <= s.Max.Y
+}
+
+// update moves the solid along its path, frames is the number of movement target frames that passed
+func (s *Solid) update(frames float64) {
+	s.delta = Vector2{}
+	if s.follower == nil {
+		return
+	}
+
+	s.delta = s.follower.Advance(&s.Min, s.follower.Speed*frames)
+	s.Max.X += s.delta.X
+	s.Max.Y += s.delta.Y
+
+	if s.Sprite != nil {
+		s.Sprite.X = (s.Min.X + s.Max.X) / 2
+		s.Sprite.Y = (s.Min.Y + s.Max.Y) / 2
+	}
+}
+
+// AddSolid adds a moving solid to the map
+func (m *Map) AddSolid(s *Solid) {
+	m.solids = append(m.solids, s)
+}
+
+// GetSolids returns the moving solids in the map
+func (m *Map) GetSolids() []*Solid {
+	return m.solids
+}
+
+// solidBlocks returns the solid obstructing the camera at the grid position and its current height, if any
+func (c *Camera) solidBlocks(x, y float64) *Solid {
+	for _, s := range c.mapObj.solids {
+		if s.Contains(x, y) && s.Top > c.posZ+stepHeight && s.Base < c.posZ+EyeHeight {
+			return s
+		}
+	}
+	return nil
+}
+
+// solidGround returns the highest solid top under the camera at or below its feet (plus step height)
+func (c *Camera) solidGround() (float64, *Solid) {
+	ground := 0.0
+	var under *Solid
+	for _, s := range c.mapObj.solids {
+		if s.Contains(c.pos.X, c.pos.Y) && s.Top <= c.posZ+stepHeight && s.Top > ground {
+			ground = s.Top
+			under = s
+		}
+	}
+	return ground, under
+}
+
+// updateSolids carries the camera along with the solid it stands on and pushes it out of solids moving into it
+func (c *Camera) updateSolids() {
+	if ground, s := c.solidGround(); s != nil && c.posZ-ground < 0.01 {
+		c.translate(s.delta.X, s.delta.Y)
+	}
+
+	s := c.solidBlocks(c.pos.X, c.pos.Y)
+	if s == nil {
+		return
+	}
+
+	c.translate(s.delta.X, s.delta.Y)
+	if c.solidBlocks(c.pos.X, c.pos.Y) == s && c.Events.OnCrush != nil && !c.invulnerable {
+		// pinned between the solid and a wall
+		c.Events.OnCrush(s)
+	}
+}
@@ -0,0 +1,249 @@
+package sim
+
+import "math"
+
+const (
+	// tries at finding a walkable cell within the scatter radius before spawning at the spawner itself
+	spawnTries = 8
+)
+
+// Wave is one batch of sprites a spawner creates, the next wave starts once all of them are gone
+type Wave struct {
+	// Count --sprites spawned in the wave--//
+	Count int
+
+	// Interval --seconds between spawns--//
+	Interval float64 `json:",omitempty"`
+
+	// Delay --seconds before the first spawn, after the spawner starts or the previous wave is cleared--//
+	Delay float64 `json:",omitempty"`
+}
+
+// Spawner creates sprites over time in waves, from the start of the map or once its trigger fires.
+// Sprites come from the New callback, which the game sets since callbacks are not part of map data.
+type Spawner struct {
+	// Name --looked up with Map.GetSpawner--//
+	Name string
+
+	// Pos --grid position sprites are spawned around--//
+	Pos Vector2
+
+	// Scatter --radius around Pos sprites are spread over--//
+	Scatter float64 `json:",omitempty"`
+
+	// Trigger --tile trigger id that starts the spawner, empty to start with the map--//
+	Trigger string `json:",omitempty"`
+
+	// Waves --spawned one after another--//
+	Waves []Wave
+
+	// MaxAlive --most spawned sprites in the map at once, spawning waits while at the cap, 0 for no cap--//
+	MaxAlive int `json:",omitempty"`
+
+	// New --creates a sprite at a grid position, returning nil skips the spawn--//
+	New func(sp *Spawner, pos Vector2) *Sprite `json:"-"`
+
+	// OnWaveStart --called when a wave starts, with its index--//
+	OnWaveStart func(sp *Spawner, wave int) `json:"-"`
+
+	// OnWaveCleared --called when the last sprite of a wave is gone, with its index--//
+	OnWaveCleared func(sp *Spawner, wave int) `json:"-"`
+
+	// OnComplete --called when the last wave is cleared--//
+	OnComplete func(sp *Spawner) `json:"-"`
+
+	started   bool
+	completed bool
+	wave      int
+	spawned   int
+	timer     float64
+	alive     []*Sprite
+}
+
+// Start begins the first wave, doing nothing if the spawner already started
+func (sp *Spawner) Start() {
+	if sp.started {
+		return
+	}
+	sp.started = true
+	sp.beginWave(0)
+}
+
+// IsStarted returns true once the spawner has started
+func (sp *Spawner) IsStarted() bool {
+	return sp.started
+}
+
+// IsComplete returns true once every wave has been spawned and cleared
+func (sp *Spawner) IsComplete() bool {
+	return sp.completed
+}
+
+// GetWave returns the index of the current wave
+func (sp *Spawner) GetWave() int {
+	return sp.wave
+}
+
+// GetAlive returns the spawned sprites still in the map
+func (sp *Spawner) GetAlive() []*Sprite {
+	return sp.alive
+}
+
+// capacity returns the most sprites the spawner can have in the map at once
+func (sp *Spawner) capacity() int {
+	if sp.MaxAlive > 0 {
+		return sp.MaxAlive
+	}
+	most := 0
+	for _, w := range sp.Waves {
+		if w.Count > most {
+			most = w.Count
+		}
+	}
+	return most
+}
+
+// beginWave resets the spawn count and timer for a wave, completing the spawner after the last one
+func (sp *Spawner) beginWave(wave int) {
+	sp.wave, sp.spawned = wave, 0
+	if wave >= len(sp.Waves) {
+		sp.completed = true
+		if sp.OnComplete != nil {
+			sp.OnComplete(sp)
+		}
+		return
+	}
+
+	sp.timer = sp.Waves[wave].Delay
+	if sp.OnWaveStart != nil {
+		sp.OnWaveStart(sp, wave)
+	}
+}
+
+// despawned forgets a spawned sprite removed from the map
+func (sp *Spawner) despawned(s *Sprite) {
+	for i, a := range sp.alive {
+		if a == s {
+			sp.alive = append(sp.alive[:i], sp.alive[i+1:]...)
+			return
+		}
+	}
+}
+
+// update spawns the sprites due in the current wave and moves on to the next once it is cleared,
+// waiting until the game has set New
+func (sp *Spawner) update(m *Map, dt float64) {
+	if !sp.started || sp.completed || sp.New == nil {
+		return
+	}
+
+	w := sp.Waves[sp.wave]
+	if sp.spawned < w.Count {
+		sp.timer -= dt
+		for sp.timer <= 0 && sp.spawned < w.Count && (sp.MaxAlive <= 0 || len(sp.alive) < sp.MaxAlive) {
+			sp.spawn(m)
+			sp.timer += w.Interval
+		}
+		return
+	}
+
+	if len(sp.alive) == 0 {
+		if sp.OnWaveCleared != nil {
+			sp.OnWaveCleared(sp, sp.wave)
+		}
+		sp.beginWave(sp.wave + 1)
+	}
+}
+
+// spawn adds one sprite from New at a walkable spot within the scatter radius
+func (sp *Spawner) spawn(m *Map) {
+	sp.spawned++
+
+	pos := sp.Pos
+	for i := 0; i < spawnTries && sp.Scatter > 0; i++ {
+		angle, dist := m.rand.Float64()*2*math.Pi, sp.Scatter*math.Sqrt(m.rand.Float64())
+		p := Vector2{X: sp.Pos.X + math.Cos(angle)*dist, Y: sp.Pos.Y + math.Sin(angle)*dist}
+		if m.IsWalkable(int(p.X), int(p.Y)) {
+			pos = p
+			break
+		}
+	}
+
+	s := sp.New(sp, pos)
+	if s == nil {
+		return
+	}
+	s.spawner = sp
+	sp.alive = append(sp.alive, s)
+	m.AddSprite(s)
+}
+
+// AddSpawner adds a spawner to the map, starting it right away if it has no trigger
+func (m *Map) AddSpawner(sp *Spawner) {
+	m.spawners = append(m.spawners, sp)
+	if sp.Trigger == "" {
+		sp.Start()
+	}
+}
+
+// GetSpawner returns the spawner with the given name, nil if there is none
+func (m *Map) GetSpawner(name string) *Spawner {
+	for _, sp := range m.spawners {
+		if sp.Name == name {
+			return sp
+		}
+	}
+	return nil
+}
+
+// GetSpawners returns the spawners in the map
+func (m *Map) GetSpawners() []*Spawner {
+	return m.spawners
+}
+
+// FireTrigger starts the spawners and boss arenas waiting for the trigger id
+func (m *Map) FireTrigger(id string) {
+	for _, sp := range m.spawners {
+		if sp.Trigger == id {
+			sp.Start()
+		}
+	}
+	for _, a := range m.arenas {
+		if a.Trigger == id {
+			a.Start()
+		}
+	}
+}
+
+// SpawnCapacity returns the most sprites the spawners of the map can have in it at once,
+// for sizing the sprite levels of a camera
+func (m *Map) SpawnCapacity() int {
+	capacity := 0
+	for _, sp := range m.spawners {
+		capacity += sp.capacity()
+	}
+	return capacity
+}
+
+// AddSprite adds a sprite to the map, cameras pick it up on their next raycast
+func (m *Map) AddSprite(s *Sprite) {
+	m.sprite = append(m.sprite, s)
+	m.numSprites = len(m.sprite)
+	m.indexSprites()
+}
+
+// RemoveSprite removes a sprite from the map, counting it as gone for the spawner that made it
+func (m *Map) RemoveSprite(s *Sprite) {
+	for i, existing := range m.sprite {
+		if existing == s {
+			m.sprite = append(m.sprite[:i], m.sprite[i+1:]...)
+			m.numSprites = len(m.sprite)
+			m.indexSprites()
+			break
+		}
+	}
+	if s.spawner != nil {
+		s.spawner.despawned(s)
+		s.spawner = nil
+	}
+}
@@ -0,0 +1,133 @@
+package sim
+
+import "image"
+
+type Sprite struct {
+	X, Y           float64
+	id             int
+	texNum, lenTex int
+	textures       []image.Image
+
+	//--sheet the frames were cut from, kept so the sprite can be exported--//
+	sheet         image.Image
+	columns, rows int
+
+	//--frames for each direction the sprite is seen from, nil to look the same from every side--//
+	directions *AnimationSet
+
+	// BlocksRays --sprite stops ray queries (hitscan, line-of-sight) as if it were cover--//
+	BlocksRays bool
+
+	// Radius --size of the sprite footprint on the grid used by spatial and ray queries--//
+	Radius float64
+
+	// OnUse --called when the camera uses the sprite (talking NPCs, readable signs), nil if not interactable--//
+	OnUse func(user *Camera) *Interaction
+
+	// Patrol --moves the sprite along a map path each update, nil to stay put--//
+	Patrol *PathFollower
+
+	// Dir --facing direction on the grid, turned towards the patrol movement, zero to face every way.
+	// Picks the frames shown by a sprite with directions.--//
+	Dir Vector2
+
+	// Faction --side the sprite is on, its relations to other factions are set on the map--//
+	Faction string
+
+	// Perception --how the sprite notices hostile targets and noises, nil for a sprite that notices nothing--//
+	Perception *Perception
+	awareness  awareness
+
+	// Item --sprite is an item to pick up, counted in the end of level stats--//
+	Item bool
+
+	//--spawner that created the sprite, told when it is removed--//
+	spawner *Spawner
+
+	// Steering --keeps a patrolling sprite apart from other sprites and walls, nil to follow the path exactly--//
+	Steering *Steering
+
+	// NavDebug --draw the route, target and line of sight of the sprite in the navigation debug view--//
+	NavDebug bool
+
+	// AnimFPS --sheet frames shown per clock second while no animation plays, 0 to stay on the current frame--//
+	AnimFPS  float64
+	animTime float64
+
+	//--named frame sequences and the one playing, see Play--//
+	animations map[string]*Animation
+	anim       animState
+
+	// OnAnimationEnd --called when a LoopOnce animation without a next animation shows its last frame--//
+	OnAnimationEnd func(s *Sprite, name string)
+}
+
+func NewSprite(x, y float64, img image.Image) *Sprite {
+	s := &Sprite{}
+	s.X, s.Y = x, y
+	s.texNum = 0
+	s.lenTex = 1
+	s.textures = make([]image.Image, s.lenTex)
+	s.textures[0] = img
+
+	return s
+}
+
+func NewSpriteFromSheet(x, y float64, img image.Image, columns, rows int) *Sprite {
+	s := &Sprite{}
+	s.X, s.Y = x, y
+	s.texNum = 0
+	s.sheet, s.columns, s.rows = img, columns, rows
+
+	// crop sheet by given number of columns and rows into a single dimension array, frames without an image
+	// for a sheet that is not loaded (simulations)
+	if img != nil {
+		b := img.Bounds()
+		for _, row := range SliceSheet(img, b.Dx()/columns, b.Dy()/rows) {
+			s.textures = append(s.textures, row...)
+		}
+	} else {
+		s.textures = make([]image.Image, columns*rows)
+	}
+	s.lenTex = len(s.textures)
+
+	// animated by the map update, never while the camera is casting
+	s.AnimFPS = 10
+
+	return s
+}
+
+// animate advances the animation playing, or cycles the whole sheet, by the clock seconds that passed
+func (s *Sprite) animate(dt float64) {
+	if s.stepAnimation(dt) || s.AnimFPS <= 0 || s.lenTex <= 1 {
+		return
+	}
+
+	s.animTime += dt * s.AnimFPS
+	for s.animTime >= 1 {
+		s.animTime--
+		s.nextTexture()
+	}
+}
+
+func (s *Sprite) nextTexture() {
+	s.texNum += 1
+	if s.texNum >= s.lenTex {
+		s.texNum = 0
+	}
+}
+
+// GetID returns the id the map gave the sprite when it was added, kept for as long as the sprite exists so
+// snapshots can find it again after other sprites are added or removed
+func (s *Sprite) GetID() int {
+	return s.id
+}
+
+// GetFrames returns every frame of the sprite, in the order animations index them
+func (s *Sprite) GetFrames() []image.Image {
+	return s.textures
+}
+
+func (s *Sprite) GetTexture() image.Image {
+	return s.textures[s.texNum]
+}
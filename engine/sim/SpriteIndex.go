@@ -0,0 +1,73 @@
+package sim
+
+import "math"
+
+// indexSprites rebuilds the grid of sprites overlapping each map cell, used for spatial queries, and gives
+// sprites added since the last rebuild an id
+func (m *Map) indexSprites() {
+	for _, s := range m.sprite {
+		// sprites copied from another map keep their id
+		if s.id > m.lastSpriteID {
+			m.lastSpriteID = s.id
+		}
+	}
+	for _, s := range m.sprite {
+		if s.id == 0 {
+			m.lastSpriteID++
+			s.id = m.lastSpriteID
+		}
+	}
+
+	if m.spriteGrid == nil {
+		m.spriteGrid = make([][][]*Sprite, len(m.worldMap))
+		for x := range m.spriteGrid {
+			m.spriteGrid[x] = make([][]*Sprite, len(m.worldMap[x]))
+		}
+	}
+
+	for x := range m.spriteGrid {
+		for y := range m.spriteGrid[x] {
+			m.spriteGrid[x][y] = m.spriteGrid[x][y][:0]
+		}
+	}
+
+	for _, s := range m.sprite {
+		minX, maxX := int(math.Floor(s.X-s.Radius)), int(math.Floor(s.X+s.Radius))
+		minY, maxY := int(math.Floor(s.Y-s.Radius)), int(math.Floor(s.Y+s.Radius))
+		for x := minX; x <= maxX; x++ {
+			for y := minY; y <= maxY; y++ {
+				if m.inBounds(x, y) {
+					m.spriteGrid[x][y] = append(m.spriteGrid[x][y], s)
+				}
+			}
+		}
+	}
+}
+
+// spritesInCell returns the sprites overlapping the grid cell as of the last index update
+func (m *Map) spritesInCell(x, y int) []*Sprite {
+	if m.spriteGrid == nil || !m.inBounds(x, y) {
+		return nil
+	}
+	return m.spriteGrid[x][y]
+}
+
+// SpritesNear returns the sprites whose centers are within radius of the grid position
+func (m *Map) SpritesNear(x, y, radius float64) []*Sprite {
+	var near []*Sprite
+	seen := make(map[*Sprite]bool)
+	for cx := int(math.Floor(x - radius)); cx <= int(math.Floor(x+radius)); cx++ {
+		for cy := int(math.Floor(y - radius)); cy <= int(math.Floor(y+radius)); cy++ {
+			for _, s := range m.spritesInCell(cx, cy) {
+				if seen[s] {
+					continue
+				}
+				seen[s] = true
+				if (s.X-x)*(s.X-x)+(s.Y-y)*(s.Y-y) <= radius*radius {
+					near = append(near, s)
+				}
+			}
+		}
+	}
+	return near
+}
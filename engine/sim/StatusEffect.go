@@ -0,0 +1,135 @@
+package sim
+
+import "image/color"
+
+// StatusEffect is a timed modifier attached to the camera (slow, blind, poison, haste).
+// Zero valued modifiers leave the matching engine setting unchanged.
+type StatusEffect struct {
+	// Name --identifies the effect, adding an effect replaces any active effect with the same name--//
+	Name string
+
+	// Duration --seconds remaining, negative lasts until removed--//
+	Duration float64
+
+	// SpeedScale --multiplier on movement speed--//
+	SpeedScale float64
+
+	// FOVScale --multiplier on the field of view--//
+	FOVScale float64
+
+	// Tint --full-screen tint drawn while active--//
+	Tint *color.RGBA
+
+	// DamagePerSecond --damage dealt while active--//
+	DamagePerSecond float64
+}
+
+// NewSlowEffect creates an effect that halves movement speed
+func NewSlowEffect(seconds float64) *StatusEffect {
+	return &StatusEffect{Name: "slow", Duration: seconds, SpeedScale: 0.5, FOVScale: 0.95}
+}
+
+// NewHasteEffect creates an effect that increases movement speed and widens the view
+func NewHasteEffect(seconds float64) *StatusEffect {
+	return &StatusEffect{Name: "haste", Duration: seconds, SpeedScale: 1.6, FOVScale: 1.1}
+}
+
+// NewBlindEffect creates an effect that almost blacks out the screen
+func NewBlindEffect(seconds float64) *StatusEffect {
+	return &StatusEffect{Name: "blind", Duration: seconds, Tint: &color.RGBA{0, 0, 0, 220}}
+}
+
+// NewPoisonEffect creates an effect that deals damage over time
+func NewPoisonEffect(seconds, damagePerSecond float64) *StatusEffect {
+	return &StatusEffect{Name: "poison", Duration: seconds, DamagePerSecond: damagePerSecond, Tint: &color.RGBA{40, 160, 40, 60}}
+}
+
+// AddEffect attaches a status effect to the camera, replacing any active effect with the same name
+func (c *Camera) AddEffect(e *StatusEffect) {
+	c.RemoveEffect(e.Name)
+	c.effects = append(c.effects, e)
+}
+
+// RemoveEffect removes the named status effect, if active
+func (c *Camera) RemoveEffect(name string) {
+	for i, e := range c.effects {
+		if e.Name == name {
+			c.effects = append(c.effects[:i], c.effects[i+1:]...)
+			if c.Events.OnEffectEnd != nil {
+				c.Events.OnEffectEnd(e)
+			}
+			return
+		}
+	}
+}
+
+// HasEffect returns true if the named status effect is active
+func (c *Camera) HasEffect(name string) bool {
+	for _, e := range c.effects {
+		if e.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// GetEffects returns the active status effects
+func (c *Camera) GetEffects() []*StatusEffect {
+	return c.effects
+}
+
+// GetEffectTints returns the full-screen tints of the active status effects
+func (c *Camera) GetEffectTints() []color.RGBA {
+	var tints []color.RGBA
+	for _, e := range c.effects {
+		if e.Tint != nil {
+			tints = append(tints, *e.Tint)
+		}
+	}
+	return tints
+}
+
+// effectSpeedScale returns the combined movement speed multiplier of the active effects
+func (c *Camera) effectSpeedScale() float64 {
+	scale := 1.0
+	for _, e := range c.effects {
+		if e.SpeedScale != 0 {
+			scale *= e.SpeedScale
+		}
+	}
+	return scale
+}
+
+// effectFOVScale returns the combined field of view multiplier of the active effects
+func (c *Camera) effectFOVScale() float64 {
+	scale := 1.0
+	for _, e := range c.effects {
+		if e.FOVScale != 0 {
+			scale *= e.FOVScale
+		}
+	}
+	return scale
+}
+
+// updateEffects applies damage over time and expires finished effects
+func (c *Camera) updateEffects() {
+	dt := c.dt
+
+	var expired []string
+	for _, e := range c.effects {
+		if e.DamagePerSecond > 0 && c.Events.OnEffectDamage != nil && !c.invulnerable {
+			c.Events.OnEffectDamage(e.DamagePerSecond*dt, e)
+		}
+		if e.Duration < 0 {
+			continue
+		}
+		e.Duration -= dt
+		if e.Duration <= 0 {
+			expired = append(expired, e.Name)
+		}
+	}
+
+	for _, name := range expired {
+		c.RemoveEffect(name)
+	}
+}
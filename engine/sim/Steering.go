@@ -0,0 +1,91 @@
+package sim
+
+import "math"
+
+// Steering is local avoidance layered on a sprite patrol, nudging it away from nearby sprites and walls
+// so groups following the same route spread out instead of stacking up in doorways
+type Steering struct {
+	// Range --distance within which other sprites push the sprite away--//
+	Range float64 `json:",omitempty"`
+
+	// Separation --strength of the push away from other sprites--//
+	Separation float64 `json:",omitempty"`
+
+	// WallDistance --distance from walls the sprite tries to keep--//
+	WallDistance float64 `json:",omitempty"`
+
+	// WallAvoidance --strength of the push away from walls--//
+	WallAvoidance float64 `json:",omitempty"`
+}
+
+// NewSteering creates steering keeping sprites about a cell apart and clear of walls
+func NewSteering() *Steering {
+	return &Steering{Range: 1, Separation: 1, WallDistance: 0.4, WallAvoidance: 1}
+}
+
+// steer returns the position a sprite ends up at after moving to pos, pushed away from its neighbours in
+// the sprite index and from nearby walls. The push is never longer than the distance moved along the path,
+// so a steered sprite still makes progress, and it never pushes the sprite into a wall.
+func (m *Map) steer(s *Sprite, pos Vector2, moved float64) Vector2 {
+	st := s.Steering
+	var fx, fy float64
+
+	//--separation from sprites--//
+	if st.Range > 0 {
+		for _, o := range m.SpritesNear(pos.X, pos.Y, st.Range+s.Radius) {
+			if o == s {
+				continue
+			}
+			dx, dy := pos.X-o.X, pos.Y-o.Y
+			dist := math.Hypot(dx, dy)
+			if dist == 0 {
+				continue
+			}
+			weight := st.Separation * (1 - dist/(st.Range+s.Radius+o.Radius))
+			if weight > 0 {
+				fx += dx / dist * weight
+				fy += dy / dist * weight
+			}
+		}
+	}
+
+	//--avoidance of walls in the cells around--//
+	cx, cy := int(pos.X), int(pos.Y)
+	for x := cx - 1; x <= cx+1; x++ {
+		for y := cy - 1; y <= cy+1; y++ {
+			if m.IsWalkable(x, y) {
+				continue
+			}
+			// closest point of the wall cell to the sprite
+			nx := math.Max(float64(x), math.Min(pos.X, float64(x+1)))
+			ny := math.Max(float64(y), math.Min(pos.Y, float64(y+1)))
+			dx, dy := pos.X-nx, pos.Y-ny
+			dist := math.Hypot(dx, dy)
+			if dist == 0 || dist >= st.WallDistance+s.Radius {
+				continue
+			}
+			weight := st.WallAvoidance * (1 - dist/(st.WallDistance+s.Radius))
+			fx += dx / dist * weight
+			fy += dy / dist * weight
+		}
+	}
+
+	push := math.Hypot(fx, fy)
+	if push == 0 {
+		return pos
+	}
+	scale := moved
+	if push < 1 {
+		scale *= push
+	}
+	fx, fy = fx/push*scale, fy/push*scale
+
+	// slide along walls rather than into them
+	if m.IsWalkable(int(pos.X+fx), int(pos.Y)) {
+		pos.X += fx
+	}
+	if m.IsWalkable(int(pos.X), int(pos.Y+fy)) {
+		pos.Y += fy
+	}
+	return pos
+}
@@ -0,0 +1,115 @@
+package sim
+
+import "math/rand"
+
+// StressPathName is the name of the looping camera path through a stress map
+const StressPathName = "flythrough"
+
+const (
+	// tile type ids used for the lights of a stress map
+	stressLightTile    = 1
+	stressSkylightTile = 2
+
+	// chance of a pillar in each free cell of a stress map
+	stressPillarChance = 0.08
+
+	// cells between the map border and the flythrough loop
+	stressPathInset = 2
+)
+
+// StressMapOptions configures a generated stress test map
+type StressMapOptions struct {
+	// Size --width and height of the map in grid cells--//
+	Size int
+
+	// Sprites --number of sprites scattered over the map--//
+	Sprites int
+
+	// Lights --number of lit floor tiles, every other one a skylight shaft--//
+	Lights int
+
+	// Seed --random seed, the same options and seed always generate the same map--//
+	Seed int64
+
+	// WallTextures --texture numbers used for walls--//
+	WallTextures []int
+
+	// SpriteTextures --texture numbers used for sprites--//
+	SpriteTextures []int
+}
+
+// NewStressMap generates a walled two level map for benchmarking, scattered with pillars, sprites and
+// light tiles, and with a looping camera path named StressPathName running inside the border.
+// Cells along the path are kept clear so a camera following it never passes through a wall.
+func NewStressMap(tex Textures, opts StressMapOptions) *Map {
+	size := opts.Size
+	if size < 2*stressPathInset+3 {
+		size = 2*stressPathInset + 3
+	}
+	walls := opts.WallTextures
+	if len(walls) == 0 {
+		walls = []int{1}
+	}
+	rnd := rand.New(rand.NewSource(opts.Seed))
+	wall := func() int { return walls[rnd.Intn(len(walls))] }
+
+	ground := make([][]int, size)
+	upper := make([][]int, size)
+	for x := 0; x < size; x++ {
+		ground[x] = make([]int, size)
+		upper[x] = make([]int, size)
+	}
+
+	lo, hi := stressPathInset, size-1-stressPathInset
+	onPath := func(x, y int) bool {
+		near := func(v, line int) bool { return v >= line-1 && v <= line+1 }
+		inX, inY := x >= lo-1 && x <= hi+1, y >= lo-1 && y <= hi+1
+		return inX && inY && (near(x, lo) || near(x, hi) || near(y, lo) || near(y, hi))
+	}
+
+	var free []Vector2
+	for x := 0; x < size; x++ {
+		for y := 0; y < size; y++ {
+			switch {
+			case x == 0 || y == 0 || x == size-1 || y == size-1:
+				ground[x][y], upper[x][y] = wall(), wall()
+			case !onPath(x, y) && rnd.Float64() < stressPillarChance:
+				ground[x][y] = wall()
+				if rnd.Intn(2) == 0 {
+					upper[x][y] = wall()
+				}
+			default:
+				free = append(free, Vector2{X: float64(x), Y: float64(y)})
+			}
+		}
+	}
+
+	m := NewMapFromLevels(tex, ground, upper)
+	m.RegisterTileType(stressLightTile, &TileType{Light: 0.8})
+	m.RegisterTileType(stressSkylightTile, &TileType{Skylight: true})
+
+	rnd.Shuffle(len(free), func(i, j int) { free[i], free[j] = free[j], free[i] })
+	for i := 0; i < opts.Lights && i < len(free); i++ {
+		tile := stressLightTile
+		if i%2 == 1 {
+			tile = stressSkylightTile
+		}
+		m.SetTileType(int(free[i].X), int(free[i].Y), tile)
+	}
+
+	for i := 0; i < opts.Sprites && len(free) > 0 && len(opts.SpriteTextures) > 0; i++ {
+		cell := free[rnd.Intn(len(free))]
+		texNum := opts.SpriteTextures[rnd.Intn(len(opts.SpriteTextures))]
+		m.sprite = append(m.sprite, NewSprite(cell.X+0.2+rnd.Float64()*0.6, cell.Y+0.2+rnd.Float64()*0.6, m.texture(texNum)))
+	}
+	m.numSprites = len(m.sprite)
+	m.indexSprites()
+
+	flo, fhi := float64(lo)+0.5, float64(hi)+0.5
+	m.AddPath(&Path{
+		Name:   StressPathName,
+		Points: []Vector2{{X: flo, Y: flo}, {X: fhi, Y: flo}, {X: fhi, Y: fhi}, {X: flo, Y: fhi}},
+		Loop:   true,
+	})
+	return m
+}
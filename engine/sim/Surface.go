@@ -0,0 +1,53 @@
+package sim
+
+import "math"
+
+// Surface is a flat horizontal rectangle at any height within the map (table tops, raised platforms),
+// rendered by a floor casting pass constrained to its footprint
+type Surface struct {
+	// Min, Max --corners of the surface on the grid--//
+	Min, Max Vector2
+
+	// Height --height of the surface in level units--//
+	Height float64
+
+	// TexNum --index into the HorLevel textures--//
+	TexNum int
+}
+
+// NewSurface creates a flat surface over the grid rectangle at the given height
+func NewSurface(min, max Vector2, height float64, texNum int) *Surface {
+	return &Surface{Min: min, Max: max, Height: height, TexNum: texNum}
+}
+
+// AddSurface adds a flat surface to the map
+func (m *Map) AddSurface(s *Surface) {
+	m.surfaces = append(m.surfaces, s)
+}
+
+// GetSurfaces returns the flat surfaces in the map
+func (m *Map) GetSurfaces() []*Surface {
+	return m.surfaces
+}
+
+// Intersect returns the ray distances where the ray enters and leaves the surface footprint
+func (s *Surface) Intersect(posX, posY, rayDirX, rayDirY float64) (float64, float64, bool) {
+	tMin, tMax := 0.0, math.Inf(1)
+
+	slab := func(pos, dir, min, max float64) bool {
+		if dir == 0 {
+			return pos >= min && pos <= max
+		}
+		t0, t1 := (min-pos)/dir, (max-pos)/dir
+		if t0 > t1 {
+			t0, t1 = t1, t0
+		}
+		tMin, tMax = math.Max(tMin, t0), math.Min(tMax, t1)
+		return tMin <= tMax
+	}
+
+	if !slab(posX, rayDirX, s.Min.X, s.Max.X) || !slab(posY, rayDirY, s.Min.Y, s.Max.Y) {
+		return 0, 0, false
+	}
+	return tMin, tMax, true
+}
@@ -0,0 +1,117 @@
+package sim
+
+import (
+	"image"
+	"image/color"
+	"math"
+)
+
+// Terrain is a heightmap rendered voxel-space style in place of the grid raycaster while the camera
+// is inside its region, for open outdoor sections. Sprites and movement still work as usual.
+type Terrain struct {
+	// Region --grid cells covered by the terrain--//
+	Region image.Rectangle
+
+	// Heights --ground height in level units for each sample, indexed [x][y]--//
+	Heights [][]float64
+
+	// Colors --color of each sample, same size as Heights--//
+	Colors *image.RGBA
+
+	// Resolution --samples per grid cell--//
+	Resolution int
+}
+
+// NewTerrain creates terrain over the region from a grayscale heightmap and a color map,
+// black being height 0 and white being maxHeight. Both images are stretched to cover the region.
+func NewTerrain(region image.Rectangle, heightMap image.Image, colorMap image.Image, maxHeight float64) *Terrain {
+	b := heightMap.Bounds()
+	resolution := int(math.Max(1, float64(b.Dx())/float64(region.Dx())))
+
+	return NewTerrainFromFunc(region, resolution, func(x, y float64) (float64, color.RGBA) {
+		u := (x - float64(region.Min.X)) / float64(region.Dx())
+		v := (y - float64(region.Min.Y)) / float64(region.Dy())
+
+		hx := b.Min.X + int(u*float64(b.Dx()))
+		hy := b.Min.Y + int(v*float64(b.Dy()))
+		gray := color.GrayModel.Convert(heightMap.At(hx, hy)).(color.Gray)
+
+		cb := colorMap.Bounds()
+		cx := cb.Min.X + int(u*float64(cb.Dx()))
+		cy := cb.Min.Y + int(v*float64(cb.Dy()))
+		clr := color.RGBAModel.Convert(colorMap.At(cx, cy)).(color.RGBA)
+
+		return float64(gray.Y) / 255 * maxHeight, clr
+	})
+}
+
+// NewTerrainFromFunc creates terrain over the region by sampling fn at the given number of samples per
+// grid cell, fn returns the height in level units and color at a grid position
+func NewTerrainFromFunc(region image.Rectangle, resolution int, fn func(x, y float64) (float64, color.RGBA)) *Terrain {
+	if resolution < 1 {
+		resolution = 1
+	}
+
+	t := &Terrain{Region: region, Resolution: resolution}
+	w, h := region.Dx()*resolution, region.Dy()*resolution
+
+	t.Heights = make([][]float64, w)
+	t.Colors = image.NewRGBA(image.Rect(0, 0, w, h))
+	for sx := 0; sx < w; sx++ {
+		t.Heights[sx] = make([]float64, h)
+		for sy := 0; sy < h; sy++ {
+			x := float64(region.Min.X) + (float64(sx)+0.5)/float64(resolution)
+			y := float64(region.Min.Y) + (float64(sy)+0.5)/float64(resolution)
+			height, clr := fn(x, y)
+			t.Heights[sx][sy] = height
+			t.Colors.SetRGBA(sx, sy, clr)
+		}
+	}
+
+	return t
+}
+
+// Contains returns true if the grid position is inside the terrain region
+func (t *Terrain) Contains(x, y float64) bool {
+	return x >= float64(t.Region.Min.X) && y >= float64(t.Region.Min.Y) &&
+		x < float64(t.Region.Max.X) && y < float64(t.Region.Max.Y)
+}
+
+// Sample returns the sample indices at a grid position, false if it is outside the region
+func (t *Terrain) Sample(x, y float64) (int, int, bool) {
+	if !t.Contains(x, y) {
+		return 0, 0, false
+	}
+	sx := int((x - float64(t.Region.Min.X)) * float64(t.Resolution))
+	sy := int((y - float64(t.Region.Min.Y)) * float64(t.Resolution))
+	return sx, sy, true
+}
+
+// HeightAt returns the ground height in level units at the grid position, 0 outside the region
+func (t *Terrain) HeightAt(x, y float64) float64 {
+	sx, sy, ok := t.Sample(x, y)
+	if !ok {
+		return 0
+	}
+	return t.Heights[sx][sy]
+}
+
+// AddTerrain adds a heightmap region to the map
+func (m *Map) AddTerrain(t *Terrain) {
+	m.terrain = append(m.terrain, t)
+}
+
+// GetTerrain returns the heightmap regions in the map
+func (m *Map) GetTerrain() []*Terrain {
+	return m.terrain
+}
+
+// TerrainAt returns the terrain covering the grid position, or nil if the position uses the grid raycaster
+func (m *Map) TerrainAt(x, y float64) *Terrain {
+	for _, t := range m.terrain {
+		if t.Contains(x, y) {
+			return t
+		}
+	}
+	return nil
+}
@@ -0,0 +1,21 @@
+package sim
+
+import "image"
+
+// Textures gives the images sprites are drawn with by texture number, the raycaster TextureHandler being one.
+// Maps can be made without textures (nil) for simulations, their sprites have no images.
+type Textures interface {
+	// GetImage returns the image of a texture number, nil if there is none
+	GetImage(texNum int) image.Image
+
+	// GetNumTextures returns the number of texture numbers, with or without an image
+	GetNumTextures() int
+}
+
+// texture returns the image of a texture number from the map textures, nil without textures
+func (m *Map) texture(texNum int) image.Image {
+	if m.tex == nil {
+		return nil
+	}
+	return m.tex.GetImage(texNum)
+}
@@ -0,0 +1,74 @@
+package sim
+
+// TileType describes special behavior for grid cells that reference it from the map tile grid
+type TileType struct {
+	// Water --cell is filled with swimmable water--//
+	Water bool
+
+	// WaterLevel --height of the water surface in level units (1.0 = top of the first wall level)--//
+	WaterLevel float64
+
+	// Ladder --wall cell can be climbed by walking into it while facing it--//
+	Ladder bool
+
+	// DamagePerSecond --damage dealt while standing on the floor of the cell (lava, acid)--//
+	DamagePerSecond float64
+
+	// Conveyor --movement per tick applied while standing on the floor of the cell (moving walkways)--//
+	Conveyor *Vector2
+
+	// Wind --acceleration per tick applied to anything in the cell, grounded or not--//
+	Wind *Vector2
+
+	// Trigger --game defined id passed along when the camera enters the cell--//
+	Trigger string
+
+	// Checkpoint --entering the cell saves a checkpoint to respawn at--//
+	Checkpoint bool
+
+	// Effect --status effect applied to the camera when it enters the cell--//
+	Effect *StatusEffect
+
+	// Light --extra light on the floor of the cell from 0 (none) to 1 (full)--//
+	Light float64
+
+	// Skylight --opening in the ceiling that casts a soft edged shaft of light on the floor below--//
+	Skylight bool
+
+	// Material --what the floor of the cell is made of, for footstep sounds--//
+	Material Material
+
+	// Secret --cell is a secret area, counted in the end of level stats when first entered--//
+	Secret bool
+
+	// Ceiling --1 based index into the floor textures of the ceiling over the cell, 0 uses the map ceiling--//
+	Ceiling int
+}
+
+// RegisterTileType associates a tile type with the id used in the map tile grid
+func (m *Map) RegisterTileType(id int, t *TileType) {
+	if m.tileTypes == nil {
+		m.tileTypes = make(map[int]*TileType)
+	}
+	m.tileTypes[id] = t
+}
+
+// SetTileType sets the tile type id of the grid cell at x, y
+func (m *Map) SetTileType(x, y, id int) {
+	if !m.inBounds(x, y) {
+		return
+	}
+	m.tileMap[x][y] = id
+}
+
+// GetTileType returns the tile type of the grid cell at x, y, or nil if it has no special behavior
+func (m *Map) GetTileType(x, y int) *TileType {
+	if !m.inBounds(x, y) {
+		return nil
+	}
+	return m.tileTypes[m.tileMap[x][y]]
+}
+
+func (m *Map) inBounds(x, y int) bool {
+	return x >= 0 && y >= 0 && x < len(m.tileMap) && y < len(m.tileMap[x])
+}
@@ -0,0 +1,136 @@
+package sim
+
+import "math"
+
+// EaseFunc maps linear progress from 0 to 1 onto an eased curve
+type EaseFunc func(t float64) float64
+
+// Linear progresses at a constant rate
+func Linear(t float64) float64 { return t }
+
+// EaseInQuad starts slow and accelerates
+func EaseInQuad(t float64) float64 { return t * t }
+
+// EaseOutQuad starts fast and decelerates
+func EaseOutQuad(t float64) float64 { return t * (2 - t) }
+
+// EaseInOutQuad accelerates through the first half and decelerates through the second
+func EaseInOutQuad(t float64) float64 {
+	if t < 0.5 {
+		return 2 * t * t
+	}
+	return -1 + (4-2*t)*t
+}
+
+// EaseInCubic starts slower than EaseInQuad and accelerates harder
+func EaseInCubic(t float64) float64 { return t * t * t }
+
+// EaseOutCubic starts faster than EaseOutQuad and decelerates harder
+func EaseOutCubic(t float64) float64 {
+	t--
+	return t*t*t + 1
+}
+
+// EaseInOutSine follows half a cosine wave, gentle at both ends
+func EaseInOutSine(t float64) float64 { return -(math.Cos(math.Pi*t) - 1) / 2 }
+
+// EaseOutBack overshoots the target slightly before settling
+func EaseOutBack(t float64) float64 {
+	const c1 = 1.70158
+	const c3 = c1 + 1
+	return 1 + c3*math.Pow(t-1, 3) + c1*math.Pow(t-1, 2)
+}
+
+// Tween interpolates a value over time along an easing curve, and can be chained with further tweens
+type Tween struct {
+	duration float64
+	elapsed  float64
+	ease     EaseFunc
+
+	start    func()
+	apply    func(t float64)
+	started  bool
+	complete func()
+
+	next *Tween
+}
+
+// NewTween creates a tween calling apply with the eased progress from 0 to 1 every update
+func NewTween(seconds float64, ease EaseFunc, apply func(t float64)) *Tween {
+	if ease == nil {
+		ease = Linear
+	}
+	return &Tween{duration: seconds, ease: ease, apply: apply}
+}
+
+// TweenFloat creates a tween moving the float from its value when the tween starts to the target value
+func TweenFloat(value *float64, to, seconds float64, ease EaseFunc) *Tween {
+	var from float64
+	t := NewTween(seconds, ease, func(t float64) {
+		*value = from + (to-from)*t
+	})
+	t.start = func() { from = *value }
+	return t
+}
+
+// TweenVector creates a tween moving the vector from its value when the tween starts to the target value
+func TweenVector(value *Vector2, to Vector2, seconds float64, ease EaseFunc) *Tween {
+	var from Vector2
+	t := NewTween(seconds, ease, func(t float64) {
+		value.X = from.X + (to.X-from.X)*t
+		value.Y = from.Y + (to.Y-from.Y)*t
+	})
+	t.start = func() { from = *value }
+	return t
+}
+
+// Then chains a tween to start when this one completes, returns the chained tween so calls can be strung together
+func (t *Tween) Then(next *Tween) *Tween {
+	last := t
+	for last.next != nil {
+		last = last.next
+	}
+	last.next = next
+	return next
+}
+
+// OnComplete sets a function called when this tween (not the rest of its chain) completes
+func (t *Tween) OnComplete(fn func()) *Tween {
+	t.complete = fn
+	return t
+}
+
+// Update advances the tween by dt seconds, carrying leftover time into chained tweens.
+// Returns the tween in the chain now running, or nil once the whole chain has completed.
+func (t *Tween) Update(dt float64) *Tween {
+	current := t
+	for current != nil {
+		if !current.started {
+			current.started = true
+			if current.start != nil {
+				current.start()
+			}
+		}
+
+		current.elapsed += dt
+		if current.elapsed < current.duration {
+			current.apply(current.ease(current.elapsed / current.duration))
+			return current
+		}
+
+		current.apply(current.ease(1))
+		if current.complete != nil {
+			current.complete()
+		}
+
+		dt = current.elapsed - current.duration
+		current = current.next
+	}
+	return nil
+}
+
+// Tween runs a tween chain on the clock, so it follows pause and time scale
+func (c *Clock) Tween(t *Tween) *Tween {
+	c.tweens = append(c.tweens, t)
+	return t
+}
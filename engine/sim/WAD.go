@@ -0,0 +1,170 @@
+package sim
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"strings"
+)
+
+const (
+	// sizes of the WAD map lump records
+	wadVertexSize  = 4
+	wadLinedefSize = 14
+	wadSidedefSize = 30
+	wadThingSize   = 10
+
+	// linedef side with no sidedef
+	wadNoSide = 0xFFFF
+
+	// thing type of the player 1 start
+	wadPlayerStart = 1
+)
+
+// wadLump is one entry of a WAD directory
+type wadLump struct {
+	name string
+	data []byte
+}
+
+// LoadWADMap imports the geometry of a Doom style WAD level (E1M1, MAP01, ...) flattened to a grid, each
+// cell covering cellSize map units. One sided lines become ground level walls textured by the name of their
+// middle texture, height differences and two sided lines are ignored. Things with a sprite in the texture
+// table become sprites and the player 1 start is returned as the spawn.
+func LoadWADMap(tex Textures, wad []byte, mapName string, cellSize float64, textures *ImportTextures) (*Map, *Spawn, error) {
+	lumps, err := readWADLumps(wad)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	//--the map lumps follow the marker named after the map--//
+	mapName = strings.ToUpper(mapName)
+	named := make(map[string][]byte)
+	for i, lump := range lumps {
+		if lump.name != mapName {
+			continue
+		}
+		for _, l := range lumps[i+1:] {
+			switch l.name {
+			case "THINGS", "LINEDEFS", "SIDEDEFS", "VERTEXES":
+				named[l.name] = l.data
+				continue
+			case "SEGS", "SSECTORS", "NODES", "SECTORS", "REJECT", "BLOCKMAP":
+				continue
+			}
+			break
+		}
+		break
+	}
+	if named["VERTEXES"] == nil || named["LINEDEFS"] == nil {
+		return nil, nil, fmt.Errorf("wad: no map %s", mapName)
+	}
+	if cellSize <= 0 {
+		cellSize = 64
+	}
+
+	//--vertices, with y flipped so north is -y like the engine--//
+	vdata := named["VERTEXES"]
+	verts := make([]Vector2, len(vdata)/wadVertexSize)
+	minX, minY := math.MaxFloat64, math.MaxFloat64
+	maxX, maxY := -math.MaxFloat64, -math.MaxFloat64
+	for i := range verts {
+		x := float64(int16(binary.LittleEndian.Uint16(vdata[i*wadVertexSize:])))
+		y := -float64(int16(binary.LittleEndian.Uint16(vdata[i*wadVertexSize+2:])))
+		verts[i] = Vector2{X: x, Y: y}
+		minX, minY = math.Min(minX, x), math.Min(minY, y)
+		maxX, maxY = math.Max(maxX, x), math.Max(maxY, y)
+	}
+	if len(verts) == 0 {
+		return nil, nil, fmt.Errorf("wad: map %s has no vertices", mapName)
+	}
+
+	//--one cell of border so walls on the edge of the map stay inside the grid--//
+	toGrid := func(p Vector2) Vector2 {
+		return Vector2{X: (p.X-minX)/cellSize + 1, Y: (p.Y-minY)/cellSize + 1}
+	}
+	width := int(math.Ceil((maxX-minX)/cellSize)) + 2
+	height := int(math.Ceil((maxY-minY)/cellSize)) + 2
+	grid := makeGrid(width, height)
+
+	sdata := named["SIDEDEFS"]
+	ldata := named["LINEDEFS"]
+	for i := 0; i+wadLinedefSize <= len(ldata); i += wadLinedefSize {
+		v1 := int(binary.LittleEndian.Uint16(ldata[i:]))
+		v2 := int(binary.LittleEndian.Uint16(ldata[i+2:]))
+		right := int(binary.LittleEndian.Uint16(ldata[i+10:]))
+		left := int(binary.LittleEndian.Uint16(ldata[i+12:]))
+		if left != wadNoSide || v1 >= len(verts) || v2 >= len(verts) {
+			continue
+		}
+
+		texNum := textures.defaultWall()
+		if right != wadNoSide && (right+1)*wadSidedefSize <= len(sdata) {
+			texNum = textures.named(wadName(sdata[right*wadSidedefSize+20 : right*wadSidedefSize+28]))
+		}
+
+		//--mark every cell the line passes through--//
+		a, b := toGrid(verts[v1]), toGrid(verts[v2])
+		steps := int(math.Ceil(math.Max(math.Abs(b.X-a.X), math.Abs(b.Y-a.Y))*4)) + 1
+		for s := 0; s <= steps; s++ {
+			t := float64(s) / float64(steps)
+			x := int(a.X + (b.X-a.X)*t)
+			y := int(a.Y + (b.Y-a.Y)*t)
+			if x >= 0 && x < width && y >= 0 && y < height {
+				grid[x][y] = texNum
+			}
+		}
+	}
+
+	var sprites []*Sprite
+	spawn := &Spawn{Pos: Vector2{X: float64(width) / 2, Y: float64(height) / 2}, Dir: Vector2{X: 1, Y: 0}}
+	tdata := named["THINGS"]
+	for i := 0; i+wadThingSize <= len(tdata); i += wadThingSize {
+		x := float64(int16(binary.LittleEndian.Uint16(tdata[i:])))
+		y := -float64(int16(binary.LittleEndian.Uint16(tdata[i+2:])))
+		angle := float64(int16(binary.LittleEndian.Uint16(tdata[i+4:]))) * math.Pi / 180
+		thing := int(binary.LittleEndian.Uint16(tdata[i+6:]))
+		pos := toGrid(Vector2{X: x, Y: y})
+
+		if thing == wadPlayerStart {
+			spawn = &Spawn{Pos: pos, Dir: Vector2{X: math.Cos(angle), Y: -math.Sin(angle)}}
+		} else if img := textures.Objects[thing]; img != nil {
+			sprites = append(sprites, NewSprite(pos.X, pos.Y, img))
+		}
+	}
+
+	return importedMap(tex, grid, sprites), spawn, nil
+}
+
+// readWADLumps reads the directory of an IWAD or PWAD file
+func readWADLumps(wad []byte) ([]wadLump, error) {
+	if len(wad) < 12 || (string(wad[:4]) != "IWAD" && string(wad[:4]) != "PWAD") {
+		return nil, fmt.Errorf("wad: not a WAD file")
+	}
+	count := int(binary.LittleEndian.Uint32(wad[4:]))
+	dir := int(binary.LittleEndian.Uint32(wad[8:]))
+	if dir < 0 || count < 0 || dir+count*16 > len(wad) {
+		return nil, fmt.Errorf("wad: directory out of range")
+	}
+
+	lumps := make([]wadLump, count)
+	for i := range lumps {
+		entry := wad[dir+i*16:]
+		pos := int(binary.LittleEndian.Uint32(entry))
+		size := int(binary.LittleEndian.Uint32(entry[4:]))
+		if pos < 0 || size < 0 || pos+size > len(wad) {
+			return nil, fmt.Errorf("wad: lump %d out of range", i)
+		}
+		lumps[i] = wadLump{name: wadName(entry[8:16]), data: wad[pos : pos+size]}
+	}
+	return lumps, nil
+}
+
+// wadName returns an 8 byte, zero padded WAD name as an upper case string
+func wadName(b []byte) string {
+	if i := bytes.IndexByte(b, 0); i >= 0 {
+		b = b[:i]
+	}
+	return strings.ToUpper(string(b))
+}
@@ -0,0 +1,99 @@
+package sim
+
+import "math"
+
+const (
+	// movement speed multiplier while wading or swimming
+	waterSpeedFactor = 0.5
+
+	// strength of the pull towards floating at the water surface
+	buoyancy = 0.02
+
+	// damping applied to vertical velocity each tick at the movement target framerate while in water
+	waterDrag = 0.85
+
+	// how far above the water surface the eye floats when at rest
+	surfaceOffset = 0.05
+
+	// air meter change per tick at the movement target framerate
+	airDrainRate  = 1.0 / (20.0 * movementTPS)
+	airRefillRate = 1.0 / (2.0 * movementTPS)
+)
+
+// waterTile returns the water tile type at the camera position, or nil if not in water
+func (c *Camera) waterTile() *TileType {
+	t := c.mapObj.GetTileType(int(c.pos.X), int(c.pos.Y))
+	if t == nil || !t.Water {
+		return nil
+	}
+	return t
+}
+
+// InWater returns true if the camera is wading or swimming
+func (c *Camera) InWater() bool {
+	t := c.waterTile()
+	return t != nil && c.posZ < t.WaterLevel
+}
+
+// IsUnderwater returns true if the camera eye is below the water surface
+func (c *Camera) IsUnderwater() bool {
+	t := c.waterTile()
+	return t != nil && c.posZ+EyeHeight < t.WaterLevel
+}
+
+// GetAir returns the air meter value from 0 (empty) to 1 (full)
+func (c *Camera) GetAir() float64 {
+	return c.air
+}
+
+// Swim pushes the camera up (positive) or down (negative) while in water
+func (c *Camera) Swim(zSpeed float64) {
+	if !c.InWater() {
+		return
+	}
+	c.velZ += c.getNormalSpeed(zSpeed)
+}
+
+// applyBuoyancy replaces gravity while in water, floating the eye towards the surface
+func (c *Camera) applyBuoyancy(t *TileType) {
+	target := t.WaterLevel - EyeHeight + surfaceOffset
+	c.velZ += c.getNormalSpeed((target - c.posZ) * buoyancy)
+	c.velZ *= math.Pow(waterDrag, c.dt*movementTPS)
+}
+
+// updateAir drains or refills the air meter and fires the related events
+func (c *Camera) updateAir() {
+	underwater := c.IsUnderwater()
+	if underwater != c.submerged {
+		c.submerged = underwater
+		if underwater && c.Events.OnSubmerge != nil {
+			c.Events.OnSubmerge()
+		} else if !underwater && c.Events.OnSurface != nil {
+			c.Events.OnSurface()
+		}
+	}
+
+	air := c.air
+	if underwater && !c.invulnerable {
+		air -= c.getNormalSpeed(airDrainRate)
+	} else {
+		air += c.getNormalSpeed(airRefillRate)
+	}
+	if air < 0 {
+		air = 0
+	} else if air > 1 {
+		air = 1
+	}
+
+	if air == c.air {
+		return
+	}
+	c.air = air
+
+	if c.Events.OnAirChange != nil {
+		c.Events.OnAirChange(c.air)
+	}
+	if c.air == 0 && c.Events.OnAirDepleted != nil {
+		c.Events.OnAirDepleted()
+	}
+}
@@ -0,0 +1,84 @@
+package sim
+
+import (
+	"image/color"
+	"math"
+)
+
+// Waypoint is a world position marked on the compass and on screen to guide the player
+type Waypoint struct {
+	X, Y float64
+
+	// Z --height of the marker above the floor, in level units--//
+	Z float64
+
+	// Label --short text shown next to the marker--//
+	Label string
+
+	// Color --color of the marker--//
+	Color color.RGBA
+
+	// Objective --id of the objective the waypoint leads to, it is hidden once that objective is complete--//
+	Objective string
+}
+
+// NewWaypoint creates a yellow waypoint marker at the grid position and height
+func NewWaypoint(x, y, z float64, label string) *Waypoint {
+	return &Waypoint{X: x, Y: y, Z: z, Label: label, Color: color.RGBA{255, 220, 60, 255}}
+}
+
+// AddWaypoint adds a waypoint marker to the map
+func (m *Map) AddWaypoint(w *Waypoint) {
+	m.waypoints = append(m.waypoints, w)
+}
+
+// RemoveWaypoint removes a waypoint marker from the map
+func (m *Map) RemoveWaypoint(w *Waypoint) {
+	for i, existing := range m.waypoints {
+		if existing == w {
+			m.waypoints = append(m.waypoints[:i], m.waypoints[i+1:]...)
+			return
+		}
+	}
+}
+
+// GetWaypoints returns all waypoint markers in the map
+func (m *Map) GetWaypoints() []*Waypoint {
+	return m.waypoints
+}
+
+// GetActiveWaypoints returns the waypoints that should be shown, leaving out those whose objective is complete
+func (m *Map) GetActiveWaypoints() []*Waypoint {
+	active := make([]*Waypoint, 0, len(m.waypoints))
+	for _, w := range m.waypoints {
+		if w.Objective != "" && m.objectives != nil && m.objectives.IsComplete(w.Objective) {
+			continue
+		}
+		active = append(active, w)
+	}
+	return active
+}
+
+// RelativeAngle returns the angle in radians from the view direction to the world direction dx, dy,
+// positive towards the right side of the screen and in the range -Pi to Pi
+func (c *Camera) RelativeAngle(dx, dy float64) float64 {
+	dirLen := c.dirLength()
+	planeLen := math.Hypot(c.plane.X, c.plane.Y)
+	if dirLen == 0 || planeLen == 0 {
+		return 0
+	}
+
+	forward := (dx*c.dir.X + dy*c.dir.Y) / dirLen
+	right := (dx*c.plane.X + dy*c.plane.Y) / planeLen
+	return math.Atan2(right, forward)
+}
+
+// AngleTo returns the angle in radians from the view direction to the grid position, positive to the right
+func (c *Camera) AngleTo(x, y float64) float64 {
+	return c.RelativeAngle(x-c.pos.X, y-c.pos.Y)
+}
+
+// DistanceTo returns the distance from the camera to the grid position
+func (c *Camera) DistanceTo(x, y float64) float64 {
+	return math.Hypot(x-c.pos.X, y-c.pos.Y)
+}
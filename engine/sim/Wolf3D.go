@@ -0,0 +1,176 @@
+package sim
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+const (
+	// Carmack compression pointer tags
+	carmackNear = 0xA7
+	carmackFar  = 0xA8
+
+	// Wolf3D plane 0 tile ranges
+	wolfLastWall  = 63
+	wolfFirstDoor = 90
+	wolfLastDoor  = 101
+
+	// Wolf3D plane 1 player starts, facing north, east, south and west
+	wolfStartNorth = 19
+	wolfStartWest  = 22
+
+	// Wolf3D plane 1 static objects (lamps, tables, barrels, ...)
+	wolfFirstObject = 23
+	wolfLastObject  = 70
+)
+
+// LoadWolf3DMap imports a level from the MAPHEAD and GAMEMAPS files of Wolfenstein 3D (or a compatible game).
+// Walls become the ground level of the map using the texture table, doors use the door texture, static
+// objects with a sprite in the table become sprites and the player start is returned as the spawn.
+func LoadWolf3DMap(tex Textures, mapHead, gameMaps []byte, level int, textures *ImportTextures) (*Map, *Spawn, error) {
+	if len(mapHead) < 2+4*(level+1) || level < 0 {
+		return nil, nil, fmt.Errorf("wolf3d: no level %d in map header", level)
+	}
+	rlewTag := binary.LittleEndian.Uint16(mapHead)
+	offset := int(binary.LittleEndian.Uint32(mapHead[2+4*level:]))
+	if offset <= 0 || offset+38 > len(gameMaps) {
+		return nil, nil, fmt.Errorf("wolf3d: level %d is empty", level)
+	}
+
+	//--level header: plane offsets, plane lengths, size and name--//
+	header := gameMaps[offset:]
+	width := int(binary.LittleEndian.Uint16(header[18:]))
+	height := int(binary.LittleEndian.Uint16(header[20:]))
+
+	planes := make([][]uint16, 2)
+	for i := range planes {
+		start := int(binary.LittleEndian.Uint32(header[4*i:]))
+		length := int(binary.LittleEndian.Uint16(header[12+2*i:]))
+		if start < 0 || start+length > len(gameMaps) {
+			return nil, nil, fmt.Errorf("wolf3d: level %d plane %d is out of range", level, i)
+		}
+
+		carmacked, err := carmackExpand(gameMaps[start : start+length])
+		if err != nil {
+			return nil, nil, fmt.Errorf("wolf3d: level %d plane %d: %v", level, i, err)
+		}
+		plane := rlewExpand(carmacked, rlewTag)
+		if len(plane) < width*height {
+			return nil, nil, fmt.Errorf("wolf3d: level %d plane %d is %d tiles, expected %d", level, i, len(plane), width*height)
+		}
+		planes[i] = plane
+	}
+
+	grid := makeGrid(width, height)
+	var sprites []*Sprite
+	spawn := &Spawn{Pos: Vector2{X: float64(width) / 2, Y: float64(height) / 2}, Dir: Vector2{X: 0, Y: -1}}
+
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			tile := int(planes[0][y*width+x])
+			switch {
+			case tile >= 1 && tile <= wolfLastWall:
+				grid[x][y] = textures.wall(tile)
+			case tile >= wolfFirstDoor && tile <= wolfLastDoor:
+				grid[x][y] = textures.Door
+			}
+
+			object := int(planes[1][y*width+x])
+			pos := Vector2{X: float64(x) + 0.5, Y: float64(y) + 0.5}
+			switch {
+			case object >= wolfStartNorth && object <= wolfStartWest:
+				//--north, east, south, west with north being -y--//
+				dirs := []Vector2{{X: 0, Y: -1}, {X: 1, Y: 0}, {X: 0, Y: 1}, {X: -1, Y: 0}}
+				spawn = &Spawn{Pos: pos, Dir: dirs[object-wolfStartNorth]}
+			case object >= wolfFirstObject && object <= wolfLastObject:
+				if img := textures.Objects[object]; img != nil {
+					sprites = append(sprites, NewSprite(pos.X, pos.Y, img))
+				}
+			}
+		}
+	}
+
+	return importedMap(tex, grid, sprites), spawn, nil
+}
+
+// carmackExpand decompresses Carmack compressed words, the first word is the expanded length in bytes
+func carmackExpand(src []byte) ([]uint16, error) {
+	if len(src) < 2 {
+		return nil, fmt.Errorf("carmack data too short")
+	}
+	length := int(binary.LittleEndian.Uint16(src)) / 2
+	out := make([]uint16, 0, length)
+	i := 2
+
+	for len(out) < length {
+		if i+2 > len(src) {
+			return nil, fmt.Errorf("carmack data ends early")
+		}
+		word := binary.LittleEndian.Uint16(src[i:])
+		i += 2
+
+		count, tag := int(word&0xFF), word>>8
+		if tag != carmackNear && tag != carmackFar {
+			out = append(out, word)
+			continue
+		}
+
+		if count == 0 {
+			//--an escaped word that happens to look like a pointer, the next byte is its low byte--//
+			if i >= len(src) {
+				return nil, fmt.Errorf("carmack data ends early")
+			}
+			out = append(out, tag<<8|uint16(src[i]))
+			i++
+			continue
+		}
+
+		var from int
+		if tag == carmackNear {
+			if i >= len(src) {
+				return nil, fmt.Errorf("carmack data ends early")
+			}
+			from = len(out) - int(src[i])
+			i++
+		} else {
+			if i+2 > len(src) {
+				return nil, fmt.Errorf("carmack data ends early")
+			}
+			from = int(binary.LittleEndian.Uint16(src[i:]))
+			i += 2
+		}
+		if from < 0 || from >= len(out) {
+			return nil, fmt.Errorf("carmack pointer out of range")
+		}
+		//--word by word, a copy may run into the words it is writing and repeat them--//
+		for n := 0; n < count; n++ {
+			out = append(out, out[from+n])
+		}
+	}
+
+	return out, nil
+}
+
+// rlewExpand decompresses run length encoded words, the first word is the expanded length in bytes
+// and a tag word is followed by a count and the word to repeat
+func rlewExpand(src []uint16, tag uint16) []uint16 {
+	if len(src) == 0 {
+		return nil
+	}
+	length := int(src[0]) / 2
+	out := make([]uint16, 0, length)
+
+	for i := 1; i < len(src) && len(out) < length; i++ {
+		if src[i] != tag || i+2 >= len(src) {
+			out = append(out, src[i])
+			continue
+		}
+		count, value := int(src[i+1]), src[i+2]
+		for n := 0; n < count; n++ {
+			out = append(out, value)
+		}
+		i += 2
+	}
+
+	return out
+}
@@ -0,0 +1,63 @@
+package sim
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCarmackExpand(t *testing.T) {
+	tests := []struct {
+		name string
+		src  []byte
+		want []uint16
+	}{
+		{
+			name: "plain words",
+			src:  []byte{0x04, 0x00, 0x34, 0x12, 0x78, 0x56},
+			want: []uint16{0x1234, 0x5678},
+		},
+		{
+			name: "escaped pointer tag",
+			src:  []byte{0x02, 0x00, 0x00, 0xA7, 0x42},
+			want: []uint16{0xA742},
+		},
+		{
+			name: "near copy",
+			src:  []byte{0x08, 0x00, 0x34, 0x12, 0x78, 0x56, 0x02, 0xA7, 0x02},
+			want: []uint16{0x1234, 0x5678, 0x1234, 0x5678},
+		},
+		{
+			name: "overlapping near copy",
+			src:  []byte{0x0A, 0x00, 0x34, 0x12, 0x04, 0xA7, 0x01},
+			want: []uint16{0x1234, 0x1234, 0x1234, 0x1234, 0x1234},
+		},
+		{
+			name: "overlapping far copy",
+			src:  []byte{0x0C, 0x00, 0x34, 0x12, 0x78, 0x56, 0x04, 0xA8, 0x00, 0x00},
+			want: []uint16{0x1234, 0x5678, 0x1234, 0x5678, 0x1234, 0x5678},
+		},
+	}
+
+	for _, tt := range tests {
+		got, err := carmackExpand(tt.src)
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: got %04x, want %04x", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestCarmackExpandBadPointer(t *testing.T) {
+	for _, src := range [][]byte{
+		{0x04, 0x00, 0x34, 0x12, 0x01, 0xA8, 0x01, 0x00},
+		{0x04, 0x00, 0x34, 0x12, 0x01, 0xA7, 0x02},
+		{0x04, 0x00, 0x34, 0x12, 0x01, 0xA8},
+	} {
+		if out, err := carmackExpand(src); err == nil {
+			t.Errorf("% x: expanded to %04x, want an error", src, out)
+		}
+	}
+}
@@ -0,0 +1,44 @@
+package sim
+
+import "math"
+
+// Zoom eases the field of view to scale times the base over the given seconds, scale below 1 zooms in.
+// Zooming to 1 eases back out to the base field of view.
+func (c *Camera) Zoom(scale, seconds float64) {
+	if scale <= 0 {
+		return
+	}
+	if scale != 1 {
+		c.zoomScale = scale
+	}
+	c.zoomKick = c.holdKick(c.zoomKick, scale, seconds, scale != 1)
+}
+
+// GetZoom returns the field of view scale currently applied by zoom, 1 when not zoomed
+func (c *Camera) GetZoom() float64 {
+	if c.zoomKick == nil {
+		return 1
+	}
+	return c.zoomKick.scale
+}
+
+// IsZoomed returns true if the camera is zoomed in or on its way in
+func (c *Camera) IsZoomed() bool {
+	return c.zoomKick != nil && c.zoomKick.hold
+}
+
+// GetZoomProgress returns how far the view is zoomed towards the last zoom scale, from 0 (base view) to 1
+func (c *Camera) GetZoomProgress() float64 {
+	if c.zoomKick == nil || c.zoomScale == 1 {
+		return 0
+	}
+	return math.Max(0, math.Min(1, (1-c.zoomKick.scale)/(1-c.zoomScale)))
+}
+
+// zoomTurnScale returns how much turning is slowed by the current zoom
+func (c *Camera) zoomTurnScale() float64 {
+	if !c.ZoomScalesTurning {
+		return 1
+	}
+	return c.GetZoom()
+}
@@ -1,13 +1,39 @@
 package main
 
 import (
+	"flag"
 	"fmt"
+	"log"
+	"path/filepath"
 	"runtime"
 
 	"raycaster-go/engine"
 )
 
 func main() {
+	crashDir := flag.String("crashdir", "", "record input and write a replay here if the game crashes")
+	replayFile := flag.String("replay", "", "play back a crash replay")
+	captureDir := flag.String("capture", "", "write every rendered frame as a png here")
+	warpShot := flag.String("warp", "", "start from the camera pose stored in a screenshot")
+	quality := flag.String("quality", "auto", "quality preset: low, medium, high or auto to pick one for the platform")
+	lang := flag.String("lang", engine.DefaultLanguage, "language of the engine text, from engine/content/lang")
+	profile := flag.String("profile", "", "load and save player progress under this profile name")
+	title := flag.Bool("title", false, "start at the title menu")
+	mapFile := flag.String("map", "", "load a map JSON file behind the loading screen")
+	serve := flag.String("serve", "", "stream the game to spectators connecting to this address")
+	spectate := flag.String("spectate", "", "watch the game streamed from this address instead of playing")
+	coopHost := flag.String("coop-host", "", "wait for a co-op partner on this address and play lockstep with them")
+	coopJoin := flag.String("coop-join", "", "join the lockstep co-op game hosted at this address")
+	bench := flag.Bool("bench", false, "fly through a generated stress scene and report frame times")
+	benchOpts := engine.DefaultBenchmarkOptions()
+	flag.IntVar(&benchOpts.MapSize, "bench-size", benchOpts.MapSize, "benchmark map width and height in cells")
+	flag.IntVar(&benchOpts.Sprites, "bench-sprites", benchOpts.Sprites, "benchmark sprite count")
+	flag.IntVar(&benchOpts.Lights, "bench-lights", benchOpts.Lights, "benchmark light count")
+	flag.IntVar(&benchOpts.Width, "bench-width", benchOpts.Width, "benchmark render width, 0 keeps the default")
+	flag.IntVar(&benchOpts.Height, "bench-height", benchOpts.Height, "benchmark render height, 0 keeps the default")
+	flag.Float64Var(&benchOpts.Seconds, "bench-seconds", benchOpts.Seconds, "benchmark flythrough length in seconds")
+	flag.Parse()
+
 	numCPU := runtime.NumCPU()
 	// only way to see maxprocs is to set it and see the return value, then set it back
 	maxProcs := runtime.GOMAXPROCS(numCPU)
@@ -16,5 +42,71 @@ func main() {
 
 	// run the game
 	g := engine.NewGame()
+	g.SetCrashCapture(*crashDir)
+	if err := g.SetFrameCapture(*captureDir); err != nil {
+		log.Fatal(err)
+	}
+	preset, err := engine.ParseQuality(*quality)
+	if err != nil {
+		log.Fatal(err)
+	}
+	g.SetQuality(preset)
+	if err := g.SetLanguage(*lang); err != nil {
+		log.Fatal(err)
+	}
+	if *profile != "" {
+		storage, err := engine.NewProfileStorage("raycaster-go")
+		if err != nil {
+			log.Fatal(err)
+		}
+		p, err := engine.LoadProfile(storage, *profile)
+		if err != nil {
+			log.Fatal(err)
+		}
+		g.SetProfile(p)
+	}
+	if *mapFile != "" {
+		g.LoadMap(filepath.Base(*mapFile), *mapFile)
+	}
+	if *title {
+		g.OpenMenu(g.NewTitleMenu())
+	}
+	if *bench {
+		g.StartBenchmark(benchOpts)
+	}
+	if *warpShot != "" {
+		if err := g.WarpToScreenshot(*warpShot); err != nil {
+			log.Fatal(err)
+		}
+	}
+	if *replayFile != "" {
+		r, err := engine.LoadReplay(*replayFile)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if err := g.PlayReplay(r); err != nil {
+			log.Fatal(err)
+		}
+	}
+	if *serve != "" {
+		if err := g.ServeSpectators(*serve); err != nil {
+			log.Fatal(err)
+		}
+	}
+	if *spectate != "" {
+		if err := g.Spectate(*spectate); err != nil {
+			log.Fatal(err)
+		}
+	}
+	if *coopHost != "" {
+		if err := g.HostLockstep(*coopHost); err != nil {
+			log.Fatal(err)
+		}
+	}
+	if *coopJoin != "" {
+		if err := g.JoinLockstep(*coopJoin); err != nil {
+			log.Fatal(err)
+		}
+	}
 	g.Run()
 }